@@ -41,6 +41,7 @@ const (
 	LangGo Lang = iota
 	LangJava
 	LangObjC
+	LangGoState // Go bindings calling the contract against a state database
 )
 
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
@@ -94,7 +95,8 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalizedName := methodNormalizer[lang](alias(aliases, original.Name))
 			// Ensure there is no duplicated identifier
 			var identifiers = callIdentifiers
-			if !original.IsConstant() {
+			// State bindings have the calls and transacts on the same type.
+			if !original.IsConstant() && lang != LangGoState {
 				identifiers = transactIdentifiers
 			}
 			if identifiers[normalizedName] {
@@ -222,13 +224,14 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
+		"stateMethod":   stateMethod,
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
 		return "", err
 	}
 	// For Go bindings pass the code through gofmt to clean it up
-	if lang == LangGo {
+	if lang == LangGo || lang == LangGoState {
 		code, err := format.Source(buffer.Bytes())
 		if err != nil {
 			return "", fmt.Errorf("%v\n%s", err, buffer)
@@ -242,8 +245,9 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindTypeGo,
-	LangJava: bindTypeJava,
+	LangGo:      bindTypeGo,
+	LangJava:    bindTypeJava,
+	LangGoState: bindTypeGo,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -364,8 +368,9 @@ func bindTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindTopicTypeGo,
-	LangJava: bindTopicTypeJava,
+	LangGo:      bindTopicTypeGo,
+	LangJava:    bindTopicTypeJava,
+	LangGoState: bindTopicTypeGo,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -405,8 +410,9 @@ func bindTopicTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindStructTypeGo,
-	LangJava: bindStructTypeJava,
+	LangGo:      bindStructTypeGo,
+	LangJava:    bindStructTypeJava,
+	LangGoState: bindStructTypeGo,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -488,8 +494,9 @@ func bindStructTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
-	LangGo:   func(string, abi.Type) string { panic("this shouldn't be needed") },
-	LangJava: namedTypeJava,
+	LangGo:      func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangJava:    namedTypeJava,
+	LangGoState: func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // namedTypeJava converts some primitive data types to named variants that can
@@ -530,8 +537,9 @@ func alias(aliases map[string]string, n string) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming conventions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo:   abi.ToCamelCase,
-	LangJava: decapitalise,
+	LangGo:      abi.ToCamelCase,
+	LangJava:    decapitalise,
+	LangGoState: abi.ToCamelCase,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
@@ -75,8 +75,9 @@ type tmplStruct struct {
 // tmplSource is language to template mapping containing all the supported
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
-	LangGo:   tmplSourceGo,
-	LangJava: tmplSourceJava,
+	LangGo:      tmplSourceGo,
+	LangJava:    tmplSourceJava,
+	LangGoState: tmplSourceGoState,
 }
 
 // tmplSourceGo is the Go source template that the generated Go contract binding
@@ -0,0 +1,156 @@
+package bind
+
+// tmplSourceGoState is the Go source template of the bindings calling the
+// contract directly against a state database, through the statebind package.
+const tmplSourceGoState = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/accounts/abi/statebind"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.ConvertType
+	_ = statebind.NewBoundContract
+	_ = common.Big1
+	_ = types.BloomLookup
+)
+
+{{$structs := .Structs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}ABI is the input ABI used to generate the binding from.
+	const {{.Type}}ABI = "{{.InputABI}}"
+
+	// {{.Type}} is an auto generated Go binding around an Ethereum contract, calling
+	// it directly against a state database.
+	type {{.Type}} struct {
+		contract *statebind.BoundContract // Generic contract wrapper for the low level calls
+	}
+
+	// New{{.Type}} creates a new instance of {{.Type}}, bound to a specific deployed contract.
+	func New{{.Type}}(address common.Address, caller statebind.StateCaller) (*{{.Type}}, error) {
+	  parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	  if err != nil {
+	    return nil, err
+	  }
+	  return &{{.Type}}{contract: statebind.NewBoundContract(address, parsed, caller)}, nil
+	}
+
+	// ABI returns the parsed ABI the binding was generated from.
+	func (_{{$contract.Type}} *{{$contract.Type}}) ABI() *abi.ABI {
+		return _{{$contract.Type}}.contract.ABI()
+	}
+
+	// Address returns the address of the bound contract.
+	func (_{{$contract.Type}} *{{$contract.Type}}) Address() common.Address {
+		return _{{$contract.Type}}.contract.Address()
+	}
+
+	// Call invokes the contract method with params as input values and sets the
+	// output to result.
+	func (_{{$contract.Type}} *{{$contract.Type}}) Call(statedb *state.StateDB, header *types.Header, result *[]interface{}, method string, params ...interface{}) error {
+		return _{{$contract.Type}}.contract.Call(statedb, header, result, method, params...)
+	}
+
+	{{range .Calls}}
+		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		{{template "stateMethod" (stateMethod $contract . $structs)}}
+	{{end}}
+
+	{{range .Transacts}}
+		// {{.Normalized.Name}} is a mutator call binding the contract method 0x{{printf "%x" .Original.ID}},
+		// its changes are applied to the state database.
+		//
+		// Solidity: {{.Original.String}}
+		{{template "stateMethod" (stateMethod $contract . $structs)}}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}; {{end}}
+			Raw types.Log // Blockchain specific contextual infos
+		}
+
+		// {{$contract.Type}}{{.Normalized.Name}}ID is the topic of the {{.Normalized.Name}} event.
+		var {{$contract.Type}}{{.Normalized.Name}}ID = common.HexToHash("0x{{printf "%x" .Original.ID}}")
+
+		// Parse{{.Normalized.Name}} is a log parse operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Parse{{.Normalized.Name}}(log types.Log) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			event := new({{$contract.Type}}{{.Normalized.Name}})
+			if err := _{{$contract.Type}}.contract.UnpackLog(event, "{{.Original.Name}}", log); err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return event, nil
+		}
+	{{end}}
+{{end}}
+
+{{define "stateMethod"}}{{$contract := .Contract}}{{$structs := .Structs}}{{with .Method -}}
+	func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(statedb *state.StateDB, header *types.Header {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+		{{- if not .Normalized.Outputs}}
+		return _{{$contract.Type}}.contract.Call(statedb, header, nil, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		{{- else}}
+		var out []interface{}
+		err := _{{$contract.Type}}.contract.Call(statedb, header, &out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		{{- if .Structured}}
+		outstruct := new(struct{ {{range .Normalized.Outputs}} {{.Name}} {{bindtype .Type $structs}}; {{end}} })
+		if err != nil {
+			return *outstruct, err
+		}
+		{{- range $i, $t := .Normalized.Outputs}}
+		outstruct.{{.Name}} = *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+		return *outstruct, nil
+		{{- else}}
+		if err != nil {
+			return {{range $i, $_ := .Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}} err
+		}
+		{{- range $i, $t := .Normalized.Outputs}}
+		out{{$i}} := *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+		return {{range $i, $t := .Normalized.Outputs}}out{{$i}}, {{end}} nil
+		{{- end}}
+		{{- end}}
+	}
+	{{end}}
+{{end}}
+`
+
+// tmplStateMethod is the data of the method template of the state bindings.
+type tmplStateMethod struct {
+	Contract *tmplContract
+	Method   *tmplMethod
+	Structs  map[string]*tmplStruct
+}
+
+// stateMethod bundles the data needed by the method template of the state bindings.
+func stateMethod(contract *tmplContract, method *tmplMethod, structs map[string]*tmplStruct) tmplStateMethod {
+	return tmplStateMethod{Contract: contract, Method: method, Structs: structs}
+}
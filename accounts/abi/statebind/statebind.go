@@ -0,0 +1,102 @@
+// Package statebind contains the runtime of the contract bindings generated with
+// bind.LangGoState, which call the contracts directly against a state database.
+package statebind
+
+import (
+	"errors"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// StateCaller executes contract calls directly against a state database as of
+// a block header, without going through a transaction. It is implemented by the
+// protocol to call the system contracts.
+type StateCaller interface {
+	// StateCall executes the contract with the given input data and returns the
+	// output. Changes made by mutating methods are applied to statedb.
+	StateCall(statedb *state.StateDB, header *types.Header, contract common.Address, input []byte) ([]byte, error)
+}
+
+// BoundContract is the base wrapper object of the generated bindings, it packs
+// the calls to the contract and unpacks their results.
+type BoundContract struct {
+	address common.Address
+	abi     abi.ABI
+	caller  StateCaller
+}
+
+// NewBoundContract creates a low level contract interface through which calls
+// can be made against a state database.
+func NewBoundContract(address common.Address, abi abi.ABI, caller StateCaller) *BoundContract {
+	return &BoundContract{
+		address: address,
+		abi:     abi,
+		caller:  caller,
+	}
+}
+
+// ABI returns the contract ABI the binding was generated from.
+func (c *BoundContract) ABI() *abi.ABI {
+	return &c.abi
+}
+
+// Address returns the address of the bound contract.
+func (c *BoundContract) Address() common.Address {
+	return c.address
+}
+
+// Call invokes the contract method with params as input values against statedb
+// and sets the output to results.
+func (c *BoundContract) Call(statedb *state.StateDB, header *types.Header, results *[]interface{}, method string, params ...interface{}) error {
+	if results == nil {
+		results = new([]interface{})
+	}
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return err
+	}
+	output, err := c.caller.StateCall(statedb, header, c.address, input)
+	if err != nil {
+		return err
+	}
+	if len(c.abi.Methods[method].Outputs) == 0 {
+		return nil
+	}
+	if len(output) == 0 && len(statedb.GetCode(c.address)) == 0 {
+		return ErrNoCode
+	}
+	res, err := c.abi.Unpack(method, output)
+	*results = res
+	return err
+}
+
+// UnpackLog unpacks a log emitted by the contract into out.
+func (c *BoundContract) UnpackLog(out interface{}, event string, log types.Log) error {
+	if len(log.Topics) == 0 || log.Topics[0] != c.abi.Events[event].ID {
+		return errNoEventSignature
+	}
+	if len(log.Data) > 0 {
+		if err := c.abi.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range c.abi.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, log.Topics[1:])
+}
+
+var (
+	// ErrNoCode is returned by calls to an address without contract code.
+	ErrNoCode = errors.New("no contract code at given address")
+
+	// errNoEventSignature is returned when a log is unpacked into an event it
+	// wasn't emitted for.
+	errNoEventSignature = errors.New("log is not of the event")
+)
@@ -0,0 +1,78 @@
+package statebind
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+const testABI = `[
+	{"type":"function","name":"get","stateMutability":"view","inputs":[{"name":"key","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"key","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Set","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"key","type":"uint256","indexed":false}]}
+]`
+
+type testCaller struct {
+	input  []byte
+	output []byte
+}
+
+func (c *testCaller) StateCall(statedb *state.StateDB, header *types.Header, contract common.Address, input []byte) ([]byte, error) {
+	c.input = input
+	return c.output, nil
+}
+
+func TestBoundContract(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	require.NoError(t, err)
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	address := common.Address{1}
+	caller := new(testCaller)
+	contract := NewBoundContract(address, parsed, caller)
+	header := &types.Header{Number: big.NewInt(1)}
+
+	// The calls are packed and their outputs unpacked.
+	value := common.Address{2}
+	caller.output = common.LeftPadBytes(value[:], 32)
+	var out []interface{}
+	require.NoError(t, contract.Call(statedb, header, &out, "get", big.NewInt(3)))
+	packed, err := parsed.Pack("get", big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, packed, caller.input)
+	require.Equal(t, []interface{}{value}, out)
+
+	// Methods without outputs ignore the returned data.
+	caller.output = nil
+	require.NoError(t, contract.Call(statedb, header, nil, "set", big.NewInt(3)))
+
+	// An empty output is an error without contract code.
+	require.Equal(t, ErrNoCode, contract.Call(statedb, header, &out, "get", big.NewInt(3)))
+	statedb.SetCode(address, []byte{0x00})
+	require.Error(t, contract.Call(statedb, header, &out, "get", big.NewInt(3)))
+
+	// Logs are unpacked only into their event.
+	log := types.Log{
+		Address: address,
+		Topics:  []common.Hash{parsed.Events["Set"].ID, common.BytesToHash(value[:])},
+		Data:    common.LeftPadBytes([]byte{3}, 32),
+	}
+	var event struct {
+		From common.Address
+		Key  *big.Int
+	}
+	require.NoError(t, contract.UnpackLog(&event, "Set", log))
+	require.Equal(t, value, event.From)
+	require.Equal(t, big.NewInt(3), event.Key)
+	log.Topics[0] = crypto.Keccak256Hash([]byte("Other(address,uint256)"))
+	require.Equal(t, errNoEventSignature, contract.UnpackLog(&event, "Set", log))
+}
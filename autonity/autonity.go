@@ -1,14 +1,18 @@
 package autonity
 
+//go:generate abigen --abi ../common/acdefault/generated/Autonity.abi --pkg autonity --type Autonity --lang gostate --out bindings.go
+
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/accounts/abi/statebind"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
@@ -19,9 +23,16 @@ import (
 
 var ErrAutonityContract = errors.New("could not call Autonity contract")
 var ErrWrongParameter = errors.New("wrong parameter")
+
+// errUnknownABI is returned when calling the contract in a state whose ABI is
+// unknown, which is the case between the upgrades applied before the ABIs were
+// versioned per height.
+var errUnknownABI = errors.New("autonity contract abi unknown at this height")
 var Deployer = common.Address{}
 var ContractAddress = crypto.CreateAddress(Deployer, 0)
 
+// ABISPEC is the key of the single contract ABI stored by the nodes predating the
+// ABIs versioned per height, it is only read to migrate them.
 const ABISPEC = "ABISPEC"
 
 // EVMProvider provides a new evm. This allows us to decouple the contract from *params.ChainConfig which is required to build a new evm.
@@ -33,15 +44,18 @@ type Blockchainer interface {
 	UpdateEnodeWhitelist(newWhitelist *types.Nodes)
 	ReadEnodeWhitelist() *types.Nodes
 
-	PutKeyValue(key []byte, value []byte) error
+	WriteContractABI(number uint64, abi []byte)
+	ReadContractABIs() ([]uint64, [][]byte)
+
+	WriteContractUpgrade(number uint64, upgrade []byte)
+	ReadContractUpgrades() [][]byte
 }
 
 type Contract struct {
 	evmProvider        EVMProvider
 	operator           common.Address
 	initialMinGasPrice uint64
-	contractABI        *abi.ABI
-	stringABI          string
+	abis               []versionedABI // in ascending height order, the first one at genesis
 	bc                 Blockchainer
 	metrics            EconomicMetrics
 
@@ -56,41 +70,47 @@ func NewAutonityContract(
 	evmProvider EVMProvider,
 ) (*Contract, error) {
 	contract := Contract{
-		stringABI:          ABI,
 		operator:           operator,
 		initialMinGasPrice: minGasPrice,
 		bc:                 bc,
 		evmProvider:        evmProvider,
 	}
-	err := contract.upgradeAbiCache(ABI)
-	return &contract, err
-}
-
-// measure metrics of user's meta data by regarding of network economic.
-func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateDB *state.StateDB) error {
-	// prepare abi and evm context
-	gas := uint64(0xFFFFFFFF)
-	evm := ac.evmProvider.EVM(header, Deployer, stateDB)
-	ABI := ac.contractABI
-
-	// pack the function which dump the data from contract.
-	input, err := ABI.Pack("dumpEconomicMetrics")
-	if err != nil {
-		return fmt.Errorf("failed to pack contract function: %v", err)
+	if err := contract.upgradeAbiCache(0, ABI); err != nil {
+		return nil, err
+	}
+	if bc == nil {
+		return &contract, nil
 	}
 
-	// call evm.
-	value := new(big.Int).SetUint64(0x00)
-	ret, _, vmerr := evm.Call(vm.AccountRef(Deployer), ContractAddress, input, gas, value)
-	if vmerr != nil {
-		return fmt.Errorf("EVM call to dumpNetworkEconomics failed: %v", vmerr)
+	numbers, abis := bc.ReadContractABIs()
+	if len(numbers) == 0 {
+		bc.WriteContractABI(0, []byte(ABI))
+	}
+	for i, number := range numbers {
+		if err := contract.upgradeAbiCache(number, string(abis[i])); err != nil {
+			return nil, fmt.Errorf("invalid contract abi stored for height %d: %v", number, err)
+		}
 	}
+	return &contract, nil
+}
 
-	// marshal the data from bytes arrays into specified structure.
-	v := EconomicMetaData{make([]common.Address, 32), make([]uint8, 32), make([]*big.Int, 32), new(big.Int), new(big.Int)}
+// Bindings returns the typed bindings of the current ABI of the Autonity contract,
+// called by the protocol against a state database.
+func (ac *Contract) Bindings() *Autonity {
+	return ac.abiAt(math.MaxUint64).binding
+}
 
-	if err := ABI.UnpackIntoInterface(&v, "dumpEconomicMetrics", ret); err != nil {
-		return fmt.Errorf("cannot unpack data from return value from contract: %v", err)
+// measure metrics of user's meta data by regarding of network economic. stateDB is
+// the state of the block of header, processed with upgrade applied by its finalize
+// if not nil.
+func (ac *Contract) MeasureMetricsOfNetworkEconomic(header *types.Header, stateDB *state.StateDB, upgrade *Upgrade) error {
+	binding, err := ac.stateBinding(header, upgrade)
+	if err != nil {
+		return err
+	}
+	v, err := binding.DumpEconomicMetrics(stateDB, header)
+	if err != nil {
+		return fmt.Errorf("call to dumpEconomicMetrics failed: %v", err)
 	}
 
 	if len(v.Accounts) != len(v.Usertypes) {
@@ -120,8 +140,7 @@ func (ac *Contract) GetCommittee(header *types.Header, statedb *state.StateDB) (
 		return nil, errors.New("calling GetCommittee for block #1 or #0")
 	}
 
-	var committeeSet types.Committee
-	err := ac.AutonityContractCall(statedb, header, "getCommittee", &committeeSet)
+	committeeSet, err := ac.callGetCommittee(statedb, header)
 	if err != nil {
 		return nil, err
 	}
@@ -129,8 +148,10 @@ func (ac *Contract) GetCommittee(header *types.Header, statedb *state.StateDB) (
 	return committeeSet, err
 }
 
-func (ac *Contract) UpdateEnodesWhitelist(state *state.StateDB, block *types.Block) error {
-	newWhitelist, err := ac.GetWhitelist(block, state)
+// UpdateEnodesWhitelist updates the whitelist of the blockchain from state, the
+// state of block processed with upgrade applied by its finalize if not nil.
+func (ac *Contract) UpdateEnodesWhitelist(state *state.StateDB, block *types.Block, upgrade *Upgrade) error {
+	newWhitelist, err := ac.getWhitelist(block, state, upgrade)
 	if err != nil {
 		log.Error("Could not call contract", "err", err)
 		return ErrAutonityContract
@@ -141,20 +162,22 @@ func (ac *Contract) UpdateEnodesWhitelist(state *state.StateDB, block *types.Blo
 }
 
 func (ac *Contract) GetWhitelist(block *types.Block, db *state.StateDB) (*types.Nodes, error) {
-	var (
-		newWhitelist *types.Nodes
-		err          error
-	)
+	return ac.getWhitelist(block, db, nil)
+}
 
+// getWhitelist returns the whitelist in db, the state of block processed with
+// upgrade applied by its finalize if not nil.
+func (ac *Contract) getWhitelist(block *types.Block, db *state.StateDB, upgrade *Upgrade) (*types.Nodes, error) {
 	if block.Number().Uint64() == 1 {
 		// use genesis block whitelist
-		newWhitelist = ac.bc.ReadEnodeWhitelist()
-	} else {
-		// call retrieveWhitelist contract function
-		newWhitelist, err = ac.callGetWhitelist(db, block.Header())
+		return ac.bc.ReadEnodeWhitelist(), nil
 	}
-
-	return newWhitelist, err
+	binding, err := ac.stateBinding(block.Header(), upgrade)
+	if err != nil {
+		return nil, err
+	}
+	// call retrieveWhitelist contract function
+	return ac.callGetWhitelist(binding, db, block.Header())
 }
 
 func (ac *Contract) GetMinimumGasPrice(block *types.Block, db *state.StateDB) (uint64, error) {
@@ -165,8 +188,10 @@ func (ac *Contract) GetMinimumGasPrice(block *types.Block, db *state.StateDB) (u
 	return ac.callGetMinimumGasPrice(db, block.Header())
 }
 
-func (ac *Contract) GetProposerFromAC(header *types.Header, db *state.StateDB, height uint64, round int64) common.Address {
-	return ac.callGetProposer(db, header, height, round)
+// GetElectionCommittee returns the committee in the order of the contract storage,
+// in which the proposer is elected, while the header committees are sorted.
+func (ac *Contract) GetElectionCommittee(header *types.Header, statedb *state.StateDB) (types.Committee, error) {
+	return ac.callGetCommittee(statedb, header)
 }
 
 func (ac *Contract) SetMinimumGasPrice(block *types.Block, db *state.StateDB, price *big.Int) error {
@@ -177,7 +202,10 @@ func (ac *Contract) SetMinimumGasPrice(block *types.Block, db *state.StateDB, pr
 	return ac.callSetMinimumGasPrice(db, block.Header(), price)
 }
 
-func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, receipts types.Receipts, header *types.Header, statedb *state.StateDB) (types.Committee, *types.Receipt, error) {
+// FinalizeAndGetCommittee punishes the offences verified by the engine, calls
+// finalize and returns the committee of the next block with the receipt of the
+// finalize call.
+func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, receipts types.Receipts, header *types.Header, statedb *state.StateDB, lastBlockSigners []common.Address, offences []Offence) (types.Committee, *types.Receipt, error) {
 	if header.Number.Uint64() == 0 {
 		return nil, nil, nil
 	}
@@ -191,7 +219,16 @@ func (ac *Contract) FinalizeAndGetCommittee(transactions types.Transactions, rec
 		"block", header.Number.Uint64(),
 		"gas", blockGas.Uint64())
 
-	upgradeContract, committee, err := ac.callFinalize(statedb, header, blockGas)
+	// the offenders are punished first, the committee computed by finalize
+	// excludes the jailed validators.
+	for _, offence := range offences {
+		log.Warn("Punishing misbehaving validator", "offender", offence.Offender, "reason", offence.Reason, "block", header.Number.Uint64())
+	}
+	if err := ac.callPunish(statedb, header, offences); err != nil {
+		return nil, nil, err
+	}
+
+	upgradeContract, committee, err := ac.callFinalize(statedb, header, blockGas, lastBlockSigners)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -234,6 +271,12 @@ func (ac *Contract) performContractUpgrade(statedb *state.StateDB, header *types
 		return errContract
 	}
 
+	// the new abi is checked before anything is changed, there is nothing to
+	// roll back if it is invalid.
+	if _, err := abi.JSON(strings.NewReader(newAbi)); err != nil {
+		return err
+	}
+
 	// take snapshot in case of roll back to former view.
 	snapshot := statedb.Snapshot()
 
@@ -245,40 +288,130 @@ func (ac *Contract) performContractUpgrade(statedb *state.StateDB, header *types
 		return err
 	}
 
-	// save new abi in persistent, once node reset, it load from persistent level db.
-	if err := ac.bc.PutKeyValue([]byte(ABISPEC), []byte(newAbi)); err != nil {
-		statedb.RevertToSnapshot(snapshot)
-		return err
+	// the new abi is in use from the state of this block, it is persisted with
+	// the record of the upgrade once the block is inserted in the canonical chain.
+	log.Info("Autonity Contract upgraded", "number", header.Number.Uint64())
+	return nil
+}
+
+// versionedABI is an ABI of the contract in use from the state of a height
+// onwards, until the next upgrade. The ABI of an unresolved range of heights is
+// unknown, it is neither parsed nor bound.
+type versionedABI struct {
+	number  uint64
+	parsed  *abi.ABI
+	json    string
+	binding *Autonity
+}
+
+// newVersionedABI parses newAbi, in use from the state of number. An empty ABI
+// is unknown.
+func (ac *Contract) newVersionedABI(number uint64, newAbi string) (versionedABI, error) {
+	version := versionedABI{number: number}
+	if newAbi != "" {
+		newABI, err := abi.JSON(strings.NewReader(newAbi))
+		if err != nil {
+			return versionedABI{}, err
+		}
+		version.parsed, version.json = &newABI, newAbi
+		version.binding = &Autonity{contract: statebind.NewBoundContract(ContractAddress, newABI, ac)}
 	}
+	return version, nil
+}
 
-	// upgrade ac.ContractStateStore too right after the contract upgrade successfully.
-	if err := ac.upgradeAbiCache(newAbi); err != nil {
-		statedb.RevertToSnapshot(snapshot)
+// upgradeAbiCache adds the ABI in use from the state of number, replacing the
+// one already known for this height. An empty ABI marks the heights from number
+// onwards as unresolved.
+func (ac *Contract) upgradeAbiCache(number uint64, newAbi string) error {
+	version, err := ac.newVersionedABI(number, newAbi)
+	if err != nil {
 		return err
 	}
-	log.Info("Autonity Contract upgrade success")
+	ac.addVersion(version)
 	return nil
 }
 
-func (ac *Contract) upgradeAbiCache(newAbi string) error {
+// addVersion adds version, replacing the one already known for its height.
+func (ac *Contract) addVersion(version versionedABI) {
+	number := version.number
 	ac.Lock()
 	defer ac.Unlock()
-	newABI, err := abi.JSON(strings.NewReader(newAbi))
-	if err != nil {
-		return err
+	i := sort.Search(len(ac.abis), func(i int) bool { return ac.abis[i].number >= number })
+	if i < len(ac.abis) && ac.abis[i].number == number {
+		ac.abis[i] = version
+		return
 	}
+	ac.abis = append(ac.abis, versionedABI{})
+	copy(ac.abis[i+1:], ac.abis[i:])
+	ac.abis[i] = version
+}
 
-	ac.contractABI = &newABI
-	ac.stringABI = newAbi
-	return nil
+// abiAt returns the ABI of the contract in the state of the given height.
+func (ac *Contract) abiAt(number uint64) versionedABI {
+	ac.RLock()
+	defer ac.RUnlock()
+	i := sort.Search(len(ac.abis), func(i int) bool { return ac.abis[i].number > number })
+	return ac.abis[i-1]
+}
+
+// processingVersion returns the ABI of the contract the block of header is
+// processed with, which is the one in the state of its parent.
+func (ac *Contract) processingVersion(header *types.Header) versionedABI {
+	number := header.Number.Uint64()
+	if number > 0 {
+		number--
+	}
+	return ac.abiAt(number)
+}
+
+// processingABI returns the ABI the block of header is processed with.
+func (ac *Contract) processingABI(header *types.Header) (*abi.ABI, error) {
+	version := ac.processingVersion(header)
+	if version.parsed == nil {
+		return nil, errUnknownABI
+	}
+	return version.parsed, nil
+}
+
+// processingBinding returns the typed bindings of the ABI the block of header
+// is processed with.
+func (ac *Contract) processingBinding(header *types.Header) (*Autonity, error) {
+	version := ac.processingVersion(header)
+	if version.binding == nil {
+		return nil, errUnknownABI
+	}
+	return version.binding, nil
+}
+
+// stateBinding returns the typed bindings of the ABI in the state of the block of
+// header, processed with upgrade applied by its finalize if not nil. Until the
+// block is inserted in the canonical chain, the ABI of upgrade is only known to
+// the caller.
+func (ac *Contract) stateBinding(header *types.Header, upgrade *Upgrade) (*Autonity, error) {
+	if upgrade != nil {
+		return upgrade.version.binding, nil
+	}
+	return ac.processingBinding(header)
 }
 
 // StringABI returns the current autonity contract ABI in string format
 func (ac *Contract) StringABI() string {
-	return ac.stringABI
+	return ac.abiAt(math.MaxUint64).json
 }
 
 // ABI returns the current autonity contract's ABI
 func (ac *Contract) ABI() *abi.ABI {
-	return ac.contractABI
+	return ac.abiAt(math.MaxUint64).parsed
+}
+
+// StringABIAt returns the ABI of the contract in the state of the given height
+// in string format, empty if it is unknown.
+func (ac *Contract) StringABIAt(number uint64) string {
+	return ac.abiAt(number).json
+}
+
+// ABIAt returns the ABI of the contract in the state of the given height, nil if
+// it is unknown.
+func (ac *Contract) ABIAt(number uint64) *abi.ABI {
+	return ac.abiAt(number).parsed
 }
@@ -0,0 +1,55 @@
+package autonity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+func TestBindings(t *testing.T) {
+	stakes := []uint64{10, 20, 30}
+	ac, statedb, header := deployTestContract(t, stakes)
+	autonity := ac.Bindings()
+
+	validators, err := autonity.GetValidators(statedb, header)
+	require.NoError(t, err)
+	require.Len(t, validators, len(stakes))
+	for i, validator := range validators {
+		user, err := autonity.GetUser(statedb, header, validator)
+		require.NoError(t, err)
+		require.Equal(t, validator, user.Addr)
+		require.Equal(t, uint8(Validator), user.UserType)
+		require.Equal(t, new(big.Int).SetUint64(stakes[i]), user.Stake)
+	}
+
+	// The committee is computed by finalize, which is restricted to the protocol.
+	signers := validators[:2]
+	updateReady, committee, err := ac.callFinalize(statedb, header, new(big.Int), signers)
+	require.NoError(t, err)
+	require.False(t, updateReady)
+	require.Len(t, committee, len(stakes))
+	lastBlockSigners, err := autonity.GetLastBlockSigners(statedb, header)
+	require.NoError(t, err)
+	require.Equal(t, signers, lastBlockSigners)
+	sorted, err := ac.GetCommittee(&types.Header{Number: big.NewInt(2)}, statedb)
+	require.NoError(t, err)
+	require.Equal(t, committee, sorted)
+
+	// Mutators are executed against the state database, events are parsed from its logs.
+	statedb.Prepare(common.Hash{1}, common.Hash{}, 0)
+	require.NoError(t, autonity.SetMinimumGasPrice(statedb, header, big.NewInt(5000)))
+	price, err := ac.callGetMinimumGasPrice(statedb, header)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5000), price)
+	logs := statedb.GetLogs(common.Hash{1})
+	require.Len(t, logs, 1)
+	event, err := autonity.ParseMinimumGasPriceUpdated(*logs[0])
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5000), event.GasPrice)
+	_, err = autonity.ParseUserAdded(*logs[0])
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package autonity
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"reflect"
@@ -15,23 +16,6 @@ import (
 	"github.com/clearmatics/autonity/params"
 )
 
-/*
- * ContractState is a unified structure to represent the autonity contract state.
- * By using a unified structure, the new state meta introduced in the Autonity.sol
- * should be synced with this structure.
- */
-type ContractState struct {
-	Users           []common.Address `abi:"users"`
-	Enodes          []string         `abi:"enodes"`
-	Types           []*big.Int       `abi:"types"`
-	Stakes          []*big.Int       `abi:"stakes"`
-	CommissionRates []*big.Int       `abi:"commisionrates"`
-	Operator        common.Address   `abi:"operator"`
-	Deployer        common.Address   `abi:"deployer"`
-	MinGasPrice     *big.Int         `abi:"mingasprice"`
-	BondingPeriod   *big.Int         `abi:"bondingperiod"`
-}
-
 type raw []byte
 
 func DeployContract(abi *abi.ABI, autonityConfig *params.AutonityContractGenesis, evm *vm.EVM) error {
@@ -102,7 +86,11 @@ func (ac *Contract) updateAutonityContract(header *types.Header, statedb *state.
 // interface.
 func (ac *Contract) AutonityContractCall(statedb *state.StateDB, header *types.Header, function string, result interface{}, args ...interface{}) error {
 
-	packedArgs, err := ac.contractABI.Pack(function, args...)
+	contractABI, err := ac.processingABI(header)
+	if err != nil {
+		return err
+	}
+	packedArgs, err := contractABI.Pack(function, args...)
 	if err != nil {
 		return err
 	}
@@ -118,7 +106,7 @@ func (ac *Contract) AutonityContractCall(statedb *state.StateDB, header *types.H
 		return nil
 	}
 
-	if err := ac.contractABI.UnpackIntoInterface(result, function, ret); err != nil {
+	if err := contractABI.UnpackIntoInterface(result, function, ret); err != nil {
 		log.Error("Could not unpack returned value", "function", function)
 		return err
 	}
@@ -132,92 +120,161 @@ func (ac *Contract) AutonityContractCall(statedb *state.StateDB, header *types.H
 // Callers should use the autonity contract ABI to pack and unpack the args and
 // result.
 func (ac *Contract) CallContractFunc(statedb *state.StateDB, header *types.Header, function string, packedArgs []byte) ([]byte, error) {
+	return ac.StateCall(statedb, header, ContractAddress, packedArgs)
+}
+
+// StateCall implements bind.StateCaller, the contract is called by the protocol
+// through a new evm.
+func (ac *Contract) StateCall(statedb *state.StateDB, header *types.Header, contract common.Address, input []byte) ([]byte, error) {
 	gas := uint64(math.MaxUint64)
 	evm := ac.evmProvider.EVM(header, Deployer, statedb)
-	packedResult, _, err := evm.Call(vm.AccountRef(Deployer), ContractAddress, packedArgs, gas, new(big.Int))
+	packedResult, _, err := evm.Call(vm.AccountRef(Deployer), contract, input, gas, new(big.Int))
 	return packedResult, err
 }
 
-func (ac *Contract) callGetWhitelist(state *state.StateDB, header *types.Header) (*types.Nodes, error) {
-	var returnedEnodes []string
-	err := ac.AutonityContractCall(state, header, "getWhitelist", &returnedEnodes)
+// callGetWhitelist returns the enodes of the users of the contract along with
+// their user type, calling the contract through binding.
+func (ac *Contract) callGetWhitelist(binding *Autonity, state *state.StateDB, header *types.Header) (*types.Nodes, error) {
+	users, err := binding.GetState(state, header)
 	if err != nil {
 		return nil, err
 	}
-	return types.NewNodes(returnedEnodes), nil
+	enodes := make([]string, 0, len(users.Enode))
+	roles := make([]params.UserType, 0, len(users.Enode))
+	for i, enode := range users.Enode {
+		if enode == "" || i >= len(users.UserType) {
+			continue
+		}
+		role, ok := params.UserTypeFromID(int(users.UserType[i].Int64()))
+		if !ok {
+			return nil, fmt.Errorf("unknown user type %v for %s", users.UserType[i], enode)
+		}
+		enodes = append(enodes, enode)
+		roles = append(roles, role)
+	}
+	return types.NewNodesWithRoles(enodes, roles), nil
 }
 
 func (ac *Contract) callGetMinimumGasPrice(state *state.StateDB, header *types.Header) (uint64, error) {
-	minGasPrice := new(big.Int)
-	err := ac.AutonityContractCall(state, header, "getMinimumGasPrice", &minGasPrice)
+	binding, err := ac.processingBinding(header)
+	if err != nil {
+		return 0, err
+	}
+	minGasPrice, err := binding.GetMinimumGasPrice(state, header)
 	if err != nil {
 		return 0, err
 	}
 	return minGasPrice.Uint64(), nil
 }
 
-func (ac *Contract) callGetProposer(state *state.StateDB, header *types.Header, height uint64, round int64) common.Address {
-	var proposer common.Address
-	h := new(big.Int).SetUint64(height)
-	r := new(big.Int).SetInt64(round)
-	err := ac.AutonityContractCall(state, header, "getProposer", &proposer, h, r)
+func (ac *Contract) callGetCommittee(state *state.StateDB, header *types.Header) (types.Committee, error) {
+	binding, err := ac.processingBinding(header)
 	if err != nil {
-		log.Error("get proposer failed from contract.", "error", err)
-		return common.Address{}
+		return nil, err
 	}
-	return proposer
+	members, err := binding.GetCommittee(state, header)
+	if err != nil {
+		return nil, err
+	}
+	return committeeFromBinding(members), nil
 }
 
-func (ac *Contract) callFinalize(state *state.StateDB, header *types.Header, blockGas *big.Int) (bool, types.Committee, error) {
-
-	var updateReady bool
-	var committee types.Committee
+func (ac *Contract) callFinalize(state *state.StateDB, header *types.Header, blockGas *big.Int, lastBlockSigners []common.Address) (bool, types.Committee, error) {
+	contractABI, err := ac.processingABI(header)
+	if err != nil {
+		return false, nil, err
+	}
+	// Contracts deployed before the last block signers were passed only take the amount.
+	if method, ok := contractABI.Methods["finalize"]; ok && len(method.Inputs) == 1 {
+		var updateReady bool
+		var committee types.Committee
+		err := ac.AutonityContractCall(state, header, "finalize", &[]interface{}{&updateReady, &committee}, blockGas)
+		if err != nil {
+			return false, nil, err
+		}
+		sort.Sort(committee)
+		return updateReady, committee, nil
+	}
 
-	err := ac.AutonityContractCall(state, header, "finalize", &[]interface{}{&updateReady, &committee}, blockGas)
+	if lastBlockSigners == nil {
+		lastBlockSigners = []common.Address{}
+	}
+	binding, err := ac.processingBinding(header)
 	if err != nil {
 		return false, nil, err
 	}
+	updateReady, members, err := binding.Finalize(state, header, blockGas, lastBlockSigners)
+	if err != nil {
+		return false, nil, err
+	}
+	committee := committeeFromBinding(members)
 	sort.Sort(committee)
 	// submit the final reward distribution metrics.
 	//ac.metrics.SubmitRewardDistributionMetrics(&v, header.Number.Uint64())
 	return updateReady, committee, nil
 }
 
-func (ac *Contract) callRetrieveState(statedb *state.StateDB, header *types.Header) ([]byte, error) {
-	var state raw
+// callPunish slashes and jails the offenders. The contracts deployed before the
+// punishment of validators have no entrypoint for it, the offences are dropped.
+func (ac *Contract) callPunish(state *state.StateDB, header *types.Header, offences []Offence) error {
+	if len(offences) == 0 {
+		return nil
+	}
+	binding, err := ac.processingBinding(header)
+	if err != nil {
+		return err
+	}
+	if _, ok := binding.ABI().Methods["punish"]; !ok {
+		log.Warn("Autonity contract does not punish validators", "offences", len(offences))
+		return nil
+	}
+	offenders := make([]common.Address, len(offences))
+	reasons := make([]uint8, len(offences))
+	for i, offence := range offences {
+		offenders[i], reasons[i] = offence.Offender, uint8(offence.Reason)
+	}
+	return binding.Punish(state, header, offenders, reasons)
+}
 
-	err := ac.AutonityContractCall(statedb, header, "getState", &state)
+// callRetrieveState returns the packed output of getState, which is the input of
+// the constructor of the upgraded contract.
+func (ac *Contract) callRetrieveState(statedb *state.StateDB, header *types.Header) ([]byte, error) {
+	binding, err := ac.processingBinding(header)
 	if err != nil {
 		return nil, err
 	}
-
-	return state, nil
+	return ac.StateCall(statedb, header, ContractAddress, binding.ABI().Methods["getState"].ID)
 }
 
 func (ac *Contract) callRetrieveContract(state *state.StateDB, header *types.Header) (string, string, error) {
-	var bytecode string
-	var abi string
-	err := ac.AutonityContractCall(state, header, "getNewContract", &[]interface{}{&bytecode, &abi})
+	binding, err := ac.processingBinding(header)
 	if err != nil {
 		return "", "", err
 	}
-	return bytecode, abi, nil
+	return binding.GetNewContract(state, header)
 }
 
 func (ac *Contract) callSetMinimumGasPrice(state *state.StateDB, header *types.Header, price *big.Int) error {
-	// Needs to be refactored somehow
-	gas := uint64(0xFFFFFFFF)
-	evm := ac.evmProvider.EVM(header, Deployer, state)
-
-	input, err := ac.contractABI.Pack("setMinimumGasPrice")
+	binding, err := ac.processingBinding(header)
 	if err != nil {
 		return err
 	}
-
-	_, _, vmerr := evm.Call(vm.AccountRef(Deployer), ContractAddress, input, gas, price)
-	if vmerr != nil {
-		log.Error("Error Autonity Contract getMinimumGasPrice()")
-		return vmerr
+	if err := binding.SetMinimumGasPrice(state, header, price); err != nil {
+		log.Error("Error Autonity Contract setMinimumGasPrice()", "err", err)
+		return err
 	}
 	return nil
 }
+
+// committeeFromBinding converts the committee members returned by the contract.
+func committeeFromBinding(members []AutonityCommitteeMember) types.Committee {
+	committee := make(types.Committee, len(members))
+	for i, member := range members {
+		committee[i] = types.CommitteeMember{
+			Address:     member.Addr,
+			VotingPower: member.VotingPower,
+			BLSKey:      member.BlsKey,
+		}
+	}
+	return committee
+}
@@ -62,14 +62,8 @@ const (
 	BlockRewardHeightWindowStepRange = 600  // each 10 minutes to shrink the window.
 )
 
-// refer to autonity contract abt spec, keep in same meta.
-type EconomicMetaData struct {
-	Accounts        []common.Address `abi:"accounts"`
-	Usertypes       []uint8          `abi:"usertypes"`
-	Stakes          []*big.Int       `abi:"stakes"`
-	Mingasprice     *big.Int         `abi:"mingasprice"`
-	Stakesupply     *big.Int         `abi:"stakesupply"`
-}
+// EconomicMetaData is the economic metrics dumped by the autonity contract.
+type EconomicMetaData = AutonityEconomicMetrics
 
 // refer to autonity contract abi spec, keep in same meta.
 type RewardDistributionMetaData struct {
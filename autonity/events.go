@@ -0,0 +1,196 @@
+package autonity
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/params"
+)
+
+// Subscriptions to the events of the Autonity contract.
+const (
+	EventCommitteeChanged   = "committeeChanged"
+	EventUserAdded          = "userAdded"
+	EventStakeChanged       = "stakeChanged"
+	EventContractUpgraded   = "contractUpgraded"
+	EventMinGasPriceChanged = "minGasPriceChanged"
+	EventValidatorSlashed   = "validatorSlashed"
+)
+
+// Directions of a StakeChangedEvent.
+const (
+	StakeMinted = "mint"
+	StakeBurned = "burn"
+)
+
+var (
+	// EventTopics are the log topics decoded for each subscription. The
+	// committee changes are read from the headers and have no topic.
+	EventTopics = map[string][]common.Hash{
+		EventUserAdded:          {AutonityUserAddedID},
+		EventStakeChanged:       {AutonityMintedStakeID, AutonityBurnedStakeID},
+		EventContractUpgraded:   {AutonityContractUpgradedID},
+		EventMinGasPriceChanged: {AutonityMinimumGasPriceUpdatedID},
+		EventValidatorSlashed:   {AutonitySlashedID},
+	}
+
+	errUnknownEvent = errors.New("unknown autonity contract event")
+
+	// logParser decodes the logs of the contract, it makes no calls.
+	logParser = func() *Autonity {
+		b, err := NewAutonity(ContractAddress, nil)
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}()
+)
+
+// EventLog locates the log an event was decoded from. Finalize is set for the
+// logs emitted during the finalize call, whose receipt has the common.ACHash
+// transaction hash, and Removed for the logs reverted by a reorg.
+type EventLog struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	Finalize    bool           `json:"finalize"`
+	Removed     bool           `json:"removed"`
+}
+
+// CommitteeChangedEvent is sent when the committee of a header, which is the
+// committee of the next block, differs from the committee of its parent.
+type CommitteeChangedEvent struct {
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	Committee   types.Committee `json:"committee"`
+}
+
+// UserAddedEvent is decoded from UserAdded.
+type UserAddedEvent struct {
+	EventLog
+	Address common.Address  `json:"address"`
+	Type    params.UserType `json:"type"`
+	Stake   *big.Int        `json:"stake"`
+}
+
+// StakeChangedEvent is decoded from MintedStake and BurnedStake.
+type StakeChangedEvent struct {
+	EventLog
+	Address   common.Address `json:"address"`
+	Direction string         `json:"direction"`
+	Amount    *big.Int       `json:"amount"`
+}
+
+// ContractUpgradedEvent is decoded from ContractUpgraded, which is emitted when
+// an upgrade is requested. The upgrade is applied by the next finalize.
+type ContractUpgradedEvent struct {
+	EventLog
+	Version string `json:"version"`
+}
+
+// MinGasPriceChangedEvent is decoded from MinimumGasPriceUpdated.
+type MinGasPriceChangedEvent struct {
+	EventLog
+	GasPrice *big.Int `json:"gasPrice"`
+}
+
+// ValidatorSlashedEvent is decoded from Slashed, which is emitted by the
+// finalize of the block punishing a misbehaving validator.
+type ValidatorSlashedEvent struct {
+	EventLog
+	Address     common.Address `json:"address"`
+	Reason      string         `json:"reason"`
+	Amount      *big.Int       `json:"amount"`
+	JailedUntil hexutil.Uint64 `json:"jailedUntil"`
+}
+
+// DecodeEvent decodes a log of the Autonity contract into the event of one of
+// the subscriptions.
+func DecodeEvent(l *types.Log) (interface{}, error) {
+	if l.Address != ContractAddress || len(l.Topics) == 0 {
+		return nil, errUnknownEvent
+	}
+	el := EventLog{
+		BlockNumber: hexutil.Uint64(l.BlockNumber),
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		Finalize:    l.TxHash == common.ACHash(new(big.Int).SetUint64(l.BlockNumber)),
+		Removed:     l.Removed,
+	}
+	switch l.Topics[0] {
+	case AutonityUserAddedID:
+		ev, err := logParser.ParseUserAdded(*l)
+		if err != nil {
+			return nil, err
+		}
+		userType, ok := params.UserTypeFromID(int(ev.Type))
+		if !ok {
+			return nil, errors.New("unknown user type")
+		}
+		return &UserAddedEvent{EventLog: el, Address: ev.Address, Type: userType, Stake: ev.Stake}, nil
+	case AutonityMintedStakeID:
+		ev, err := logParser.ParseMintedStake(*l)
+		if err != nil {
+			return nil, err
+		}
+		return &StakeChangedEvent{EventLog: el, Address: ev.Address, Direction: StakeMinted, Amount: ev.Amount}, nil
+	case AutonityBurnedStakeID:
+		ev, err := logParser.ParseBurnedStake(*l)
+		if err != nil {
+			return nil, err
+		}
+		return &StakeChangedEvent{EventLog: el, Address: ev.Address, Direction: StakeBurned, Amount: ev.Amount}, nil
+	case AutonityContractUpgradedID:
+		ev, err := logParser.ParseContractUpgraded(*l)
+		if err != nil {
+			return nil, err
+		}
+		return &ContractUpgradedEvent{EventLog: el, Version: ev.Version}, nil
+	case AutonityMinimumGasPriceUpdatedID:
+		ev, err := logParser.ParseMinimumGasPriceUpdated(*l)
+		if err != nil {
+			return nil, err
+		}
+		return &MinGasPriceChangedEvent{EventLog: el, GasPrice: ev.GasPrice}, nil
+	case AutonitySlashedID:
+		ev, err := logParser.ParseSlashed(*l)
+		if err != nil {
+			return nil, err
+		}
+		return &ValidatorSlashedEvent{
+			EventLog:    el,
+			Address:     ev.Address,
+			Reason:      Misbehaviour(ev.Reason).String(),
+			Amount:      ev.Amount,
+			JailedUntil: hexutil.Uint64(ev.JailedUntil.Uint64()),
+		}, nil
+	}
+	return nil, errUnknownEvent
+}
+
+// NewCommitteeChangedEvent returns the event for header, or nil if its
+// committee is the one of its parent. The committee is kept inside an epoch,
+// the headers carrying only its hash. The committee of a parent inside an epoch
+// being the epoch committee, an epoch header is compared against its hash.
+func NewCommitteeChangedEvent(parent, header *types.Header) *CommitteeChangedEvent {
+	if header.CommitteeHash != (common.Hash{}) {
+		return nil
+	}
+	if parent != nil {
+		parentHash := parent.CommitteeHash
+		if len(parent.Committee) > 0 || parentHash == (common.Hash{}) {
+			parentHash = parent.Committee.Hash()
+		}
+		if parentHash == header.Committee.Hash() {
+			return nil
+		}
+	}
+	return &CommitteeChangedEvent{
+		BlockNumber: hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:   header.Hash(),
+		Committee:   header.Committee,
+	}
+}
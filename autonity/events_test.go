@@ -0,0 +1,101 @@
+package autonity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/params"
+)
+
+func testEventLog(t *testing.T, event string, txHash common.Hash, args ...interface{}) *types.Log {
+	ev := logParser.contract.ABI().Events[event]
+	data, err := ev.Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+	return &types.Log{
+		Address:     ContractAddress,
+		Topics:      []common.Hash{ev.ID},
+		Data:        data,
+		BlockNumber: 5,
+		TxHash:      txHash,
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	user := common.HexToAddress("0x1234")
+	txHash := common.HexToHash("0xabcd")
+
+	ev, err := DecodeEvent(testEventLog(t, "UserAdded", txHash, user, uint8(2), big.NewInt(10)))
+	require.NoError(t, err)
+	require.Equal(t, &UserAddedEvent{
+		EventLog: EventLog{BlockNumber: 5, TxHash: txHash},
+		Address:  user,
+		Type:     params.UserValidator,
+		Stake:    big.NewInt(10),
+	}, ev)
+
+	ev, err = DecodeEvent(testEventLog(t, "BurnedStake", txHash, user, big.NewInt(3)))
+	require.NoError(t, err)
+	require.Equal(t, StakeBurned, ev.(*StakeChangedEvent).Direction)
+	require.Equal(t, big.NewInt(3), ev.(*StakeChangedEvent).Amount)
+
+	// The logs of the finalize receipt are flagged.
+	ev, err = DecodeEvent(testEventLog(t, "MintedStake", common.ACHash(big.NewInt(5)), user, big.NewInt(3)))
+	require.NoError(t, err)
+	require.True(t, ev.(*StakeChangedEvent).Finalize)
+	require.Equal(t, StakeMinted, ev.(*StakeChangedEvent).Direction)
+
+	ev, err = DecodeEvent(testEventLog(t, "ContractUpgraded", txHash, "v2"))
+	require.NoError(t, err)
+	require.Equal(t, "v2", ev.(*ContractUpgradedEvent).Version)
+
+	ev, err = DecodeEvent(testEventLog(t, "MinimumGasPriceUpdated", txHash, big.NewInt(7)))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(7), ev.(*MinGasPriceChangedEvent).GasPrice)
+
+	ev, err = DecodeEvent(testEventLog(t, "Slashed", common.ACHash(big.NewInt(5)), user, uint8(Downtime), big.NewInt(4), big.NewInt(3605)))
+	require.NoError(t, err)
+	require.Equal(t, &ValidatorSlashedEvent{
+		EventLog:    EventLog{BlockNumber: 5, TxHash: common.ACHash(big.NewInt(5)), Finalize: true},
+		Address:     user,
+		Reason:      "downtime",
+		Amount:      big.NewInt(4),
+		JailedUntil: 3605,
+	}, ev)
+
+	_, err = DecodeEvent(testEventLog(t, "Rewarded", txHash, user, big.NewInt(1)))
+	require.Equal(t, errUnknownEvent, err)
+	l := testEventLog(t, "ContractUpgraded", txHash, "v2")
+	l.Address = common.Address{}
+	_, err = DecodeEvent(l)
+	require.Equal(t, errUnknownEvent, err)
+	_, err = DecodeEvent(testEventLog(t, "UserAdded", txHash, user, uint8(9), big.NewInt(10)))
+	require.Error(t, err)
+}
+
+func TestNewCommitteeChangedEvent(t *testing.T) {
+	committee := types.Committee{{Address: common.HexToAddress("0x1"), VotingPower: big.NewInt(1)}}
+	parent := &types.Header{Number: big.NewInt(1), Committee: committee}
+	header := &types.Header{Number: big.NewInt(2), Committee: committee}
+	require.Nil(t, NewCommitteeChangedEvent(parent, header))
+
+	header.Committee = append(types.Committee{{Address: common.HexToAddress("0x2"), VotingPower: big.NewInt(1)}}, committee...)
+	ev := NewCommitteeChangedEvent(parent, header)
+	require.NotNil(t, ev)
+	require.Equal(t, header.Hash(), ev.BlockHash)
+	require.Equal(t, header.Committee, ev.Committee)
+	require.NotNil(t, NewCommitteeChangedEvent(nil, header))
+
+	// Inside an epoch the header carries the hash of the committee of its parent.
+	inside := &types.Header{Number: big.NewInt(3), CommitteeHash: header.Committee.Hash()}
+	require.Nil(t, NewCommitteeChangedEvent(header, inside))
+
+	// The next epoch header is compared against the epoch committee of its parent.
+	next := &types.Header{Number: big.NewInt(4), Committee: header.Committee}
+	require.Nil(t, NewCommitteeChangedEvent(inside, next))
+	next.Committee = committee
+	require.NotNil(t, NewCommitteeChangedEvent(inside, next))
+}
@@ -0,0 +1,32 @@
+package autonity
+
+import (
+	"github.com/clearmatics/autonity/common"
+)
+
+// Misbehaviour is the reason a validator is punished, it matches the
+// Misbehaviour enum of the contract.
+type Misbehaviour uint8
+
+const (
+	DoubleSign Misbehaviour = iota
+	Downtime
+)
+
+func (m Misbehaviour) String() string {
+	switch m {
+	case DoubleSign:
+		return "doubleSign"
+	case Downtime:
+		return "downtime"
+	default:
+		return "unknown"
+	}
+}
+
+// Offence is a misbehaviour of a validator verified by the consensus engine,
+// the offender is slashed and jailed by the finalize of the block.
+type Offence struct {
+	Offender common.Address
+	Reason   Misbehaviour
+}
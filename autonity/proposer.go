@@ -0,0 +1,71 @@
+package autonity
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/math"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+// ErrCommitteeNotStaking is returned when the proposer is elected from a committee
+// without voting power.
+var ErrCommitteeNotStaking = errors.New("the committee is not staking")
+
+// ProposerElection elects the block proposers from a committee with the weighted
+// random sampling of the Autonity contract getProposer function. The committee must
+// be in the order of the contract storage, see Contract.GetElectionCommittee.
+type ProposerElection struct {
+	committee  types.Committee
+	cumulative []*big.Int // voting power of the members up to each index included
+	totalPower *big.Int
+}
+
+// NewProposerElection precomputes the voting power distribution of committee.
+func NewProposerElection(committee types.Committee) (*ProposerElection, error) {
+	e := &ProposerElection{
+		committee:  committee,
+		cumulative: make([]*big.Int, len(committee)),
+		totalPower: new(big.Int),
+	}
+	for i, member := range committee {
+		if member.VotingPower != nil {
+			e.totalPower.Add(e.totalPower, member.VotingPower)
+		}
+		e.cumulative[i] = new(big.Int).Set(e.totalPower)
+	}
+	if e.totalPower.Sign() == 0 {
+		return nil, ErrCommitteeNotStaking
+	}
+	return e, nil
+}
+
+// Proposer returns the proposer of the given height and round, the height being
+// the one the contract is called with, i.e. the number of the parent block.
+func (e *ProposerElection) Proposer(height uint64, round int64) common.Address {
+	key := new(big.Int).Add(new(big.Int).SetUint64(height), big.NewInt(round))
+	value := new(big.Int).SetBytes(crypto.Keccak256(math.U256Bytes(key)))
+	index := value.Mod(value, e.totalPower)
+	// The first member whose cumulative voting power exceeds the index is elected,
+	// the total power being greater than the index there is always one. As in the
+	// contract, where counter - 1 wraps around, a leading member without voting
+	// power is elected.
+	for i, counter := range e.cumulative {
+		if counter.Sign() == 0 || index.Cmp(counter) < 0 {
+			return e.committee[i].Address
+		}
+	}
+	return e.committee[len(e.committee)-1].Address
+}
+
+// ElectProposer returns the proposer elected from committee for the given height
+// and round.
+func ElectProposer(committee types.Committee, height uint64, round int64) (common.Address, error) {
+	e, err := NewProposerElection(committee)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return e.Proposer(height, round), nil
+}
@@ -0,0 +1,126 @@
+package autonity
+
+import (
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/acdefault"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
+)
+
+type testEVMProvider struct{}
+
+func (testEVMProvider) EVM(header *types.Header, origin common.Address, statedb *state.StateDB) *vm.EVM {
+	ctx := vm.Context{
+		CanTransfer: func(db vm.StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
+			db.SubBalance(sender, amount)
+			db.AddBalance(recipient, amount)
+		},
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Origin:      origin,
+		BlockNumber: header.Number,
+		Time:        new(big.Int).SetUint64(header.Time),
+		GasLimit:    header.GasLimit,
+		Difficulty:  new(big.Int),
+		GasPrice:    new(big.Int),
+	}
+	return vm.NewEVM(ctx, statedb, params.TestChainConfig, vm.Config{})
+}
+
+// deployTestContract deploys the Autonity contract with a validator of each stake,
+// the operator being the deployer.
+func deployTestContract(t *testing.T, stakes []uint64) (*Contract, *state.StateDB, *types.Header) {
+	if acdefault.Bytecode() == "" {
+		t.Skip("Autonity contract bytecode not generated")
+	}
+	genesis := &params.AutonityContractGenesis{Bytecode: acdefault.Bytecode(), ABI: acdefault.ABI(), Operator: Deployer}
+	for _, stake := range stakes {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		genesis.Users = append(genesis.Users, params.User{
+			Address: &address,
+			Enode:   enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).URLv4(),
+			Type:    params.UserValidator,
+			Stake:   stake,
+		})
+	}
+	contractABI, err := abi.JSON(strings.NewReader(acdefault.ABI()))
+	require.NoError(t, err)
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 1 << 32}
+	evm := testEVMProvider{}.EVM(header, Deployer, statedb)
+	require.NoError(t, DeployContract(&contractABI, genesis, evm))
+	ac, err := NewAutonityContract(nil, Deployer, 0, acdefault.ABI(), testEVMProvider{})
+	require.NoError(t, err)
+	return ac, statedb, header
+}
+
+// TestProposerElection cross-checks the proposers elected in Go with the ones of
+// the Autonity contract getProposer function.
+func TestProposerElection(t *testing.T) {
+	stakes := []uint64{10, 1, 100, 35, 7, 1000, 3, 64}
+	ac, statedb, header := deployTestContract(t, stakes)
+	autonity := ac.Bindings()
+
+	// A committee smaller than the validators is ranked by stake.
+	for _, size := range []int64{int64(len(stakes)), 5} {
+		require.NoError(t, autonity.SetCommitteeSize(statedb, header, big.NewInt(size)))
+		require.NoError(t, autonity.ComputeCommittee(statedb, header))
+
+		committee, err := ac.GetElectionCommittee(header, statedb)
+		require.NoError(t, err)
+		require.Len(t, committee, int(size))
+		election, err := NewProposerElection(committee)
+		require.NoError(t, err)
+		for height := uint64(0); height < 50; height++ {
+			for round := int64(0); round < 10; round++ {
+				expected, err := autonity.GetProposer(statedb, header, new(big.Int).SetUint64(height), big.NewInt(round))
+				require.NoError(t, err)
+				require.Equal(t, expected, election.Proposer(height, round), "height %d round %d", height, round)
+			}
+		}
+	}
+}
+
+func TestElectProposer(t *testing.T) {
+	committee := types.Committee{
+		{Address: common.Address{1}, VotingPower: big.NewInt(1)},
+		{Address: common.Address{2}, VotingPower: big.NewInt(3)},
+	}
+	counts := make(map[common.Address]int)
+	for height := uint64(0); height < 1000; height++ {
+		proposer, err := ElectProposer(committee, height, 0)
+		require.NoError(t, err)
+		counts[proposer]++
+		// The seed is the sum of the height and the round.
+		next, err := ElectProposer(committee, height+1, 0)
+		require.NoError(t, err)
+		shifted, err := ElectProposer(committee, height, 1)
+		require.NoError(t, err)
+		require.Equal(t, next, shifted)
+	}
+	require.InDelta(t, 750, counts[common.Address{2}], 75)
+	require.Equal(t, 1000, counts[common.Address{1}]+counts[common.Address{2}])
+
+	_, err := ElectProposer(types.Committee{{Address: common.Address{1}, VotingPower: new(big.Int)}}, 0, 0)
+	require.Equal(t, ErrCommitteeNotStaking, err)
+	_, err = ElectProposer(nil, 0, 0)
+	require.Equal(t, ErrCommitteeNotStaking, err)
+}
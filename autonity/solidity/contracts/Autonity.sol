@@ -11,6 +11,7 @@ contract Autonity is IERC20 {
     using SafeMath for uint256;
 
     enum UserType { Participant, Stakeholder, Validator}
+    enum Misbehaviour { DoubleSign, Downtime }
     struct User {
         address payable addr;
         UserType userType;
@@ -21,6 +22,7 @@ contract Autonity is IERC20 {
     struct CommitteeMember {
         address payable addr;
         uint256 votingPower;
+        bytes blsKey;
     }
 
     struct EconomicMetrics {
@@ -47,6 +49,24 @@ contract Autonity is IERC20 {
     address[] private stakeholders;
     uint256 private stakeSupply;
     CommitteeMember[] private committee;
+    address[] private lastBlockSigners;
+
+    /*
+    BLS public keys of the validators used for the aggregated committed seals. They are not
+    part of the state dump, the validators register their key again after a contract upgrade.
+    */
+    mapping (address => bytes) private blsKeys;
+
+    /*
+    Punishment of the misbehaving validators, slashingRate being the fraction of the stake burned
+    out of SLASHING_RATE_PRECISION and jailPeriod the number of blocks a punished validator is
+    excluded from the committee. They are not part of the state dump either, an upgrade resets
+    them to their default and releases the jailed validators.
+    */
+    uint256 private constant SLASHING_RATE_PRECISION = 10000;
+    uint256 public slashingRate;
+    uint256 public jailPeriod;
+    mapping (address => uint256) private jailedUntil;
 
     /*
     We're saving the address of who is deploying the contract and we use it
@@ -82,6 +102,13 @@ contract Autonity is IERC20 {
      * @dev Emitted when the Autonity Contract was upgraded to a new version (`version`).
      */
     event ContractUpgraded(string version);
+    event RegisteredBLSKey(address _address, bytes _key);
+
+    /**
+     * @dev Emitted when `_amount` of the stake of a validator was burned for a misbehaviour
+     * proven by the protocol. The validator is not elected in the committee before block `_jailedUntil`.
+     */
+    event Slashed(address _address, Misbehaviour _reason, uint256 _amount, uint256 _jailedUntil);
 
     constructor (address[] memory _participantAddress,
         string[] memory _participantEnode,
@@ -108,6 +135,8 @@ contract Autonity is IERC20 {
         contractVersion = _contractVersion;
         committeeSize = _committeeSize;
         deployer = msg.sender;
+        slashingRate = 1000;
+        jailPeriod = 3600;
     }
 
     /**
@@ -148,6 +177,19 @@ contract Autonity is IERC20 {
         emit UserAdded(_address, _role, _stake);
     }
 
+    /**
+    * @notice Register the BLS public key signing the committed seals of the caller, a validator.
+    * @param _key Uncompressed G1 public key.
+    * @param _proof Proof of possession of the secret key, an uncompressed G2 signature.
+    * @dev Emit a {RegisteredBLSKey} event.
+    */
+    function registerBLSKey(bytes memory _key, bytes memory _proof) public {
+        require(users[msg.sender].userType == UserType.Validator, "caller is not a validator");
+        require(Precompiled.blsPossessionCheck(_key, _proof), "invalid bls key proof of possession");
+        blsKeys[msg.sender] = _key;
+        emit RegisteredBLSKey(msg.sender, _key);
+    }
+
     /**
     * @notice Change the user account type. Restricted to the operator account.
     */
@@ -183,6 +225,23 @@ contract Autonity is IERC20 {
         committeeSize = size;
     }
 
+    /**
+    * @notice Set the fraction of the stake burned when a validator is punished, out of 10000.
+    * Restricted to the operator account.
+    */
+    function setSlashingRate(uint256 _rate) public onlyOperator(msg.sender) {
+        require(_rate <= SLASHING_RATE_PRECISION, "slashing rate exceeds the stake");
+        slashingRate = _rate;
+    }
+
+    /**
+    * @notice Set the number of blocks a punished validator is excluded from the committee.
+    * Restricted to the operator account.
+    */
+    function setJailPeriod(uint256 _period) public onlyOperator(msg.sender) {
+        jailPeriod = _period;
+    }
+
     /*
     * @notice Mint new stake token (NEW) and add it to the recipient balance. Restricted to the Operator account.
     * @dev emit a MintStake event.
@@ -277,18 +336,45 @@ contract Autonity is IERC20 {
     * protocol only.
     *
     * @param amount The amount of transaction fees collected for this block.
+    * @param _lastBlockSigners The committee members which committed the previous block.
     * @return upgrade Set to true if an autonity contract upgrade is available.
     * @return committee The next block consensus committee.
     */
-    function finalize(uint256 amount) external onlyProtocol(msg.sender)
+    function finalize(uint256 amount, address[] memory _lastBlockSigners) external onlyProtocol(msg.sender)
         returns(bool , CommitteeMember[] memory) {
 
+        lastBlockSigners = _lastBlockSigners;
         _performRedistribution(amount);
         bool _updateAvailable = bytes(bytecode).length != 0;
         computeCommittee();
         return (_updateAvailable, committee);
     }
 
+    /**
+    * @dev punish burns a fraction of the stake of the validators whose misbehaviour was verified
+    * by the protocol and jails them. It is called before finalize() so that the next committee
+    * excludes them. It must be restricted to the protocol only and never reverts for an offender
+    * which is no longer a validator.
+    *
+    * @param _offenders The misbehaving validators.
+    * @param _reasons The misbehaviour of each offender.
+    * @dev Emit a {Slashed} event for every punished validator.
+    */
+    function punish(address[] memory _offenders, Misbehaviour[] memory _reasons) external onlyProtocol(msg.sender) {
+        require(_offenders.length == _reasons.length, "Incorrect punish params");
+        for (uint256 i = 0; i < _offenders.length; i++) {
+            User storage _user = users[_offenders[i]];
+            if (_user.addr == address(0) || _user.userType != UserType.Validator) {
+                continue;
+            }
+            uint256 _amount = _user.stake.mul(slashingRate).div(SLASHING_RATE_PRECISION);
+            _user.stake = _user.stake.sub(_amount);
+            stakeSupply = stakeSupply.sub(_amount);
+            jailedUntil[_user.addr] = block.number.add(jailPeriod);
+            emit Slashed(_user.addr, _reasons[i], _amount, jailedUntil[_user.addr]);
+        }
+    }
+
     /**
     * @dev Dump the current internal state key elements. Called by the protocol during a contract upgrade.
     * The returned data will be passed directly to the constructor of the new contract at deployment.
@@ -341,6 +427,14 @@ contract Autonity is IERC20 {
         return committee;
     }
 
+    /**
+     * @notice Returns the committee members which committed the previous block.
+     * @dev Set by finalize(), from the past committed seals of the current block.
+     */
+    function getLastBlockSigners() external view returns (address[] memory) {
+        return lastBlockSigners;
+    }
+
     /**
      * @notice Returns the current list of validators.
      */
@@ -386,6 +480,14 @@ contract Autonity is IERC20 {
         return users[_account];
     }
 
+    /**
+    * @return Returns the block from which the validator can be elected in the committee again,
+    * zero if it was never punished.
+    */
+    function getJailedUntil(address _account) external view returns(uint256) {
+        return jailedUntil[_account];
+    }
+
     /**
     * @return Returns the maximum size of the consensus committee.
     */
@@ -462,18 +564,13 @@ contract Autonity is IERC20 {
     function computeCommittee() public onlyProtocol(msg.sender) {
         // Left public for testing purposes.
         require(validators.length > 0, "There must be validators");
-        uint _len = validators.length;
+        User[] memory _validatorList = _electableValidators();
+        uint _len = _validatorList.length;
         uint256 _committeeLength = committeeSize;
         if (_committeeLength >= _len) {_committeeLength = _len;}
 
-        User[] memory _validatorList = new User[](_len);
         User[] memory _committeeList = new User[](_committeeLength);
 
-        for (uint256 i = 0;i < validators.length; i++) {
-            User memory _user = users[validators[i]];
-            _validatorList[i] =_user;
-        }
-
         // If there are more validators than seats in the committee
         if (_validatorList.length > committeeSize) {
             // sort validators by stake in ascending order
@@ -491,7 +588,7 @@ contract Autonity is IERC20 {
         // Update committee in persistent storage
         delete committee;
         for (uint256 _k =0 ; _k < _committeeLength; _k++) {
-            CommitteeMember memory _member = CommitteeMember(_committeeList[_k].addr, _committeeList[_k].stake);
+            CommitteeMember memory _member = CommitteeMember(_committeeList[_k].addr, _committeeList[_k].stake, blsKeys[_committeeList[_k].addr]);
             committee.push(_member);
         }
 
@@ -562,6 +659,37 @@ contract Autonity is IERC20 {
         }
     }
 
+    /**
+    * @dev Returns the validators which are not jailed. If none is left every validator is
+    * returned, the network must keep a committee.
+    */
+    function _electableValidators() internal view returns (User[] memory) {
+        uint256 _count = 0;
+        for (uint256 i = 0; i < validators.length; i++) {
+            if (_isElectable(validators[i])) {
+                _count++;
+            }
+        }
+
+        bool _all = _count == 0;
+        if (_all) {
+            _count = validators.length;
+        }
+        User[] memory _validatorList = new User[](_count);
+        uint256 _j = 0;
+        for (uint256 i = 0; i < validators.length; i++) {
+            if (_all || _isElectable(validators[i])) {
+                _validatorList[_j] = users[validators[i]];
+                _j++;
+            }
+        }
+        return _validatorList;
+    }
+
+    function _isElectable(address _address) internal view returns (bool) {
+        return jailedUntil[_address] <= block.number;
+    }
+
     function _transfer(address sender, address recipient, uint256 amount) internal canUseStake(sender) canUseStake(recipient) {
         users[sender].stake = users[sender].stake.sub(amount, "Transfer amount exceeds balance");
         users[recipient].stake = users[recipient].stake.add(amount);
@@ -646,6 +774,7 @@ contract Autonity is IERC20 {
         }
         stakeSupply = stakeSupply.sub(u.stake);
         _removeFromArray(u.addr, usersList);
+        delete blsKeys[_address];
         delete users[_address];
         emit RemovedUser(_address, u.userType);
     }
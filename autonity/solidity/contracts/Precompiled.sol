@@ -14,4 +14,15 @@ library Precompiled {
 
         return p;
     }
+
+    function blsPossessionCheck(bytes memory _key, bytes memory _proof) internal view returns (bool) {
+        bytes memory _input = abi.encodePacked(_key, _proof);
+        uint[1] memory _valid;
+        assembly {
+            if iszero(staticcall(gas(), 0xfe, add(_input, 0x20), mload(_input), _valid, 0x20)) {
+                revert(0, 0)
+            }
+        }
+        return _valid[0] == 1;
+    }
 }
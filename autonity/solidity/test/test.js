@@ -97,7 +97,7 @@ contract('Autonity', function (accounts) {
 
         it('test redistribution fails with empty balance', async function () {
             try {
-                await token.finalize(10000, {from: deployer});
+                await token.finalize(10000, [], {from: deployer});
                 assert.fail('Expected throw not received', r);
             } catch (e) {
 
@@ -115,7 +115,7 @@ contract('Autonity', function (accounts) {
                 assert.fail("incorrect balance")
             }
             try {
-                await token.finalize(10000, {from: accounts[0]});
+                await token.finalize(10000, [], {from: accounts[0]});
                 assert.fail('Expected throw not received', r);
             } catch (e) {
 
@@ -138,7 +138,7 @@ contract('Autonity', function (accounts) {
             let totalStake= stakes.reduce((a,b) => a + b);
             let stakeholdersPart = stakes.map(element => element * performAmount / totalStake);
 
-            await token.finalize(performAmount, {from: operator});
+            await token.finalize(performAmount, validatorsList, {from: operator});
 
             let balancesAfter = [];
             for (let i = 0; i < st.length; i++) {
@@ -152,6 +152,9 @@ contract('Autonity', function (accounts) {
 
                 assert(check, "not equal")
             }
+
+            let signers = await token.getLastBlockSigners();
+            assert.deepEqual(signers, validatorsList, "last block signers are not the ones passed to finalize");
         });
     });
 
@@ -700,6 +703,82 @@ contract('Autonity', function (accounts) {
         });
     });
 
+    describe('Slashing', function() {
+
+        beforeEach(async function(){
+            token = await utils.deployContract(validatorsList, whiteList,
+                userTypes, stakes, operator, minGasPrice, committeeSize, version,  { from:accounts[8]} );
+        });
+
+        it('test only the operator can set the slashing rate and jail period', async function () {
+            await token.setSlashingRate(2500, {from: operator});
+            await token.setJailPeriod(10, {from: operator});
+            assert.equal(await token.slashingRate(), 2500);
+            assert.equal(await token.jailPeriod(), 10);
+
+            try {
+                let r = await token.setSlashingRate(10001, {from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.equal(await token.slashingRate(), 2500);
+            }
+
+            try {
+                let r = await token.setJailPeriod(20, {from: accounts[6]});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.equal(await token.jailPeriod(), 10);
+            }
+        });
+
+        it('test non deployer cannot punish', async function () {
+            try {
+                let r = await token.punish([validatorsList[0]], [0], {from: operator});
+                assert.fail('Expected throw not received', r);
+            } catch (e) {
+                assert.equal(await token.balanceOf(validatorsList[0]), stakes[0]);
+            }
+        });
+
+        it('test punished validator is slashed and excluded from the committee', async function () {
+            await token.setSlashingRate(1000, {from: operator});
+            let supply = await token.totalSupply();
+
+            let tx = await token.punish([validatorsList[3]], [0], {from: deployer});
+            assert.equal(tx.logs[0].event, "Slashed");
+            assert.equal(tx.logs[0].args._amount, 11);
+            assert.equal(await token.balanceOf(validatorsList[3]), 99);
+            assert.equal(await token.totalSupply(), supply - 11);
+            assert(await token.getJailedUntil(validatorsList[3]) > 0, "validator should be jailed");
+
+            await token.computeCommittee({from: deployer});
+            let committee = await token.getCommittee();
+            let members = committee.map(m => m[0]);
+            assert.equal(members.length, validatorsList.length - 1);
+            assert(!members.includes(validatorsList[3]), "jailed validator should not be in the committee");
+        });
+
+        it('test punishing a non validator is ignored', async function () {
+            let tx = await token.punish([accounts[6]], [1], {from: deployer});
+            assert.equal(tx.logs.length, 0);
+        });
+
+        it('test validator is elected again after the jail period', async function () {
+            await token.setJailPeriod(1, {from: operator});
+            await token.punish([validatorsList[0]], [1], {from: deployer});
+            await token.computeCommittee({from: deployer});
+            let members = (await token.getCommittee()).map(m => m[0]);
+            assert(members.includes(validatorsList[0]), "released validator should be in the committee");
+        });
+
+        it('test committee is kept when every validator is jailed', async function () {
+            await token.punish(validatorsList, [0, 0, 0, 0, 0], {from: deployer});
+            await token.computeCommittee({from: deployer});
+            let members = (await token.getCommittee()).map(m => m[0]);
+            assert.deepEqual(members.slice().sort(), validatorsList.slice().sort());
+        });
+    });
+
     describe('Proposer selection, Normal case.', function() {
 
         beforeEach(async function(){
@@ -0,0 +1,88 @@
+package autonity
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/clearmatics/autonity/accounts/abi/statebind"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/params"
+)
+
+// StateDumpUser is a user of the Autonity contract as returned by getState.
+type StateDumpUser struct {
+	Address common.Address  `json:"address"`
+	Enode   string          `json:"enode"`
+	Type    params.UserType `json:"type"`
+	Stake   *big.Int        `json:"stake"`
+}
+
+// StateDump is the decoded output of the getState contract method, which
+// holds everything required to rebuild the contract at another height.
+type StateDump struct {
+	Users           []StateDumpUser `json:"users"`
+	Operator        common.Address  `json:"operator"`
+	MinGasPrice     *big.Int        `json:"minGasPrice"`
+	CommitteeSize   *big.Int        `json:"committeeSize"`
+	ContractVersion string          `json:"contractVersion"`
+}
+
+// DumpState calls getState on the state of the block of header and decodes its
+// output.
+func (ac *Contract) DumpState(statedb *state.StateDB, header *types.Header) (*StateDump, error) {
+	binding := ac.abiAt(header.Number.Uint64()).binding
+	if binding == nil {
+		return nil, errUnknownABI
+	}
+	return dumpState(binding.contract, statedb, header)
+}
+
+// dumpState calls getState through contract, whose ABI may not be the one of
+// the bindings, and checks the output types before decoding it.
+func dumpState(contract *statebind.BoundContract, statedb *state.StateDB, header *types.Header) (*StateDump, error) {
+	var out []interface{}
+	if err := contract.Call(statedb, header, &out, "getState"); err != nil {
+		return nil, err
+	}
+	if len(out) != 8 {
+		return nil, fmt.Errorf("unexpected getState output: %d values", len(out))
+	}
+	addrs, ok0 := out[0].([]common.Address)
+	enodes, ok1 := out[1].([]string)
+	userTypes, ok2 := out[2].([]*big.Int)
+	stakes, ok3 := out[3].([]*big.Int)
+	operator, ok4 := out[4].(common.Address)
+	minGasPrice, ok5 := out[5].(*big.Int)
+	committeeSize, ok6 := out[6].(*big.Int)
+	version, ok7 := out[7].(string)
+	if !(ok0 && ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7) {
+		return nil, fmt.Errorf("unexpected getState output types")
+	}
+	if len(addrs) != len(enodes) || len(addrs) != len(userTypes) || len(addrs) != len(stakes) {
+		return nil, fmt.Errorf("inconsistent getState output: %d addresses, %d enodes, %d user types, %d stakes",
+			len(addrs), len(enodes), len(userTypes), len(stakes))
+	}
+
+	dump := &StateDump{
+		Users:           make([]StateDumpUser, len(addrs)),
+		Operator:        operator,
+		MinGasPrice:     minGasPrice,
+		CommitteeSize:   committeeSize,
+		ContractVersion: version,
+	}
+	for i := range addrs {
+		userType, ok := params.UserTypeFromID(int(userTypes[i].Int64()))
+		if !userTypes[i].IsInt64() || !ok {
+			return nil, fmt.Errorf("unknown user type %v for user %s", userTypes[i], addrs[i].Hex())
+		}
+		dump.Users[i] = StateDumpUser{
+			Address: addrs[i],
+			Enode:   enodes[i],
+			Type:    userType,
+			Stake:   stakes[i],
+		}
+	}
+	return dump, nil
+}
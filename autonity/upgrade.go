@@ -0,0 +1,417 @@
+package autonity
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/accounts/abi/statebind"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// ContractUpgrade records an upgrade of the Autonity contract applied by the
+// finalize of the block at Number.
+type ContractUpgrade struct {
+	Number  uint64      `json:"number"`
+	Version string      `json:"version"`
+	ABIHash common.Hash `json:"abiHash"`
+}
+
+// UpgradeChange is a difference of the contract state or committee between
+// before and after an upgrade.
+type UpgradeChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ViewCheck compares the output of a view method without arguments before and
+// after an upgrade. A method missing on one side has an Error.
+type ViewCheck struct {
+	Method  string      `json:"method"`
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+	Changed bool        `json:"changed"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// UpgradeSimulation reports the effects of an upgrade of the Autonity contract
+// run on a copy of the state of the block at Number.
+type UpgradeSimulation struct {
+	Number          uint64          `json:"number"`
+	StateBefore     *StateDump      `json:"stateBefore"`
+	StateAfter      *StateDump      `json:"stateAfter"`
+	CommitteeBefore types.Committee `json:"committeeBefore"`
+	CommitteeAfter  types.Committee `json:"committeeAfter"`
+	Diff            []UpgradeChange `json:"diff"`
+	Views           []ViewCheck     `json:"views"`
+	FinalizeError   string          `json:"finalizeError,omitempty"`
+}
+
+// UpgradeHistory returns the upgrades of the contract applied by this node in
+// ascending height order.
+func (ac *Contract) UpgradeHistory() ([]ContractUpgrade, error) {
+	records := ac.bc.ReadContractUpgrades()
+	history := make([]ContractUpgrade, 0, len(records))
+	for _, record := range records {
+		var upgrade ContractUpgrade
+		if err := rlp.DecodeBytes(record, &upgrade); err != nil {
+			return nil, err
+		}
+		history = append(history, upgrade)
+	}
+	return history, nil
+}
+
+func (ac *Contract) recordUpgrade(number uint64, version string, newAbi string) {
+	record, err := rlp.EncodeToBytes(&ContractUpgrade{
+		Number:  number,
+		Version: version,
+		ABIHash: crypto.Keccak256Hash([]byte(newAbi)),
+	})
+	if err != nil {
+		log.Error("Could not encode contract upgrade", "err", err)
+		return
+	}
+	ac.bc.WriteContractUpgrade(number, record)
+}
+
+// Upgrade is an upgrade of the contract applied by the finalize of a block, whose
+// ABI is in use from the state of this block. It is only known to the processing
+// of the block until committed.
+type Upgrade struct {
+	version versionedABI
+}
+
+// AppliedUpgrade returns the upgrade of the contract applied by the finalize of
+// the block of header, nil if there is none. An upgrade is pending in parent, the
+// state of its parent, and no longer in statedb, the state of the block, once it
+// is applied.
+func (ac *Contract) AppliedUpgrade(header *types.Header, parent, statedb *state.StateDB) (*Upgrade, error) {
+	// the contract is not deployed before the first block
+	bytecode, newAbi, err := ac.callRetrieveContract(parent, header)
+	if err != nil || bytecode == "" {
+		return nil, nil
+	}
+	// a failed upgrade is still pending, it is retried by the next block. The
+	// upgraded contract may not retrieve the upgrades anymore.
+	if bytecode, _, err := ac.callRetrieveContract(statedb.Copy(), header); err == nil && bytecode != "" {
+		return nil, nil
+	}
+	number := header.Number.Uint64()
+	version, err := ac.newVersionedABI(number, newAbi)
+	if err != nil {
+		return nil, fmt.Errorf("invalid abi of the upgrade at block %d: %v", number, err)
+	}
+	return &Upgrade{version: version}, nil
+}
+
+// CommitUpgrade persists the ABI and records upgrade, applied by the finalize of
+// the block of header whose state is statedb. It is called once the block is
+// inserted in the canonical chain, nothing is persisted for the blocks proposed
+// or verified. The version is read from the upgraded contract, it is left empty
+// if the new contract cannot dump its state.
+func (ac *Contract) CommitUpgrade(header *types.Header, statedb *state.StateDB, upgrade *Upgrade) {
+	if upgrade == nil {
+		return
+	}
+	number := header.Number.Uint64()
+	// the new abi is in use from the state of this block.
+	ac.addVersion(upgrade.version)
+	newAbi := upgrade.version.json
+	ac.bc.WriteContractABI(number, []byte(newAbi))
+	var version string
+	if dump, err := ac.DumpState(statedb.Copy(), header); err != nil {
+		log.Warn("Upgraded Autonity Contract cannot dump its state", "number", number, "err", err)
+	} else {
+		version = dump.ContractVersion
+	}
+	ac.recordUpgrade(number, version, newAbi)
+	log.Info("Autonity Contract upgrade success", "number", number, "version", version)
+}
+
+// simulationCaller calls the contract for an upgrade simulation, with a gas cap
+// and an evm cancelled once ctx is done.
+type simulationCaller struct {
+	ctx         context.Context
+	evmProvider EVMProvider
+	gas         uint64
+}
+
+// run executes fn on a new evm, which is cancelled once the context is done.
+func (c *simulationCaller) run(header *types.Header, statedb *state.StateDB, fn func(evm *vm.EVM) ([]byte, error)) ([]byte, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	evm := c.evmProvider.EVM(header, Deployer, statedb)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	ret, err := fn(evm)
+	if ctxErr := c.ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return ret, err
+}
+
+// StateCall implements statebind.StateCaller.
+func (c *simulationCaller) StateCall(statedb *state.StateDB, header *types.Header, contract common.Address, input []byte) ([]byte, error) {
+	return c.run(header, statedb, func(evm *vm.EVM) ([]byte, error) {
+		ret, _, err := evm.Call(vm.AccountRef(Deployer), contract, input, c.gas, new(big.Int))
+		return ret, err
+	})
+}
+
+// deploy replaces the contract with bytecode, constructed with the packed state.
+func (c *simulationCaller) deploy(header *types.Header, statedb *state.StateDB, bytecode string, packedState []byte) error {
+	_, err := c.run(header, statedb, func(evm *vm.EVM) ([]byte, error) {
+		data := append(common.Hex2Bytes(bytecode), packedState...)
+		_, _, _, err := evm.CreateWithAddress(vm.AccountRef(Deployer), data, c.gas, new(big.Int), ContractAddress)
+		return nil, err
+	})
+	return err
+}
+
+// SimulateUpgrade dry-runs the upgrade of the contract to bytecode and newAbi on
+// a copy of statedb: the state is dumped, the contract redeployed with it, the
+// view methods without arguments are compared and finalize is called on the
+// new contract. Nothing is persisted. Every call is given at most gas and the
+// simulation is aborted once ctx is done.
+func (ac *Contract) SimulateUpgrade(ctx context.Context, statedb *state.StateDB, header *types.Header, bytecode string, newAbi string, gas uint64) (*UpgradeSimulation, error) {
+	bytecode = strings.TrimPrefix(bytecode, "0x")
+	if _, err := hex.DecodeString(bytecode); err != nil || len(bytecode) == 0 {
+		return nil, fmt.Errorf("invalid bytecode: %v", err)
+	}
+	caller := &simulationCaller{ctx: ctx, evmProvider: ac.evmProvider, gas: gas}
+	newContract, err := newBoundContract(newAbi, caller)
+	if err != nil {
+		return nil, fmt.Errorf("invalid abi: %v", err)
+	}
+	binding, err := NewAutonity(ContractAddress, caller)
+	if err != nil {
+		return nil, err
+	}
+	oldContract := statebind.NewBoundContract(ContractAddress, *ac.ABIAt(header.Number.Uint64()), caller)
+	statedb = statedb.Copy()
+
+	sim := &UpgradeSimulation{Number: header.Number.Uint64()}
+	if sim.StateBefore, err = dumpState(oldContract, statedb, header); err != nil {
+		return nil, fmt.Errorf("could not dump the current state: %v", err)
+	}
+	members, err := binding.GetCommittee(statedb, header)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the current committee: %v", err)
+	}
+	sim.CommitteeBefore = committeeFromBinding(members)
+	sort.Sort(sim.CommitteeBefore)
+	viewsBefore := callViews(oldContract, statedb, header)
+
+	packedState, err := caller.StateCall(statedb, header, ContractAddress, binding.ABI().Methods["getState"].ID)
+	if err != nil {
+		return nil, err
+	}
+	statedb.CreateAccount(ContractAddress)
+	if err := caller.deploy(header, statedb, bytecode, packedState); err != nil {
+		return nil, fmt.Errorf("could not deploy the new contract: %v", err)
+	}
+	if sim.StateAfter, err = dumpState(newContract, statedb, header); err != nil {
+		return nil, fmt.Errorf("could not dump the state of the new contract: %v", err)
+	}
+	sim.Views = compareViews(viewsBefore, callViews(newContract, statedb, header))
+
+	var out []interface{}
+	if err := newContract.Call(statedb, header, &out, "finalize", new(big.Int), []common.Address{}); err != nil {
+		sim.FinalizeError = err.Error()
+	} else if sim.CommitteeAfter, err = committeeFromOutput(out); err != nil {
+		sim.FinalizeError = err.Error()
+	} else {
+		sort.Sort(sim.CommitteeAfter)
+	}
+	// the view and finalize calls fail once the context is done
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("upgrade simulation aborted: %v", err)
+	}
+
+	sim.Diff = append(diffStates(sim.StateBefore, sim.StateAfter), diffCommittees(sim.CommitteeBefore, sim.CommitteeAfter)...)
+	return sim, nil
+}
+
+// newBoundContract binds the contract address to an ABI which may differ from
+// the one the bindings are generated from.
+func newBoundContract(abiJSON string, caller statebind.StateCaller) (*statebind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	return statebind.NewBoundContract(ContractAddress, parsed, caller), nil
+}
+
+type viewResult struct {
+	out interface{}
+	err error
+}
+
+// callViews calls the view methods without arguments, except the ones covered
+// by the state dump or holding the upgrade itself.
+func callViews(contract *statebind.BoundContract, statedb *state.StateDB, header *types.Header) map[string]viewResult {
+	results := make(map[string]viewResult)
+	for name, method := range contract.ABI().Methods {
+		if len(method.Inputs) != 0 || !method.IsConstant() || name == "getState" || name == "getNewContract" {
+			continue
+		}
+		var out []interface{}
+		err := contract.Call(statedb, header, &out, name)
+		if len(out) == 1 {
+			results[name] = viewResult{out: out[0], err: err}
+		} else {
+			results[name] = viewResult{out: out, err: err}
+		}
+	}
+	return results
+}
+
+func compareViews(before, after map[string]viewResult) []ViewCheck {
+	names := make(map[string]struct{})
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+	checks := make([]ViewCheck, 0, len(names))
+	for name := range names {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		check := ViewCheck{Method: name, Before: b.out, After: a.out}
+		switch {
+		case !inBefore:
+			check.Error = "method added"
+		case !inAfter:
+			check.Error = "method removed"
+		case b.err != nil:
+			check.Error = fmt.Sprintf("before: %v", b.err)
+		case a.err != nil:
+			check.Error = fmt.Sprintf("after: %v", a.err)
+		}
+		check.Changed = check.Error != "" || !reflect.DeepEqual(b.out, a.out)
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Method < checks[j].Method })
+	return checks
+}
+
+// committeeFromOutput decodes the committee returned by finalize. The output
+// is unpacked with an ABI unknown at compile time, the members are converted
+// through their JSON encoding instead of an unchecked type conversion.
+func committeeFromOutput(out []interface{}) (types.Committee, error) {
+	if len(out) != 2 {
+		return nil, fmt.Errorf("unexpected finalize output: %d values", len(out))
+	}
+	encoded, err := json.Marshal(out[1])
+	if err != nil {
+		return nil, err
+	}
+	var members []struct {
+		Addr        common.Address `json:"addr"`
+		VotingPower *big.Int       `json:"votingPower"`
+		BlsKey      []byte         `json:"blsKey"`
+	}
+	if err := json.Unmarshal(encoded, &members); err != nil {
+		return nil, fmt.Errorf("unexpected finalize committee: %v", err)
+	}
+	committee := make(types.Committee, len(members))
+	for i, m := range members {
+		if m.VotingPower == nil {
+			return nil, fmt.Errorf("unexpected finalize committee: missing voting power")
+		}
+		committee[i] = types.CommitteeMember{Address: m.Addr, VotingPower: m.VotingPower, BLSKey: m.BlsKey}
+	}
+	return committee, nil
+}
+
+func diffStates(before, after *StateDump) []UpgradeChange {
+	var diff []UpgradeChange
+	usersAfter := make(map[common.Address]StateDumpUser)
+	for _, u := range after.Users {
+		usersAfter[u.Address] = u
+	}
+	usersBefore := make(map[common.Address]struct{})
+	for _, b := range before.Users {
+		usersBefore[b.Address] = struct{}{}
+		field := "users." + b.Address.Hex()
+		a, ok := usersAfter[b.Address]
+		switch {
+		case !ok:
+			diff = append(diff, UpgradeChange{Field: field, Before: b})
+		case a.Enode != b.Enode || a.Type != b.Type || a.Stake.Cmp(b.Stake) != 0:
+			diff = append(diff, UpgradeChange{Field: field, Before: b, After: a})
+		}
+	}
+	for _, a := range after.Users {
+		if _, ok := usersBefore[a.Address]; !ok {
+			diff = append(diff, UpgradeChange{Field: "users." + a.Address.Hex(), After: a})
+		}
+	}
+	if before.Operator != after.Operator {
+		diff = append(diff, UpgradeChange{Field: "operator", Before: before.Operator, After: after.Operator})
+	}
+	if before.MinGasPrice.Cmp(after.MinGasPrice) != 0 {
+		diff = append(diff, UpgradeChange{Field: "minGasPrice", Before: before.MinGasPrice, After: after.MinGasPrice})
+	}
+	if before.CommitteeSize.Cmp(after.CommitteeSize) != 0 {
+		diff = append(diff, UpgradeChange{Field: "committeeSize", Before: before.CommitteeSize, After: after.CommitteeSize})
+	}
+	if before.ContractVersion != after.ContractVersion {
+		diff = append(diff, UpgradeChange{Field: "contractVersion", Before: before.ContractVersion, After: after.ContractVersion})
+	}
+	return diff
+}
+
+// diffCommittees compares the voting powers of the members, after is nil if
+// finalize failed on the new contract.
+func diffCommittees(before, after types.Committee) []UpgradeChange {
+	if after == nil {
+		return nil
+	}
+	var diff []UpgradeChange
+	powersAfter := make(map[common.Address]*big.Int)
+	for _, m := range after {
+		powersAfter[m.Address] = m.VotingPower
+	}
+	membersBefore := make(map[common.Address]struct{})
+	for _, m := range before {
+		membersBefore[m.Address] = struct{}{}
+		field := "committee." + m.Address.Hex()
+		power, ok := powersAfter[m.Address]
+		switch {
+		case !ok:
+			diff = append(diff, UpgradeChange{Field: field, Before: m.VotingPower})
+		case power.Cmp(m.VotingPower) != 0:
+			diff = append(diff, UpgradeChange{Field: field, Before: m.VotingPower, After: power})
+		}
+	}
+	for _, m := range after {
+		if _, ok := membersBefore[m.Address]; !ok {
+			diff = append(diff, UpgradeChange{Field: "committee." + m.Address.Hex(), After: m.VotingPower})
+		}
+	}
+	return diff
+}
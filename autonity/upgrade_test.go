@@ -0,0 +1,264 @@
+package autonity
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/acdefault"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/params"
+)
+
+type testBlockchain struct {
+	db ethdb.Database
+}
+
+func (bc *testBlockchain) UpdateEnodeWhitelist(*types.Nodes) {}
+
+func (bc *testBlockchain) ReadEnodeWhitelist() *types.Nodes { return types.NewNodes(nil) }
+
+func (bc *testBlockchain) WriteContractABI(number uint64, abi []byte) {
+	rawdb.WriteContractABI(bc.db, number, abi)
+}
+
+func (bc *testBlockchain) ReadContractABIs() ([]uint64, [][]byte) {
+	return rawdb.ReadContractABIs(bc.db)
+}
+
+func (bc *testBlockchain) WriteContractUpgrade(number uint64, upgrade []byte) {
+	rawdb.WriteContractUpgrade(bc.db, number, upgrade)
+}
+
+func (bc *testBlockchain) ReadContractUpgrades() [][]byte {
+	return rawdb.ReadContractUpgrades(bc.db)
+}
+
+func TestUpgradeHistory(t *testing.T) {
+	ac, err := NewAutonityContract(&testBlockchain{db: rawdb.NewMemoryDatabase()}, Deployer, 0, acdefault.ABI(), testEVMProvider{})
+	require.NoError(t, err)
+
+	history, err := ac.UpgradeHistory()
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	ac.recordUpgrade(300, "v2", "[]")
+	ac.recordUpgrade(20, "v1", "[{}]")
+	history, err = ac.UpgradeHistory()
+	require.NoError(t, err)
+	require.Equal(t, []ContractUpgrade{
+		{Number: 20, Version: "v1", ABIHash: crypto.Keccak256Hash([]byte("[{}]"))},
+		{Number: 300, Version: "v2", ABIHash: crypto.Keccak256Hash([]byte("[]"))},
+	}, history)
+}
+
+func TestDiffStates(t *testing.T) {
+	a, b, c := common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xc")
+	before := &StateDump{
+		Users: []StateDumpUser{
+			{Address: a, Type: params.UserValidator, Stake: big.NewInt(1)},
+			{Address: b, Type: params.UserValidator, Stake: big.NewInt(2)},
+		},
+		MinGasPrice:     big.NewInt(5),
+		CommitteeSize:   big.NewInt(10),
+		ContractVersion: "v1",
+	}
+	after := &StateDump{
+		Users: []StateDumpUser{
+			{Address: a, Type: params.UserValidator, Stake: big.NewInt(1)},
+			{Address: c, Type: params.UserParticipant, Stake: big.NewInt(0)},
+		},
+		MinGasPrice:     big.NewInt(5),
+		CommitteeSize:   big.NewInt(10),
+		ContractVersion: "v2",
+	}
+	require.Equal(t, []UpgradeChange{
+		{Field: "users." + b.Hex(), Before: before.Users[1]},
+		{Field: "users." + c.Hex(), After: after.Users[1]},
+		{Field: "contractVersion", Before: "v1", After: "v2"},
+	}, diffStates(before, after))
+	require.Empty(t, diffStates(before, before))
+
+	committee := types.Committee{{Address: a, VotingPower: big.NewInt(1)}, {Address: b, VotingPower: big.NewInt(2)}}
+	require.Nil(t, diffCommittees(committee, nil))
+	require.Equal(t, []UpgradeChange{
+		{Field: "committee." + b.Hex(), Before: big.NewInt(2), After: big.NewInt(3)},
+	}, diffCommittees(committee, types.Committee{{Address: a, VotingPower: big.NewInt(1)}, {Address: b, VotingPower: big.NewInt(3)}}))
+}
+
+func TestCompareViews(t *testing.T) {
+	before := map[string]viewResult{
+		"getVersion":  {out: "v1"},
+		"totalSupply": {out: big.NewInt(3)},
+		"removed":     {out: true},
+	}
+	after := map[string]viewResult{
+		"getVersion":  {out: "v2"},
+		"totalSupply": {out: big.NewInt(3)},
+		"added":       {out: true},
+	}
+	checks := compareViews(before, after)
+	require.Equal(t, []ViewCheck{
+		{Method: "added", After: true, Changed: true, Error: "method added"},
+		{Method: "getVersion", Before: "v1", After: "v2", Changed: true},
+		{Method: "removed", Before: true, Changed: true, Error: "method removed"},
+		{Method: "totalSupply", Before: big.NewInt(3), After: big.NewInt(3)},
+	}, checks)
+}
+
+func TestCommitteeFromOutput(t *testing.T) {
+	finalize := logParser.contract.ABI().Methods["finalize"]
+	members := []AutonityCommitteeMember{{Addr: common.HexToAddress("0xa"), VotingPower: big.NewInt(4), BlsKey: []byte{1, 2}}}
+	packed, err := finalize.Outputs.Pack(true, members)
+	require.NoError(t, err)
+	out, err := finalize.Outputs.Unpack(packed)
+	require.NoError(t, err)
+
+	committee, err := committeeFromOutput(out)
+	require.NoError(t, err)
+	require.Equal(t, types.Committee{{Address: members[0].Addr, VotingPower: big.NewInt(4), BLSKey: []byte{1, 2}}}, committee)
+
+	_, err = committeeFromOutput(out[:1])
+	require.Error(t, err)
+}
+
+func TestSimulateUpgrade(t *testing.T) {
+	ac, statedb, header := deployTestContract(t, []uint64{10, 20, 30})
+	root := statedb.IntermediateRoot(true)
+
+	simulation, err := ac.SimulateUpgrade(context.Background(), statedb, header, acdefault.Bytecode(), acdefault.ABI(), math.MaxUint64/2)
+	require.NoError(t, err)
+	require.Empty(t, simulation.Diff)
+	require.Empty(t, simulation.FinalizeError)
+	require.Equal(t, simulation.CommitteeBefore, simulation.CommitteeAfter)
+	for _, view := range simulation.Views {
+		require.False(t, view.Changed, view.Method)
+	}
+	// The simulation runs on a copy of the state.
+	require.Equal(t, root, statedb.IntermediateRoot(true))
+
+	// The simulation is bounded by the gas cap and the context.
+	_, err = ac.SimulateUpgrade(context.Background(), statedb, header, acdefault.Bytecode(), acdefault.ABI(), 1000)
+	require.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ac.SimulateUpgrade(ctx, statedb, header, acdefault.Bytecode(), acdefault.ABI(), math.MaxUint64/2)
+	require.Error(t, err)
+
+	_, err = ac.SimulateUpgrade(context.Background(), statedb, header, "zz", acdefault.ABI(), math.MaxUint64/2)
+	require.Error(t, err)
+	_, err = ac.SimulateUpgrade(context.Background(), statedb, header, acdefault.Bytecode(), "{", math.MaxUint64/2)
+	require.Error(t, err)
+}
+
+func TestCommitUpgrade(t *testing.T) {
+	ac, statedb, header := deployTestContract(t, []uint64{10, 20, 30})
+	bc := &testBlockchain{db: rawdb.NewMemoryDatabase()}
+	ac.bc = bc
+
+	// No upgrade is pending.
+	upgrade, err := ac.AppliedUpgrade(header, statedb.Copy(), statedb)
+	require.NoError(t, err)
+	require.Nil(t, upgrade)
+
+	_, err = ac.Bindings().UpgradeContract(statedb, header, acdefault.Bytecode(), acdefault.ABI(), "v2")
+	require.NoError(t, err)
+	parent := statedb.Copy()
+
+	// The upgrade is still pending in a state where it was not applied.
+	upgrade, err = ac.AppliedUpgrade(header, parent, parent.Copy())
+	require.NoError(t, err)
+	require.Nil(t, upgrade)
+
+	// The upgrade applied by finalize is in use from the state of its block, it
+	// is only persisted and recorded once committed.
+	require.NoError(t, ac.performContractUpgrade(statedb, header))
+	upgrade, err = ac.AppliedUpgrade(header, parent, statedb)
+	require.NoError(t, err)
+	require.NotNil(t, upgrade)
+	require.NoError(t, ac.MeasureMetricsOfNetworkEconomic(header, statedb, upgrade))
+	require.Empty(t, bc.ReadContractUpgrades())
+	numbers, _ := bc.ReadContractABIs()
+	require.Empty(t, numbers)
+	ac.CommitUpgrade(header, statedb, upgrade)
+	numbers, _ = bc.ReadContractABIs()
+	require.Equal(t, []uint64{header.Number.Uint64()}, numbers)
+	history, err := ac.UpgradeHistory()
+	require.NoError(t, err)
+	require.Equal(t, []ContractUpgrade{{Number: header.Number.Uint64(), Version: "v2", ABIHash: crypto.Keccak256Hash([]byte(acdefault.ABI()))}}, history)
+}
+
+func TestVersionedABI(t *testing.T) {
+	bc := &testBlockchain{db: rawdb.NewMemoryDatabase()}
+	ac, err := NewAutonityContract(bc, Deployer, 0, acdefault.ABI(), testEVMProvider{})
+	require.NoError(t, err)
+	numbers, _ := bc.ReadContractABIs()
+	require.Equal(t, []uint64{0}, numbers)
+
+	const (
+		abiV2 = `[{"type":"function","name":"finalize","stateMutability":"nonpayable","inputs":[{"name":"amount","type":"uint256"}],"outputs":[]}]`
+		abiV3 = `[]`
+	)
+	require.NoError(t, ac.upgradeAbiCache(20, abiV3))
+	require.NoError(t, ac.upgradeAbiCache(10, abiV2))
+	require.Error(t, ac.upgradeAbiCache(30, "{"))
+
+	for number, want := range map[uint64]string{0: acdefault.ABI(), 9: acdefault.ABI(), 10: abiV2, 19: abiV2, 20: abiV3, 1000: abiV3} {
+		require.Equal(t, want, ac.StringABIAt(number), number)
+	}
+	require.Equal(t, abiV3, ac.StringABI())
+	require.Empty(t, ac.ABI().Methods)
+
+	// The block following an upgrade is processed with the new ABI and bindings.
+	processingABI := func(number int64) *abi.ABI {
+		parsed, err := ac.processingABI(&types.Header{Number: big.NewInt(number)})
+		require.NoError(t, err)
+		binding, err := ac.processingBinding(&types.Header{Number: big.NewInt(number)})
+		require.NoError(t, err)
+		require.Equal(t, parsed, binding.ABI())
+		return parsed
+	}
+	require.Equal(t, ac.ABIAt(9), processingABI(10))
+	require.Contains(t, processingABI(20).Methods, "finalize")
+	require.Empty(t, processingABI(21).Methods)
+	require.Empty(t, ac.Bindings().ABI().Methods)
+
+	// The state of a block applying an upgrade is called with its ABI before it
+	// is committed.
+	version, err := ac.newVersionedABI(30, abiV2)
+	require.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(30)}
+	binding, err := ac.stateBinding(header, &Upgrade{version: version})
+	require.NoError(t, err)
+	require.Contains(t, binding.ABI().Methods, "finalize")
+	binding, err = ac.stateBinding(header, nil)
+	require.NoError(t, err)
+	require.Empty(t, binding.ABI().Methods)
+
+	// The ABI of an unresolved range of heights is unknown.
+	require.NoError(t, ac.upgradeAbiCache(40, ""))
+	require.Nil(t, ac.ABIAt(40))
+	require.Empty(t, ac.StringABIAt(45))
+	_, err = ac.processingBinding(&types.Header{Number: big.NewInt(41)})
+	require.Equal(t, errUnknownABI, err)
+	_, err = ac.callGetCommittee(nil, &types.Header{Number: big.NewInt(41)})
+	require.Equal(t, errUnknownABI, err)
+	require.NoError(t, ac.upgradeAbiCache(50, abiV2))
+	require.Equal(t, abiV2, ac.StringABIAt(50))
+
+	// The stored ABIs are loaded on restart.
+	bc.WriteContractABI(10, []byte(abiV2))
+	ac, err = NewAutonityContract(bc, Deployer, 0, acdefault.ABI(), testEVMProvider{})
+	require.NoError(t, err)
+	require.Equal(t, abiV2, ac.StringABIAt(15))
+	require.Equal(t, abiV2, ac.StringABI())
+	require.Equal(t, acdefault.ABI(), ac.StringABIAt(0))
+}
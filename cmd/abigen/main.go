@@ -91,7 +91,7 @@ var (
 	}
 	langFlag = cli.StringFlag{
 		Name:  "lang",
-		Usage: "Destination language for the bindings (go, java, objc)",
+		Usage: "Destination language for the bindings (go, gostate, java, objc)",
 		Value: "go",
 	}
 	aliasFlag = cli.StringFlag{
@@ -130,6 +130,8 @@ func abigen(c *cli.Context) error {
 	switch c.GlobalString(langFlag.Name) {
 	case "go":
 		lang = bind.LangGo
+	case "gostate":
+		lang = bind.LangGoState
 	case "java":
 		lang = bind.LangJava
 	case "objc":
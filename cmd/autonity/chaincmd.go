@@ -222,6 +222,10 @@ func initGenesis(ctx *cli.Context) error {
 
 	setupDefaults(genesis)
 
+	if err := genesis.Config.Tendermint.Validate(); err != nil {
+		return fmt.Errorf("tendermint section is invalid. error:%v", err.Error())
+	}
+
 	// Open an initialise both full and light databases
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -252,6 +256,7 @@ func setupDefaults(genesis *core.Genesis) {
 		if genesis.Config.Tendermint.BlockPeriod == 0 {
 			genesis.Config.Tendermint.BlockPeriod = defaultConfig.BlockPeriod
 		}
+		genesis.Config.Tendermint.SetDefaults()
 	}
 }
 
@@ -163,8 +163,9 @@ func loadGenesisFile(genesisPath string) (*core.Genesis, error) {
 		return nil, err
 	}
 
-	if genesis.Config.Tendermint.BlockPeriod == 0 {
-		return nil, fmt.Errorf("invalid block period configured for tendermint")
+	genesis.Config.Tendermint.SetDefaults()
+	if err := genesis.Config.Tendermint.Validate(); err != nil {
+		return nil, err
 	}
 
 	return genesis, nil
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clearmatics/autonity/cmd/utils"
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	consensusJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report as JSON",
+	}
+
+	consensusCommand = cli.Command{
+		Name:      "consensus",
+		Usage:     "Analyse the consensus of the local node",
+		ArgsUsage: "",
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The consensus commands run against the local chain database, the node must be
+stopped.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "replay",
+				Usage:     "Replay a consensus message log and report the rounds",
+				ArgsUsage: "[<logDir>]",
+				Action:    utils.MigrateFlags(replayConsensusLog),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.SyncModeFlag,
+					configFileFlag,
+					consensusJSONFlag,
+				},
+				Description: `
+    autonity consensus replay [logDir]
+
+replays the consensus messages recorded by a node with the Tendermint MessageLog
+option through a headless consensus engine, the configured log directory by
+default. It prints the round changes of the node, the votes tallied per round
+and the committee members whose votes were missing or arrived after the node
+left the round. The blocks the messages build upon must be in the database.`,
+			},
+		},
+	}
+)
+
+func replayConsensusLog(ctx *cli.Context) error {
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	dir := ctx.Args().First()
+	if dir == "" {
+		if cfg.Eth.Tendermint.MessageLog == "" {
+			utils.Fatalf("No message log directory given or configured")
+		}
+		dir = stack.ResolvePath(cfg.Eth.Tendermint.MessageLog)
+	}
+	messages, err := tendermintCore.ReadMessageLog(dir)
+	if err != nil {
+		utils.Fatalf("Could not read the message log: %v", err)
+	}
+	if len(messages) == 0 {
+		utils.Fatalf("No message recorded in %s", dir)
+	}
+
+	chain, chainDb := utils.MakeChain(ctx, stack, true)
+	defer chainDb.Close()
+	if chain.Config().Tendermint == nil {
+		utils.Fatalf("The chain doesn't run the Tendermint consensus")
+	}
+
+	report := tendermintCore.Replay(chain, &cfg.Eth.Tendermint, messages)
+	if ctx.Bool(consensusJSONFlag.Name) {
+		return printJSON(report)
+	}
+	printReplayReport(os.Stdout, report)
+	return nil
+}
+
+func printReplayReport(w io.Writer, report *tendermintCore.ReplayReport) {
+	fmt.Fprintf(w, "Node %s: %d messages, %d invalid\n", report.Node.Hex(), report.Messages, report.Invalid)
+
+	fmt.Fprintln(w, "\nRound changes:")
+	for _, change := range report.RoundChanges {
+		fmt.Fprintf(w, "  %s  height %d round %d  (%s)\n", change.Time.Format("15:04:05.000"), change.Height, change.Round, change.Cause)
+	}
+
+	for _, height := range report.Heights {
+		fmt.Fprintf(w, "\nHeight %d", height.Height)
+		if height.Decision != nil {
+			fmt.Fprintf(w, ": committed %s at round %d", height.Decision.Hash.TerminalString(), height.Decision.Round)
+		}
+		fmt.Fprintln(w)
+		total := height.Committee.TotalVotingPower()
+		for _, round := range height.Rounds {
+			fmt.Fprintf(w, "  Round %d", round.Round)
+			if !round.Start.IsZero() {
+				fmt.Fprintf(w, "  entered %s", round.Start.Format("15:04:05.000"))
+			}
+			if !round.End.IsZero() {
+				fmt.Fprintf(w, "  left after %v", round.End.Sub(round.Start))
+			}
+			fmt.Fprintln(w)
+			for _, proposal := range round.Proposals {
+				fmt.Fprintf(w, "    proposal    %s from %s (valid round %d)%s\n", proposal.Hash.TerminalString(),
+					proposal.Proposer.Hex(), proposal.ValidRound, since(round.Start, proposal.Arrival))
+			}
+			printStepReport(w, "prevotes", round.Start, total, round.Prevotes)
+			printStepReport(w, "precommits", round.Start, total, round.Precommits)
+		}
+	}
+}
+
+func printStepReport(w io.Writer, name string, start time.Time, total uint64, step *tendermintCore.StepReport) {
+	var tally []string
+	for _, t := range step.Tally {
+		value := "nil"
+		if t.Hash != (common.Hash{}) {
+			value = t.Hash.TerminalString()
+		}
+		tally = append(tally, fmt.Sprintf("%s %d/%d", value, t.Power, total))
+	}
+	fmt.Fprintf(w, "    %-11s %s", name, strings.Join(tally, ", "))
+	if !step.Quorum.IsZero() {
+		fmt.Fprintf(w, "  quorum%s", since(start, step.Quorum))
+	}
+	fmt.Fprintln(w)
+	if len(step.Missing) > 0 {
+		var missing []string
+		for _, address := range step.Missing {
+			missing = append(missing, address.Hex())
+		}
+		fmt.Fprintf(w, "      missing %s\n", strings.Join(missing, ", "))
+	}
+	for _, late := range step.Late {
+		fmt.Fprintf(w, "      late    %s by %v\n", late.Validator.Hex(), late.Delay)
+	}
+}
+
+// since formats the time elapsed from the start of a round, if the node entered it.
+func since(start, t time.Time) string {
+	if start.IsZero() {
+		return " at " + t.Format("15:04:05.000")
+	}
+	return fmt.Sprintf(" after %v", t.Sub(start))
+}
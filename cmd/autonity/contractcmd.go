@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/clearmatics/autonity/cmd/utils"
+	"github.com/clearmatics/autonity/common"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	contractBytecodeFlag = cli.StringFlag{
+		Name:  "bytecode",
+		Usage: "File holding the hex encoded bytecode of the new Autonity contract",
+	}
+	contractABIFlag = cli.StringFlag{
+		Name:  "abi",
+		Usage: "File holding the JSON ABI of the new Autonity contract",
+	}
+
+	contractCommand = cli.Command{
+		Name:      "contract",
+		Usage:     "Manage the Autonity contract",
+		ArgsUsage: "",
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The contract commands run against the local chain database, the node must be
+stopped.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "simulate-upgrade",
+				Usage:     "Dry-run an upgrade of the Autonity contract",
+				ArgsUsage: "[<blockHash> | <blockNum>]",
+				Action:    utils.MigrateFlags(simulateContractUpgrade),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.SyncModeFlag,
+					contractBytecodeFlag,
+					contractABIFlag,
+				},
+				Description: `
+    autonity contract simulate-upgrade --bytecode Autonity.bin --abi Autonity.abi [block]
+
+redeploys the contract with the given bytecode and ABI on a copy of the state of
+the given block, the head block by default, calls its view methods and finalize
+and prints the differences it makes as JSON. Nothing is written to the database.`,
+			},
+			{
+				Name:   "history",
+				Usage:  "Print the upgrades of the Autonity contract applied so far",
+				Action: utils.MigrateFlags(contractUpgradeHistory),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.SyncModeFlag,
+				},
+			},
+		},
+	}
+)
+
+func simulateContractUpgrade(ctx *cli.Context) error {
+	if !ctx.IsSet(contractBytecodeFlag.Name) || !ctx.IsSet(contractABIFlag.Name) {
+		utils.Fatalf("Both --%s and --%s are required", contractBytecodeFlag.Name, contractABIFlag.Name)
+	}
+	bytecode, err := ioutil.ReadFile(ctx.String(contractBytecodeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Could not read the bytecode: %v", err)
+	}
+	abi, err := ioutil.ReadFile(ctx.String(contractABIFlag.Name))
+	if err != nil {
+		utils.Fatalf("Could not read the ABI: %v", err)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, chainDb := utils.MakeChain(ctx, stack, true)
+	defer chainDb.Close()
+	contract := chain.GetAutonityContract()
+	if contract == nil {
+		utils.Fatalf("The chain has no Autonity contract")
+	}
+
+	header := chain.CurrentHeader()
+	if arg := ctx.Args().First(); arg != "" {
+		if hashish(arg) {
+			header = chain.GetHeaderByHash(common.HexToHash(arg))
+		} else {
+			num, _ := strconv.ParseUint(arg, 10, 64)
+			header = chain.GetHeaderByNumber(num)
+		}
+	}
+	if header == nil {
+		utils.Fatalf("block not found")
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		utils.Fatalf("could not create new state: %v", err)
+	}
+	// the simulation runs locally, it is only bounded by the RPC gas cap
+	gas := ctx.GlobalUint64(utils.RPCGlobalGasCapFlag.Name)
+	if gas == 0 {
+		gas = math.MaxUint64 / 2
+	}
+	simulation, err := contract.SimulateUpgrade(context.Background(), statedb, header, strings.TrimSpace(string(bytecode)), string(abi), gas)
+	if err != nil {
+		utils.Fatalf("Upgrade simulation failed: %v", err)
+	}
+	return printJSON(simulation)
+}
+
+func contractUpgradeHistory(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, chainDb := utils.MakeChain(ctx, stack, true)
+	defer chainDb.Close()
+	contract := chain.GetAutonityContract()
+	if contract == nil {
+		utils.Fatalf("The chain has no Autonity contract")
+	}
+	history, err := contract.UpgradeHistory()
+	if err != nil {
+		utils.Fatalf("Could not read the upgrade history: %v", err)
+	}
+	return printJSON(history)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
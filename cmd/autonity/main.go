@@ -216,6 +216,10 @@ func init() {
 		removedbCommand,
 		dumpCommand,
 		inspectCommand,
+		// See contractcmd.go:
+		contractCommand,
+		// See consensuscmd.go:
+		consensusCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
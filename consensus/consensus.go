@@ -151,6 +151,14 @@ type PoW interface {
 	Hashrate() float64
 }
 
+// TrustedHeaderVerifier is a consensus engine able to verify the headers whose
+// authenticity is established by their hash linkage to trusted headers, all their
+// rules being checked but their seals.
+type TrustedHeaderVerifier interface {
+	// VerifyTrustedHeaders is VerifyHeaders without the verification of the seals.
+	VerifyTrustedHeaders(chain ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error)
+}
+
 // BFT is a consensus engine to avoid byzantine failure
 type BFT interface {
 	Engine
@@ -26,8 +26,10 @@ import (
 type Broadcaster interface {
 	// Enqueue add a block into fetcher queue
 	Enqueue(id string, block *types.Block)
-	// FindPeers retrives connected peers by addresses
+	// FindPeers retrives connected peers by addresses, all of them if nil
 	FindPeers(map[common.Address]struct{}) map[common.Address]Peer
+	// ConsensusPeer reports whether the peer of the address may send consensus messages
+	ConsensusPeer(address common.Address) bool
 }
 
 // Peer defines the interface to communicate with peer
@@ -35,3 +37,11 @@ type Peer interface {
 	// Send sends the message to this peer
 	Send(msgcode uint64, data interface{}) error
 }
+
+// VersionedPeer is implemented by the peers reporting the version of the consensus
+// protocol negotiated with them.
+type VersionedPeer interface {
+	Peer
+	// ConsensusVersion returns the version of the consensus protocol run with the peer
+	ConsensusVersion() uint
+}
@@ -60,6 +60,20 @@ func (mr *MockBroadcasterMockRecorder) FindPeers(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPeers", reflect.TypeOf((*MockBroadcaster)(nil).FindPeers), arg0)
 }
 
+// ConsensusPeer mocks base method
+func (m *MockBroadcaster) ConsensusPeer(address common.Address) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsensusPeer", address)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ConsensusPeer indicates an expected call of ConsensusPeer
+func (mr *MockBroadcasterMockRecorder) ConsensusPeer(address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsensusPeer", reflect.TypeOf((*MockBroadcaster)(nil).ConsensusPeer), address)
+}
+
 // MockPeer is a mock of Peer interface
 type MockPeer struct {
 	ctrl     *gomock.Controller
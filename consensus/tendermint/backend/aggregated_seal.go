@@ -0,0 +1,166 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/bft"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto/bls"
+)
+
+// errNotAggregatable is returned when the committed seals of a block can't be
+// aggregated, the secp256k1 seals are then written in the header.
+var errNotAggregatable = errors.New("committed seals not aggregatable")
+
+// SetBLSKey sets the key signing the BLS committed seals, it must be called
+// before the engine is started.
+func (sb *Backend) SetBLSKey(key *bls.SecretKey) {
+	sb.blsKey = key
+}
+
+// BLSKeyRegistration returns the BLS public key of the node and its proof of
+// possession, which are registered in the Autonity contract by the validator.
+func (sb *Backend) BLSKeyRegistration() (key []byte, proof []byte) {
+	if sb.blsKey == nil {
+		return nil, nil
+	}
+	return sb.blsKey.PublicKey().Marshal(), sb.blsKey.ProvePossession().Marshal()
+}
+
+// SignAggregatedSeal implements tendermint.AggregatedSealer.SignAggregatedSeal.
+// Nothing is signed unless the aggregated seals are enabled and the key of the node
+// is the one registered for the current committee.
+func (sb *Backend) SignAggregatedSeal(seal []byte) ([]byte, error) {
+	if !sb.config.AggregatedSeals || sb.blsKey == nil || sb.currentBlock == nil {
+		return nil, nil
+	}
+	head := sb.currentBlock().Header()
+	if sb.blockchain != nil {
+		var err error
+		if head, err = resolveCommittee(sb.blockchain, head); err != nil {
+			return nil, err
+		}
+	}
+	member := head.CommitteeMember(sb.address)
+	if member == nil || !bytes.Equal(member.BLSKey, sb.blsKey.PublicKey().Marshal()) {
+		return nil, nil
+	}
+	return sb.blsKey.Sign(seal).Marshal(), nil
+}
+
+// aggregateSeals aggregates the BLS parts of the committed seals of header and
+// returns the aggregated signature with the bitmap flagging the signers among the
+// committee of parent. Every seal must carry a BLS part.
+func aggregateSeals(header, parent *types.Header, round int64, seals [][]byte) (seal []byte, bitmap []byte, err error) {
+	if len(seals) == 0 {
+		return nil, nil, errNotAggregatable
+	}
+	committedSeal := tendermintCore.PrepareCommittedSeal(header.Hash(), round, header.Number)
+	bitmap = make([]byte, (len(parent.Committee)+7)/8)
+	sigs := make([]*bls.Signature, 0, len(seals))
+	for _, s := range seals {
+		if len(s) != types.BFTExtraSeal+bls.SignatureLength {
+			return nil, nil, errNotAggregatable
+		}
+		addr, err := types.GetSignatureAddress(committedSeal, s[:types.BFTExtraSeal])
+		if err != nil {
+			return nil, nil, err
+		}
+		index := committeeIndex(parent.Committee, addr)
+		if index < 0 || len(parent.Committee[index].BLSKey) == 0 || bitmap[index/8]&(1<<(index%8)) != 0 {
+			return nil, nil, errNotAggregatable
+		}
+		sig, err := bls.SignatureFromBytes(s[types.BFTExtraSeal:])
+		if err != nil {
+			return nil, nil, err
+		}
+		bitmap[index/8] |= 1 << (index % 8)
+		sigs = append(sigs, sig)
+	}
+	aggregated, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aggregated.Marshal(), bitmap, nil
+}
+
+// verifyAggregatedSeal validates the aggregated committed seals of header with a
+// single pairing check and returns the signers, which are flagged in the bitmap
+// among the committee of parent.
+func verifyAggregatedSeal(header, parent *types.Header) ([]common.Address, error) {
+	committee := parent.Committee
+	if len(committee) == 0 || len(header.SealBitmap) != (len(committee)+7)/8 {
+		return nil, types.ErrInvalidCommittedSeals
+	}
+	var (
+		signers           []common.Address
+		keys              []*bls.PublicKey
+		power, totalPower uint64
+		unusedBits        = len(header.SealBitmap)*8 - len(committee)
+		lastByte          = header.SealBitmap[len(header.SealBitmap)-1]
+	)
+	if unusedBits > 0 && lastByte>>(8-unusedBits) != 0 {
+		return nil, types.ErrInvalidCommittedSeals
+	}
+	for i, member := range committee {
+		totalPower += member.VotingPower.Uint64()
+		if header.SealBitmap[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		key, err := bls.PublicKeyFromBytes(member.BLSKey)
+		if err != nil {
+			return nil, types.ErrInvalidCommittedSeals
+		}
+		keys = append(keys, key)
+		signers = append(signers, member.Address)
+		power += member.VotingPower.Uint64()
+	}
+	if power < bft.Quorum(totalPower) {
+		return nil, types.ErrInvalidCommittedSeals
+	}
+	sig, err := bls.SignatureFromBytes(header.AggregatedSeal)
+	if err != nil {
+		return nil, types.ErrInvalidSignature
+	}
+	committedSeal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	if !bls.FastAggregateVerify(keys, committedSeal, sig) {
+		return nil, types.ErrInvalidSignature
+	}
+	return signers, nil
+}
+
+// writeCommittedSeals writes the committed seals in header, aggregated if enabled
+// and possible, the secp256k1 seals otherwise.
+func (sb *Backend) writeCommittedSeals(header *types.Header, round int64, seals [][]byte) error {
+	if sb.config.AggregatedSeals && sb.blockchain != nil {
+		parent, err := resolveCommittee(sb.blockchain, sb.blockchain.GetHeader(header.ParentHash, header.Number.Uint64()-1))
+		if err == nil && parent != nil {
+			seal, bitmap, err := aggregateSeals(header, parent, round, seals)
+			if err == nil {
+				return types.WriteAggregatedSeal(header, seal, bitmap)
+			}
+			sb.logger.Debug("Writing secp256k1 committed seals", "number", header.Number, "reason", err)
+		}
+	}
+	ecdsaSeals := make([][]byte, len(seals))
+	for i, seal := range seals {
+		ecdsaSeals[i] = seal
+		if len(seal) > types.BFTExtraSeal {
+			ecdsaSeals[i] = seal[:types.BFTExtraSeal]
+		}
+	}
+	return types.WriteCommittedSeals(header, ecdsaSeals)
+}
+
+// committeeIndex returns the index of addr in committee, -1 if it isn't a member.
+func committeeIndex(committee types.Committee, addr common.Address) int {
+	for i, member := range committee {
+		if member.Address == addr {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/crypto/bls"
+	"github.com/clearmatics/autonity/log"
+)
+
+func TestAggregatedSeals(t *testing.T) {
+	committee, keys := newLightTestCommittee(t, 4)
+	var blsKeys []*bls.SecretKey
+	for i := range committee {
+		key, err := bls.GenerateKey()
+		require.NoError(t, err)
+		blsKeys = append(blsKeys, key)
+		committee[i].BLSKey = key.PublicKey().Marshal()
+	}
+	parent := &types.Header{Number: big.NewInt(1), MixDigest: types.BFTDigest, Committee: committee}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   crypto.PubkeyToAddress(keys[0].PublicKey),
+		Number:     big.NewInt(2),
+		MixDigest:  types.BFTDigest,
+		Committee:  committee,
+	}
+	require.NoError(t, tendermintCrypto.SignHeader(header, keys[0]))
+
+	const round = 1
+	seal := tendermintCore.PrepareCommittedSeal(header.Hash(), round, header.Number)
+	seals := func(signers ...int) [][]byte {
+		var seals [][]byte
+		for _, i := range signers {
+			sig, err := crypto.Sign(crypto.Keccak256(seal), keys[i])
+			require.NoError(t, err)
+			seals = append(seals, append(sig, blsKeys[i].Sign(seal).Marshal()...))
+		}
+		return seals
+	}
+	aggregated := func(signers ...int) *types.Header {
+		aggregatedSeal, bitmap, err := aggregateSeals(header, parent, round, seals(signers...))
+		require.NoError(t, err)
+		h := types.CopyHeader(header)
+		require.NoError(t, types.WriteAggregatedSeal(h, aggregatedSeal, bitmap))
+		h.Round = round
+		return h
+	}
+
+	logger := log.New()
+	h := aggregated(3, 0, 2)
+	require.Equal(t, []byte{0x0d}, h.SealBitmap)
+	require.NoError(t, verifyCommittedSeals(h, parent, logger))
+
+	// The round is part of the committed seal.
+	h.Round = 0
+	require.Equal(t, types.ErrInvalidSignature, verifyCommittedSeals(h, parent, logger))
+
+	// The bitmap must flag the actual signers.
+	h = aggregated(3, 0, 2)
+	h.SealBitmap = []byte{0x0f}
+	require.Equal(t, types.ErrInvalidSignature, verifyCommittedSeals(h, parent, logger))
+	h.SealBitmap = []byte{0x1d}
+	require.Equal(t, types.ErrInvalidCommittedSeals, verifyCommittedSeals(h, parent, logger))
+	h.SealBitmap = []byte{0x0d, 0x00}
+	require.Equal(t, types.ErrInvalidCommittedSeals, verifyCommittedSeals(h, parent, logger))
+
+	// A quorum is required.
+	require.Equal(t, types.ErrInvalidCommittedSeals, verifyCommittedSeals(aggregated(1, 2), parent, logger))
+
+	// The aggregated past committed seals are replaced by the aggregate of the
+	// precommits collected for the block if it has more signers.
+	h = aggregated(3, 0, 2)
+	require.Equal(t, []byte{0x0f}, pastCommittedSeals(h, parent, seals(0, 1, 2, 3)).Seals[0])
+	require.Equal(t, []byte{0x0d}, pastCommittedSeals(h, parent, seals(0, 1)).Seals[0])
+	require.Equal(t, []byte{0x0d}, pastCommittedSeals(h, nil, seals(0, 1, 2, 3)).Seals[0])
+
+	// Seals without BLS part or from a member without BLS key are not aggregated.
+	partial := seals(0, 1, 2)
+	partial[1] = partial[1][:types.BFTExtraSeal]
+	_, _, err := aggregateSeals(header, parent, round, partial)
+	require.Equal(t, errNotAggregatable, err)
+	_, _, err = aggregateSeals(header, parent, round, append(seals(0, 1), seals(1)...))
+	require.Equal(t, errNotAggregatable, err)
+	withoutKey := types.CopyHeader(parent)
+	withoutKey.Committee[1].BLSKey = nil
+	_, _, err = aggregateSeals(header, withoutKey, round, seals(0, 1, 2))
+	require.Equal(t, errNotAggregatable, err)
+	require.Equal(t, types.ErrInvalidCommittedSeals, verifyCommittedSeals(aggregated(0, 1, 2), withoutKey, logger))
+
+	// Without the parent header, the secp256k1 parts of the seals are written.
+	b := &Backend{config: &config.Config{AggregatedSeals: true}, logger: logger}
+	h = types.CopyHeader(header)
+	require.NoError(t, b.writeCommittedSeals(h, round, seals(0, 1, 2)))
+	require.Nil(t, h.AggregatedSeal)
+	require.Len(t, h.CommittedSeals, 3)
+	h.Round = round
+	require.NoError(t, verifyCommittedSeals(h, parent, logger))
+}
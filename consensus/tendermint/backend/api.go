@@ -19,6 +19,7 @@ package backend
 import (
 	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/consensus/tendermint/core"
 	"github.com/clearmatics/autonity/core/types"
@@ -32,9 +33,17 @@ type API struct {
 	getCommittee func(header *types.Header, chain consensus.ChainReader) (types.Committee, error)
 }
 
-// GetCommittee retrieves the list of authorized committee at the specified block.
+// GetCommittee retrieves the list of authorized committee at the specified block,
+// the latest one if no block is given.
 func (api *API) GetCommittee(number *rpc.BlockNumber) (types.Committee, error) {
-	header := api.chain.GetHeaderByNumber(uint64(*number))
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber || *number == rpc.PendingBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else if *number == rpc.EarliestBlockNumber {
+		header = api.chain.GetHeaderByNumber(0)
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(*number))
+	}
 	if header == nil {
 		return nil, errUnknownBlock
 	}
@@ -63,9 +72,25 @@ func (api *API) GetContractAddress() common.Address {
 	return autonity.ContractAddress
 }
 
-// Get Autonity contract ABI
-func (api *API) GetContractABI() string {
-	return api.tendermint.GetContractABI()
+// GetContractABI returns the Autonity contract ABI at the specified block, the
+// ABI changes when the contract is upgraded. The latest one is returned if no
+// block is given.
+func (api *API) GetContractABI(number *rpc.BlockNumber) (string, error) {
+	if number == nil || *number == rpc.LatestBlockNumber || *number == rpc.PendingBlockNumber {
+		return api.tendermint.GetContractABI(), nil
+	}
+	height := uint64(0)
+	if *number != rpc.EarliestBlockNumber {
+		height = uint64(*number)
+	}
+	if api.chain.GetHeaderByNumber(height) == nil {
+		return "", errUnknownBlock
+	}
+	contractABI := api.tendermint.GetContractABIAt(height)
+	if contractABI == "" {
+		return "", errUnknownContractABI
+	}
+	return contractABI, nil
 }
 
 // Get current white list
@@ -73,6 +98,71 @@ func (api *API) GetWhitelist() []string {
 	return api.tendermint.WhiteList()
 }
 
+// GetMisbehaviourEvidence retrieves the evidence of committee members misbehaviour
+// detected at the specified height, or every recorded evidence if no height is given.
+func (api *API) GetMisbehaviourEvidence(number *rpc.BlockNumber) ([]*core.MisbehaviourEvidence, error) {
+	if number == nil {
+		return api.tendermint.MisbehaviourEvidence(nil), nil
+	}
+
+	var height uint64
+	switch *number {
+	case rpc.LatestBlockNumber:
+		height = api.chain.CurrentHeader().Number.Uint64()
+	case rpc.PendingBlockNumber:
+		// The pending height is the one consensus is currently running for.
+		height = api.chain.CurrentHeader().Number.Uint64() + 1
+	default:
+		if *number < 0 {
+			return nil, errUnknownBlock
+		}
+		height = uint64(*number)
+	}
+	return api.tendermint.MisbehaviourEvidence(&height), nil
+}
+
+// GetSigningWatermark retrieves the latest consensus message signed by the node, nil
+// if it never signed any.
+func (api *API) GetSigningWatermark() *SigningWatermark {
+	return api.tendermint.SigningWatermark()
+}
+
+// GetValidatorUptime retrieves the signed and missed blocks and the proposals of
+// the committee members over the last blocks.
+func (api *API) GetValidatorUptime() *core.UptimeReport {
+	return api.tendermint.ValidatorUptime()
+}
+
+// BLSKeyRegistration is the BLS key of the node with its proof of possession, the
+// arguments of the registerBLSKey call of the Autonity contract.
+type BLSKeyRegistration struct {
+	Key   hexutil.Bytes `json:"key"`
+	Proof hexutil.Bytes `json:"proof"`
+}
+
+// GetBLSKey retrieves the BLS key signing the aggregated committed seals, nil if
+// the aggregated seals are disabled.
+func (api *API) GetBLSKey() *BLSKeyRegistration {
+	key, proof := api.tendermint.BLSKeyRegistration()
+	if key == nil {
+		return nil
+	}
+	return &BLSKeyRegistration{Key: key, Proof: proof}
+}
+
+// AdminAPI is the API to manage the consensus engine, it is exposed in the admin
+// namespace which is only available over IPC by default.
+type AdminAPI struct {
+	tendermint *Backend
+}
+
+// ResetSigningWatermark allows the node to sign consensus messages below its signing
+// watermark again, it returns the watermark cleared. It is only meant to recover from
+// a chain rewind and must never be used while another instance of the validator runs.
+func (api *AdminAPI) ResetSigningWatermark() *SigningWatermark {
+	return api.tendermint.ResetSigningWatermark()
+}
+
 // Get current tendermint's core state
 func (api *API) GetCoreState() core.TendermintState {
 	return api.tendermint.CoreState()
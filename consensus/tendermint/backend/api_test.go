@@ -11,7 +11,11 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/acdefault"
 	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/rpc"
 )
 
@@ -37,6 +41,15 @@ func TestGetCommittee(t *testing.T) {
 	got, err := API.GetCommittee(&bn)
 	assert.NoError(t, err)
 	assert.Equal(t, want, got)
+
+	c.EXPECT().CurrentHeader().Return(h).Times(2)
+	latest := rpc.LatestBlockNumber
+	got, err = API.GetCommittee(&latest)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	got, err = API.GetCommittee(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
 }
 
 func TestGetCommitteeAtHash(t *testing.T) {
@@ -97,11 +110,22 @@ func TestAPIGetContractABI(t *testing.T) {
 	want := acdefault.ABI()
 
 	API := &API{
+		chain:      chain,
 		tendermint: engine,
 	}
 
-	got := API.GetContractABI()
+	got, err := API.GetContractABI(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+
+	number := rpc.BlockNumber(1)
+	got, err = API.GetContractABI(&number)
+	assert.Nil(t, err)
 	assert.Equal(t, want, got)
+
+	number = rpc.BlockNumber(100)
+	_, err = API.GetContractABI(&number)
+	assert.Equal(t, errUnknownBlock, err)
 }
 
 func TestAPIGetContractAddress(t *testing.T) {
@@ -138,3 +162,59 @@ func TestAPIGetWhitelist(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func TestAPIGetMisbehaviourEvidence(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	newEvidence := func(height int64) *core.MisbehaviourEvidence {
+		var payloads [][]byte
+		for _, hash := range []common.Hash{common.HexToHash("0x1"), {}} {
+			vote, err := core.Encode(&core.Vote{Round: 0, Height: big.NewInt(height), ProposedBlockHash: hash})
+			assert.NoError(t, err)
+			// Code 1 is a prevote
+			msg := &core.Message{Code: 1, Msg: vote, Address: crypto.PubkeyToAddress(key.PublicKey)}
+			data, err := msg.PayloadNoSig()
+			assert.NoError(t, err)
+			msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key)
+			assert.NoError(t, err)
+			payloads = append(payloads, msg.Payload())
+		}
+		evidence, err := core.NewMisbehaviourEvidence(payloads[0], payloads[1])
+		assert.NoError(t, err)
+		return evidence
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chain := consensus.NewMockChainReader(ctrl)
+	chain.EXPECT().CurrentHeader().Return(&types.Header{Number: big.NewInt(4)}).AnyTimes()
+
+	engine := &Backend{db: rawdb.NewMemoryDatabase(), logger: log.New()}
+	API := &API{chain: chain, tendermint: engine}
+
+	evidence5, evidence6 := newEvidence(5), newEvidence(6)
+	engine.StoreMisbehaviourEvidence(evidence6)
+	engine.StoreMisbehaviourEvidence(evidence5)
+	engine.StoreMisbehaviourEvidence(evidence5)
+
+	got, err := API.GetMisbehaviourEvidence(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.MisbehaviourEvidence{evidence5, evidence6}, got)
+
+	bn := rpc.BlockNumber(6)
+	got, err = API.GetMisbehaviourEvidence(&bn)
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.MisbehaviourEvidence{evidence6}, got)
+
+	bn = rpc.PendingBlockNumber
+	got, err = API.GetMisbehaviourEvidence(&bn)
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.MisbehaviourEvidence{evidence5}, got)
+
+	bn = rpc.LatestBlockNumber
+	got, err = API.GetMisbehaviourEvidence(&bn)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
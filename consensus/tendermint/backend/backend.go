@@ -18,7 +18,6 @@ package backend
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"errors"
 	"sync"
 	"time"
@@ -30,13 +29,15 @@ import (
 	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/rawdb"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/core/vm"
-	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/crypto/bls"
 	"github.com/clearmatics/autonity/ethdb"
 	"github.com/clearmatics/autonity/event"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/params"
+	"github.com/clearmatics/autonity/rlp"
 	lru "github.com/hashicorp/golang-lru"
 	ring "github.com/zfjagann/golang-ring"
 )
@@ -57,25 +58,30 @@ var (
 )
 
 // New creates an Ethereum Backend for BFT core engine.
-func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database, chainConfig *params.ChainConfig, vmConfig *vm.Config) *Backend {
+func New(config *tendermintConfig.Config, signer Signer, db ethdb.Database, chainConfig *params.ChainConfig, vmConfig *vm.Config) *Backend {
 	if chainConfig.Tendermint.BlockPeriod != 0 {
 		config.BlockPeriod = chainConfig.Tendermint.BlockPeriod
 	}
+	config.SetTimeouts(chainConfig.Tendermint)
+	config.AggregatedSeals = chainConfig.Tendermint.AggregatedSeals
+	config.EpochLength = chainConfig.Tendermint.EpochLength
+	config.MisbehaviourWindow = chainConfig.Tendermint.MisbehaviourWindow
+	config.DowntimeWindow = chainConfig.Tendermint.DowntimeWindow
+	config.SetDefaults()
 
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
 
-	pub := crypto.PubkeyToAddress(privateKey.PublicKey).String()
-	logger := log.New("addr", pub)
+	logger := log.New("addr", signer.Address().String())
 
 	logger.Warn("new backend with public key")
 
 	backend := &Backend{
 		config:         config,
 		eventMux:       event.NewTypeMuxSilent(logger),
-		privateKey:     privateKey,
-		address:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		signer:         signer,
+		address:        signer.Address(),
 		logger:         logger,
 		db:             db,
 		recents:        recents,
@@ -83,10 +89,13 @@ func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb
 		recentMessages: recentMessages,
 		knownMessages:  knownMessages,
 		vmConfig:       vmConfig,
+		uptime:         tendermintCore.NewUptimeTracker(tendermintCore.DefaultUptimeWindow),
 	}
 
 	backend.pendingMessages.SetCapacity(ringCapacity)
-	backend.core = tendermintCore.New(backend, config)
+	consensusCore := tendermintCore.New(backend, config)
+	consensusCore.SetWAL(tendermintCore.NewWAL(db))
+	backend.core = consensusCore
 	return backend
 }
 
@@ -95,7 +104,8 @@ func New(config *tendermintConfig.Config, privateKey *ecdsa.PrivateKey, db ethdb
 type Backend struct {
 	config       *tendermintConfig.Config
 	eventMux     *event.TypeMuxSilent
-	privateKey   *ecdsa.PrivateKey
+	signer       Signer
+	blsKey       *bls.SecretKey
 	address      common.Address
 	logger       log.Logger
 	db           ethdb.Database
@@ -126,6 +136,18 @@ type Backend struct {
 
 	contractsMu sync.RWMutex
 	vmConfig    *vm.Config
+
+	watermarkMu sync.Mutex // serialises the signing watermark checks
+
+	uptime   *tendermintCore.UptimeTracker
+	uptimeMu sync.Mutex // serialises the uptime tracker updates
+
+	messageLog *tendermintCore.MessageLog // records the consensus messages if set
+
+	sentry sentry // sentries of the validator or validators of the sentry
+	votes  votes  // messages of the height being decided, for the bitmap gossip
+
+	catchUpState catchUp // catch-up requests sent and answered
 }
 
 func (sb *Backend) BlockChain() *core.BlockChain {
@@ -139,6 +161,7 @@ func (sb *Backend) Address() common.Address {
 
 // Broadcast implements tendermint.Backend.Broadcast
 func (sb *Backend) Broadcast(ctx context.Context, committee types.Committee, payload []byte) error {
+	sb.recordMessage(true, sb.address, payload)
 	// send to others
 	sb.Gossip(ctx, committee, payload)
 	// send to self
@@ -149,6 +172,18 @@ func (sb *Backend) Broadcast(ctx context.Context, committee types.Committee, pay
 	return nil
 }
 
+// SetMessageLog makes the backend record every consensus message it broadcasts
+// and the first arrival of every consensus message it receives in the given log.
+func (sb *Backend) SetMessageLog(l *tendermintCore.MessageLog) {
+	sb.messageLog = l
+}
+
+func (sb *Backend) recordMessage(sent bool, peer common.Address, payload []byte) {
+	if err := sb.messageLog.Record(sent, peer, payload); err != nil {
+		sb.logger.Warn("Failed to record consensus message", "err", err)
+	}
+}
+
 func (sb *Backend) postEvent(event interface{}) {
 	go sb.Post(event)
 }
@@ -156,6 +191,17 @@ func (sb *Backend) postEvent(event interface{}) {
 func (sb *Backend) AskSync(header *types.Header) {
 	sb.logger.Info("Broadcasting consensus synchronization request")
 
+	if len(sb.sentry.sentries) > 0 {
+		// The sentries are the only peers of the validator.
+		if sb.broadcaster != nil {
+			for addr, p := range sb.broadcaster.FindPeers(sb.sentry.sentries) {
+				sb.logger.Info("Asking sync to sentry", "addr", addr)
+				sb.send(p, tendermintSyncMsg, []byte{})
+			}
+		}
+		return
+	}
+
 	targets := make(map[common.Address]struct{})
 	for _, val := range header.Committee {
 		if val.Address != sb.Address() {
@@ -165,14 +211,22 @@ func (sb *Backend) AskSync(header *types.Header) {
 
 	if sb.broadcaster != nil && len(targets) > 0 {
 		ps := sb.broadcaster.FindPeers(targets)
-		var count uint64
+		// The peers running tendermint67 only send the messages the node misses,
+		// the others are asked to sync if those don't hold enough voting power.
+		count := sb.catchUp(header, ps)
+		if count > bft.F(header.TotalVotingPower()) {
+			return
+		}
 		for addr, p := range ps {
 			//ask to a quorum nodes to sync, 1 must then be honest and updated
 			if count >= bft.Quorum(header.TotalVotingPower()) {
 				break
 			}
+			if sb.awaitingVotes(addr) {
+				continue
+			}
 			sb.logger.Info("Asking sync to", "addr", addr)
-			go p.Send(tendermintSyncMsg, []byte{}) //nolint
+			sb.send(p, tendermintSyncMsg, []byte{})
 
 			member := header.CommitteeMember(addr)
 			if member == nil {
@@ -195,27 +249,45 @@ func (sb *Backend) Gossip(ctx context.Context, committee types.Committee, payloa
 			targets[val.Address] = struct{}{}
 		}
 	}
+	for addr := range sb.sentry.sentries {
+		targets[addr] = struct{}{}
+	}
 
 	if sb.broadcaster != nil && len(targets) > 0 {
-		ps := sb.broadcaster.FindPeers(targets)
-		for addr, p := range ps {
-			ms, ok := sb.recentMessages.Get(addr)
-			var m *lru.ARCCache
-			if ok {
-				m, _ = ms.(*lru.ARCCache)
-				if _, k := m.Get(hash); k {
-					// This peer had this event, skip it
-					continue
-				}
-			} else {
-				m, _ = lru.NewARC(inmemoryMessages)
-			}
+		sb.gossip(sb.broadcaster.FindPeers(targets), hash, payload, committee)
+	}
+}
 
-			m.Add(hash, true)
-			sb.recentMessages.Add(addr, m)
+// gossip sends a message to the peers which didn't send it nor were sent it. The
+// peers running the bitmap gossip are announced the messages of the height being
+// decided instead, and pull them if they miss them. The committee of the message
+// height is read from the chain if not given.
+func (sb *Backend) gossip(peers map[common.Address]consensus.Peer, hash common.Hash, payload []byte, committee types.Committee) {
+	key, index, stored := sb.storeVote(payload, committee)
+	for addr, p := range peers {
+		if stored && bitmapGossip(p) {
+			if have := sb.announcement(addr, key, index); have != nil {
+				sb.send(p, tendermintHaveMsg, have)
+			}
+			continue
+		}
 
-			go p.Send(tendermintMsg, payload) //nolint
+		ms, ok := sb.recentMessages.Get(addr)
+		var m *lru.ARCCache
+		if ok {
+			m, _ = ms.(*lru.ARCCache)
+			if _, k := m.Get(hash); k {
+				// This peer had this event, skip it
+				continue
+			}
+		} else {
+			m, _ = lru.NewARC(inmemoryMessages)
 		}
+
+		m.Add(hash, true)
+		sb.recentMessages.Add(addr, m)
+
+		sb.send(p, tendermintMsg, payload)
 	}
 }
 
@@ -232,7 +304,7 @@ func (sb *Backend) KnownMsgHash() []common.Hash {
 func (sb *Backend) Commit(proposal *types.Block, round int64, seals [][]byte) error {
 	h := proposal.Header()
 	// Append seals and round into extra-data
-	if err := types.WriteCommittedSeals(h, seals); err != nil {
+	if err := sb.writeCommittedSeals(h, round, seals); err != nil {
 		return err
 	}
 
@@ -335,6 +407,19 @@ func (sb *Backend) VerifyProposal(proposal types.Block) (time.Duration, error) {
 			return 0, err
 		}
 
+		// Inside an epoch the header only carries the hash of the committee
+		if !sb.config.IsEpochHeader(proposalNumber) {
+			if header.CommitteeHash != committeeSet.Hash() {
+				sb.logger.Error("wrong committee hash",
+					"proposalNumber", proposalNumber,
+					"hash", header.CommitteeHash,
+					"current", committeeSet,
+				)
+				return 0, consensus.ErrInconsistentCommitteeSet
+			}
+			return 0, nil
+		}
+
 		//Perform the actual comparison
 		if len(header.Committee) != len(committeeSet) {
 			sb.logger.Error("wrong committee set",
@@ -371,10 +456,50 @@ func (sb *Backend) VerifyProposal(proposal types.Block) (time.Duration, error) {
 	return 0, err
 }
 
-// Sign implements tendermint.Backend.Sign
+// Sign implements tendermint.Backend.Sign, consensus messages conflicting with the
+// signing watermark are refused.
 func (sb *Backend) Sign(data []byte) ([]byte, error) {
-	hashData := crypto.Keccak256(data)
-	return crypto.Sign(hashData, sb.privateKey)
+	if err := sb.raiseWatermark(data); err != nil {
+		return nil, err
+	}
+	return sb.signer.SignData(sb.consensusHeight(), data)
+}
+
+// StoreMisbehaviourEvidence implements tendermint.Backend.StoreMisbehaviourEvidence
+func (sb *Backend) StoreMisbehaviourEvidence(evidence *tendermintCore.MisbehaviourEvidence) {
+	height, hash := evidence.Height.Uint64(), evidence.Hash()
+	if rawdb.HasMisbehaviourEvidence(sb.db, height, hash) {
+		return
+	}
+
+	data, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		sb.logger.Error("Failed to encode misbehaviour evidence", "err", err)
+		return
+	}
+	rawdb.WriteMisbehaviourEvidence(sb.db, height, hash, data)
+}
+
+// MisbehaviourEvidence retrieves the recorded misbehaviour evidence for the given
+// height, or all of them if height is nil.
+func (sb *Backend) MisbehaviourEvidence(height *uint64) []*tendermintCore.MisbehaviourEvidence {
+	var encoded [][]byte
+	if height == nil {
+		encoded = rawdb.ReadAllMisbehaviourEvidence(sb.db)
+	} else {
+		encoded = rawdb.ReadMisbehaviourEvidence(sb.db, *height)
+	}
+
+	result := make([]*tendermintCore.MisbehaviourEvidence, 0, len(encoded))
+	for _, data := range encoded {
+		evidence := new(tendermintCore.MisbehaviourEvidence)
+		if err := rlp.DecodeBytes(data, evidence); err != nil {
+			sb.logger.Error("Invalid misbehaviour evidence in database", "err", err)
+			continue
+		}
+		result = append(result, evidence)
+	}
+	return result
 }
 
 // CheckSignature implements tendermint.Backend.CheckSignature
@@ -404,6 +529,16 @@ func (sb *Backend) LastCommittedProposal() (*types.Block, common.Address) {
 		}
 	}
 
+	// The committee of a block inside an epoch is resolved from its epoch header.
+	if sb.blockchain != nil {
+		header, err := resolveCommittee(sb.blockchain, block.Header())
+		if err != nil {
+			sb.logger.Error("Failed to resolve block committee", "err", err)
+			return new(types.Block), common.Address{}
+		}
+		block = block.WithSeal(header)
+	}
+
 	// Return header only block here since we don't need block body
 	return block, proposer
 }
@@ -420,6 +555,12 @@ func (sb *Backend) GetContractABI() string {
 	return sb.blockchain.GetAutonityContract().StringABI()
 }
 
+// GetContractABIAt returns the ABI of the autonity contract in the state of the
+// given height.
+func (sb *Backend) GetContractABIAt(number uint64) string {
+	return sb.blockchain.GetAutonityContract().StringABIAt(number)
+}
+
 func (sb *Backend) CoreState() tendermintCore.TendermintState {
 	return sb.core.CoreState()
 }
@@ -457,7 +598,7 @@ func (sb *Backend) SyncPeer(address common.Address) {
 	messages := sb.core.GetCurrentHeightMessages()
 	for _, msg := range messages {
 		//We do not save sync messages in the arc cache as recipient could not have been able to process some previous sent.
-		go p.Send(tendermintMsg, msg.Payload()) //nolint
+		sb.send(p, tendermintMsg, msg.Payload())
 	}
 }
 
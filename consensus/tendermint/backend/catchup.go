@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/bft"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+const (
+	// catchUpTimeout is the time the batches answering a catch-up request are
+	// accepted for, the later ones being dropped.
+	catchUpTimeout = 5 * time.Second
+	// catchUpInterval is the minimum time between two requests of a peer being
+	// answered, the requests coming faster being dropped.
+	catchUpInterval = time.Second
+	// catchUpBatchSize is the maximum number of messages of a batch.
+	catchUpBatchSize = 32
+	// maxCatchUpMessages is the maximum number of messages answering a request.
+	maxCatchUpMessages = 256
+	// maxCatchUpRounds is the maximum number of rounds of a request.
+	maxCatchUpRounds = 16
+)
+
+// catchUpRequest asks a peer for the messages of the rounds FromRound to ToRound
+// of a height, but those of the Have bitmaps the node already holds.
+type catchUpRequest struct {
+	Height    uint64
+	FromRound uint64
+	ToRound   uint64
+	Have      []voteBitmap
+}
+
+// catchUp holds the state of the catch-up requests, those sent to the peers and
+// those answered.
+type catchUp struct {
+	mu       sync.Mutex
+	asked    map[common.Address]time.Time // deadline of the answers of the peers asked
+	answered map[common.Address]time.Time // time the last request of the peers was answered
+}
+
+// catchUp asks the peers running tendermint67 for the messages of the height
+// following header the node misses, until the peers asked hold more than F of the
+// voting power of the committee so that one of them at least is honest. It returns
+// the voting power of the peers asked.
+func (sb *Backend) catchUp(header *types.Header, peers map[common.Address]consensus.Peer) uint64 {
+	var request *catchUpRequest
+	f := bft.F(header.TotalVotingPower())
+	now := time.Now()
+	c := &sb.catchUpState
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.asked == nil {
+		c.asked = make(map[common.Address]time.Time)
+	}
+	var power uint64
+	for addr, p := range peers {
+		if power > f {
+			break
+		}
+		member := header.CommitteeMember(addr)
+		if member == nil || peerVersion(p) < tendermint67 {
+			continue
+		}
+		if request == nil {
+			request = sb.catchUpRequest(header.Number.Uint64() + 1)
+		}
+		sb.logger.Info("Asking catch-up to", "addr", addr, "height", request.Height, "from", request.FromRound, "to", request.ToRound)
+		c.asked[addr] = now.Add(catchUpTimeout)
+		sb.send(p, tendermintCatchUpMsg, request)
+		power += member.VotingPower.Uint64()
+	}
+	return power
+}
+
+// catchUpRequest returns the request of the messages of height the node misses,
+// up to the round following the highest one it holds messages of.
+func (sb *Backend) catchUpRequest(height uint64) *catchUpRequest {
+	request := &catchUpRequest{Height: height, ToRound: maxCatchUpRounds - 1}
+	v := &sb.votes
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !sb.votesAt(height, nil) {
+		return request
+	}
+	var highest uint64
+	for key := range v.payloads {
+		if key.round > highest {
+			highest = key.round
+		}
+	}
+	if request.ToRound < highest+1 {
+		request.ToRound = highest + 1
+		request.FromRound = request.ToRound - (maxCatchUpRounds - 1)
+	}
+	for key := range v.payloads {
+		if key.round >= request.FromRound && key.round <= request.ToRound {
+			request.Have = append(request.Have, voteBitmap{Height: height, Round: key.round, Step: key.step, Bits: v.bitmap(key)})
+		}
+	}
+	return request
+}
+
+// awaitingVotes returns whether the node asked a peer for the messages it misses
+// and still accepts its answers.
+func (sb *Backend) awaitingVotes(addr common.Address) bool {
+	c := &sb.catchUpState
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deadline, ok := c.asked[addr]
+	if ok && time.Now().After(deadline) {
+		delete(c.asked, addr)
+		return false
+	}
+	return ok
+}
+
+// handleCatchUp answers the catch-up request of a peer with the messages of the
+// height being decided it misses, in batches. A peer is answered at most once per
+// catchUpInterval.
+func (sb *Backend) handleCatchUp(addr common.Address, request *catchUpRequest) {
+	if !sb.answerCatchUp(addr, time.Now()) {
+		sb.logger.Debug("Dropping catch-up request", "from", addr)
+		return
+	}
+	if request.ToRound < request.FromRound {
+		return
+	}
+	rounds := request.ToRound - request.FromRound + 1
+	if rounds > maxCatchUpRounds || rounds == 0 {
+		rounds = maxCatchUpRounds
+	}
+	have := make(map[voteKey][]byte)
+	for i, bm := range request.Have {
+		if i == maxMsgBitmaps {
+			break
+		}
+		if bm.Height == request.Height {
+			have[voteKey{round: bm.Round, step: bm.Step}] = bm.Bits
+		}
+	}
+
+	var payloads [][]byte
+	v := &sb.votes
+	v.mu.Lock()
+	if v.members != nil && request.Height == v.height {
+	collect:
+		for round := request.FromRound; round < request.FromRound+rounds; round++ {
+			for step := tendermintCore.ProposeStep; step <= tendermintCore.PrecommitStep; step++ {
+				key := voteKey{round: round, step: uint64(step)}
+				for index := 0; index < len(v.members); index++ {
+					payload, held := v.payloads[key][index]
+					if !held || hasBit(have[key], index) {
+						continue
+					}
+					if len(payloads) == maxCatchUpMessages {
+						break collect
+					}
+					payloads = append(payloads, payload)
+					if known := v.peerBitmap(addr, key); known != nil {
+						setBit(known, index)
+					}
+				}
+			}
+		}
+	}
+	v.mu.Unlock()
+
+	for len(payloads) > 0 {
+		size := len(payloads)
+		if size > catchUpBatchSize {
+			size = catchUpBatchSize
+		}
+		sb.sendTo(addr, tendermintVotesMsg, payloads[:size])
+		payloads = payloads[size:]
+	}
+}
+
+// answerCatchUp returns whether the request of a peer received at now is answered,
+// recording the answer.
+func (sb *Backend) answerCatchUp(addr common.Address, now time.Time) bool {
+	c := &sb.catchUpState
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.answered[addr]; ok && now.Sub(last) < catchUpInterval {
+		return false
+	}
+	if c.answered == nil {
+		c.answered = make(map[common.Address]time.Time)
+	}
+	for peer, last := range c.answered {
+		if now.Sub(last) >= catchUpInterval {
+			delete(c.answered, peer)
+		}
+	}
+	c.answered[addr] = now
+	return true
+}
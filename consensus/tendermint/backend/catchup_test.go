@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/event"
+	"github.com/clearmatics/autonity/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catchUpPeer is a peer running the catch-up requests.
+type catchUpPeer struct {
+	bitmapPeer
+}
+
+func (p catchUpPeer) ConsensusVersion() uint {
+	return tendermint67
+}
+
+func TestHandleCatchUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	firstKey, _ := crypto.GenerateKey()
+	secondKey, _ := crypto.GenerateKey()
+	first, second := crypto.PubkeyToAddress(firstKey.PublicKey), crypto.PubkeyToAddress(secondKey.PublicKey)
+	lagging := common.HexToAddress("0x03")
+	committee := testCommittee(first, second, lagging)
+
+	peer := catchUpPeer{bitmapPeer(make(chan sentMsg, 1))}
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(gomock.Any()).Return(true).AnyTimes()
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{lagging: {}}).Return(map[common.Address]consensus.Peer{
+		lagging: peer,
+	})
+	sb := newTestSentry(broadcaster)
+	firstVote, secondVote := signedPrevote(t, firstKey, 1), signedPrevote(t, secondKey, 1)
+	for _, payload := range [][]byte{firstVote, secondVote} {
+		_, _, stored := sb.storeVote(payload, committee)
+		require.True(t, stored)
+	}
+
+	request := &catchUpRequest{
+		Height:  2,
+		ToRound: 1,
+		Have:    []voteBitmap{{Height: 2, Round: 0, Step: 1, Bits: []byte{0x01}}},
+	}
+	handled, err := sb.HandleMsg(lagging, makeMsg(tendermintCatchUpMsg, request))
+	require.True(t, handled)
+	require.NoError(t, err)
+	select {
+	case msg := <-peer.bitmapPeer:
+		t.Fatalf("request of another height answered: %v", msg)
+	default:
+	}
+
+	request.Height, request.Have[0].Height = 1, 1
+	handled, err = sb.HandleMsg(lagging, makeMsg(tendermintCatchUpMsg, request))
+	require.True(t, handled)
+	require.NoError(t, err)
+	select {
+	case msg := <-peer.bitmapPeer:
+		t.Fatalf("request answered before the catch-up interval: %v", msg)
+	default:
+	}
+
+	sb.catchUpState.answered[lagging] = time.Now().Add(-catchUpInterval)
+	handled, err = sb.HandleMsg(lagging, makeMsg(tendermintCatchUpMsg, request))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, sentMsg{code: tendermintVotesMsg, data: [][]byte{secondVote}}, <-peer.bitmapPeer, "only the missing messages are sent")
+}
+
+func TestCatchUpVotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, _ := crypto.GenerateKey()
+	legacyPeer, catchUpAddr := common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	payload := signedPrevote(t, key, 1)
+
+	peer := catchUpPeer{bitmapPeer(make(chan sentMsg, 1))}
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(gomock.Any()).Return(true).AnyTimes()
+	sb := newTestSentry(broadcaster)
+	sb.eventMux = event.NewTypeMuxSilent(log.New())
+	sb.coreStarted = true
+	sub := sb.Subscribe(events.MessageEvent{})
+	defer sub.Unsubscribe()
+
+	batch := [][]byte{payload}
+	handled, err := sb.HandleMsg(catchUpAddr, makeMsg(tendermintVotesMsg, batch))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sb.knownMessages.Len(), "the batches not answering a request are dropped")
+
+	header := &types.Header{Number: big.NewInt(0), Committee: testCommittee(legacyPeer, catchUpAddr)}
+	assert.Zero(t, sb.catchUp(header, map[common.Address]consensus.Peer{legacyPeer: consensus.NewMockPeer(ctrl)}), "the older peers are asked to sync")
+	require.Equal(t, uint64(1), sb.catchUp(header, map[common.Address]consensus.Peer{catchUpAddr: peer}))
+	assert.Equal(t, sentMsg{code: tendermintCatchUpMsg, data: &catchUpRequest{Height: 1, ToRound: maxCatchUpRounds - 1}}, <-peer.bitmapPeer)
+
+	handled, err = sb.HandleMsg(catchUpAddr, makeMsg(tendermintVotesMsg, batch))
+	require.True(t, handled)
+	require.NoError(t, err)
+	select {
+	case ev := <-sub.Chan():
+		assert.Equal(t, events.MessageEvent{Payload: payload}, ev.Data, "the messages are handed to the core")
+	case <-time.After(time.Second):
+		t.Fatal("catch-up message not posted to the core")
+	}
+}
+
+func TestAskSyncCatchUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first, second := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	legacy, other := common.HexToAddress("0x03"), common.HexToAddress("0x04")
+	header := &types.Header{Number: big.NewInt(0), Committee: testCommittee(first, second, legacy, other)}
+	targets := map[common.Address]struct{}{first: {}, second: {}, legacy: {}, other: {}}
+	firstPeer, secondPeer := catchUpPeer{bitmapPeer(make(chan sentMsg, 1))}, catchUpPeer{bitmapPeer(make(chan sentMsg, 1))}
+	legacyPeer, otherPeer := bitmapPeer(make(chan sentMsg, 1)), bitmapPeer(make(chan sentMsg, 1))
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	sb := newTestSentry(broadcaster)
+
+	// The catch-up peer doesn't hold more than F, the others are asked to sync up
+	// to a quorum.
+	broadcaster.EXPECT().FindPeers(targets).Return(map[common.Address]consensus.Peer{
+		first:  firstPeer,
+		legacy: legacyPeer,
+		other:  otherPeer,
+	})
+	sb.AskSync(header)
+	assert.Equal(t, uint64(tendermintCatchUpMsg), (<-firstPeer.bitmapPeer).code)
+	assert.Equal(t, sentMsg{code: tendermintSyncMsg, data: []byte{}}, <-legacyPeer)
+	assert.Equal(t, sentMsg{code: tendermintSyncMsg, data: []byte{}}, <-otherPeer)
+
+	// The catch-up peers hold more than F, the others aren't asked to sync.
+	broadcaster.EXPECT().FindPeers(targets).Return(map[common.Address]consensus.Peer{
+		first:  firstPeer,
+		second: secondPeer,
+		legacy: legacyPeer,
+	})
+	sb.AskSync(header)
+	assert.Equal(t, uint64(tendermintCatchUpMsg), (<-firstPeer.bitmapPeer).code)
+	assert.Equal(t, uint64(tendermintCatchUpMsg), (<-secondPeer.bitmapPeer).code)
+	select {
+	case msg := <-legacyPeer:
+		t.Fatalf("unexpected message %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -26,16 +26,17 @@ import (
 	"time"
 
 	"github.com/clearmatics/autonity/consensus/tendermint/bft"
-	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/core"
 
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
 	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/rpc"
 )
 
@@ -54,6 +55,10 @@ var (
 	// errUnknownBlock is returned when the list of committee is requested for a block
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
+	// errUnknownContractABI is returned when the ABI of the autonity contract is
+	// requested for a block whose ABI was lost by the nodes predating their
+	// versioning per height.
+	errUnknownContractABI = errors.New("unknown autonity contract abi")
 	// errUnauthorized is returned if a header is signed by a non authorized entity.
 	errUnauthorized = errors.New("unauthorized")
 	// errInvalidCoindbase is returned if the signer is not the coinbase address,
@@ -92,13 +97,29 @@ func (sb *Backend) Author(header *types.Header) (common.Address, error) {
 // given engine. Verifying the seal may be done optionally here, or explicitly
 // via the VerifySeal method.
 func (sb *Backend) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, _ bool) error {
-	return sb.verifyHeader(header, chain.GetHeaderByHash(header.ParentHash))
+	parent, err := resolveCommittee(chain, chain.GetHeaderByHash(header.ParentHash))
+	if err != nil {
+		return err
+	}
+	if err := verifyHeader(header, parent, sb.config, sb.logger); err != nil || header.IsGenesis() {
+		return err
+	}
+	return verifyPastCommittedSeals(chain, header, parent, sb.logger)
 }
 
 // verifyHeader checks whether a header conforms to the consensus rules. It
 // expects the parent header to be provided unless header is the genesis
 // header.
-func (sb *Backend) verifyHeader(header, parent *types.Header) error {
+func verifyHeader(header, parent *types.Header, config *tendermintConfig.Config, logger log.Logger) error {
+	if err := verifyHeaderFields(header, parent, config); err != nil || header.IsGenesis() {
+		return err
+	}
+	return verifyHeaderSeals(header, parent, logger)
+}
+
+// verifyHeaderFields checks the rules of verifyHeader but the proposer and the
+// committed seals, which are left to verifyHeaderSeals.
+func verifyHeaderFields(header, parent *types.Header, config *tendermintConfig.Config) error {
 	if header.Number == nil {
 		return errUnknownBlock
 	}
@@ -126,6 +147,10 @@ func (sb *Backend) verifyHeader(header, parent *types.Header) error {
 	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDifficulty) != 0 {
 		return errInvalidDifficulty
 	}
+	// The evidence themselves are verified when the block is processed.
+	if len(header.Misbehaviour) > maxMisbehaviourEvidence {
+		return errTooManyMisbehaviourEvidence
+	}
 
 	// If this is the genesis block there is no further verification to be
 	// done.
@@ -136,42 +161,79 @@ func (sb *Backend) verifyHeader(header, parent *types.Header) error {
 	if parent == nil {
 		return errUnknownBlock
 	}
-	return sb.verifyHeaderAgainstParent(header, parent)
+	return verifyHeaderAgainstParent(header, parent, config)
 }
 
 // verifyHeaderAgainstParent verifies that the given header is valid with respect to its parent.
-func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error {
+func verifyHeaderAgainstParent(header, parent *types.Header, config *tendermintConfig.Config) error {
 	if parent.Number.Uint64() != header.Number.Uint64()-1 || parent.Hash() != header.ParentHash {
 		return consensus.ErrUnknownAncestor
 	}
 	// Ensure that the block's timestamp isn't too close to it's parent
-	if parent.Time+sb.config.BlockPeriod > header.Time {
+	if parent.Time+config.BlockPeriod > header.Time {
 		return errInvalidTimestamp
 	}
-	if err := sb.verifySigner(header, parent); err != nil {
+	return verifyEpochCommittee(header, parent, config)
+}
+
+// verifyHeaderSeals checks that header is proposed and committed by the committee
+// of its parent.
+func verifyHeaderSeals(header, parent *types.Header, logger log.Logger) error {
+	if err := verifySigner(header, parent); err != nil {
 		return err
 	}
-
-	return sb.verifyCommittedSeals(header, parent)
+	return verifyCommittedSeals(header, parent, logger)
 }
 
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
 // concurrently. The method returns a quit channel to abort the operations and
 // a results channel to retrieve the async verifications (the order is that of
-// the input slice).
-func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+// the input slice). The seals of every header are verified, whatever their seal
+// flag.
+func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, _ []bool) (chan<- struct{}, <-chan error) {
+	return verifyHeaders(chain, headers, false, sb.config, true, sb.logger)
+}
+
+// VerifyTrustedHeaders implements consensus.TrustedHeaderVerifier, it is used by
+// the downloader for the headers authenticated by the committee hand-offs. The
+// proposer, committed seals and past committed seals of the headers are skipped.
+func (sb *Backend) VerifyTrustedHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	return verifyHeaders(chain, headers, true, sb.config, true, sb.logger)
+}
+
+// verifyHeaders verifies headers sequentially in the background, the parent of
+// each header being either the previous header of the batch or read from chain.
+// The seals of the headers are verified unless trusted is set, the past committed
+// seals being verified along them if pastSeals is set. The headers of the batch
+// decoded without their committee are checked against the committee of the
+// previous one, which they carry the hash of.
+func verifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, trusted bool, config *tendermintConfig.Config, pastSeals bool, logger log.Logger) (chan<- struct{}, <-chan error) {
 	abort := make(chan struct{}, 1)
 	results := make(chan error, len(headers))
 	go func() {
+		var parent, ancestor *types.Header
 		for i, header := range headers {
-			var parent *types.Header
+			var err error
 			switch {
+			case i > 0 && parent != nil:
+				ancestor, parent = parent, withCommittee(headers[i-1], parent.Committee)
 			case i > 0:
 				parent = headers[i-1]
-			case i == 0:
-				parent = chain.GetHeaderByHash(header.ParentHash)
+			default:
+				if parent, err = resolveCommittee(chain, chain.GetHeaderByHash(header.ParentHash)); err == nil && pastSeals && !trusted && parent != nil {
+					ancestor, err = grandparent(chain, parent)
+				}
+			}
+			switch {
+			case err != nil:
+			case !trusted:
+				err = verifyHeader(header, parent, config, logger)
+				if err == nil && pastSeals && !header.IsGenesis() {
+					_, err = pastCommittedSealSigners(header, parent, ancestor, logger)
+				}
+			default:
+				err = verifyHeaderFields(header, parent, config)
 			}
-			err := sb.verifyHeader(header, parent)
 			select {
 			case <-abort:
 				return
@@ -192,7 +254,7 @@ func (sb *Backend) VerifyUncles(chain consensus.ChainReader, block *types.Block)
 }
 
 // verifySigner checks that the signer is part of the committee.
-func (sb *Backend) verifySigner(header, parent *types.Header) error {
+func verifySigner(header, parent *types.Header) error {
 	// resolve the authorization key and check against signers
 	signer, err := types.Ecrecover(header)
 	if err != nil {
@@ -214,14 +276,25 @@ func (sb *Backend) verifySigner(header, parent *types.Header) error {
 // verifyCommittedSeals validates that the committed seals for header come from
 // committee members and that the voting power of the committed seals constitutes
 // a quorum.
-func (sb *Backend) verifyCommittedSeals(header, parent *types.Header) error {
+func verifyCommittedSeals(header, parent *types.Header, logger log.Logger) error {
+	_, err := committedSealSigners(header, parent, logger)
+	return err
+}
+
+// committedSealSigners validates the committed seals for header as verifyCommittedSeals
+// does and returns the addresses of the committee members which signed them.
+func committedSealSigners(header, parent *types.Header, logger log.Logger) ([]common.Address, error) {
+	if len(header.AggregatedSeal) > 0 {
+		return verifyAggregatedSeal(header, parent)
+	}
 	// The length of Committed seals should be larger than 0
 	if len(header.CommittedSeals) == 0 {
-		return types.ErrEmptyCommittedSeals
+		return nil, types.ErrEmptyCommittedSeals
 	}
 
 	// Setup map to track votes made by committee members
 	votes := make(map[common.Address]int, len(parent.Committee))
+	signers := make([]common.Address, 0, len(header.CommittedSeals))
 
 	// Calculate total voting power
 	var committeeVotingPower uint64
@@ -239,36 +312,55 @@ func (sb *Backend) verifyCommittedSeals(header, parent *types.Header) error {
 		// 2. Get the address from signature
 		addr, err := types.GetSignatureAddress(headerSeal, signedSeal)
 		if err != nil {
-			sb.logger.Error("not a valid address", "err", err)
-			return types.ErrInvalidSignature
+			logger.Error("not a valid address", "err", err)
+			return nil, types.ErrInvalidSignature
 		}
 
 		member := parent.CommitteeMember(addr)
 		if member == nil {
-			sb.logger.Error(fmt.Sprintf("block had seal from non committee member %q", addr))
-			return types.ErrInvalidCommittedSeals
+			logger.Error(fmt.Sprintf("block had seal from non committee member %q", addr))
+			return nil, types.ErrInvalidCommittedSeals
 		}
 
 		votes[member.Address]++
 		if votes[member.Address] > 1 {
-			sb.logger.Error(fmt.Sprintf("committee member %q had multiple seals on block", addr))
-			return types.ErrInvalidCommittedSeals
+			logger.Error(fmt.Sprintf("committee member %q had multiple seals on block", addr))
+			return nil, types.ErrInvalidCommittedSeals
 		}
 		power += member.VotingPower.Uint64()
+		signers = append(signers, member.Address)
 	}
 
 	// We need at least a quorum for the block to be considered valid
 	if power < bft.Quorum(committeeVotingPower) {
-		return types.ErrInvalidCommittedSeals
+		return nil, types.ErrInvalidCommittedSeals
 	}
 
-	return nil
+	return signers, nil
+}
+
+// VerifyCommitteeHandoff checks that header is committed by a quorum of the
+// committee stored in trusted, an ancestor of header. It allows following a chain
+// without verifying every header as long as the committee did not change.
+func VerifyCommitteeHandoff(header, trusted *types.Header) error {
+	if header.Number.Cmp(trusted.Number) <= 0 {
+		return consensus.ErrUnknownAncestor
+	}
+	// Seals from another committee are expected when searching for a hand-off,
+	// they are not worth an error log.
+	logger := log.New()
+	logger.SetHandler(log.DiscardHandler())
+	return verifyCommittedSeals(header, trusted, logger)
 }
 
 // VerifySeal checks whether the crypto seal on a header is valid according to
 // the consensus rules of the given engine.
 func (sb *Backend) VerifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
-	// Ensure the signer is part of the committee
+	return verifySeal(chain, header)
+}
+
+// verifySeal ensures that the signer of header is part of the committee of its parent.
+func verifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
 
 	// The genesis block is not signed.
 	if header.IsGenesis() {
@@ -285,7 +377,11 @@ func (sb *Backend) VerifySeal(chain consensus.ChainHeaderReader, header *types.H
 		// TODO make this ErrUnknownAncestor
 		return errUnknownBlock
 	}
-	return sb.verifySigner(header, parent)
+	parent, err := resolveCommittee(chain, parent)
+	if err != nil {
+		return err
+	}
+	return verifySigner(header, parent)
 }
 
 // Prepare initializes the consensus fields of a block header according to the
@@ -302,6 +398,10 @@ func (sb *Backend) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
+	// carry the committed seals of the parent, they are empty for the genesis block
+	header.PastCommittedSeals = sb.pastCommittedSeals(chain, parent)
+	// include the evidence of double signing recorded by this node for the offenders to be punished
+	header.Misbehaviour = sb.pendingMisbehaviour(chain, header)
 	// use the same difficulty for all blocks
 	header.Difficulty = defaultDifficulty
 
@@ -342,8 +442,13 @@ func (sb *Backend) FinalizeAndAssemble(chain consensus.ChainReader, header *type
 	header.Root = statedb.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = nilUncleHash
 
-	// add committee to extraData's committee section
+	// add committee to extraData's committee section, the headers inside an epoch
+	// only carry its hash
 	header.Committee = committeeSet
+	header.CommitteeHash = common.Hash{}
+	if !sb.config.IsEpochHeader(header.Number.Uint64()) {
+		header.CommitteeHash = committeeSet.Hash()
+	}
 	return types.NewBlock(header, txs, nil, *receipts, new(trie.Trie)), nil
 }
 
@@ -351,14 +456,30 @@ func (sb *Backend) FinalizeAndAssemble(chain consensus.ChainReader, header *type
 // committee field containaining the list of committee members allowed to participate in consensus for the next block.
 func (sb *Backend) AutonityContractFinalize(header *types.Header, chain consensus.ChainReader, state *state.StateDB,
 	txs []*types.Transaction, receipts []*types.Receipt) (types.Committee, *types.Receipt, error) {
+	signers, err := lastBlockSigners(chain, header, sb.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the evidence carried by the block are verified, the block is invalid if
+	// any is forged, along with the downtime proven by the past committed seals.
+	offences, err := blockOffences(chain, header, sb.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	sb.contractsMu.Lock()
 	defer sb.contractsMu.Unlock()
 
-	committeeSet, receipt, err := sb.blockchain.GetAutonityContract().FinalizeAndGetCommittee(txs, receipts, header, state)
+	committeeSet, receipt, err := sb.blockchain.GetAutonityContract().FinalizeAndGetCommittee(txs, receipts, header, state, signers, offences)
 	if err != nil {
 		sb.logger.Error("Autonity Contract finalize returns err", "err", err)
 		return nil, nil, err
 	}
+	// the committee only changes at the start of an epoch
+	committeeSet, err = sb.nextCommittee(chain, header, committeeSet)
+	if err != nil {
+		return nil, nil, err
+	}
 	return committeeSet, receipt, nil
 }
 
@@ -380,13 +501,17 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, results
 		sb.logger.Error("Error ancestor")
 		return consensus.ErrUnknownAncestor
 	}
+	parent, err := resolveCommittee(chain, parent)
+	if err != nil {
+		return err
+	}
 	nodeAddress := sb.Address()
 	if parent.CommitteeMember(nodeAddress) == nil {
 		sb.logger.Error("error validator errUnauthorized", "addr", sb.address)
 		return errUnauthorized
 	}
 
-	block, err := sb.AddSeal(block)
+	block, err = sb.AddSeal(block)
 	if err != nil {
 		sb.logger.Error("seal error updateBlock", "err", err.Error())
 		return err
@@ -449,10 +574,13 @@ func (sb *Backend) SetProposedBlockHash(hash common.Hash) {
 func (sb *Backend) AddSeal(block *types.Block) (*types.Block, error) {
 	header := block.Header()
 
-	err := crypto.SignHeader(header, sb.privateKey)
+	seal, err := sb.signer.SignHeader(sb.consensusHeight(), header)
 	if err != nil {
 		return nil, err
 	}
+	if err = types.WriteSeal(header, seal); err != nil {
+		return nil, err
+	}
 
 	return block.WithSeal(header), nil
 }
@@ -464,16 +592,21 @@ func (sb *Backend) APIs(chain consensus.ChainReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, tendermint: sb, getCommittee: getCommittee},
 		Public:    true,
+	}, {
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   &AdminAPI{tendermint: sb},
 	}}
 }
 
-// getCommittee retrieves the committee for the given header.
+// getCommittee retrieves the committee for the given header, the one stored in
+// its parent or in the header starting the epoch of its parent.
 func getCommittee(header *types.Header, chain consensus.ChainReader) (types.Committee, error) {
 	parent := chain.GetHeaderByHash(header.ParentHash)
 	if parent == nil {
 		return nil, errUnknownBlock
 	}
-	return parent.Committee, nil
+	return epochCommittee(chain, parent)
 }
 
 // Start implements consensus.Start
@@ -499,6 +632,8 @@ func (sb *Backend) Start(ctx context.Context) error {
 
 // Stop implements consensus.Stop
 func (sb *Backend) Close() error {
+	sb.saveVotes(true)
+
 	// the mutex along with coreStarted should prevent double stop
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
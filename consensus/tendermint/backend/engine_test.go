@@ -227,7 +227,7 @@ func TestVerifySeal(t *testing.T) {
 
 	// unauthorized users but still can get correct signer address
 	privateKey, _ := crypto.GenerateKey()
-	engine.privateKey = privateKey
+	engine.signer = NewKeySigner(privateKey)
 	err = engine.VerifySeal(chain, block.Header())
 	if err != nil {
 		t.Errorf("error mismatch: have %v, want nil", err)
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"errors"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+var (
+	// errInvalidCommitteeHash is returned if a header inside an epoch does not carry
+	// the hash of the epoch committee, or if an epoch header carries a committee hash.
+	errInvalidCommitteeHash = errors.New("invalid committee hash")
+	// errUnknownCommittee is returned if the committee of a header inside an epoch
+	// cannot be resolved from its epoch header.
+	errUnknownCommittee = errors.New("unknown committee")
+)
+
+// verifyEpochCommittee checks that header carries its committee if it starts an
+// epoch, or the hash of the committee of its parent otherwise. The committee of
+// the parent must have been resolved, header being left untouched.
+func verifyEpochCommittee(header, parent *types.Header, config *tendermintConfig.Config) error {
+	if config.IsEpochHeader(header.Number.Uint64()) {
+		if header.CommitteeHash != (common.Hash{}) {
+			return errInvalidCommitteeHash
+		}
+		return nil
+	}
+	if len(parent.Committee) == 0 {
+		return errUnknownCommittee
+	}
+	if header.CommitteeHash != parent.Committee.Hash() {
+		return errInvalidCommitteeHash
+	}
+	if len(header.Committee) > 0 && header.Committee.Hash() != header.CommitteeHash {
+		return errInvalidCommitteeHash
+	}
+	return nil
+}
+
+// epochCommittee returns the committee stored in header, the committee of its
+// child. A header inside an epoch decoded without its committee has it resolved
+// from the epoch header, the nearest ancestor carrying it.
+func epochCommittee(chain consensus.ChainHeaderReader, header *types.Header) (types.Committee, error) {
+	for current := header; ; {
+		if len(current.Committee) > 0 {
+			if header.CommitteeHash != (common.Hash{}) && current.Committee.Hash() != header.CommitteeHash {
+				return nil, errInvalidCommitteeHash
+			}
+			return current.Committee, nil
+		}
+		if current.CommitteeHash == (common.Hash{}) || current.IsGenesis() {
+			return nil, errUnknownCommittee
+		}
+		if current = chain.GetHeader(current.ParentHash, current.Number.Uint64()-1); current == nil {
+			return nil, errUnknownBlock
+		}
+	}
+}
+
+// nextCommittee returns the committee to be stored in header, the one computed
+// by finalize at the start of an epoch and the committee of the parent inside
+// an epoch. The epoch committee is kept even if finalize returns another one,
+// as done by the contracts deployed before the epochs.
+func (sb *Backend) nextCommittee(chain consensus.ChainHeaderReader, header *types.Header, computed types.Committee) (types.Committee, error) {
+	if sb.config.IsEpochHeader(header.Number.Uint64()) {
+		return computed, nil
+	}
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	return epochCommittee(chain, parent)
+}
+
+// resolveCommittee returns header with its committee, resolved from its epoch
+// header through chain if it was decoded without it. header is returned as is if
+// it carries its committee or starts an epoch, a copy being returned otherwise.
+func resolveCommittee(chain consensus.ChainHeaderReader, header *types.Header) (*types.Header, error) {
+	if header == nil || len(header.Committee) > 0 || header.CommitteeHash == (common.Hash{}) {
+		return header, nil
+	}
+	committee, err := epochCommittee(chain, header)
+	if err != nil {
+		return nil, err
+	}
+	return withCommittee(header, committee), nil
+}
+
+// withCommittee returns header with committee, the committee of its epoch. header
+// is returned as is if it carries its committee, a copy being returned otherwise.
+func withCommittee(header *types.Header, committee types.Committee) *types.Header {
+	if len(header.Committee) > 0 || len(committee) == 0 {
+		return header
+	}
+	cpy := types.CopyHeader(header)
+	cpy.Committee = committee
+	return cpy
+}
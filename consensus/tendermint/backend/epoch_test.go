@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+// newEpochTestHeader returns a child of parent committed by keys, carrying the
+// hash of committee in place of the committee if committeeHash is set.
+func newEpochTestHeader(t *testing.T, parent *types.Header, committee types.Committee, keys []*ecdsa.PrivateKey, committeeHash bool) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  nilUncleHash,
+		Coinbase:   crypto.PubkeyToAddress(keys[0].PublicKey),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       parent.Time + 1,
+		Difficulty: defaultDifficulty,
+		MixDigest:  types.BFTDigest,
+		Committee:  committee,
+	}
+	if committeeHash {
+		header.CommitteeHash = committee.Hash()
+	}
+	require.NoError(t, tendermintCrypto.SignHeader(header, keys[0]))
+	seal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	for _, key := range keys {
+		sig, err := crypto.Sign(crypto.Keccak256(seal), key)
+		require.NoError(t, err)
+		header.CommittedSeals = append(header.CommittedSeals, sig)
+	}
+	return header
+}
+
+// withoutCommittee returns a copy of header as decoded before the committee of its epoch is known.
+func withoutCommittee(header *types.Header) *types.Header {
+	cpy := types.CopyHeader(header)
+	if cpy.CommitteeHash != (common.Hash{}) {
+		cpy.Committee = nil
+	}
+	return cpy
+}
+
+func TestVerifyEpochCommittee(t *testing.T) {
+	config := &tendermintConfig.Config{BlockPeriod: 1, EpochLength: 2}
+	committee, keys := newLightTestCommittee(t, 4)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee}
+
+	t.Run("a header inside an epoch is verified against the committee of its parent", func(t *testing.T) {
+		header := withoutCommittee(newEpochTestHeader(t, genesis, committee, keys, true))
+		require.NoError(t, verifyHeader(header, genesis, config, nil))
+		assert.Nil(t, header.Committee)
+	})
+
+	t.Run("a header inside an epoch must carry the hash of the epoch committee", func(t *testing.T) {
+		other, _ := newLightTestCommittee(t, 4)
+		header := withoutCommittee(newEpochTestHeader(t, genesis, other, keys, true))
+		assert.Equal(t, errInvalidCommitteeHash, verifyHeader(header, genesis, config, nil))
+
+		header = newEpochTestHeader(t, genesis, committee, keys, false)
+		assert.Equal(t, errInvalidCommitteeHash, verifyHeader(header, genesis, config, nil))
+	})
+
+	t.Run("an epoch header must carry its committee", func(t *testing.T) {
+		header1 := newEpochTestHeader(t, genesis, committee, keys, true)
+		header2 := newEpochTestHeader(t, header1, committee, keys, true)
+		assert.Equal(t, errInvalidCommitteeHash, verifyHeader(header2, header1, config, nil))
+
+		header2 = newEpochTestHeader(t, header1, committee, keys, false)
+		assert.NoError(t, verifyHeader(header2, header1, config, nil))
+	})
+
+	t.Run("every header carries its committee without epochs", func(t *testing.T) {
+		header := newEpochTestHeader(t, genesis, committee, keys, true)
+		assert.Equal(t, errInvalidCommitteeHash, verifyHeader(header, genesis, &tendermintConfig.Config{BlockPeriod: 1}, nil))
+	})
+}
+
+func TestEpochCommittee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committee1, keys1 := newLightTestCommittee(t, 4)
+	committee2, keys2 := newLightTestCommittee(t, 4)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee1}
+
+	// With epochs of 2 blocks, the committee changes at header 2.
+	header1 := newEpochTestHeader(t, genesis, committee1, keys1, true)
+	header2 := newEpochTestHeader(t, header1, committee2, keys1, false)
+	header3 := newEpochTestHeader(t, header2, committee2, keys2, true)
+	header4 := newEpochTestHeader(t, header3, committee2, keys2, false)
+
+	chain := consensus.NewMockChainReader(ctrl)
+	for _, header := range []*types.Header{genesis, header1, header2, header3} {
+		stored := withoutCommittee(header)
+		chain.EXPECT().GetHeader(header.Hash(), header.Number.Uint64()).Return(stored).AnyTimes()
+		chain.EXPECT().GetHeaderByHash(header.Hash()).Return(stored).AnyTimes()
+	}
+
+	committee, err := epochCommittee(chain, withoutCommittee(header3))
+	require.NoError(t, err)
+	assert.Equal(t, committee2, committee)
+
+	committee, err = epochCommittee(chain, withoutCommittee(header1))
+	require.NoError(t, err)
+	assert.Equal(t, committee1, committee)
+
+	// The committee of a block is the one of its parent.
+	committee, err = getCommittee(header4, chain)
+	require.NoError(t, err)
+	assert.Equal(t, committee2, committee)
+
+	committee, err = getCommittee(header2, chain)
+	require.NoError(t, err)
+	assert.Equal(t, committee1, committee)
+
+	// A committee which is not the one of the epoch header is rejected.
+	forged := withoutCommittee(header3)
+	forged.CommitteeHash = committee1.Hash()
+	_, err = epochCommittee(chain, forged)
+	assert.Equal(t, errInvalidCommitteeHash, err)
+
+	// The committee is resolved on a copy, the stored header is left untouched.
+	stored := withoutCommittee(header3)
+	resolved, err := resolveCommittee(chain, stored)
+	require.NoError(t, err)
+	assert.Equal(t, committee2, resolved.Committee)
+	assert.Nil(t, stored.Committee)
+	assert.Equal(t, stored.Hash(), resolved.Hash())
+
+	// The headers of a batch are verified against the committee of the previous one.
+	config := &tendermintConfig.Config{BlockPeriod: 1, EpochLength: 2}
+	batch := []*types.Header{withoutCommittee(header1), header2}
+	_, results := verifyHeaders(chain, batch, false, config, false, nil)
+	for range batch {
+		assert.NoError(t, <-results)
+	}
+	assert.Nil(t, batch[0].Committee)
+}
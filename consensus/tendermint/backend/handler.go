@@ -25,6 +25,7 @@ import (
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/p2p"
+	"github.com/clearmatics/autonity/rlp"
 	"github.com/hashicorp/golang-lru"
 	"io"
 )
@@ -32,6 +33,22 @@ import (
 const (
 	tendermintMsg     = 0x11
 	tendermintSyncMsg = 0x12
+	tendermintHaveMsg = 0x13 // bitmaps of the messages a node has, since tendermint66
+	tendermintGetMsg  = 0x14 // bitmaps of the messages a node pulls, since tendermint66
+
+	tendermintCatchUpMsg = 0x15 // request of the messages of a range of rounds, since tendermint67
+	tendermintVotesMsg   = 0x16 // batch of messages answering a catch-up request, since tendermint67
+)
+
+const (
+	// tendermint66 is the version of the tendermint protocol gossiping the consensus
+	// messages through have bitmaps, the peers running older versions being sent the
+	// messages as before.
+	tendermint66 = 66
+	// tendermint67 is the version of the tendermint protocol where a lagging node
+	// catches up by requesting the messages it misses, instead of asking its peers
+	// to send it all the messages of the height.
+	tendermint67 = 67
 )
 
 type UnhandledMsg struct {
@@ -42,11 +59,13 @@ type UnhandledMsg struct {
 var (
 	// errDecodeFailed is returned when decode message fails
 	errDecodeFailed = errors.New("fail to decode tendermint message")
+	// errUnauthorizedPeer is returned when the role of the sender doesn't allow consensus messages
+	errUnauthorizedPeer = errors.New("peer is not authorized to send tendermint messages")
 )
 
 // Protocol implements consensus.Handler.Protocol
 func (sb *Backend) Protocol() (protocolName string, extraMsgCodes uint64) {
-	return "tendermint", 2 //nolint
+	return "tendermint", 6 //nolint
 }
 
 func (sb *Backend) HandleUnhandledMsgs(ctx context.Context) {
@@ -68,20 +87,58 @@ func (sb *Backend) HandleUnhandledMsgs(ctx context.Context) {
 
 // HandleMsg implements consensus.Handler.HandleMsg
 func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
-	if msg.Code != tendermintMsg && msg.Code != tendermintSyncMsg {
+	switch msg.Code {
+	case tendermintMsg, tendermintSyncMsg, tendermintHaveMsg, tendermintGetMsg, tendermintCatchUpMsg, tendermintVotesMsg:
+	default:
 		return false, nil
 	}
+	// The proxies may only relay the consensus messages of the peers allowed to
+	// send them, the messages being checked once decoded.
+	consensusPeer := sb.broadcaster == nil || sb.broadcaster.ConsensusPeer(addr)
+	if !consensusPeer && (msg.Code != tendermintMsg || !sb.proxy(addr)) {
+		return true, errUnauthorizedPeer
+	}
+	defer sb.saveVotes(false)
+
+	switch msg.Code {
+	case tendermintHaveMsg, tendermintGetMsg:
+		meterIn(msg.Code, nil, msg.Size)
+		var bitmaps []voteBitmap
+		if err := msg.Decode(&bitmaps); err != nil {
+			return true, errDecodeFailed
+		}
+		if msg.Code == tendermintHaveMsg {
+			sb.handleHave(addr, bitmaps)
+		} else {
+			sb.handleGet(addr, bitmaps)
+		}
+		return true, nil
+	case tendermintCatchUpMsg:
+		meterIn(msg.Code, nil, msg.Size)
+		var request catchUpRequest
+		if err := msg.Decode(&request); err != nil {
+			return true, errDecodeFailed
+		}
+		sb.handleCatchUp(addr, &request)
+		return true, nil
+	}
 
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
 
 	switch msg.Code {
 	case tendermintMsg:
-		if !sb.coreStarted {
+		if !sb.coreStarted && !sb.relaying() {
 			buffer := new(bytes.Buffer)
 			if _, err := io.Copy(buffer, msg.Payload); err != nil {
 				return true, errDecodeFailed
 			}
+			if !consensusPeer {
+				var data []byte
+				if err := rlp.DecodeBytes(buffer.Bytes(), &data); err != nil || !sb.signedByConsensusPeer(types.RLPHash(data), data) {
+					return true, errUnauthorizedPeer
+				}
+			}
 			savedMsg := msg
 			savedMsg.Payload = buffer
 			sb.pendingMessages.Enqueue(UnhandledMsg{addr: addr, msg: savedMsg})
@@ -92,31 +149,37 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		if err := msg.Decode(&data); err != nil {
 			return true, errDecodeFailed
 		}
-
-		hash := types.RLPHash(data)
-
-		// Mark peer's message
-		ms, ok := sb.recentMessages.Get(addr)
-		var m *lru.ARCCache
-		if ok {
-			m, _ = ms.(*lru.ARCCache)
-		} else {
-			m, _ = lru.NewARC(inmemoryMessages)
-			sb.recentMessages.Add(addr, m)
+		meterIn(msg.Code, data, msg.Size)
+		if err := sb.handleConsensusMsg(addr, data, consensusPeer); err != nil {
+			return true, err
 		}
-		m.Add(hash, true)
-
-		// Mark self known message
-		if _, ok := sb.knownMessages.Get(hash); ok {
+	case tendermintVotesMsg:
+		meterIn(msg.Code, nil, msg.Size)
+		var batch [][]byte
+		if err := msg.Decode(&batch); err != nil {
+			return true, errDecodeFailed
+		}
+		// The batches arriving after the core stopped or not answering a request
+		// are dropped, the core asking again if it still lags.
+		if !sb.coreStarted || !sb.awaitingVotes(addr) {
 			return true, nil
 		}
-		sb.knownMessages.Add(hash, true)
-
-		sb.postEvent(events.MessageEvent{
-			Payload: data,
-		})
+		if len(batch) > catchUpBatchSize {
+			batch = batch[:catchUpBatchSize]
+		}
+		for _, data := range batch {
+			if err := sb.handleConsensusMsg(addr, data, consensusPeer); err != nil {
+				return true, err
+			}
+		}
 	case tendermintSyncMsg:
+		meterIn(msg.Code, nil, msg.Size)
 		if !sb.coreStarted {
+			if sb.relaying() {
+				sb.logger.Info("Relaying sync message", "from", addr)
+				sb.syncRelayed(addr)
+				return true, nil
+			}
 			sb.logger.Info("Sync message received but core not running")
 			return true, nil // we return nil as we don't want to shutdown the connection if core is stopped
 		}
@@ -129,6 +192,44 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	return true, nil
 }
 
+// handleConsensusMsg processes a consensus message received from a peer, posting
+// it to the core and relaying it. It must be called with the core lock held.
+func (sb *Backend) handleConsensusMsg(addr common.Address, data []byte, consensusPeer bool) error {
+	hash := types.RLPHash(data)
+
+	// A peer which may not send consensus messages can be the sentry of a validator.
+	if !consensusPeer && !sb.signedByConsensusPeer(hash, data) {
+		return errUnauthorizedPeer
+	}
+	sb.markVote(addr, data)
+
+	// Mark peer's message
+	ms, ok := sb.recentMessages.Get(addr)
+	var m *lru.ARCCache
+	if ok {
+		m, _ = ms.(*lru.ARCCache)
+	} else {
+		m, _ = lru.NewARC(inmemoryMessages)
+		sb.recentMessages.Add(addr, m)
+	}
+	m.Add(hash, true)
+
+	// Mark self known message
+	if _, ok := sb.knownMessages.Get(hash); ok {
+		return nil
+	}
+	sb.knownMessages.Add(hash, true)
+	sb.recordMessage(false, addr, data)
+
+	if sb.coreStarted {
+		sb.postEvent(events.MessageEvent{
+			Payload: data,
+		})
+	}
+	sb.relay(data)
+	return nil
+}
+
 // SetBroadcaster implements consensus.Handler.SetBroadcaster
 func (sb *Backend) SetBroadcaster(broadcaster consensus.Broadcaster) {
 	sb.broadcaster = broadcaster
@@ -141,5 +242,6 @@ func (sb *Backend) NewChainHead() error {
 		return ErrStoppedEngine
 	}
 	sb.postEvent(events.CommitEvent{})
+	go sb.syncUptime()
 	return nil
 }
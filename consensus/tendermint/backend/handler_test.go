@@ -22,14 +22,33 @@ import (
 	"time"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/event"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/p2p"
 	"github.com/clearmatics/autonity/rlp"
+	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/golang-lru"
 )
 
+func TestUnauthorizedConsensusPeer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr := common.BytesToAddress([]byte("participant"))
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(addr).Return(false).Times(2)
+	backend := &Backend{broadcaster: broadcaster}
+
+	for _, code := range []uint64{tendermintMsg, tendermintSyncMsg} {
+		handled, err := backend.HandleMsg(addr, makeMsg(code, []byte("data")))
+		if !handled || err != errUnauthorizedPeer {
+			t.Fatalf("message code %d: have handled %v and error %v, want %v", code, handled, err, errUnauthorizedPeer)
+		}
+	}
+}
+
 func TestTendermintMessage(t *testing.T) {
 	_, backend := newBlockChain(1)
 
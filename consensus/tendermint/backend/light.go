@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/core/state"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/params"
+	"github.com/clearmatics/autonity/rpc"
+)
+
+// errLightClient is returned when a light client is asked to produce or finalize a block.
+var errLightClient = errors.New("tendermint light client does not take part in consensus")
+
+// LightBackend is the consensus engine of light clients following a Tendermint
+// sealed chain. It does not take part in consensus and does not need the state:
+// headers are verified by following the committee hand-offs, the committed seals of
+// every header must come from a quorum of the committee stored in its parent
+// header, as done by the full node Backend.
+type LightBackend struct {
+	config *tendermintConfig.Config
+	logger log.Logger
+}
+
+// NewLight creates a Tendermint header verification engine for light clients.
+func NewLight(config *tendermintConfig.Config, chainConfig *params.ChainConfig) *LightBackend {
+	if chainConfig.Tendermint.BlockPeriod != 0 {
+		config.BlockPeriod = chainConfig.Tendermint.BlockPeriod
+	}
+	config.EpochLength = chainConfig.Tendermint.EpochLength
+	return &LightBackend{
+		config: config,
+		logger: log.New("engine", "tendermint-light"),
+	}
+}
+
+// Author retrieves the address of the committee member which proposed the given block.
+func (lb *LightBackend) Author(header *types.Header) (common.Address, error) {
+	return types.Ecrecover(header)
+}
+
+// VerifyHeader checks that header is proposed and committed by the committee of
+// its parent. The seal flag is ignored as the committed seals are what the light
+// client relies on to follow the committee hand-offs.
+func (lb *LightBackend) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, _ bool) error {
+	parent, err := resolveCommittee(chain, chain.GetHeaderByHash(header.ParentHash))
+	if err != nil {
+		return err
+	}
+	return verifyHeader(header, parent, lb.config, lb.logger)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. Each
+// header is checked against the committee of the previous one in the batch.
+func (lb *LightBackend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, _ []bool) (chan<- struct{}, <-chan error) {
+	return verifyHeaders(chain, headers, false, lb.config, false, lb.logger)
+}
+
+// VerifyUncles verifies that the given block does not contain uncles.
+func (lb *LightBackend) VerifyUncles(_ consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errInvalidUncleHash
+	}
+	return nil
+}
+
+// VerifySeal checks that the proposer of header is part of the committee of its parent.
+func (lb *LightBackend) VerifySeal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return verifySeal(chain, header)
+}
+
+// Prepare is not supported by light clients.
+func (lb *LightBackend) Prepare(_ consensus.ChainHeaderReader, _ *types.Header) error {
+	return errLightClient
+}
+
+// Finalize is not supported by light clients.
+func (lb *LightBackend) Finalize(_ consensus.ChainReader, _ *types.Header, _ *state.StateDB, _ []*types.Transaction,
+	_ []*types.Header, _ []*types.Receipt) (types.Committee, *types.Receipt, error) {
+	return nil, nil, errLightClient
+}
+
+// FinalizeAndAssemble is not supported by light clients.
+func (lb *LightBackend) FinalizeAndAssemble(_ consensus.ChainReader, _ *types.Header, _ *state.StateDB, _ []*types.Transaction,
+	_ []*types.Header, _ *[]*types.Receipt) (*types.Block, error) {
+	return nil, errLightClient
+}
+
+// Seal is not supported by light clients.
+func (lb *LightBackend) Seal(_ consensus.ChainReader, _ *types.Block, _ chan<- *types.Block, _ <-chan struct{}) error {
+	return errLightClient
+}
+
+// SealHash returns the hash of a block prior to it being sealed.
+func (lb *LightBackend) SealHash(header *types.Header) common.Hash {
+	return types.SigHash(header)
+}
+
+// CalcDifficulty returns the constant difficulty of Tendermint blocks.
+func (lb *LightBackend) CalcDifficulty(_ consensus.ChainHeaderReader, _ uint64, _ *types.Header) *big.Int {
+	return defaultDifficulty
+}
+
+// APIs returns the RPC APIs this engine provides, the tendermint namespace
+// requires the state and is only available on full nodes.
+func (lb *LightBackend) APIs(_ consensus.ChainReader) []rpc.API {
+	return nil
+}
+
+// Close implements consensus.Engine, there are no background threads to stop.
+func (lb *LightBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,132 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/params"
+)
+
+// newLightTestHeader returns a child of parent proposed by keys[0] and committed
+// by keys[0:sealers], handing-off to committee. It carries the seals of parent.
+func newLightTestHeader(t *testing.T, parent *types.Header, committee types.Committee, keys []*ecdsa.PrivateKey, sealers int) *types.Header {
+	return newLightTestHeaderWithPastSeals(t, parent, pastCommittedSeals(parent, nil, nil), committee, keys, sealers)
+}
+
+// newLightTestHeaderWithPastSeals returns a child of parent carrying past, committed by the sealers first keys.
+func newLightTestHeaderWithPastSeals(t *testing.T, parent *types.Header, past types.PastSeals, committee types.Committee, keys []*ecdsa.PrivateKey, sealers int) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  nilUncleHash,
+		Coinbase:   crypto.PubkeyToAddress(keys[0].PublicKey),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       parent.Time + 1,
+		Difficulty: defaultDifficulty,
+		MixDigest:  types.BFTDigest,
+		Committee:  committee,
+	}
+	header.PastCommittedSeals = past
+	require.NoError(t, tendermintCrypto.SignHeader(header, keys[0]))
+
+	seal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	for _, key := range keys[:sealers] {
+		sig, err := crypto.Sign(crypto.Keccak256(seal), key)
+		require.NoError(t, err)
+		header.CommittedSeals = append(header.CommittedSeals, sig)
+	}
+	return header
+}
+
+func newLightTestCommittee(t *testing.T, n int) (types.Committee, []*ecdsa.PrivateKey) {
+	var committee types.Committee
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys = append(keys, key)
+		committee = append(committee, types.CommitteeMember{Address: crypto.PubkeyToAddress(key.PublicKey), VotingPower: common.Big1})
+	}
+	return committee, keys
+}
+
+func TestLightBackendVerifyHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committee1, keys1 := newLightTestCommittee(t, 4)
+	committee2, keys2 := newLightTestCommittee(t, 4)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee1}
+
+	chain := consensus.NewMockChainReader(ctrl)
+	chain.EXPECT().GetHeaderByHash(genesis.Hash()).Return(genesis).AnyTimes()
+
+	engine := NewLight(config.DefaultConfig(), &params.ChainConfig{Tendermint: &config.Config{BlockPeriod: 1}})
+
+	t.Run("committee hand-off is followed", func(t *testing.T) {
+		header1 := newLightTestHeader(t, genesis, committee2, keys1, 3)
+		header2 := newLightTestHeader(t, header1, committee2, keys2, 3)
+
+		_, results := engine.VerifyHeaders(chain, []*types.Header{header1, header2}, []bool{false, false})
+		assert.NoError(t, <-results)
+		assert.NoError(t, <-results)
+	})
+
+	t.Run("header committed by the previous committee is rejected", func(t *testing.T) {
+		header1 := newLightTestHeader(t, genesis, committee2, keys1, 3)
+		header2 := newLightTestHeader(t, header1, committee2, keys1, 3)
+
+		_, results := engine.VerifyHeaders(chain, []*types.Header{header1, header2}, []bool{false, false})
+		assert.NoError(t, <-results)
+		assert.Equal(t, errUnauthorized, <-results)
+	})
+
+	t.Run("header without a quorum of committed seals is rejected", func(t *testing.T) {
+		header := newLightTestHeader(t, genesis, committee1, keys1, 2)
+		assert.Equal(t, types.ErrInvalidCommittedSeals, engine.VerifyHeader(chain, header, false))
+	})
+
+	t.Run("light client cannot seal blocks", func(t *testing.T) {
+		assert.Equal(t, errLightClient, engine.Seal(nil, nil, nil, nil))
+		assert.Equal(t, errLightClient, engine.Prepare(chain, &types.Header{}))
+	})
+}
+
+func TestVerifyTrustedHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committee, keys := newLightTestCommittee(t, 4)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee}
+	chain := consensus.NewMockChainReader(ctrl)
+	chain.EXPECT().GetHeaderByHash(genesis.Hash()).Return(genesis).AnyTimes()
+	chain.EXPECT().GetHeader(genesis.Hash(), uint64(0)).Return(genesis).AnyTimes()
+
+	sb := &Backend{config: &config.Config{BlockPeriod: 1}, logger: log.New()}
+	header1 := newLightTestHeader(t, genesis, committee, keys, 2)
+	header2 := newLightTestHeader(t, header1, committee, keys, 3)
+
+	_, results := sb.VerifyTrustedHeaders(chain, []*types.Header{header1, header2})
+	assert.NoError(t, <-results, "the seals of the trusted headers are skipped")
+	assert.NoError(t, <-results, "the past committed seals of the trusted headers are skipped")
+
+	_, results = sb.VerifyHeaders(chain, []*types.Header{header1, header2}, []bool{false, false})
+	assert.Equal(t, types.ErrInvalidCommittedSeals, <-results, "the seals are verified whatever the flags")
+	<-results // the batch is verified before its headers are altered
+
+	header1.Time = genesis.Time
+	_, results = sb.VerifyTrustedHeaders(chain, []*types.Header{header1})
+	assert.Equal(t, errInvalidTimestamp, <-results, "the other rules of the trusted headers are still verified")
+}
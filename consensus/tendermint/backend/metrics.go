@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"bytes"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/metrics"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// gossipMeter returns the meter of the bytes sent or received, depending on the
+// direction, for the type of a message of the tendermint protocol.
+func gossipMeter(direction string, code uint64, payload []byte) metrics.Meter {
+	return metrics.GetOrRegisterMeter("tendermint/gossip/"+direction+"/"+msgType(code, payload), nil)
+}
+
+// msgType names the message types in the metrics, the consensus messages being
+// named after their step.
+func msgType(code uint64, payload []byte) string {
+	switch code {
+	case tendermintSyncMsg:
+		return "sync"
+	case tendermintHaveMsg:
+		return "have"
+	case tendermintGetMsg:
+		return "get"
+	case tendermintCatchUpMsg:
+		return "catchup"
+	case tendermintVotesMsg:
+		return "votes"
+	}
+	s := rlp.NewStream(bytes.NewReader(payload), uint64(len(payload)))
+	if _, err := s.List(); err != nil {
+		return "invalid"
+	}
+	msgCode, err := s.Uint()
+	if err != nil {
+		return "invalid"
+	}
+	step, err := (&tendermintCore.Message{Code: msgCode}).Step()
+	if err != nil {
+		return "invalid"
+	}
+	return step.String()
+}
+
+// meterIn records a received message of size bytes.
+func meterIn(code uint64, payload []byte, size uint32) {
+	if metrics.Enabled {
+		gossipMeter("in", code, payload).Mark(int64(size))
+	}
+}
+
+// send sends a message to a peer without waiting, recording its size.
+func (sb *Backend) send(p consensus.Peer, code uint64, data interface{}) {
+	if metrics.Enabled {
+		payload, ok := data.([]byte)
+		size := len(payload)
+		if !ok {
+			if encoded, err := rlp.EncodeToBytes(data); err == nil {
+				size = len(encoded)
+			}
+		}
+		gossipMeter("out", code, payload).Mark(int64(size))
+	}
+	go p.Send(code, data) //nolint
+}
+
+// sendTo sends a message to the peer of an address if it is connected.
+func (sb *Backend) sendTo(addr common.Address, code uint64, data interface{}) {
+	if sb.broadcaster == nil {
+		return
+	}
+	if p, connected := sb.broadcaster.FindPeers(map[common.Address]struct{}{addr: {}})[addr]; connected {
+		sb.send(p, code, data)
+	}
+}
@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// maxMisbehaviourEvidence is the maximum number of evidence carried by a block.
+const maxMisbehaviourEvidence = 16
+
+var (
+	// errTooManyMisbehaviourEvidence is returned if a header carries more than maxMisbehaviourEvidence.
+	errTooManyMisbehaviourEvidence = errors.New("too many misbehaviour evidence")
+	// errExpiredMisbehaviourEvidence is returned if an evidence is too old or from a future height.
+	errExpiredMisbehaviourEvidence = errors.New("misbehaviour evidence out of the inclusion window")
+	// errDuplicateMisbehaviourEvidence is returned if an evidence is already included in the block or one of its ancestors.
+	errDuplicateMisbehaviourEvidence = errors.New("duplicate misbehaviour evidence")
+)
+
+// misbehaviourKey identifies the offence proven by an evidence, an offender is
+// punished at most once per height whatever the number of messages it equivocated.
+type misbehaviourKey struct {
+	offender common.Address
+	height   uint64
+}
+
+func keyOf(evidence *tendermintCore.MisbehaviourEvidence) misbehaviourKey {
+	return misbehaviourKey{offender: evidence.Offender, height: evidence.Height.Uint64()}
+}
+
+// pendingMisbehaviour returns the recorded evidence to be included in header, the
+// ones in its inclusion window whose offence is not included in an ancestor yet.
+func (sb *Backend) pendingMisbehaviour(chain consensus.ChainHeaderReader, header *types.Header) [][]byte {
+	if sb.db == nil {
+		return nil
+	}
+	number, window := header.Number.Uint64(), sb.config.MisbehaviourWindow
+	var candidates []*tendermintCore.MisbehaviourEvidence
+	for height := evidenceWindowStart(number, window); height <= number; height++ {
+		h := height
+		candidates = append(candidates, sb.MisbehaviourEvidence(&h)...)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ancestors, err := ancestorHeaders(chain, header, int(window)+1)
+	if err != nil {
+		sb.logger.Debug("Failed to read the misbehaviour window", "number", number, "err", err)
+		return nil
+	}
+	included := includedMisbehaviour(ancestors)
+	var pending [][]byte
+	for _, evidence := range candidates {
+		if len(pending) == maxMisbehaviourEvidence {
+			break
+		}
+		if _, ok := included[keyOf(evidence)]; ok {
+			continue
+		}
+		if err := verifyEvidenceAgainst(evidence, number, window, ancestors); err != nil {
+			sb.logger.Debug("Dropping misbehaviour evidence", "offender", evidence.Offender, "height", evidence.Height, "err", err)
+			continue
+		}
+		data, err := rlp.EncodeToBytes(evidence)
+		if err != nil {
+			sb.logger.Error("Failed to encode misbehaviour evidence", "err", err)
+			continue
+		}
+		included[keyOf(evidence)] = struct{}{}
+		pending = append(pending, data)
+	}
+	return pending
+}
+
+// verifyMisbehaviour checks the evidence carried by header and returns the
+// double signing offences they prove, the evidence being included at most window
+// blocks after their height. The proposer cannot forge an evidence: both messages
+// must be signed by the offender, a member of the committee at the evidence
+// height, and every evidence is only accepted once.
+func verifyMisbehaviour(chain consensus.ChainHeaderReader, header *types.Header, window uint64) ([]autonity.Offence, error) {
+	if len(header.Misbehaviour) == 0 {
+		return nil, nil
+	}
+	if len(header.Misbehaviour) > maxMisbehaviourEvidence {
+		return nil, errTooManyMisbehaviourEvidence
+	}
+	ancestors, err := ancestorHeaders(chain, header, int(window)+1)
+	if err != nil {
+		return nil, err
+	}
+	included := includedMisbehaviour(ancestors)
+
+	offences := make([]autonity.Offence, 0, len(header.Misbehaviour))
+	for _, data := range header.Misbehaviour {
+		evidence := new(tendermintCore.MisbehaviourEvidence)
+		if err := rlp.DecodeBytes(data, evidence); err != nil {
+			return nil, err
+		}
+		if err := verifyEvidenceAgainst(evidence, header.Number.Uint64(), window, ancestors); err != nil {
+			return nil, err
+		}
+		if _, ok := included[keyOf(evidence)]; ok {
+			return nil, errDuplicateMisbehaviourEvidence
+		}
+		included[keyOf(evidence)] = struct{}{}
+		offences = append(offences, autonity.Offence{Offender: evidence.Offender, Reason: autonity.DoubleSign})
+	}
+	return offences, nil
+}
+
+// verifyEvidenceAgainst verifies evidence to be included in the block at number,
+// ancestors being the chain of its parent down to the start of the inclusion window.
+func verifyEvidenceAgainst(evidence *tendermintCore.MisbehaviourEvidence, number, window uint64, ancestors []*types.Header) error {
+	if !evidence.Height.IsUint64() {
+		return errExpiredMisbehaviourEvidence
+	}
+	height := evidence.Height.Uint64()
+	if height == 0 || height > number || height < evidenceWindowStart(number, window) {
+		return errExpiredMisbehaviourEvidence
+	}
+	// ancestors[0] is the header at number-1.
+	i := int(number - height)
+	if i >= len(ancestors) {
+		return consensus.ErrUnknownAncestor
+	}
+	return tendermintCore.VerifyMisbehaviourEvidence(evidence, ancestors[i])
+}
+
+// downtimeOffenders returns the committee members which did not commit any block
+// of the window ending at the parent of header, while being a member of the
+// committee of at least half of them. The signers of each block are the ones of
+// the past committed seals, which are part of the chain unlike the committed seals.
+// They carry the precommits the next proposer received after the quorum as well,
+// so that a member always precommitting late is not taken for an offline one.
+// The check is only run at the blocks whose number is a multiple of window.
+func downtimeOffenders(chain consensus.ChainHeaderReader, header *types.Header, window uint64) ([]common.Address, error) {
+	number := header.Number.Uint64()
+	if window == 0 || number%window != 0 || number <= window {
+		return nil, nil
+	}
+	// headers[i] is the header at number-i, down to the parent of the first block of the window.
+	ancestors, err := ancestorHeaders(chain, header, int(window)+1)
+	if err != nil {
+		return nil, err
+	}
+	headers := append([]*types.Header{header}, ancestors...)
+	if len(headers) != int(window)+2 {
+		return nil, consensus.ErrUnknownAncestor
+	}
+
+	// Seals from another committee are not expected, the blocks are verified.
+	logger := log.New()
+	logger.SetHandler(log.DiscardHandler())
+	memberOf := make(map[common.Address]uint64)
+	signed := make(map[common.Address]uint64)
+	for i := 1; i <= int(window); i++ {
+		block, parent := headers[i], headers[i+1]
+		for _, member := range parent.Committee {
+			memberOf[member.Address]++
+		}
+		signers, err := pastCommittedSealSigners(headers[i-1], block, parent, logger)
+		if err != nil {
+			return nil, err
+		}
+		for _, signer := range signers {
+			signed[signer]++
+		}
+	}
+
+	var offenders []common.Address
+	for member, blocks := range memberOf {
+		if signed[member] == 0 && blocks >= window/2 {
+			offenders = append(offenders, member)
+		}
+	}
+	sort.Slice(offenders, func(i, j int) bool { return bytes.Compare(offenders[i][:], offenders[j][:]) < 0 })
+	return offenders, nil
+}
+
+// blockOffences returns the offences punished by the finalize of header, within
+// the windows of config.
+func blockOffences(chain consensus.ChainHeaderReader, header *types.Header, config *tendermintConfig.Config) ([]autonity.Offence, error) {
+	if header.IsGenesis() {
+		return nil, nil
+	}
+	offences, err := verifyMisbehaviour(chain, header, config.MisbehaviourWindow)
+	if err != nil {
+		return nil, err
+	}
+	offline, err := downtimeOffenders(chain, header, config.DowntimeWindow)
+	if err != nil {
+		return nil, err
+	}
+	for _, offender := range offline {
+		offences = append(offences, autonity.Offence{Offender: offender, Reason: autonity.Downtime})
+	}
+	return offences, nil
+}
+
+// ancestorHeaders returns up to n ancestors of header read from chain, starting
+// with its parent. Less are returned if the genesis block is reached. The ancestors
+// decoded without their committee have it resolved from the oldest one.
+func ancestorHeaders(chain consensus.ChainHeaderReader, header *types.Header, n int) ([]*types.Header, error) {
+	ancestors := make([]*types.Header, 0, n)
+	for current := header; len(ancestors) < n && !current.IsGenesis(); {
+		current = chain.GetHeader(current.ParentHash, current.Number.Uint64()-1)
+		if current == nil {
+			break
+		}
+		ancestors = append(ancestors, current)
+	}
+	if len(ancestors) == 0 {
+		return ancestors, nil
+	}
+	oldest, err := resolveCommittee(chain, ancestors[len(ancestors)-1])
+	if err != nil {
+		return nil, err
+	}
+	ancestors[len(ancestors)-1] = oldest
+	for i := len(ancestors) - 2; i >= 0; i-- {
+		ancestors[i] = withCommittee(ancestors[i], ancestors[i+1].Committee)
+	}
+	return ancestors, nil
+}
+
+// includedMisbehaviour returns the offences proven by the evidence carried by headers.
+func includedMisbehaviour(headers []*types.Header) map[misbehaviourKey]struct{} {
+	included := make(map[misbehaviourKey]struct{})
+	for _, header := range headers {
+		for _, data := range header.Misbehaviour {
+			evidence := new(tendermintCore.MisbehaviourEvidence)
+			if err := rlp.DecodeBytes(data, evidence); err == nil {
+				included[keyOf(evidence)] = struct{}{}
+			}
+		}
+	}
+	return included
+}
+
+// evidenceWindowStart returns the lowest height of an evidence included in the
+// block at number, window blocks at most after its height.
+func evidenceWindowStart(number, window uint64) uint64 {
+	if number <= window {
+		return 1
+	}
+	return number - window
+}
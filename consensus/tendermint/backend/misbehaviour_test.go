@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// newTestPrevote returns the payload of a prevote for hash sent by sender and signed by key.
+func newTestPrevote(t *testing.T, key *ecdsa.PrivateKey, sender common.Address, height uint64, hash common.Hash) []byte {
+	vote, err := rlp.EncodeToBytes(&tendermintCore.Vote{Round: 0, Height: new(big.Int).SetUint64(height), ProposedBlockHash: hash})
+	require.NoError(t, err)
+	msg := &tendermintCore.Message{Code: 1, Msg: vote, Address: sender}
+	data, err := msg.PayloadNoSig()
+	require.NoError(t, err)
+	msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key)
+	require.NoError(t, err)
+	payload, err := rlp.EncodeToBytes(msg)
+	require.NoError(t, err)
+	return payload
+}
+
+// newTestEvidence returns the encoded evidence of key prevoting for two blocks at height.
+func newTestEvidence(t *testing.T, key *ecdsa.PrivateKey, height uint64) []byte {
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	evidence, err := tendermintCore.NewMisbehaviourEvidence(
+		newTestPrevote(t, key, sender, height, common.HexToHash("0x1")),
+		newTestPrevote(t, key, sender, height, common.HexToHash("0x2")),
+	)
+	require.NoError(t, err)
+	data, err := rlp.EncodeToBytes(evidence)
+	require.NoError(t, err)
+	return data
+}
+
+// newTestChain returns n headers following genesis, committed by the sealers first keys.
+func newTestChain(t *testing.T, ctrl *gomock.Controller, n int, sealers int) (*consensus.MockChainReader, []*types.Header, types.Committee, []*ecdsa.PrivateKey) {
+	committee, keys := newLightTestCommittee(t, 4)
+	headers := []*types.Header{{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee}}
+	for i := 1; i <= n; i++ {
+		headers = append(headers, newLightTestHeader(t, headers[i-1], committee, keys, sealers))
+	}
+	chain := consensus.NewMockChainReader(ctrl)
+	for _, header := range headers {
+		chain.EXPECT().GetHeader(header.Hash(), header.Number.Uint64()).Return(header).AnyTimes()
+	}
+	return chain, headers, committee, keys
+}
+
+func TestVerifyMisbehaviour(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chain, headers, committee, keys := newTestChain(t, ctrl, 3, 4)
+	offender := committee[1].Address
+
+	newHeader := func(parent *types.Header, evidence ...[]byte) *types.Header {
+		header := newLightTestHeader(t, parent, committee, keys, 4)
+		header.Misbehaviour = evidence
+		return header
+	}
+
+	t.Run("valid evidence is an offence", func(t *testing.T) {
+		header := newHeader(headers[3], newTestEvidence(t, keys[1], 2), newTestEvidence(t, keys[2], 4))
+		offences, err := verifyMisbehaviour(chain, header, tendermintConfig.DefaultMisbehaviourWindow)
+		require.NoError(t, err)
+		assert.Equal(t, []autonity.Offence{
+			{Offender: offender, Reason: autonity.DoubleSign},
+			{Offender: committee[2].Address, Reason: autonity.DoubleSign},
+		}, offences)
+	})
+
+	t.Run("evidence of a non member is rejected", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		header := newHeader(headers[3], newTestEvidence(t, key, 2))
+		_, err = verifyMisbehaviour(chain, header, tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Error(t, err)
+	})
+
+	t.Run("forged evidence is rejected", func(t *testing.T) {
+		// The proposer signs a conflicting vote in the name of the offender.
+		evidence, err := tendermintCore.NewMisbehaviourEvidence(
+			newTestPrevote(t, keys[1], offender, 2, common.HexToHash("0x1")),
+			newTestPrevote(t, keys[0], offender, 2, common.HexToHash("0x2")),
+		)
+		require.NoError(t, err)
+		data, err := rlp.EncodeToBytes(evidence)
+		require.NoError(t, err)
+
+		_, err = verifyMisbehaviour(chain, newHeader(headers[3], data), tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Equal(t, tendermintCore.ErrUnauthorizedAddress, err)
+	})
+
+	t.Run("evidence is only accepted once", func(t *testing.T) {
+		header := newHeader(headers[3], newTestEvidence(t, keys[1], 2), newTestEvidence(t, keys[1], 2))
+		_, err := verifyMisbehaviour(chain, header, tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Equal(t, errDuplicateMisbehaviourEvidence, err)
+
+		parent := newHeader(headers[3], newTestEvidence(t, keys[1], 2))
+		chain.EXPECT().GetHeader(parent.Hash(), parent.Number.Uint64()).Return(parent).AnyTimes()
+		_, err = verifyMisbehaviour(chain, newHeader(parent, newTestEvidence(t, keys[1], 2)), tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Equal(t, errDuplicateMisbehaviourEvidence, err)
+	})
+
+	t.Run("evidence from a future height is rejected", func(t *testing.T) {
+		_, err := verifyMisbehaviour(chain, newHeader(headers[3], newTestEvidence(t, keys[1], 5)), tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Equal(t, errExpiredMisbehaviourEvidence, err)
+	})
+
+	t.Run("evidence older than the configured window is rejected", func(t *testing.T) {
+		header := newHeader(headers[3], newTestEvidence(t, keys[1], 1))
+		_, err := verifyMisbehaviour(chain, header, 3)
+		assert.NoError(t, err)
+		_, err = verifyMisbehaviour(chain, header, 2)
+		assert.Equal(t, errExpiredMisbehaviourEvidence, err)
+	})
+
+	t.Run("too many evidence are rejected", func(t *testing.T) {
+		evidence := make([][]byte, maxMisbehaviourEvidence+1)
+		for i := range evidence {
+			evidence[i] = newTestEvidence(t, keys[1], 2)
+		}
+		header := newHeader(headers[3], evidence...)
+		_, err := verifyMisbehaviour(chain, header, tendermintConfig.DefaultMisbehaviourWindow)
+		assert.Equal(t, errTooManyMisbehaviourEvidence, err)
+		assert.Equal(t, errTooManyMisbehaviourEvidence, verifyHeader(header, headers[3], &tendermintConfig.Config{BlockPeriod: 1}, nil))
+	})
+}
+
+func TestDowntimeOffenders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The last member of the committee never commits a block.
+	chain, headers, committee, _ := newTestChain(t, ctrl, 8, 3)
+
+	offenders, err := downtimeOffenders(chain, headers[8], 4)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{committee[3].Address}, offenders)
+
+	offenders, err = downtimeOffenders(chain, headers[7], 4)
+	require.NoError(t, err)
+	assert.Empty(t, offenders)
+
+	// The first window starts after the genesis block.
+	offenders, err = downtimeOffenders(chain, headers[4], 4)
+	require.NoError(t, err)
+	assert.Empty(t, offenders)
+
+	// Every member committed a block of the window.
+	chain, headers, _, _ = newTestChain(t, ctrl, 8, 4)
+	offenders, err = downtimeOffenders(chain, headers[8], 4)
+	require.NoError(t, err)
+	assert.Empty(t, offenders)
+
+	// The last member always precommits after the quorum, its committed seals are
+	// only carried by the past committed seals of the next blocks.
+	committee, keys := newLightTestCommittee(t, 4)
+	headers = []*types.Header{{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee}}
+	for i := 1; i <= 8; i++ {
+		var past types.PastSeals
+		if parent := headers[i-1]; !parent.IsGenesis() {
+			late, err := crypto.Sign(crypto.Keccak256(tendermintCore.PrepareCommittedSeal(parent.Hash(), int64(parent.Round), parent.Number)), keys[3])
+			require.NoError(t, err)
+			past = pastCommittedSeals(parent, headers[i-2], [][]byte{late})
+			require.Len(t, past.Seals, 4)
+		}
+		headers = append(headers, newLightTestHeaderWithPastSeals(t, headers[i-1], past, committee, keys, 3))
+	}
+	chain = consensus.NewMockChainReader(ctrl)
+	for _, header := range headers {
+		chain.EXPECT().GetHeader(header.Hash(), header.Number.Uint64()).Return(header).AnyTimes()
+	}
+	offenders, err = downtimeOffenders(chain, headers[8], 4)
+	require.NoError(t, err)
+	assert.Empty(t, offenders)
+}
@@ -0,0 +1,172 @@
+package backend
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+)
+
+// errMissingPastCommittedSeals is returned when a header doesn't carry the committed
+// seals of its parent.
+var errMissingPastCommittedSeals = errors.New("missing past committed seals")
+
+// pastCommittedSeals returns the committed seals of parent to be carried by its
+// child, read from chain. The seals of parent are completed by the precommits
+// collected by the core for parent at its round, the ones received after the
+// quorum included.
+func (sb *Backend) pastCommittedSeals(chain consensus.ChainHeaderReader, parent *types.Header) types.PastSeals {
+	var (
+		collected [][]byte
+		ancestor  *types.Header
+	)
+	if sb.core != nil && !parent.IsGenesis() {
+		collected = sb.core.PastCommittedSeals(parent.Hash(), int64(parent.Round))
+	}
+	if len(collected) > 0 {
+		var err error
+		if ancestor, err = grandparent(chain, parent); err != nil {
+			sb.logger.Debug("Carrying the committed seals of the parent only", "number", parent.Number, "err", err)
+		}
+	}
+	return pastCommittedSeals(parent, ancestor, collected)
+}
+
+// pastCommittedSeals returns the committed seals of parent with their kind, those
+// of collected, signed by the committee of grandparent, being added. Aggregated
+// seals are carried as the bitmap followed by the signature, they are replaced by
+// the aggregate of collected if it has more signers.
+func pastCommittedSeals(parent, grandparent *types.Header, collected [][]byte) types.PastSeals {
+	if parent.IsGenesis() {
+		return types.PastSeals{}
+	}
+	if grandparent == nil {
+		collected = nil
+	}
+	if len(parent.AggregatedSeal) > 0 {
+		seal, bitmap := parent.AggregatedSeal, parent.SealBitmap
+		if len(collected) > 0 {
+			sealed := types.CopyHeader(parent)
+			var err error
+			sealed.AggregatedSeal, sealed.SealBitmap, err = aggregateSeals(parent, grandparent, int64(parent.Round), collected)
+			if err == nil && bitmapSigners(sealed.SealBitmap) > bitmapSigners(bitmap) {
+				if _, err := verifyAggregatedSeal(sealed, grandparent); err == nil {
+					seal, bitmap = sealed.AggregatedSeal, sealed.SealBitmap
+				}
+			}
+		}
+		return types.PastSeals{
+			Kind:  types.AggregatedSeals,
+			Seals: [][]byte{common.CopyBytes(bitmap), common.CopyBytes(seal)},
+		}
+	}
+	seals := make([][]byte, 0, len(parent.CommittedSeals)+len(collected))
+	signed := make(map[common.Address]bool, len(parent.CommittedSeals)+len(collected))
+	headerSeal := tendermintCore.PrepareCommittedSeal(parent.Hash(), int64(parent.Round), parent.Number)
+	for _, seal := range parent.CommittedSeals {
+		if signer, err := types.GetSignatureAddress(headerSeal, seal); err == nil {
+			signed[signer] = true
+		}
+		seals = append(seals, common.CopyBytes(seal))
+	}
+	for _, seal := range collected {
+		if len(seal) < types.BFTExtraSeal {
+			continue
+		}
+		seal = seal[:types.BFTExtraSeal]
+		signer, err := types.GetSignatureAddress(headerSeal, seal)
+		if err != nil || signed[signer] || grandparent.CommitteeMember(signer) == nil {
+			continue
+		}
+		signed[signer] = true
+		seals = append(seals, common.CopyBytes(seal))
+	}
+	return types.PastSeals{Kind: types.ECDSASeals, Seals: seals}
+}
+
+// bitmapSigners returns the number of signers flagged in bitmap.
+func bitmapSigners(bitmap []byte) int {
+	var count int
+	for _, b := range bitmap {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// pastCommittedSealSigners validates the committed seals of parent carried by header
+// against the committee of grandparent and returns their signers. The headers whose
+// parent is the genesis block carry no seals. The seals are checked for the round of
+// parent and the kind carried along with them.
+func pastCommittedSealSigners(header, parent, grandparent *types.Header, logger log.Logger) ([]common.Address, error) {
+	past := header.PastCommittedSeals
+	if parent.IsGenesis() {
+		if !past.Empty() {
+			return nil, types.ErrInvalidCommittedSeals
+		}
+		return nil, nil
+	}
+	if past.Empty() {
+		return nil, errMissingPastCommittedSeals
+	}
+	if grandparent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	sealed := types.CopyHeader(parent)
+	sealed.CommittedSeals, sealed.AggregatedSeal, sealed.SealBitmap = nil, nil, nil
+	switch past.Kind {
+	case types.ECDSASeals:
+		sealed.CommittedSeals = past.Seals
+	case types.AggregatedSeals:
+		if len(past.Seals) != 2 {
+			return nil, types.ErrInvalidCommittedSeals
+		}
+		sealed.SealBitmap, sealed.AggregatedSeal = past.Seals[0], past.Seals[1]
+	default:
+		return nil, types.ErrInvalidCommittedSeals
+	}
+	return committedSealSigners(sealed, grandparent, logger)
+}
+
+// verifyPastCommittedSeals checks the past committed seals of header, the
+// grandparent being read from chain.
+func verifyPastCommittedSeals(chain consensus.ChainHeaderReader, header, parent *types.Header, logger log.Logger) error {
+	ancestor, err := grandparent(chain, parent)
+	if err != nil {
+		return err
+	}
+	_, err = pastCommittedSealSigners(header, parent, ancestor, logger)
+	return err
+}
+
+// grandparent returns the parent of parent read from chain with its committee,
+// nil for the genesis block.
+func grandparent(chain consensus.ChainHeaderReader, parent *types.Header) (*types.Header, error) {
+	if parent.IsGenesis() {
+		return nil, nil
+	}
+	return resolveCommittee(chain, chain.GetHeader(parent.ParentHash, parent.Number.Uint64()-1))
+}
+
+// lastBlockSigners returns the committee members which committed the parent of
+// header, as proven by its past committed seals.
+func lastBlockSigners(chain consensus.ChainHeaderReader, header *types.Header, logger log.Logger) ([]common.Address, error) {
+	if header.IsGenesis() {
+		return nil, nil
+	}
+	parent, err := resolveCommittee(chain, chain.GetHeader(header.ParentHash, header.Number.Uint64()-1))
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	ancestor, err := grandparent(chain, parent)
+	if err != nil {
+		return nil, err
+	}
+	return pastCommittedSealSigners(header, parent, ancestor, logger)
+}
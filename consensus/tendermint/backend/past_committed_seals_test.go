@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tendermintCrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+)
+
+// resealTestHeader updates the proposer and committed seals of header, committed by keys.
+func resealTestHeader(t *testing.T, header *types.Header, keys []*ecdsa.PrivateKey) {
+	require.NoError(t, tendermintCrypto.SignHeader(header, keys[0]))
+	seal := tendermintCore.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	header.CommittedSeals = nil
+	for _, key := range keys {
+		sig, err := crypto.Sign(crypto.Keccak256(seal), key)
+		require.NoError(t, err)
+		header.CommittedSeals = append(header.CommittedSeals, sig)
+	}
+}
+
+func TestPastCommittedSeals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	committee, keys := newLightTestCommittee(t, 4)
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: defaultDifficulty, MixDigest: types.BFTDigest, UncleHash: nilUncleHash, Committee: committee}
+	header1 := newLightTestHeader(t, genesis, committee, keys, 3)
+	header2 := newLightTestHeader(t, header1, committee, keys, 4)
+
+	chain := consensus.NewMockChainReader(ctrl)
+	chain.EXPECT().GetHeaderByHash(genesis.Hash()).Return(genesis).AnyTimes()
+	chain.EXPECT().GetHeaderByHash(header1.Hash()).Return(header1).AnyTimes()
+	chain.EXPECT().GetHeader(genesis.Hash(), uint64(0)).Return(genesis).AnyTimes()
+	chain.EXPECT().GetHeader(header1.Hash(), uint64(1)).Return(header1).AnyTimes()
+
+	engine := &Backend{config: &config.Config{BlockPeriod: 1}, logger: log.New()}
+
+	t.Run("past committed seals are verified", func(t *testing.T) {
+		assert.Empty(t, header1.PastCommittedSeals)
+		assert.Len(t, header2.PastCommittedSeals.Seals, 3)
+		assert.NoError(t, engine.VerifyHeader(chain, header1, false))
+		assert.NoError(t, engine.VerifyHeader(chain, header2, false))
+
+		_, results := engine.VerifyHeaders(chain, []*types.Header{header1, header2}, nil)
+		assert.NoError(t, <-results)
+		assert.NoError(t, <-results)
+
+		signers, err := lastBlockSigners(chain, header2, log.New())
+		require.NoError(t, err)
+		assert.Equal(t, []common.Address{committee[0].Address, committee[1].Address, committee[2].Address}, signers)
+	})
+
+	t.Run("header without past committed seals is rejected", func(t *testing.T) {
+		header := types.CopyHeader(header2)
+		header.PastCommittedSeals = types.PastSeals{}
+		resealTestHeader(t, header, keys)
+		assert.Equal(t, errMissingPastCommittedSeals, engine.VerifyHeader(chain, header, false))
+	})
+
+	t.Run("past committed seals without quorum are rejected", func(t *testing.T) {
+		header := types.CopyHeader(header2)
+		header.PastCommittedSeals.Seals = header.PastCommittedSeals.Seals[:2]
+		resealTestHeader(t, header, keys)
+		assert.Equal(t, types.ErrInvalidCommittedSeals, engine.VerifyHeader(chain, header, false))
+	})
+
+	t.Run("past committed seals of another block are rejected", func(t *testing.T) {
+		header := types.CopyHeader(header2)
+		header.PastCommittedSeals.Seals = header.CommittedSeals
+		resealTestHeader(t, header, keys)
+		assert.Equal(t, types.ErrInvalidCommittedSeals, engine.VerifyHeader(chain, header, false))
+	})
+	t.Run("past committed seals are checked for the round of the parent", func(t *testing.T) {
+		parent := types.CopyHeader(header1)
+		parent.Round = 1
+		assert.Equal(t, types.ErrInvalidCommittedSeals, pastCommittedSealsError(header2, parent, genesis))
+	})
+
+	t.Run("precommits received after the quorum are carried", func(t *testing.T) {
+		seal := tendermintCore.PrepareCommittedSeal(header1.Hash(), int64(header1.Round), header1.Number)
+		late, err := crypto.Sign(crypto.Keccak256(seal), keys[3])
+		require.NoError(t, err)
+		outsider, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		unknown, err := crypto.Sign(crypto.Keccak256(seal), outsider)
+		require.NoError(t, err)
+		// the seals of the parent and of the non members are not carried twice
+		collected := [][]byte{header1.CommittedSeals[0], append(late, make([]byte, 96)...), unknown, {0x1}}
+
+		tendermintC := tendermintCore.NewMockTendermint(ctrl)
+		tendermintC.EXPECT().PastCommittedSeals(header1.Hash(), int64(header1.Round)).Return(collected)
+		engine := &Backend{config: &config.Config{BlockPeriod: 1}, logger: log.New(), core: tendermintC}
+		header := types.CopyHeader(header2)
+		header.PastCommittedSeals = engine.pastCommittedSeals(chain, header1)
+		assert.Equal(t, [][]byte{header1.CommittedSeals[0], header1.CommittedSeals[1], header1.CommittedSeals[2], late}, header.PastCommittedSeals.Seals)
+
+		signers, err := pastCommittedSealSigners(header, header1, genesis, log.New())
+		require.NoError(t, err)
+		assert.Equal(t, []common.Address{committee[0].Address, committee[1].Address, committee[2].Address, committee[3].Address}, signers)
+
+		// without the committee of the grandparent only the seals of the parent are carried
+		assert.Equal(t, header1.CommittedSeals, pastCommittedSeals(header1, nil, collected).Seals)
+	})
+
+	t.Run("past committed seals of an unknown kind are rejected", func(t *testing.T) {
+		header := types.CopyHeader(header2)
+		header.PastCommittedSeals.Kind = types.AggregatedSeals + 1
+		assert.Equal(t, types.ErrInvalidCommittedSeals, pastCommittedSealsError(header, header1, genesis))
+
+		header.PastCommittedSeals.Kind = types.AggregatedSeals
+		assert.Equal(t, types.ErrInvalidCommittedSeals, pastCommittedSealsError(header, header1, genesis))
+	})
+}
+
+func pastCommittedSealsError(header, parent, grandparent *types.Header) error {
+	_, err := pastCommittedSealSigners(header, parent, grandparent, log.New())
+	return err
+}
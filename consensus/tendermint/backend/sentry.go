@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// maxRelayedMessages is the number of messages of the current height a sentry
+// keeps to answer the sync requests.
+const maxRelayedMessages = 1024
+
+// sentry holds the state of the sentry architecture. A validator with sentries
+// only connects to them and sends them its consensus messages, the sentries relay
+// the messages of their validators to their peers and the other way around.
+type sentry struct {
+	sentries  map[common.Address]struct{} // sentries of the validator
+	validator map[common.Address]struct{} // validators the node is a sentry of
+
+	mu      sync.Mutex
+	height  uint64   // height of the relayed messages
+	relayed [][]byte // messages relayed at height
+}
+
+// SetSentries makes the validator reach the committee through the sentries of
+// the given addresses, it must only be called before the engine starts.
+func (sb *Backend) SetSentries(sentries []common.Address) {
+	sb.sentry.sentries = make(map[common.Address]struct{}, len(sentries))
+	for _, addr := range sentries {
+		sb.sentry.sentries[addr] = struct{}{}
+	}
+}
+
+// SetProtectedValidators makes the node a sentry of the validators of the given
+// addresses, it must only be called before the engine starts.
+func (sb *Backend) SetProtectedValidators(validators []common.Address) {
+	sb.sentry.validator = make(map[common.Address]struct{}, len(validators))
+	for _, addr := range validators {
+		sb.sentry.validator[addr] = struct{}{}
+	}
+}
+
+// relaying returns whether the node is a sentry.
+func (sb *Backend) relaying() bool {
+	return len(sb.sentry.validator) > 0
+}
+
+// proxy returns whether the peer of the address relays the consensus messages of
+// a validator for this node: a sentry of the validator or a validator of the sentry.
+func (sb *Backend) proxy(addr common.Address) bool {
+	if _, ok := sb.sentry.sentries[addr]; ok {
+		return true
+	}
+	_, ok := sb.sentry.validator[addr]
+	return ok
+}
+
+// relay forwards a consensus message received by a sentry to the validators it
+// protects and to the committee of the message height, those which sent or were
+// sent it being skipped as done by Gossip.
+func (sb *Backend) relay(payload []byte) {
+	if !sb.relaying() || sb.broadcaster == nil {
+		return
+	}
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return
+	}
+	height, err := msg.Height()
+	if err != nil {
+		return
+	}
+	sb.keepRelayed(height.Uint64(), payload)
+	sb.gossip(sb.broadcaster.FindPeers(sb.relayTargets(height.Uint64())), types.RLPHash(payload), payload, nil)
+}
+
+// relayTargets returns the peers a sentry relays the messages of height to: the
+// validators it protects and the members of the committee of height if known.
+func (sb *Backend) relayTargets(height uint64) map[common.Address]struct{} {
+	targets := make(map[common.Address]struct{}, len(sb.sentry.validator))
+	for addr := range sb.sentry.validator {
+		targets[addr] = struct{}{}
+	}
+	v := &sb.votes
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if sb.votesAt(height, nil) {
+		for addr := range v.members {
+			targets[addr] = struct{}{}
+		}
+	}
+	return targets
+}
+
+// keepRelayed stores a relayed message if it is of the height being decided, the
+// messages of the previous heights being dropped.
+func (sb *Backend) keepRelayed(number uint64, payload []byte) {
+	if sb.blockchain != nil && number > sb.blockchain.CurrentHeader().Number.Uint64()+1 {
+		return
+	}
+
+	sb.sentry.mu.Lock()
+	defer sb.sentry.mu.Unlock()
+	switch {
+	case number < sb.sentry.height:
+		return
+	case number > sb.sentry.height:
+		sb.sentry.height = number
+		sb.sentry.relayed = nil
+	}
+	if len(sb.sentry.relayed) < maxRelayedMessages {
+		sb.sentry.relayed = append(sb.sentry.relayed, payload)
+	}
+}
+
+// syncRelayed answers the sync request of a peer with the messages relayed at
+// the height being decided, a sentry not running the consensus itself.
+func (sb *Backend) syncRelayed(addr common.Address) {
+	if sb.broadcaster == nil {
+		return
+	}
+	p, connected := sb.broadcaster.FindPeers(map[common.Address]struct{}{addr: {}})[addr]
+	if !connected {
+		return
+	}
+
+	sb.sentry.mu.Lock()
+	relayed := append([][]byte{}, sb.sentry.relayed...)
+	sb.sentry.mu.Unlock()
+	for _, payload := range relayed {
+		sb.send(p, tendermintMsg, payload)
+	}
+}
+
+// signedByConsensusPeer returns whether a consensus message was signed by a peer
+// allowed to send them, the message being relayed by one of its sentries. The
+// known messages were already checked and aren't recovered again.
+func (sb *Backend) signedByConsensusPeer(hash common.Hash, payload []byte) bool {
+	if _, ok := sb.knownMessages.Get(hash); ok {
+		return true
+	}
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return false
+	}
+	data, err := msg.PayloadNoSig()
+	if err != nil {
+		return false
+	}
+	signer, err := types.GetSignatureAddress(data, msg.Signature)
+	if err != nil || signer != msg.Address {
+		return false
+	}
+	return sb.broadcaster.ConsensusPeer(signer)
+}
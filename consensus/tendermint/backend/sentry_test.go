@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSentry(broadcaster consensus.Broadcaster, validators ...common.Address) *Backend {
+	recentMessages, _ := lru.NewARC(inmemoryPeers)
+	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	sb := &Backend{
+		broadcaster:    broadcaster,
+		recentMessages: recentMessages,
+		knownMessages:  knownMessages,
+		logger:         log.New(),
+	}
+	sb.SetProtectedValidators(validators)
+	return sb
+}
+
+// signedPrevote returns the payload of a prevote for height signed by key.
+func signedPrevote(t *testing.T, key *ecdsa.PrivateKey, height int64) []byte {
+	vote, err := tendermintCore.Encode(&tendermintCore.Vote{Height: big.NewInt(height), ProposedBlockHash: common.HexToHash("0x01")})
+	require.NoError(t, err)
+	msg := &tendermintCore.Message{
+		Code:    1, // prevote
+		Msg:     vote,
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+	data, err := msg.PayloadNoSig()
+	require.NoError(t, err)
+	msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key)
+	require.NoError(t, err)
+	return msg.Payload()
+}
+
+func TestSentryRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validatorKey, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(validatorKey.PublicKey)
+	member := common.HexToAddress("0x02")
+	payload := signedPrevote(t, validatorKey, 1)
+
+	validatorPeer := consensus.NewMockPeer(ctrl)
+	memberPeer := consensus.NewMockPeer(ctrl)
+	sent := make(chan []byte, 1)
+	memberPeer.EXPECT().Send(uint64(tendermintMsg), gomock.Any()).DoAndReturn(func(_ uint64, data interface{}) error {
+		sent <- data.([]byte)
+		return nil
+	})
+
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(validator).Return(true).AnyTimes()
+	broadcaster.EXPECT().ConsensusPeer(member).Return(true).AnyTimes()
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{validator: {}, member: {}}).Return(map[common.Address]consensus.Peer{
+		validator: validatorPeer,
+		member:    memberPeer,
+	})
+	sb := newTestSentry(broadcaster, validator)
+	sb.votes.reset(1, types.Committee{{Address: validator, VotingPower: big.NewInt(1)}, {Address: member, VotingPower: big.NewInt(1)}})
+
+	handled, err := sb.HandleMsg(validator, makeMsg(tendermintMsg, payload))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, payload, <-sent, "the message is relayed to the committee but its sender")
+
+	// Known messages aren't relayed again.
+	handled, err = sb.HandleMsg(member, makeMsg(tendermintMsg, payload))
+	require.True(t, handled)
+	require.NoError(t, err)
+
+	// The sync requests are answered with the relayed messages.
+	validatorPeer.EXPECT().Send(uint64(tendermintMsg), gomock.Any()).DoAndReturn(func(_ uint64, data interface{}) error {
+		sent <- data.([]byte)
+		return nil
+	})
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{validator: {}}).Return(map[common.Address]consensus.Peer{
+		validator: validatorPeer,
+	})
+	handled, err = sb.HandleMsg(validator, makeMsg(tendermintSyncMsg, []byte{}))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, payload, <-sent)
+}
+
+func TestRelayedMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	validatorKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(validatorKey.PublicKey)
+	sentry := common.HexToAddress("0x03")
+	participant := common.HexToAddress("0x04")
+
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(sentry).Return(false).AnyTimes()
+	broadcaster.EXPECT().ConsensusPeer(validator).Return(true).AnyTimes()
+	broadcaster.EXPECT().ConsensusPeer(participant).Return(false).AnyTimes()
+	broadcaster.EXPECT().ConsensusPeer(crypto.PubkeyToAddress(otherKey.PublicKey)).Return(false).AnyTimes()
+	sb := newTestSentry(broadcaster)
+	sb.SetSentries([]common.Address{sentry})
+
+	handled, err := sb.HandleMsg(participant, makeMsg(tendermintMsg, signedPrevote(t, validatorKey, 1)))
+	assert.True(t, handled)
+	assert.Equal(t, errUnauthorizedPeer, err, "only the proxies relay messages")
+
+	unsigned := signedPrevote(t, otherKey, 1)
+	handled, err = sb.HandleMsg(sentry, makeMsg(tendermintMsg, unsigned))
+	assert.True(t, handled)
+	assert.Equal(t, errUnauthorizedPeer, err, "messages not signed by a consensus peer are rejected")
+
+	handled, err = sb.HandleMsg(sentry, makeMsg(tendermintSyncMsg, []byte{}))
+	assert.True(t, handled)
+	assert.Equal(t, errUnauthorizedPeer, err, "sentries don't ask for sync")
+
+	sb.pendingMessages.SetCapacity(ringCapacity)
+	handled, err = sb.HandleMsg(sentry, makeMsg(tendermintMsg, signedPrevote(t, validatorKey, 1)))
+	assert.True(t, handled)
+	assert.NoError(t, err, "messages relayed by a sentry are accepted")
+
+	sb.knownMessages.Add(types.RLPHash(unsigned), true)
+	handled, err = sb.HandleMsg(sentry, makeMsg(tendermintMsg, unsigned))
+	assert.True(t, handled)
+	assert.NoError(t, err, "known messages aren't checked again")
+}
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+var (
+	// errSignerHeight is returned when a remote signer is asked to sign data which
+	// doesn't belong to the current consensus height.
+	errSignerHeight = errors.New("refusing to sign for another height than the current one")
+)
+
+// Signer holds the validator key and signs the consensus messages, the committed
+// seals and the proposer seals on behalf of the Backend.
+type Signer interface {
+	// Address returns the address of the validator key.
+	Address() common.Address
+
+	// SignData signs keccak256(data), data being a consensus message payload or a
+	// committed seal for the given consensus height.
+	SignData(height *big.Int, data []byte) ([]byte, error)
+
+	// SignHeader returns the proposer seal of a block header for the given consensus height.
+	SignHeader(height *big.Int, header *types.Header) ([]byte, error)
+}
+
+// keySigner signs with a private key held by the node.
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner creates a signer using a private key held by the node.
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignData(_ *big.Int, data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key)
+}
+
+func (s *keySigner) SignHeader(_ *big.Int, header *types.Header) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(types.SigHash(header).Bytes()), s.key)
+}
+
+// ClefSigner forwards the signing requests to an external signer, such as clef,
+// through its account_signData API, so that the validator key is kept out of the
+// node. Only data belonging to the current consensus height is forwarded, the
+// headers being sent whole so that the external signer can check what it seals.
+type ClefSigner struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+}
+
+// NewClefSigner creates a signer using the given account of an external signer
+// wallet. As the committee members are reached by their p2p identity, the account
+// must be the one of the node key.
+func NewClefSigner(wallet accounts.Wallet, address common.Address) (*ClefSigner, error) {
+	account := accounts.Account{Address: address}
+	if !wallet.Contains(account) {
+		return nil, fmt.Errorf("account %v not found in external signer %v", address, wallet.URL())
+	}
+	return &ClefSigner{wallet: wallet, account: account}, nil
+}
+
+// Address implements Signer.Address
+func (s *ClefSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignData implements Signer.SignData
+func (s *ClefSigner) SignData(height *big.Int, data []byte) ([]byte, error) {
+	dataHeight := signedDataHeight(data)
+	if height == nil || dataHeight == nil || dataHeight.Cmp(height) != 0 {
+		return nil, errSignerHeight
+	}
+	return s.wallet.SignData(s.account, accounts.MimetypeTendermint, data)
+}
+
+// SignHeader implements Signer.SignHeader
+func (s *ClefSigner) SignHeader(height *big.Int, header *types.Header) ([]byte, error) {
+	if height == nil || header.Number.Cmp(height) != 0 {
+		return nil, errSignerHeight
+	}
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	return s.wallet.SignData(s.account, accounts.MimetypeTendermint, data)
+}
+
+// signedDataHeight returns the height of a consensus message payload or of a
+// committed seal built by PrepareCommittedSeal, nil if data is neither of them.
+func signedDataHeight(data []byte) *big.Int {
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(data); err == nil {
+		height, _ := msg.Height()
+		return height
+	}
+	_, _, height, err := tendermintCore.ParseCommittedSeal(data)
+	if err != nil {
+		return nil
+	}
+	return height
+}
+
+// consensusHeight returns the height the consensus is running for, nil if the
+// engine has not been started.
+func (sb *Backend) consensusHeight() *big.Int {
+	if sb.currentBlock == nil {
+		return nil
+	}
+	return new(big.Int).Add(sb.currentBlock().Number(), common.Big1)
+}
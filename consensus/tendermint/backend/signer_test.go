@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// testExternalWallet is an external signer wallet holding a single key.
+type testExternalWallet struct {
+	accounts.Wallet
+	key       *ecdsa.PrivateKey
+	mimeTypes []string
+}
+
+func (w *testExternalWallet) URL() accounts.URL {
+	return accounts.URL{Scheme: "extapi", Path: "test"}
+}
+
+func (w *testExternalWallet) Contains(account accounts.Account) bool {
+	return account.Address == crypto.PubkeyToAddress(w.key.PublicKey)
+}
+
+func (w *testExternalWallet) SignData(_ accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	w.mimeTypes = append(w.mimeTypes, mimeType)
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(data, header); err == nil {
+		data = types.SigHash(header).Bytes()
+	}
+	return crypto.Sign(crypto.Keccak256(data), w.key)
+}
+
+func TestClefSigner(t *testing.T) {
+	b := newWatermarkTestBackend(t, rawdb.NewMemoryDatabase())
+	wallet := &testExternalWallet{key: b.signer.(*keySigner).key}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := NewClefSigner(wallet, crypto.PubkeyToAddress(other.PublicKey)); err == nil {
+		t.Fatalf("expected an error for an account missing from the external signer")
+	}
+	signer, err := NewClefSigner(wallet, b.address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.signer = signer
+
+	// Nothing is signed before the consensus height is known.
+	vote := votePayload(t, testMsgPrevote, 5, 0, common.HexToHash("0x1"))
+	if _, err := b.Sign(vote); err != errSignerHeight {
+		t.Fatalf("error mismatch: have %v, want %v", err, errSignerHeight)
+	}
+
+	head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(4)})
+	b.currentBlock = func() *types.Block { return head }
+
+	sig, err := b.Sign(vote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer, _ := types.GetSignatureAddress(vote, sig); signer != b.address {
+		t.Errorf("signer mismatch: have %v, want %v", signer, b.address)
+	}
+	if _, err := b.Sign(votePayload(t, testMsgPrevote, 6, 0, common.HexToHash("0x1"))); err != errSignerHeight {
+		t.Errorf("error mismatch: have %v, want %v", err, errSignerHeight)
+	}
+	if _, err := b.Sign(tendermintCore.PrepareCommittedSeal(common.HexToHash("0x1"), 0, big.NewInt(5))); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	if _, err := b.Sign(tendermintCore.PrepareCommittedSeal(common.HexToHash("0x1"), 0, big.NewInt(4))); err != errSignerHeight {
+		t.Errorf("error mismatch: have %v, want %v", err, errSignerHeight)
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)})
+	sealed, err := b.AddSeal(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposer, _ := types.Ecrecover(sealed.Header()); proposer != b.address {
+		t.Errorf("proposer mismatch: have %v, want %v", proposer, b.address)
+	}
+	if _, err := b.AddSeal(head); err != errSignerHeight {
+		t.Errorf("error mismatch: have %v, want %v", err, errSignerHeight)
+	}
+
+	for _, mimeType := range wallet.mimeTypes {
+		if mimeType != accounts.MimetypeTendermint {
+			t.Errorf("mime type mismatch: have %v, want %v", mimeType, accounts.MimetypeTendermint)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/log"
+)
+
+// ValidatorUptime returns the liveness of the committee members over the last
+// blocks of the chain.
+func (sb *Backend) ValidatorUptime() *tendermintCore.UptimeReport {
+	sb.syncUptime()
+	return sb.uptime.Report()
+}
+
+// syncUptime feeds the uptime tracker with the blocks committed since the last
+// tracked one.
+func (sb *Backend) syncUptime() {
+	if sb.blockchain == nil {
+		return
+	}
+	sb.uptimeMu.Lock()
+	defer sb.uptimeMu.Unlock()
+	syncUptime(sb.blockchain, sb.uptime)
+}
+
+// syncUptime adds to tracker the canonical blocks following its last tracked block,
+// up to the head of chain. At most a window of blocks is read, the tracker being
+// reset by the first block if it followed another chain.
+func syncUptime(chain consensus.ChainHeaderReader, tracker *tendermintCore.UptimeTracker) {
+	head := chain.CurrentHeader().Number.Uint64()
+	from := uint64(1)
+	if window := uint64(tracker.Window()); head > window {
+		from = head - window + 1
+	}
+	if number, hash, ok := tracker.Head(); ok && number >= from && number <= head {
+		if canonical := chain.GetHeaderByNumber(number); canonical != nil && canonical.Hash() == hash {
+			from = number + 1
+		}
+	}
+
+	// Seals from another committee are not expected, the blocks are verified.
+	logger := log.New()
+	logger.SetHandler(log.DiscardHandler())
+	parent, err := resolveCommittee(chain, chain.GetHeaderByNumber(from-1))
+	if err != nil {
+		log.Warn("Unknown committee of canonical block", "number", from-1, "err", err)
+		return
+	}
+	for number := from; number <= head && parent != nil; number++ {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return
+		}
+		signers, err := committedSealSigners(header, parent, logger)
+		if err != nil {
+			log.Warn("Invalid committed seals in canonical block", "number", number, "err", err)
+		}
+		tracker.Add(header, parent.Committee, signers)
+		parent = withCommittee(header, parent.Committee)
+	}
+}
@@ -0,0 +1,421 @@
+package backend
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+const (
+	// maxPeerBitmaps is the number of steps of the height tracked per peer, the
+	// announcements of further steps being ignored.
+	maxPeerBitmaps = 3 * 32
+	// maxMsgBitmaps is the number of bitmaps of a have or get message processed,
+	// the following ones being dropped.
+	maxMsgBitmaps = 64
+	// pullTimeout is the time after which a message pulled from a peer and not
+	// received is pulled again from the next peer announcing it.
+	pullTimeout = 2 * time.Second
+	// saveInterval is the minimum time between two writes of the peers bitmaps to
+	// the database, they are also written when the engine stops.
+	saveInterval = time.Second
+)
+
+// voteKey identifies the messages of a step of a round.
+type voteKey struct {
+	round uint64
+	step  uint64
+}
+
+// voteBitmap is the content of the have and get messages: the messages of a step
+// of a round a node has or wants, a bit per member of the committee of the height
+// in the committee order.
+type voteBitmap struct {
+	Height uint64
+	Round  uint64
+	Step   uint64
+	Bits   []byte
+}
+
+// pull is a set of messages requested from a peer and not received yet.
+type pull struct {
+	bits []byte
+	at   time.Time
+}
+
+// peerBitmaps are the messages a peer has at a height, as stored in the database.
+type peerBitmaps struct {
+	Address common.Address
+	Bitmaps []voteBitmap
+}
+
+// votes holds the consensus messages of the height being decided and the ones
+// known by the peers running the bitmap gossip. Those peers are announced the
+// messages through bitmaps and only pull the ones they miss, so that a message
+// is sent once to each of them. The bitmaps of the peers are kept in the database
+// so that they aren't sent the messages they have again after a restart.
+type votes struct {
+	mu       sync.Mutex
+	height   uint64
+	members  map[common.Address]int                // committee index of the members
+	payloads map[voteKey]map[int][]byte            // messages of the height by sender index
+	peers    map[common.Address]map[voteKey][]byte // messages the peers have
+	pulls    map[voteKey]*pull
+
+	loaded  bool      // whether the bitmaps stored before the restart were read
+	dirty   bool      // whether the bitmaps of the peers changed since written
+	savedAt time.Time // time the bitmaps of the peers were last written
+}
+
+// reset moves the votes to a new height of the given committee.
+func (v *votes) reset(height uint64, committee types.Committee) {
+	v.height = height
+	v.members = make(map[common.Address]int, len(committee))
+	for i, member := range committee {
+		v.members[member.Address] = i
+	}
+	v.payloads = make(map[voteKey]map[int][]byte)
+	v.peers = make(map[common.Address]map[voteKey][]byte)
+	v.pulls = make(map[voteKey]*pull)
+}
+
+// bitmapLen returns the length in bytes of the bitmaps of the height.
+func (v *votes) bitmapLen() int {
+	return (len(v.members) + 7) / 8
+}
+
+// bitmap returns the bitmap of the messages held for key.
+func (v *votes) bitmap(key voteKey) []byte {
+	bits := make([]byte, v.bitmapLen())
+	for index := range v.payloads[key] {
+		setBit(bits, index)
+	}
+	return bits
+}
+
+// peerBitmap returns the bitmap of the messages a peer has for key to be updated,
+// nil if the peer already announced too many steps.
+func (v *votes) peerBitmap(addr common.Address, key voteKey) []byte {
+	v.dirty = true
+	known, ok := v.peers[addr]
+	if !ok {
+		known = make(map[voteKey][]byte)
+		v.peers[addr] = known
+	}
+	bits, ok := known[key]
+	if !ok {
+		if len(known) >= maxPeerBitmaps {
+			return nil
+		}
+		bits = make([]byte, v.bitmapLen())
+		known[key] = bits
+	}
+	return bits
+}
+
+// missing returns the messages of bits which are neither held nor being pulled,
+// marking them as pulled, or nil if there is none.
+func (v *votes) missing(key voteKey, bits []byte, now time.Time) []byte {
+	p, ok := v.pulls[key]
+	if !ok || now.Sub(p.at) > pullTimeout {
+		p = &pull{bits: make([]byte, v.bitmapLen())}
+		v.pulls[key] = p
+	}
+	var wanted []byte
+	for index := 0; index < len(v.members); index++ {
+		if !hasBit(bits, index) || hasBit(p.bits, index) {
+			continue
+		}
+		if _, held := v.payloads[key][index]; held {
+			continue
+		}
+		if wanted == nil {
+			wanted = make([]byte, v.bitmapLen())
+		}
+		setBit(wanted, index)
+		setBit(p.bits, index)
+	}
+	if wanted != nil {
+		p.at = now
+	}
+	return wanted
+}
+
+func setBit(bits []byte, index int) {
+	bits[index/8] |= 1 << uint(index%8)
+}
+
+func hasBit(bits []byte, index int) bool {
+	return index/8 < len(bits) && bits[index/8]&(1<<uint(index%8)) != 0
+}
+
+// voteOf returns the height, the step and the sender of a consensus message.
+func voteOf(payload []byte) (uint64, voteKey, common.Address, error) {
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return 0, voteKey{}, common.Address{}, err
+	}
+	height, err := msg.Height()
+	if err != nil {
+		return 0, voteKey{}, common.Address{}, err
+	}
+	round, err := msg.Round()
+	if err != nil {
+		return 0, voteKey{}, common.Address{}, err
+	}
+	if round < 0 {
+		return 0, voteKey{}, common.Address{}, errInvalidRound
+	}
+	step, err := msg.Step()
+	if err != nil {
+		return 0, voteKey{}, common.Address{}, err
+	}
+	return height.Uint64(), voteKey{round: uint64(round), step: uint64(step)}, msg.Address, nil
+}
+
+// peerVersion returns the version of the tendermint protocol run with a peer, the
+// peers which don't report it running the versions before tendermint66.
+func peerVersion(p consensus.Peer) uint {
+	if v, ok := p.(consensus.VersionedPeer); ok {
+		return v.ConsensusVersion()
+	}
+	return 0
+}
+
+// bitmapGossip returns whether the peer is announced the messages through bitmaps
+// rather than sent them.
+func bitmapGossip(p consensus.Peer) bool {
+	return peerVersion(p) >= tendermint66
+}
+
+// votesAt moves the votes to height if it is the one being decided, the committee
+// being read from the chain if not given. It returns whether the votes are at
+// height, it must be called with the votes locked.
+func (sb *Backend) votesAt(height uint64, committee types.Committee) bool {
+	v := &sb.votes
+	if v.members != nil && height == v.height {
+		return true
+	}
+	if v.members != nil && height < v.height {
+		return false
+	}
+	if committee == nil {
+		if committee = sb.heightCommittee(height); committee == nil {
+			return false
+		}
+	}
+	v.reset(height, committee)
+	if !v.loaded {
+		v.loaded = true
+		sb.loadVotes()
+	}
+	return true
+}
+
+// loadVotes restores the bitmaps of the peers written before a restart if they
+// are of the height being decided, it must be called with the votes locked.
+func (sb *Backend) loadVotes() {
+	if sb.db == nil {
+		return
+	}
+	data := rawdb.ReadGossipBitmaps(sb.db)
+	if len(data) == 0 {
+		return
+	}
+	var saved []peerBitmaps
+	if err := rlp.DecodeBytes(data, &saved); err != nil {
+		sb.logger.Warn("Invalid gossip bitmaps", "err", err)
+		return
+	}
+	v := &sb.votes
+	for _, peer := range saved {
+		for _, bm := range peer.Bitmaps {
+			if bm.Height != v.height || len(bm.Bits) != v.bitmapLen() {
+				continue
+			}
+			if known := v.peerBitmap(peer.Address, voteKey{round: bm.Round, step: bm.Step}); known != nil {
+				copy(known, bm.Bits)
+			}
+		}
+	}
+	v.dirty = false
+}
+
+// saveVotes writes the bitmaps of the peers to the database if they changed,
+// at most once per saveInterval unless forced.
+func (sb *Backend) saveVotes(force bool) {
+	if sb.db == nil {
+		return
+	}
+	v := &sb.votes
+	v.mu.Lock()
+	now := time.Now()
+	if !v.dirty || v.members == nil || (!force && now.Sub(v.savedAt) < saveInterval) {
+		v.mu.Unlock()
+		return
+	}
+	saved := make([]peerBitmaps, 0, len(v.peers))
+	for addr, known := range v.peers {
+		peer := peerBitmaps{Address: addr}
+		for key, bits := range known {
+			peer.Bitmaps = append(peer.Bitmaps, voteBitmap{Height: v.height, Round: key.round, Step: key.step, Bits: common.CopyBytes(bits)})
+		}
+		saved = append(saved, peer)
+	}
+	v.dirty, v.savedAt = false, now
+	v.mu.Unlock()
+
+	data, err := rlp.EncodeToBytes(saved)
+	if err != nil {
+		sb.logger.Warn("Failed to encode gossip bitmaps", "err", err)
+		return
+	}
+	rawdb.WriteGossipBitmaps(sb.db, data)
+}
+
+// heightCommittee returns the committee of height if it follows the head of the
+// chain, nil otherwise.
+func (sb *Backend) heightCommittee(height uint64) types.Committee {
+	if sb.blockchain == nil {
+		return nil
+	}
+	head := sb.blockchain.CurrentHeader()
+	if head.Number.Uint64()+1 != height {
+		return nil
+	}
+	committee, err := epochCommittee(sb.blockchain, head)
+	if err != nil {
+		return nil
+	}
+	return committee
+}
+
+// storeVote keeps a message of the height being decided to answer the pulls of
+// the peers. It returns the step of the message and the index of its sender, or
+// false if the message isn't of the height being decided or conflicts with the
+// one held for its sender and step. The first message is kept, a conflicting one
+// being sent in full so that the peers can detect the misbehaviour.
+func (sb *Backend) storeVote(payload []byte, committee types.Committee) (voteKey, int, bool) {
+	height, key, sender, err := voteOf(payload)
+	if err != nil {
+		return voteKey{}, 0, false
+	}
+	v := &sb.votes
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !sb.votesAt(height, committee) {
+		return voteKey{}, 0, false
+	}
+	index, ok := v.members[sender]
+	if !ok {
+		return voteKey{}, 0, false
+	}
+	if v.payloads[key] == nil {
+		v.payloads[key] = make(map[int][]byte)
+	}
+	if held, ok := v.payloads[key][index]; ok {
+		return key, index, bytes.Equal(held, payload)
+	}
+	v.payloads[key][index] = payload
+	return key, index, true
+}
+
+// announcement returns the have bitmap announcing a message to a peer, nil if the
+// peer already has it.
+func (sb *Backend) announcement(addr common.Address, key voteKey, index int) []voteBitmap {
+	v := &sb.votes
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if known := v.peers[addr][key]; hasBit(known, index) {
+		return nil
+	}
+	return []voteBitmap{{Height: v.height, Round: key.round, Step: key.step, Bits: v.bitmap(key)}}
+}
+
+// markVote records that a peer has a message.
+func (sb *Backend) markVote(addr common.Address, payload []byte) {
+	height, key, sender, err := voteOf(payload)
+	if err != nil {
+		return
+	}
+	v := &sb.votes
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !sb.votesAt(height, nil) {
+		return
+	}
+	if index, ok := v.members[sender]; ok {
+		if bits := v.peerBitmap(addr, key); bits != nil {
+			setBit(bits, index)
+		}
+	}
+}
+
+// handleHave records the messages announced by a peer and pulls those missing.
+func (sb *Backend) handleHave(addr common.Address, bitmaps []voteBitmap) {
+	if len(bitmaps) > maxMsgBitmaps {
+		bitmaps = bitmaps[:maxMsgBitmaps]
+	}
+	var wanted []voteBitmap
+	v := &sb.votes
+	v.mu.Lock()
+	now := time.Now()
+	for _, bm := range bitmaps {
+		if bm.Step > uint64(tendermintCore.PrecommitStep) || !sb.votesAt(bm.Height, nil) || len(bm.Bits) != v.bitmapLen() {
+			continue
+		}
+		key := voteKey{round: bm.Round, step: bm.Step}
+		known := v.peerBitmap(addr, key)
+		if known == nil {
+			continue
+		}
+		for i := range known {
+			known[i] |= bm.Bits[i]
+		}
+		if bits := v.missing(key, bm.Bits, now); bits != nil {
+			wanted = append(wanted, voteBitmap{Height: bm.Height, Round: bm.Round, Step: bm.Step, Bits: bits})
+		}
+	}
+	v.mu.Unlock()
+
+	if len(wanted) > 0 {
+		sb.sendTo(addr, tendermintGetMsg, wanted)
+	}
+}
+
+// handleGet sends a peer the messages it pulls.
+func (sb *Backend) handleGet(addr common.Address, bitmaps []voteBitmap) {
+	if len(bitmaps) > maxMsgBitmaps {
+		bitmaps = bitmaps[:maxMsgBitmaps]
+	}
+	var payloads [][]byte
+	v := &sb.votes
+	v.mu.Lock()
+	for _, bm := range bitmaps {
+		if v.members == nil || bm.Height != v.height {
+			continue
+		}
+		key := voteKey{round: bm.Round, step: bm.Step}
+		known := v.peerBitmap(addr, key)
+		for index, payload := range v.payloads[key] {
+			if hasBit(bm.Bits, index) {
+				payloads = append(payloads, payload)
+				if known != nil {
+					setBit(known, index)
+				}
+			}
+		}
+	}
+	v.mu.Unlock()
+
+	for _, payload := range payloads {
+		sb.sendTo(addr, tendermintMsg, payload)
+	}
+}
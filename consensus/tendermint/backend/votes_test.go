@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentMsg struct {
+	code uint64
+	data interface{}
+}
+
+// bitmapPeer is a peer running the bitmap gossip.
+type bitmapPeer chan sentMsg
+
+func (p bitmapPeer) Send(code uint64, data interface{}) error {
+	p <- sentMsg{code: code, data: data}
+	return nil
+}
+
+func (p bitmapPeer) ConsensusVersion() uint {
+	return tendermint66
+}
+
+func testCommittee(addrs ...common.Address) types.Committee {
+	committee := make(types.Committee, len(addrs))
+	for i, addr := range addrs {
+		committee[i] = types.CommitteeMember{Address: addr, VotingPower: big.NewInt(1)}
+	}
+	return committee
+}
+
+func TestBitmapGossip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+	newPeer, oldPeer := common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	committee := testCommittee(validator, newPeer, oldPeer)
+	payload := signedPrevote(t, key, 1)
+	assert.Equal(t, "prevote", msgType(tendermintMsg, payload))
+
+	bitmaps := bitmapPeer(make(chan sentMsg, 1))
+	legacy := consensus.NewMockPeer(ctrl)
+	sent := make(chan []byte, 1)
+	legacy.EXPECT().Send(uint64(tendermintMsg), gomock.Any()).DoAndReturn(func(_ uint64, data interface{}) error {
+		sent <- data.([]byte)
+		return nil
+	})
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(gomock.Any()).Return(true).AnyTimes()
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{validator: {}, newPeer: {}, oldPeer: {}}).Return(map[common.Address]consensus.Peer{
+		newPeer: bitmaps,
+		oldPeer: legacy,
+	}).Times(2)
+	sb := newTestSentry(broadcaster)
+
+	sb.Gossip(context.Background(), committee, payload)
+	assert.Equal(t, payload, <-sent, "the peers of the older versions are sent the message")
+	have := []voteBitmap{{Height: 1, Round: 0, Step: 1, Bits: []byte{0x01}}}
+	assert.Equal(t, sentMsg{code: tendermintHaveMsg, data: have}, <-bitmaps, "the others are announced it")
+
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{newPeer: {}}).Return(map[common.Address]consensus.Peer{
+		newPeer: bitmaps,
+	})
+	handled, err := sb.HandleMsg(newPeer, makeMsg(tendermintGetMsg, have))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.Equal(t, sentMsg{code: tendermintMsg, data: payload}, <-bitmaps, "the pulled messages are sent")
+
+	// The message is neither sent nor announced again.
+	sb.Gossip(context.Background(), committee, payload)
+	select {
+	case msg := <-bitmaps:
+		t.Fatalf("unexpected message %v", msg)
+	default:
+	}
+}
+
+func TestHandleHave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	member, first, second := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	bitmaps := bitmapPeer(make(chan sentMsg, 1))
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(gomock.Any()).Return(true).AnyTimes()
+	broadcaster.EXPECT().FindPeers(map[common.Address]struct{}{first: {}}).Return(map[common.Address]consensus.Peer{
+		first: bitmaps,
+	})
+	sb := newTestSentry(broadcaster)
+	sb.votes.reset(1, testCommittee(member, first, second))
+
+	have := []voteBitmap{
+		{Height: 1, Round: 0, Step: 2, Bits: []byte{0x03}},
+		{Height: 1, Round: 0, Step: 5, Bits: []byte{0x01}},
+		{Height: 2, Round: 0, Step: 2, Bits: []byte{0x01}},
+	}
+	handled, err := sb.HandleMsg(first, makeMsg(tendermintHaveMsg, have))
+	require.True(t, handled)
+	require.NoError(t, err)
+	get := []voteBitmap{{Height: 1, Round: 0, Step: 2, Bits: []byte{0x03}}}
+	assert.Equal(t, sentMsg{code: tendermintGetMsg, data: get}, <-bitmaps, "the missing messages are pulled")
+
+	// The messages being pulled aren't pulled from the other peers.
+	handled, err = sb.HandleMsg(second, makeMsg(tendermintHaveMsg, have[:1]))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.True(t, hasBit(sb.votes.peers[second][voteKey{round: 0, step: 2}], 1))
+}
+
+func TestBitmapMsgUnauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	peer := common.HexToAddress("0x01")
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(peer).Return(false).AnyTimes()
+	sb := newTestSentry(broadcaster)
+
+	for _, code := range []uint64{tendermintHaveMsg, tendermintGetMsg} {
+		handled, err := sb.HandleMsg(peer, makeMsg(code, []voteBitmap{}))
+		assert.True(t, handled)
+		assert.Equal(t, errUnauthorizedPeer, err)
+	}
+}
+
+func TestStoreVote(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+	payload := signedPrevote(t, key, 1)
+	vote, err := tendermintCore.Encode(&tendermintCore.Vote{Height: big.NewInt(1), ProposedBlockHash: common.HexToHash("0x02")})
+	require.NoError(t, err)
+	conflicting := (&tendermintCore.Message{Code: 1, Msg: vote, Address: validator}).Payload()
+
+	sb := newTestSentry(nil)
+	committee := testCommittee(validator)
+	_, index, stored := sb.storeVote(payload, committee)
+	assert.True(t, stored)
+	_, _, stored = sb.storeVote(payload, committee)
+	assert.True(t, stored, "the message held is announced again")
+	_, _, stored = sb.storeVote(conflicting, committee)
+	assert.False(t, stored, "a conflicting message is sent in full")
+	assert.Equal(t, payload, sb.votes.payloads[voteKey{round: 0, step: 1}][index], "the first message is kept")
+}
+
+func TestBitmapsRestart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	member, peer := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	committee := testCommittee(member, peer)
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().ConsensusPeer(gomock.Any()).Return(true).AnyTimes()
+	broadcaster.EXPECT().FindPeers(gomock.Any()).Return(nil).AnyTimes()
+	db := rawdb.NewMemoryDatabase()
+	sb := newTestSentry(broadcaster)
+	sb.db = db
+	sb.votes.reset(1, committee)
+
+	have := []voteBitmap{{Height: 1, Round: 0, Step: 1, Bits: []byte{0x01}}}
+	handled, err := sb.HandleMsg(peer, makeMsg(tendermintHaveMsg, have))
+	require.True(t, handled)
+	require.NoError(t, err)
+	assert.NotEmpty(t, rawdb.ReadGossipBitmaps(db), "the bitmaps of the peers are written")
+
+	// A restarted node doesn't announce the peers the messages they have.
+	restarted := newTestSentry(broadcaster)
+	restarted.db = db
+	restarted.votes.mu.Lock()
+	require.True(t, restarted.votesAt(1, committee))
+	restarted.votes.mu.Unlock()
+	assert.Nil(t, restarted.announcement(peer, voteKey{round: 0, step: 1}, 0))
+	assert.NotNil(t, restarted.announcement(peer, voteKey{round: 0, step: 1}, 1))
+
+	// The bitmaps of another height are dropped.
+	restarted = newTestSentry(broadcaster)
+	restarted.db = db
+	restarted.votes.mu.Lock()
+	require.True(t, restarted.votesAt(2, committee))
+	restarted.votes.mu.Unlock()
+	assert.Empty(t, restarted.votes.peers)
+}
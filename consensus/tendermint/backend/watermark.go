@@ -0,0 +1,162 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// errDoubleSign is returned when the node is asked to sign a consensus message
+// conflicting with one it already signed.
+var errDoubleSign = errors.New("refusing to sign conflicting consensus message")
+
+// SigningWatermark is the latest consensus message signed by the validator. It is
+// synced to disk before the signature is handed out so that the node never signs
+// a message going back in height, round or step, nor a second value at the same
+// step, even across restarts or crashes. The watermark is kept in the database of
+// the node: it does not guard two instances of the same validator each running on
+// its own database, as in a failover setup. Such setups have to rely on an external
+// signer shared by the instances and keeping its own watermark.
+type SigningWatermark struct {
+	Height *big.Int            `json:"height"`
+	Round  int64               `json:"round"`
+	Step   tendermintCore.Step `json:"step"`
+	Value  common.Hash         `json:"value"`
+}
+
+// signingWatermarkRLP is the storage format of a watermark, rounds of signed
+// messages are never negative.
+type signingWatermarkRLP struct {
+	Height *big.Int
+	Round  uint64
+	Step   uint64
+	Value  common.Hash
+}
+
+// cmp compares the position of w in the consensus with the one of o.
+func (w *SigningWatermark) cmp(o *SigningWatermark) int {
+	if c := w.Height.Cmp(o.Height); c != 0 {
+		return c
+	}
+	if w.Round != o.Round {
+		if w.Round < o.Round {
+			return -1
+		}
+		return 1
+	}
+	return w.Step.Cmp(o.Step)
+}
+
+func (w *SigningWatermark) String() string {
+	return fmt.Sprintf("{Height: %v, Round: %v, Step: %v, Value: %v}", w.Height, w.Round, w.Step, w.Value.String())
+}
+
+// readSigningWatermark loads the persisted watermark, nil if the validator never signed.
+func (sb *Backend) readSigningWatermark() *SigningWatermark {
+	data := rawdb.ReadSigningWatermark(sb.db)
+	if len(data) == 0 {
+		return nil
+	}
+	var stored signingWatermarkRLP
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		// Signing must not silently restart from scratch, the watermark has to be
+		// reset explicitly by the operator.
+		sb.logger.Error("Invalid signing watermark", "err", err)
+		return &SigningWatermark{Height: new(big.Int).SetUint64(^uint64(0))}
+	}
+	return &SigningWatermark{
+		Height: stored.Height,
+		Round:  int64(stored.Round),
+		Step:   tendermintCore.Step(stored.Step),
+		Value:  stored.Value,
+	}
+}
+
+// raiseWatermark checks that the consensus message encoded in data does not
+// conflict with the messages already signed and records it as the new watermark.
+// Data which is not a consensus message is not subject to the watermark: the
+// committed seals are only sent within their precommit message.
+func (sb *Backend) raiseWatermark(data []byte) error {
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(data); err != nil {
+		return nil
+	}
+	step, err := msg.Step()
+	if err != nil {
+		return nil
+	}
+	height, _ := msg.Height()
+	round, _ := msg.Round()
+	value, err := msg.Value()
+	if err != nil {
+		return err
+	}
+	next := &SigningWatermark{Height: height, Round: round, Step: step, Value: value}
+
+	sb.watermarkMu.Lock()
+	defer sb.watermarkMu.Unlock()
+
+	current := sb.readSigningWatermark()
+	if current != nil {
+		switch c := next.cmp(current); {
+		case c < 0:
+			sb.logger.Error("Refusing to sign a message below the signing watermark", "watermark", current, "msg", next)
+			return errDoubleSign
+		case c == 0 && next.Value != current.Value:
+			sb.logger.Error("Refusing to sign a conflicting message", "watermark", current, "msg", next)
+			return errDoubleSign
+		case c == 0:
+			return nil
+		}
+	}
+
+	stored, err := rlp.EncodeToBytes(&signingWatermarkRLP{
+		Height: next.Height,
+		Round:  uint64(next.Round),
+		Step:   uint64(next.Step),
+		Value:  next.Value,
+	})
+	if err != nil {
+		return err
+	}
+	batch := sb.db.NewBatch()
+	rawdb.WriteSigningWatermark(batch, stored)
+	return writeSync(batch)
+}
+
+// writeSync writes batch and waits for it to reach the disk, the signature being
+// handed out right after. The databases unable to sync, as the in-memory ones, are
+// only written.
+func writeSync(batch ethdb.Batch) error {
+	if b, ok := batch.(ethdb.SyncWriter); ok {
+		return b.WriteSync()
+	}
+	return batch.Write()
+}
+
+// SigningWatermark returns the latest consensus message signed by the validator.
+func (sb *Backend) SigningWatermark() *SigningWatermark {
+	sb.watermarkMu.Lock()
+	defer sb.watermarkMu.Unlock()
+
+	return sb.readSigningWatermark()
+}
+
+// ResetSigningWatermark clears the signing watermark, allowing the validator to sign
+// messages below it again. It is only meant to recover from a chain rewind, using it
+// while another instance of the validator is running exposes it to equivocation.
+func (sb *Backend) ResetSigningWatermark() *SigningWatermark {
+	sb.watermarkMu.Lock()
+	defer sb.watermarkMu.Unlock()
+
+	previous := sb.readSigningWatermark()
+	rawdb.DeleteSigningWatermark(sb.db)
+	sb.logger.Warn("Signing watermark reset by the operator", "previous", previous)
+	return previous
+}
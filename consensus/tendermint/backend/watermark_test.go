@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// Consensus message codes, as defined by the core package.
+const (
+	testMsgPrevote   uint64 = 1
+	testMsgPrecommit uint64 = 2
+)
+
+func newWatermarkTestBackend(t *testing.T, db ethdb.Database) *Backend {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Backend{
+		signer:  &keySigner{key: key},
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		db:      db,
+		logger:  log.New(),
+	}
+}
+
+func votePayload(t *testing.T, code uint64, height uint64, round int64, value common.Hash) []byte {
+	vote, err := rlp.EncodeToBytes(&tendermintCore.Vote{Round: round, Height: new(big.Int).SetUint64(height), ProposedBlockHash: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &tendermintCore.Message{Code: code, Msg: vote, CommittedSeal: []byte{}}
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestSigningWatermark(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	b := newWatermarkTestBackend(t, db)
+	v1, v2 := common.HexToHash("0x1"), common.HexToHash("0x2")
+
+	if b.SigningWatermark() != nil {
+		t.Fatalf("watermark set before signing")
+	}
+	mustSign := func(b *Backend, data []byte) {
+		t.Helper()
+		if _, err := b.Sign(data); err != nil {
+			t.Fatalf("error mismatch: have %v, want nil", err)
+		}
+	}
+	mustRefuse := func(b *Backend, data []byte) {
+		t.Helper()
+		if _, err := b.Sign(data); err != errDoubleSign {
+			t.Fatalf("error mismatch: have %v, want %v", err, errDoubleSign)
+		}
+	}
+
+	mustSign(b, votePayload(t, testMsgPrevote, 5, 1, v1))
+	// Signing the same message again is harmless.
+	mustSign(b, votePayload(t, testMsgPrevote, 5, 1, v1))
+	mustRefuse(b, votePayload(t, testMsgPrevote, 5, 1, v2))
+	mustSign(b, votePayload(t, testMsgPrecommit, 5, 1, v2))
+	mustRefuse(b, votePayload(t, testMsgPrevote, 5, 1, v1))
+	mustRefuse(b, votePayload(t, testMsgPrecommit, 5, 0, v1))
+	mustRefuse(b, votePayload(t, testMsgPrecommit, 4, 3, v1))
+	// Data which is not a consensus message is not restricted.
+	mustSign(b, tendermintCore.PrepareCommittedSeal(v1, 0, big.NewInt(4)))
+
+	if have := b.SigningWatermark(); have.Height.Uint64() != 5 || have.Round != 1 || have.Step.String() != "precommit" || have.Value != v2 {
+		t.Fatalf("watermark mismatch: have %v", have)
+	}
+
+	// The watermark is kept across restarts on the same database.
+	other := newWatermarkTestBackend(t, db)
+	mustRefuse(other, votePayload(t, testMsgPrecommit, 5, 1, v1))
+	mustSign(other, votePayload(t, testMsgPrevote, 5, 2, v1))
+
+	if previous := other.ResetSigningWatermark(); previous == nil || previous.Round != 2 {
+		t.Fatalf("reset watermark mismatch: have %v", previous)
+	}
+	if b.SigningWatermark() != nil {
+		t.Fatalf("watermark not reset")
+	}
+	mustSign(b, votePayload(t, testMsgPrecommit, 4, 3, v1))
+}
@@ -16,6 +16,11 @@
 
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type ProposerPolicy uint64
 
 const (
@@ -23,25 +28,195 @@ const (
 	WeightedRandomSampling
 )
 
+const (
+	DefaultTimeoutPropose        = 2000 // Base timeout of the propose step in milliseconds
+	DefaultTimeoutProposeDelta   = 500  // Increase of the propose timeout per round in milliseconds
+	DefaultTimeoutPrevote        = 1000 // Base timeout of the prevote step in milliseconds
+	DefaultTimeoutPrevoteDelta   = 500  // Increase of the prevote timeout per round in milliseconds
+	DefaultTimeoutPrecommit      = 1000 // Base timeout of the precommit step in milliseconds
+	DefaultTimeoutPrecommitDelta = 500  // Increase of the precommit timeout per round in milliseconds
+
+	DefaultMisbehaviourWindow = 256  // Number of blocks following its height an evidence of double signing can be included in
+	DefaultDowntimeWindow     = 1024 // Number of blocks over which the committee members are checked for downtime
+
+	// MaxTimeout is the upper bound of any configured timeout or delta, it prevents a
+	// misconfiguration (e.g. seconds given instead of milliseconds) from stalling the network.
+	MaxTimeout = 10 * 60 * 1000
+)
+
 type Config struct {
 	BlockPeriod    uint64         `toml:",omitempty" json:"block-period"` // Default minimum difference between two consecutive block's timestamps in second
 	ProposerPolicy ProposerPolicy `toml:",omitempty" json:"policy"`       // The policy for proposer selection
+
+	// Consensus step timeouts in milliseconds, the timeout of a step at round r is base + r * delta.
+	// The deltas are unset if nil, a zero delta keeping the timeout of the step constant.
+	TimeoutPropose        uint64  `toml:",omitempty" json:"timeout-propose,omitempty"`
+	TimeoutProposeDelta   *uint64 `toml:",omitempty" json:"timeout-propose-delta,omitempty"`
+	TimeoutPrevote        uint64  `toml:",omitempty" json:"timeout-prevote,omitempty"`
+	TimeoutPrevoteDelta   *uint64 `toml:",omitempty" json:"timeout-prevote-delta,omitempty"`
+	TimeoutPrecommit      uint64  `toml:",omitempty" json:"timeout-precommit,omitempty"`
+	TimeoutPrecommitDelta *uint64 `toml:",omitempty" json:"timeout-precommit-delta,omitempty"`
+
+	// External signer (url or path to ipc file) holding the validator key, the node key signs if unset.
+	Signer string `toml:",omitempty" json:"signer,omitempty"`
+
+	// Directory recording the consensus messages received and sent by the node, nothing is recorded if unset.
+	MessageLog string `toml:",omitempty" json:"message-log,omitempty"`
+
+	// Aggregate the BLS committed seals of the committee members into a single header signature.
+	AggregatedSeals bool `toml:",omitempty" json:"aggregated-seals,omitempty"`
+
+	// Number of blocks the committee is kept for, it is only recomputed by the blocks
+	// whose number is a multiple of it. The committee changes at every block if unset.
+	EpochLength uint64 `toml:",omitempty" json:"epoch-length,omitempty"`
+
+	// Number of blocks following its height during which an evidence of double signing
+	// can be included in a block.
+	MisbehaviourWindow uint64 `toml:",omitempty" json:"misbehaviour-window,omitempty"`
+	// Number of blocks over which the committee members are checked for downtime, at the
+	// blocks whose number is a multiple of it.
+	DowntimeWindow uint64 `toml:",omitempty" json:"downtime-window,omitempty"`
+
+	// Enode urls of the sentries of a validator. The validator only connects to its
+	// sentries, which relay its consensus messages, and stays out of discovery.
+	Sentries []string `toml:",omitempty" json:"sentries,omitempty"`
+
+	// Enode urls of the validators a sentry relays the consensus messages of.
+	ProtectedValidators []string `toml:",omitempty" json:"protected-validators,omitempty"`
 }
 
 func (c *Config) String() string {
 	return "tendermint"
 }
 
+// IsEpochHeader returns whether the header at number starts an epoch, it is
+// the one carrying the committee of the following blocks.
+func (c *Config) IsEpochHeader(number uint64) bool {
+	return c.EpochLength <= 1 || number%c.EpochLength == 0
+}
+
+// EpochHeader returns the number of the header carrying the committee of the
+// block following number.
+func (c *Config) EpochHeader(number uint64) uint64 {
+	if c.EpochLength <= 1 {
+		return number
+	}
+	return number - number%c.EpochLength
+}
+
+// TimeoutDelta returns a timeout delta of ms milliseconds.
+func TimeoutDelta(ms uint64) *uint64 {
+	return &ms
+}
+
+// SetDefaults fills the unset timeouts and windows with their default value.
+func (c *Config) SetDefaults() {
+	for _, t := range []struct {
+		value *uint64
+		def   uint64
+	}{
+		{&c.TimeoutPropose, DefaultTimeoutPropose},
+		{&c.TimeoutPrevote, DefaultTimeoutPrevote},
+		{&c.TimeoutPrecommit, DefaultTimeoutPrecommit},
+		{&c.MisbehaviourWindow, DefaultMisbehaviourWindow},
+		{&c.DowntimeWindow, DefaultDowntimeWindow},
+	} {
+		if *t.value == 0 {
+			*t.value = t.def
+		}
+	}
+	for _, t := range []struct {
+		delta **uint64
+		def   uint64
+	}{
+		{&c.TimeoutProposeDelta, DefaultTimeoutProposeDelta},
+		{&c.TimeoutPrevoteDelta, DefaultTimeoutPrevoteDelta},
+		{&c.TimeoutPrecommitDelta, DefaultTimeoutPrecommitDelta},
+	} {
+		if *t.delta == nil {
+			*t.delta = TimeoutDelta(t.def)
+		}
+	}
+}
+
+// Validate returns an error if the configuration is invalid. Unset timeouts
+// are valid since they are replaced by their default value.
+func (c *Config) Validate() error {
+	if c.BlockPeriod == 0 {
+		return fmt.Errorf("invalid block period configured for tendermint")
+	}
+	if c.ProposerPolicy != RoundRobin && c.ProposerPolicy != WeightedRandomSampling {
+		return fmt.Errorf("unknown tendermint proposer policy %d", c.ProposerPolicy)
+	}
+	delta := func(d *uint64) uint64 {
+		if d == nil {
+			return 0
+		}
+		return *d
+	}
+	for _, t := range []struct {
+		name  string
+		value uint64
+	}{
+		{"timeout-propose", c.TimeoutPropose},
+		{"timeout-propose-delta", delta(c.TimeoutProposeDelta)},
+		{"timeout-prevote", c.TimeoutPrevote},
+		{"timeout-prevote-delta", delta(c.TimeoutPrevoteDelta)},
+		{"timeout-precommit", c.TimeoutPrecommit},
+		{"timeout-precommit-delta", delta(c.TimeoutPrecommitDelta)},
+	} {
+		if t.value > MaxTimeout {
+			return fmt.Errorf("tendermint %s of %dms exceeds the maximum of %v", t.name, t.value, MaxTimeout*time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// SetTimeouts copies the consensus timeouts set in cfg, leaving the other ones unchanged.
+func (c *Config) SetTimeouts(cfg *Config) {
+	for _, t := range []struct {
+		dst *uint64
+		src uint64
+	}{
+		{&c.TimeoutPropose, cfg.TimeoutPropose},
+		{&c.TimeoutPrevote, cfg.TimeoutPrevote},
+		{&c.TimeoutPrecommit, cfg.TimeoutPrecommit},
+	} {
+		if t.src != 0 {
+			*t.dst = t.src
+		}
+	}
+	for _, t := range []struct {
+		dst **uint64
+		src *uint64
+	}{
+		{&c.TimeoutProposeDelta, cfg.TimeoutProposeDelta},
+		{&c.TimeoutPrevoteDelta, cfg.TimeoutPrevoteDelta},
+		{&c.TimeoutPrecommitDelta, cfg.TimeoutPrecommitDelta},
+	} {
+		if t.src != nil {
+			*t.dst = TimeoutDelta(*t.src)
+		}
+	}
+}
+
 func DefaultConfig() *Config {
 	return &Config{
-		BlockPeriod:    1,
-		ProposerPolicy: WeightedRandomSampling,
+		BlockPeriod:           1,
+		ProposerPolicy:        WeightedRandomSampling,
+		TimeoutPropose:        DefaultTimeoutPropose,
+		TimeoutProposeDelta:   TimeoutDelta(DefaultTimeoutProposeDelta),
+		TimeoutPrevote:        DefaultTimeoutPrevote,
+		TimeoutPrevoteDelta:   TimeoutDelta(DefaultTimeoutPrevoteDelta),
+		TimeoutPrecommit:      DefaultTimeoutPrecommit,
+		TimeoutPrecommitDelta: TimeoutDelta(DefaultTimeoutPrecommitDelta),
+		MisbehaviourWindow:    DefaultMisbehaviourWindow,
+		DowntimeWindow:        DefaultDowntimeWindow,
 	}
 }
 
 func RoundRobinConfig() *Config {
-	return &Config{
-		BlockPeriod:    1,
-		ProposerPolicy: RoundRobin,
-	}
+	config := DefaultConfig()
+	config.ProposerPolicy = RoundRobin
+	return config
 }
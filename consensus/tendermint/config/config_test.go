@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSetDefaults(t *testing.T) {
+	cfg := &Config{BlockPeriod: 1, TimeoutPrevote: 3000}
+	cfg.SetDefaults()
+
+	assert.Equal(t, uint64(DefaultTimeoutPropose), cfg.TimeoutPropose)
+	assert.Equal(t, uint64(3000), cfg.TimeoutPrevote)
+	assert.Equal(t, TimeoutDelta(DefaultTimeoutPrecommitDelta), cfg.TimeoutPrecommitDelta)
+
+	// A zero delta is kept.
+	cfg = &Config{BlockPeriod: 1, TimeoutPrevoteDelta: TimeoutDelta(0)}
+	cfg.SetDefaults()
+	assert.Equal(t, TimeoutDelta(0), cfg.TimeoutPrevoteDelta)
+	assert.Equal(t, TimeoutDelta(DefaultTimeoutProposeDelta), cfg.TimeoutProposeDelta)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+	assert.NoError(t, (&Config{BlockPeriod: 1}).Validate())
+	assert.Error(t, (&Config{}).Validate())
+	assert.Error(t, (&Config{BlockPeriod: 1, ProposerPolicy: 5}).Validate())
+	assert.Error(t, (&Config{BlockPeriod: 1, TimeoutPropose: MaxTimeout + 1}).Validate())
+	assert.Error(t, (&Config{BlockPeriod: 1, TimeoutPrevoteDelta: TimeoutDelta(MaxTimeout + 1)}).Validate())
+}
+
+func TestConfigSetTimeouts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetTimeouts(&Config{TimeoutPrecommit: 5000, TimeoutPrecommitDelta: TimeoutDelta(0)})
+
+	assert.Equal(t, uint64(5000), cfg.TimeoutPrecommit)
+	assert.Equal(t, uint64(DefaultTimeoutPropose), cfg.TimeoutPropose)
+	assert.Equal(t, TimeoutDelta(0), cfg.TimeoutPrecommitDelta)
+	assert.Equal(t, TimeoutDelta(DefaultTimeoutPrevoteDelta), cfg.TimeoutPrevoteDelta)
+}
+
+func TestConfigEpoch(t *testing.T) {
+	cfg := &Config{EpochLength: 10}
+	assert.True(t, cfg.IsEpochHeader(0))
+	assert.True(t, cfg.IsEpochHeader(20))
+	assert.False(t, cfg.IsEpochHeader(21))
+	assert.Equal(t, uint64(20), cfg.EpochHeader(29))
+	assert.Equal(t, uint64(30), cfg.EpochHeader(30))
+
+	// Every header starts an epoch when the epoch length is unset.
+	cfg = &Config{}
+	assert.True(t, cfg.IsEpochHeader(21))
+	assert.Equal(t, uint64(21), cfg.EpochHeader(21))
+}
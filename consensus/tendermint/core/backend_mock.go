@@ -150,6 +150,20 @@ func (mr *MockTendermintMockRecorder) CoreState() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoreState", reflect.TypeOf((*MockTendermint)(nil).CoreState))
 }
 
+// PastCommittedSeals mocks base method
+func (m *MockTendermint) PastCommittedSeals(hash common.Hash, round int64) [][]byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PastCommittedSeals", hash, round)
+	ret0, _ := ret[0].([][]byte)
+	return ret0
+}
+
+// PastCommittedSeals indicates an expected call of PastCommittedSeals
+func (mr *MockTendermintMockRecorder) PastCommittedSeals(hash, round interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PastCommittedSeals", reflect.TypeOf((*MockTendermint)(nil).PastCommittedSeals), hash, round)
+}
+
 // Gossip mocks base method
 func (m *MockBackend) Gossip(ctx context.Context, committee types.Committee, payload []byte) {
 	m.ctrl.T.Helper()
@@ -228,6 +242,18 @@ func (mr *MockBackendMockRecorder) Sign(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockBackend)(nil).Sign), arg0)
 }
 
+// StoreMisbehaviourEvidence mocks base method
+func (m *MockBackend) StoreMisbehaviourEvidence(evidence *MisbehaviourEvidence) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StoreMisbehaviourEvidence", evidence)
+}
+
+// StoreMisbehaviourEvidence indicates an expected call of StoreMisbehaviourEvidence
+func (mr *MockBackendMockRecorder) StoreMisbehaviourEvidence(evidence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreMisbehaviourEvidence", reflect.TypeOf((*MockBackend)(nil).StoreMisbehaviourEvidence), evidence)
+}
+
 // Subscribe mocks base method
 func (m *MockBackend) Subscribe(types ...interface{}) *event.TypeMuxSubscription {
 	m.ctrl.T.Helper()
@@ -17,8 +17,10 @@
 package core
 
 import (
+	"bytes"
 	"github.com/clearmatics/autonity/common"
 	"math/big"
+	"sort"
 )
 
 const MaxSizeBacklogUnchecked = 1000
@@ -117,7 +119,15 @@ func (c *core) storeUncheckedBacklog(msg *Message) {
 func (c *core) processBacklog() {
 	var capToLenRatio = 5
 
-	for src, backlog := range c.backlogs {
+	// The senders are taken in order so that the events are posted deterministically.
+	senders := make([]common.Address, 0, len(c.backlogs))
+	for src := range c.backlogs {
+		senders = append(senders, src)
+	}
+	sort.Slice(senders, func(i, j int) bool { return bytes.Compare(senders[i][:], senders[j][:]) < 0 })
+
+	for _, src := range senders {
+		backlog := c.backlogs[src]
 		logger := c.logger.New("from", src, "step", c.step)
 
 		initialLen := len(backlog)
@@ -141,7 +151,7 @@ func (c *core) processBacklog() {
 				}
 				logger.Debug("Post backlog event", "msg", curMsg)
 
-				go c.sendEvent(backlogEvent{
+				c.sendEventAsync(backlogEvent{
 					msg: curMsg,
 				})
 
@@ -162,7 +172,7 @@ func (c *core) processBacklog() {
 	for height := range c.backlogUnchecked {
 		if height == c.height.Uint64() {
 			for _, msg := range c.backlogUnchecked[height] {
-				go c.sendEvent(backlogUncheckedEvent{
+				c.sendEventAsync(backlogUncheckedEvent{
 					msg: msg,
 				})
 				c.logger.Debug("Post unchecked backlog event", "msg", msg)
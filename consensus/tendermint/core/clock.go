@@ -0,0 +1,35 @@
+package core
+
+import "time"
+
+// Clock schedules the timeouts and the asynchronous events of the core. The core
+// runs on the wall clock unless another one is set, e.g. by a simulation running
+// it on virtual time.
+type Clock interface {
+	// AfterFunc calls f once the duration elapsed, outside of the caller.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function scheduled by a Clock.
+type Timer interface {
+	// Stop prevents the function from being called, it returns false if the
+	// function has already been called or the timer stopped.
+	Stop() bool
+}
+
+// afterFunc schedules f on clock, the wall clock if nil.
+func afterFunc(clock Clock, d time.Duration, f func()) Timer {
+	if clock == nil {
+		return time.AfterFunc(d, f)
+	}
+	return clock.AfterFunc(d, f)
+}
+
+// SetClock sets the clock scheduling the timeouts and the internal events of the
+// core, it must be called before the core is started.
+func (c *core) SetClock(clock Clock) {
+	c.clock = clock
+	c.proposeTimeout.clock = clock
+	c.prevoteTimeout.clock = clock
+	c.precommitTimeout.clock = clock
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"sync"
 
@@ -12,7 +13,6 @@ import (
 	ethcore "github.com/clearmatics/autonity/core"
 	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
-	"github.com/clearmatics/autonity/log"
 )
 
 type committee interface {
@@ -137,19 +137,75 @@ func getMemberIndex(members types.Committee, memberAddr common.Address) int64 {
 }
 
 type weightedRandomSamplingCommittee struct {
-	previousHeader         *types.Header
-	bc                     *ethcore.BlockChain
-	autonityContract       *autonity.Contract
-	previousBlockStateRoot common.Hash
+	previousHeader *types.Header
+	election       *autonity.ProposerElection      // nil if the previous block is the genesis block
+	allProposers   map[int64]types.CommitteeMember // cached computed values
+	mu             sync.Mutex
 }
 
-func newWeightedRandomSamplingCommittee(previousBlock *types.Block, autonityContract *autonity.Contract, bc *ethcore.BlockChain) *weightedRandomSamplingCommittee {
-	return &weightedRandomSamplingCommittee{
-		previousHeader:         previousBlock.Header(),
-		bc:                     bc,
-		autonityContract:       autonityContract,
-		previousBlockStateRoot: previousBlock.Root(),
+// newWeightedRandomSamplingCommittee loads the committee of previousBlock in the
+// order of the Autonity contract, from which the proposers of the next height are
+// then elected without calling the contract.
+func newWeightedRandomSamplingCommittee(previousBlock *types.Block, autonityContract *autonity.Contract, bc *ethcore.BlockChain) (*weightedRandomSamplingCommittee, error) {
+	previousHeader := previousBlock.Header()
+	if len(previousHeader.Committee) == 0 {
+		return nil, ErrEmptyCommitteeSet
+	}
+	w := &weightedRandomSamplingCommittee{
+		previousHeader: previousHeader,
+		allProposers:   make(map[int64]types.CommitteeMember),
+	}
+	// If previous header was the genesis block then we will not yet have
+	// deployed the autonity contract so will take the proposers in turn from
+	// the sorted committee of the genesis block.
+	if previousHeader.IsGenesis() {
+		w.GetProposer(0)
+		return w, nil
 	}
+	// state.New has started takig a snapshot.Tree but it seems to be only for
+	// performance, see - https://github.com/ethereum/go-ethereum/pull/20152
+	statedb, err := state.New(previousBlock.Root(), bc.StateCache(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load state of block %v: %w", previousHeader.Number, err)
+	}
+	committee, err := autonityContract.GetElectionCommittee(previousHeader, statedb)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get committee of block %v: %w", previousHeader.Number, err)
+	}
+	return newWeightedRandomSamplingElection(previousHeader, committee)
+}
+
+// newWeightedRandomSamplingElection creates the committee of the child of
+// previousHeader, electing its proposers from the committee of previousHeader in
+// the order of committee, the one of the Autonity contract. Inside an epoch the
+// contract committee may differ from the epoch committee stored in the headers:
+// the members left out of the contract follow those it ranks, in header order.
+func newWeightedRandomSamplingElection(previousHeader *types.Header, committee types.Committee) (*weightedRandomSamplingCommittee, error) {
+	ranked := make(types.Committee, 0, len(previousHeader.Committee))
+	seen := make(map[common.Address]struct{}, len(previousHeader.Committee))
+	for _, member := range committee {
+		if m := previousHeader.CommitteeMember(member.Address); m != nil {
+			ranked = append(ranked, *m)
+			seen[member.Address] = struct{}{}
+		}
+	}
+	for _, member := range previousHeader.Committee {
+		if _, ok := seen[member.Address]; !ok {
+			ranked = append(ranked, member)
+		}
+	}
+	election, err := autonity.NewProposerElection(ranked)
+	if err != nil {
+		return nil, err
+	}
+	w := &weightedRandomSamplingCommittee{
+		previousHeader: previousHeader,
+		election:       election,
+		allProposers:   make(map[int64]types.CommitteeMember),
+	}
+	// The proposers of the next rounds are elected on round change.
+	w.GetProposer(0)
+	return w, nil
 }
 
 // Return the underlying types.Committee
@@ -179,25 +235,23 @@ func (w *weightedRandomSamplingCommittee) GetByAddress(addr common.Address) (int
 
 // Get the round proposer
 func (w *weightedRandomSamplingCommittee) GetProposer(round int64) types.CommitteeMember {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	// If previous header was the genesis block then we will not yet have
-	// deployed the autonity contract so will take the proposer as the first
-	// defined validator of the genesis block.
-	if w.previousHeader.IsGenesis() {
-		sort.Sort(w.previousHeader.Committee)
-		return w.previousHeader.Committee[round%int64(len(w.previousHeader.Committee))]
+	if v, ok := w.allProposers[round]; ok {
+		return v
 	}
-	// state.New has started takig a snapshot.Tree but it seems to be only for
-	// performance, see - https://github.com/ethereum/go-ethereum/pull/20152
-	statedb, err := state.New(w.previousBlockStateRoot, w.bc.StateCache(), nil)
-	if err != nil {
-		log.Error("cannot load state from block chain.")
-		return types.CommitteeMember{}
+	var v types.CommitteeMember
+	if w.election == nil {
+		committee := copyMembers(w.previousHeader.Committee)
+		sort.Sort(committee)
+		v = committee[round%int64(len(committee))]
+	} else {
+		// The election committee has the same members as the header.
+		v = *w.previousHeader.CommitteeMember(w.election.Proposer(w.previousHeader.Number.Uint64(), round))
 	}
-	proposer := w.autonityContract.GetProposerFromAC(w.previousHeader, statedb, w.previousHeader.Number.Uint64(), round)
-	member := w.previousHeader.CommitteeMember(proposer)
-	return *member
-	// TODO make this return an error
+	w.allProposers[round] = v
+	return v
 }
 
 // Get the optimal quorum size
@@ -209,7 +263,9 @@ func (w *weightedRandomSamplingCommittee) F() uint64 {
 	return bft.F(w.previousHeader.TotalVotingPower())
 }
 
-var ErrEmptyCommitteeSet = errors.New("committee set can't be empty")
+var (
+	ErrEmptyCommitteeSet = errors.New("committee set can't be empty")
+)
 
 func copyMembers(members types.Committee) types.Committee {
 	membersCopy := make(types.Committee, len(members))
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/core/types"
@@ -250,6 +251,71 @@ func TestSet_IsProposer(t *testing.T) {
 	})
 }
 
+func TestWeightedRandomSamplingCommittee(t *testing.T) {
+	committeeMembers := createTestCommitteeMembers(t, 5, 100)
+	committeeMembers[2].VotingPower = big.NewInt(60)
+	// The contract committee is ranked while the header committee is sorted.
+	contractCommittee := copyMembers(committeeMembers)
+	sort.Sort(committeeMembers)
+	previousHeader := &types.Header{Number: big.NewInt(7), Committee: committeeMembers}
+
+	set, err := newWeightedRandomSamplingElection(previousHeader, contractCommittee)
+	require.NoError(t, err)
+	for round := int64(0); round < 20; round++ {
+		expected, err := autonity.ElectProposer(contractCommittee, 7, round)
+		require.NoError(t, err)
+		require.Equal(t, expected, set.GetProposer(round).Address)
+		require.Equal(t, set.GetProposer(round), set.allProposers[round])
+	}
+
+	t.Run("genesis committee taken in turn", func(t *testing.T) {
+		genesis := &types.Header{Number: big.NewInt(0), Committee: copyMembers(contractCommittee)}
+		set, err := newWeightedRandomSamplingCommittee(types.NewBlockWithHeader(genesis), nil, nil)
+		require.NoError(t, err)
+		for round := int64(0); round < 10; round++ {
+			require.Equal(t, committeeMembers[round%5], set.GetProposer(round))
+		}
+		// The header committee isn't sorted in place.
+		require.Equal(t, contractCommittee, genesis.Committee)
+	})
+
+	t.Run("committee must be staking", func(t *testing.T) {
+		unstaked := types.Committee{{Address: common.Address{1}, VotingPower: new(big.Int)}}
+		_, err := newWeightedRandomSamplingElection(&types.Header{Number: big.NewInt(1), Committee: unstaked}, unstaked)
+		require.Equal(t, autonity.ErrCommitteeNotStaking, err)
+	})
+}
+
+func TestWeightedRandomSamplingEpochCommittee(t *testing.T) {
+	epochCommittee := createTestCommitteeMembers(t, 5, 100)
+	ranked := copyMembers(epochCommittee)
+	sort.Sort(epochCommittee)
+	previousHeader := &types.Header{Number: big.NewInt(12), Committee: epochCommittee}
+
+	// Inside the epoch, the contract jailed its second member, added a new one and
+	// changed the voting power of the others.
+	joined := createTestCommitteeMembers(t, 1, 100)[0]
+	contractCommittee := append(copyMembers(ranked[:1]), ranked[2:]...)
+	contractCommittee = append(contractCommittee, joined)
+	for i := range contractCommittee {
+		contractCommittee[i].VotingPower = big.NewInt(int64(i + 1))
+	}
+
+	set, err := newWeightedRandomSamplingElection(previousHeader, contractCommittee)
+	require.NoError(t, err, "a membership change inside the epoch must not halt the chain")
+	require.Equal(t, epochCommittee, set.Committee())
+	// The proposers are elected from the epoch committee, the jailed member ranked
+	// after those of the contract.
+	election := append(copyMembers(ranked[:1]), ranked[2:]...)
+	election = append(election, ranked[1])
+	for round := int64(0); round < 20; round++ {
+		expected, err := autonity.ElectProposer(election, 12, round)
+		require.NoError(t, err)
+		require.Equal(t, expected, set.GetProposer(round).Address)
+		require.NotNil(t, previousHeader.CommitteeMember(set.GetProposer(round).Address))
+	}
+}
+
 func TestSet_QandF(t *testing.T) {
 	testCases := []struct {
 		TotalVP int64
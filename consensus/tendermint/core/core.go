@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
-	"time"
 
 	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
@@ -55,6 +54,8 @@ var (
 	errInvalidMessage = errors.New("invalid message")
 	// errInvalidSenderOfCommittedSeal is returned when the committed seal is not from the sender of the message.
 	errInvalidSenderOfCommittedSeal = errors.New("invalid sender of committed seal")
+	// errInvalidBLSCommittedSeal is returned when the BLS committed seal doesn't verify against the sender key.
+	errInvalidBLSCommittedSeal = errors.New("invalid bls committed seal")
 	// errFailedDecodeProposal is returned when the PROPOSAL message is malformed.
 	errFailedDecodeProposal = errors.New("failed to decode PROPOSAL")
 	// errFailedDecodePrevote is returned when the PREVOTE message is malformed.
@@ -84,6 +85,7 @@ func New(backend Backend, config *config.Config) *core {
 	return &core{
 		proposerPolicy:        config.ProposerPolicy,
 		blockPeriod:           config.BlockPeriod,
+		timeouts:              newTimeoutConfig(config),
 		address:               addr,
 		logger:                logger,
 		backend:               backend,
@@ -93,6 +95,7 @@ func New(backend Backend, config *config.Config) *core {
 		pendingUnminedBlockCh: make(chan *types.Block),
 		stopped:               make(chan struct{}, 4),
 		committee:             nil,
+		height:                new(big.Int),
 		futureRoundChange:     make(map[int64]map[common.Address]uint64),
 		messages:              messagesMap,
 		lockedRound:           -1,
@@ -107,6 +110,7 @@ func New(backend Backend, config *config.Config) *core {
 type core struct {
 	proposerPolicy config.ProposerPolicy
 	blockPeriod    uint64
+	timeouts       *timeoutConfig // nil for the default timeouts
 	address        common.Address
 	logger         log.Logger
 
@@ -118,7 +122,7 @@ type core struct {
 	committedSub            *event.TypeMuxSubscription
 	timeoutEventSub         *event.TypeMuxSubscription
 	syncEventSub            *event.TypeMuxSubscription
-	futureProposalTimer     *time.Timer
+	futureProposalTimer     Timer
 	stopped                 chan struct{}
 
 	backlogs            map[common.Address][]*Message
@@ -144,6 +148,7 @@ type core struct {
 	step                  Step
 	curRoundMessages      *roundMessages
 	messages              messagesMap
+	pastPrecommits        pastPrecommits
 	sentProposal          bool
 	sentPrevote           bool
 	sentPrecommit         bool
@@ -161,6 +166,9 @@ type core struct {
 	futureRoundChange map[int64]map[common.Address]uint64
 
 	autonityContract *autonity.Contract
+
+	wal   *WAL
+	clock Clock // the wall clock if nil
 }
 
 func (c *core) GetCurrentHeightMessages() []*Message {
@@ -197,6 +205,12 @@ func (c *core) broadcast(ctx context.Context, msg *Message) {
 		return
 	}
 
+	// The message must be recorded before it is sent, so that it can't be forgotten after a crash
+	if err = c.wal.append(c.Height(), walMessage, payload); err != nil {
+		logger.Error("Failed to write message to the wal", "msg", msg, "err", err)
+		return
+	}
+
 	// Broadcast payload
 	logger.Debug("broadcasting", "msg", msg.String())
 	if err = c.backend.Broadcast(ctx, c.committeeSet().Committee(), payload); err != nil {
@@ -236,9 +250,7 @@ func (c *core) commit(round int64, messages *roundMessages) {
 
 	committedSeals := make([][]byte, 0)
 	for _, v := range messages.CommitedSeals(proposal.ProposalBlock.Hash()) {
-		seal := make([]byte, types.BFTExtraSeal)
-		copy(seal[:], v.CommittedSeal[:])
-		committedSeals = append(committedSeals, seal)
+		committedSeals = append(committedSeals, append([]byte(nil), v.CommittedSeal...))
 	}
 
 	if err := c.backend.Commit(proposal.ProposalBlock, round, committedSeals); err != nil {
@@ -262,8 +274,16 @@ func (c *core) measureHeightRoundMetrics(round int64) {
 func (c *core) startRound(ctx context.Context, round int64) {
 
 	c.measureHeightRoundMetrics(round)
-	// Set initial FSM state
-	c.setInitialState(round)
+	// Set initial FSM state, the height is only started once its committee is known,
+	// the core then resumes upon the next committed block.
+	if err := c.setInitialState(round); err != nil {
+		c.logger.Error("Failed to start a new round", "round", round, "err", err)
+		return
+	}
+	// Resume the height from the wal after a restart
+	if round == 0 && c.replayWAL(ctx) {
+		return
+	}
 	// c.setStep(propose) will process the pending unmined blocks sent by the backed.Seal() and set c.lastestPendingRequest
 	c.setStep(propose)
 	c.logger.Debug("Starting new Round", "Height", c.Height(), "Round", round)
@@ -296,20 +316,20 @@ func (c *core) startRound(ctx context.Context, round int64) {
 	}
 }
 
-func (c *core) setInitialState(r int64) {
+// setInitialState resets the state of the core for round r. At the start of a
+// height, it returns an error if the committee of the height cannot be built, the
+// core being left at the previous height.
+func (c *core) setInitialState(r int64) error {
 	// Start of new height where round is 0
 	if r == 0 {
 		lastBlockMined, _ := c.backend.LastCommittedProposal()
-		c.setHeight(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
-
 		lastHeader := lastBlockMined.Header()
 		var committeeSet committee
 		var err error
-		var lastProposer common.Address
 		switch c.proposerPolicy {
 		case config.RoundRobin:
+			var lastProposer common.Address
 			if !lastHeader.IsGenesis() {
-				var err error
 				lastProposer, err = types.Ecrecover(lastHeader)
 				if err != nil {
 					panic(fmt.Sprintf("unable to recover proposer address from header %q: %v", lastHeader, err))
@@ -320,11 +340,21 @@ func (c *core) setInitialState(r int64) {
 				panic(fmt.Sprintf("failed to construct committee %v", err))
 			}
 		case config.WeightedRandomSampling:
-			committeeSet = newWeightedRandomSamplingCommittee(lastBlockMined, c.autonityContract, c.backend.BlockChain())
+			committeeSet, err = newWeightedRandomSamplingCommittee(lastBlockMined, c.autonityContract, c.backend.BlockChain())
+			if err != nil {
+				return fmt.Errorf("cannot elect the proposers of height %v: %w", new(big.Int).Add(lastHeader.Number, common.Big1), err)
+			}
 		default:
 			panic(fmt.Sprintf("unrecognised proposer policy %q", c.proposerPolicy))
 		}
 
+		// The precommits of the height just committed are kept for the block of the next one.
+		var pastHeader *types.Header
+		if c.lastHeader != nil && c.Height().Cmp(lastHeader.Number) == 0 {
+			pastHeader = c.lastHeader
+		}
+		c.pastPrecommits.reset(pastHeader, &c.messages)
+		c.setHeight(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
 		c.lastHeader = lastHeader
 		c.setCommitteeSet(committeeSet)
 		c.lockedRound = -1
@@ -333,6 +363,7 @@ func (c *core) setInitialState(r int64) {
 		c.validValue = nil
 		c.messages.reset()
 		c.futureRoundChange = make(map[int64]map[common.Address]uint64)
+		c.wal.truncate(c.Height())
 	}
 
 	c.proposeTimeout.reset(propose)
@@ -344,14 +375,19 @@ func (c *core) setInitialState(r int64) {
 	c.sentPrecommit = false
 	c.setValidRoundAndValue = false
 	c.setRound(r)
+	return nil
 }
 
 func (c *core) acceptVote(roundMsgs *roundMessages, step Step, hash common.Hash, msg Message) {
+	var conflicting *Message
 	switch step {
 	case prevote:
-		roundMsgs.AddPrevote(hash, msg)
+		conflicting = roundMsgs.AddPrevote(hash, msg)
 	case precommit:
-		roundMsgs.AddPrecommit(hash, msg)
+		conflicting = roundMsgs.AddPrecommit(hash, msg)
+	}
+	if conflicting != nil {
+		c.reportMisbehaviour(conflicting, &msg)
 	}
 }
 
@@ -372,6 +408,23 @@ func PrepareCommittedSeal(hash common.Hash, round int64, height *big.Int) []byte
 	return buf.Bytes()
 }
 
+// ParseCommittedSeal decodes the hash, round and height of a committed seal built
+// by PrepareCommittedSeal. The round must be a valid one and the height a positive
+// number without leading zeros.
+func ParseCommittedSeal(data []byte) (common.Hash, int64, *big.Int, error) {
+	// round (8 bytes) + height (1 to 32 bytes) + block hash
+	heightLen := len(data) - 8 - common.HashLength
+	if heightLen < 1 || heightLen > common.HashLength || data[8] == 0 {
+		return common.Hash{}, 0, nil, errInvalidMessage
+	}
+	round := binary.LittleEndian.Uint64(data[:8])
+	if round > MaxRound {
+		return common.Hash{}, 0, nil, errInvalidMessage
+	}
+	height := new(big.Int).SetBytes(data[8 : 8+heightLen])
+	return common.BytesToHash(data[8+heightLen:]), int64(round), height, nil
+}
+
 func (c *core) setRound(round int64) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
@@ -43,9 +43,12 @@ type Backend interface {
 	// Setter for proposed block hash
 	SetProposedBlockHash(hash common.Hash)
 
-	// Sign signs input data with the backend's private key
+	// Sign signs input data with the validator key
 	Sign([]byte) ([]byte, error)
 
+	// StoreMisbehaviourEvidence persists the proof of a committee member misbehaviour.
+	StoreMisbehaviourEvidence(evidence *MisbehaviourEvidence)
+
 	Subscribe(types ...interface{}) *event.TypeMuxSubscription
 
 	SyncPeer(address common.Address)
@@ -66,9 +69,21 @@ type Backend interface {
 	RemoveMessageFromLocalCache(payload []byte)
 }
 
+// AggregatedSealer is implemented by the backends signing BLS committed seals, which
+// are aggregated into a single signature in the block header.
+type AggregatedSealer interface {
+	// SignAggregatedSeal returns the BLS signature of a committed seal, nil if the
+	// node doesn't take part in the aggregated seals.
+	SignAggregatedSeal(seal []byte) ([]byte, error)
+}
+
 type Tendermint interface {
 	Start(ctx context.Context, contract *autonity.Contract)
 	Stop()
 	GetCurrentHeightMessages() []*Message
 	CoreState() TendermintState
+	// PastCommittedSeals returns the committed seals of the precommits received
+	// for the block of hash committed at round, the last committed block, nil if
+	// they are unknown. The precommits received after the quorum are included.
+	PastCommittedSeals(hash common.Hash, round int64) [][]byte
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/metrics"
@@ -91,3 +92,26 @@ func TestCore_measureMetricsOnTimeOut(t *testing.T) {
 		}
 	})
 }
+
+func TestParseCommittedSeal(t *testing.T) {
+	hash := common.HexToHash("0xaa")
+	for _, height := range []*big.Int{big.NewInt(1), big.NewInt(5000), new(big.Int).Lsh(common.Big1, 255)} {
+		parsedHash, round, parsedHeight, err := ParseCommittedSeal(PrepareCommittedSeal(hash, 3, height))
+		if err != nil || parsedHash != hash || round != 3 || parsedHeight.Cmp(height) != 0 {
+			t.Fatalf("height %v: got %v %v %v %v", height, parsedHash, round, parsedHeight, err)
+		}
+	}
+
+	invalid := map[string][]byte{
+		"round over MaxRound": PrepareCommittedSeal(hash, MaxRound+1, big.NewInt(1)),
+		"zero height":         PrepareCommittedSeal(hash, 0, big.NewInt(0)),
+		"leading zero":        append(append(make([]byte, 9), 0x01), hash.Bytes()...),
+		"height too long":     append(append(make([]byte, 8), bytes.Repeat([]byte{0x01}, common.HashLength+1)...), hash.Bytes()...),
+		"hash only":           hash.Bytes(),
+	}
+	for name, data := range invalid {
+		if _, _, _, err := ParseCommittedSeal(data); err != errInvalidMessage {
+			t.Errorf("%s: expected %v, got %v", name, errInvalidMessage, err)
+		}
+	}
+}
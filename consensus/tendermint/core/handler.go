@@ -25,6 +25,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
 	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	"github.com/clearmatics/autonity/core/types"
 )
 
 // Start implements core.Tendermint.Start
@@ -128,60 +129,17 @@ eventLoop:
 				break eventLoop
 			}
 			// A real ev arrived, process interesting content
-			switch e := ev.Data.(type) {
-			case events.MessageEvent:
-				msg := new(Message)
-				if err := msg.FromPayload(e.Payload); err != nil {
-					c.logger.Error("consensus message invalid payload", "err", err)
-					continue
-				}
-				if err := c.handleMsg(ctx, msg); err != nil {
-					c.logger.Debug("MessageEvent payload failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.Payload)
-			case backlogEvent:
-				// No need to check signature for internal messages
-				c.logger.Debug("started handling backlogEvent")
-				if err := c.handleCheckedMsg(ctx, e.msg); err != nil {
-					c.logger.Debug("backlogEvent message handling failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
-
-			case backlogUncheckedEvent:
-				c.logger.Debug("started handling backlogUncheckedEvent")
-				if err := c.handleMsg(ctx, e.msg); err != nil {
-					c.logger.Debug("backlogUncheckedEvent message failed", "err", err)
-					continue
-				}
-				c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
-			case coreStateRequestEvent:
-				// Process Tendermint state dump request.
-				c.handleStateDump(e)
-			}
+			c.handleEvent(ctx, ev.Data)
 		case ev, ok := <-c.timeoutEventSub.Chan():
 			if !ok {
 				break eventLoop
 			}
-			if timeoutE, ok := ev.Data.(TimeoutEvent); ok {
-				switch timeoutE.step {
-				case msgProposal:
-					c.handleTimeoutPropose(ctx, timeoutE)
-				case msgPrevote:
-					c.handleTimeoutPrevote(ctx, timeoutE)
-				case msgPrecommit:
-					c.handleTimeoutPrecommit(ctx, timeoutE)
-				}
-			}
+			c.handleEvent(ctx, ev.Data)
 		case ev, ok := <-c.committedSub.Chan():
 			if !ok {
 				break eventLoop
 			}
-			switch ev.Data.(type) {
-			case events.CommitEvent:
-				c.handleCommit(ctx)
-			}
+			c.handleEvent(ctx, ev.Data)
 		case <-ctx.Done():
 			c.logger.Info("mainEventLoop is stopped", "event", ctx.Err())
 			break eventLoop
@@ -191,6 +149,75 @@ eventLoop:
 	c.stopped <- struct{}{}
 }
 
+// handleEvent processes an event of the main event loop.
+func (c *core) handleEvent(ctx context.Context, ev interface{}) {
+	switch e := ev.(type) {
+	case events.MessageEvent:
+		msg := new(Message)
+		if err := msg.FromPayload(e.Payload); err != nil {
+			c.logger.Error("consensus message invalid payload", "err", err)
+			return
+		}
+		if err := c.handleMsg(ctx, msg); err != nil {
+			c.logger.Debug("MessageEvent payload failed", "err", err)
+			return
+		}
+		c.backend.Gossip(ctx, c.committeeSet().Committee(), e.Payload)
+	case backlogEvent:
+		// No need to check signature for internal messages
+		c.logger.Debug("started handling backlogEvent")
+		if err := c.handleCheckedMsg(ctx, e.msg); err != nil {
+			c.logger.Debug("backlogEvent message handling failed", "err", err)
+			return
+		}
+		c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
+
+	case backlogUncheckedEvent:
+		c.logger.Debug("started handling backlogUncheckedEvent")
+		if err := c.handleMsg(ctx, e.msg); err != nil {
+			c.logger.Debug("backlogUncheckedEvent message failed", "err", err)
+			return
+		}
+		c.backend.Gossip(ctx, c.committeeSet().Committee(), e.msg.Payload())
+	case coreStateRequestEvent:
+		// Process Tendermint state dump request.
+		c.handleStateDump(e)
+	case TimeoutEvent:
+		switch e.step {
+		case msgProposal:
+			c.handleTimeoutPropose(ctx, e)
+		case msgPrevote:
+			c.handleTimeoutPrevote(ctx, e)
+		case msgPrecommit:
+			c.handleTimeoutPrecommit(ctx, e)
+		}
+	case events.CommitEvent:
+		c.handleCommit(ctx)
+	}
+}
+
+// Init starts the consensus at the height following the last committed block,
+// proposing the unmined block if the node is the first proposer, without starting
+// the event loops of the core: the events posted to the backend are then handed to
+// HandleEvent by the caller. It lets the core be driven one event at a time, e.g.
+// on the virtual time of a simulation.
+func (c *core) Init(ctx context.Context, unminedBlock *types.Block) {
+	lastBlockMined, _ := c.backend.LastCommittedProposal()
+	c.setHeight(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
+	c.storeUnminedBlockMsg(unminedBlock)
+	c.startRound(ctx, 0)
+}
+
+// HandleEvent processes an event posted to the backend as the event loops of a
+// started core do, it must not be called concurrently.
+func (c *core) HandleEvent(ctx context.Context, ev interface{}) {
+	if e, ok := ev.(events.NewUnminedBlockEvent); ok {
+		c.storeUnminedBlockMsg(&e.NewUnminedBlock)
+		return
+	}
+	c.handleEvent(ctx, ev)
+}
+
 func (c *core) syncLoop(ctx context.Context) {
 	/*
 		this method is responsible for asking the network to send us the current consensus state
@@ -240,6 +267,14 @@ func (c *core) sendEvent(ev interface{}) {
 	c.backend.Post(ev)
 }
 
+// sendEventAsync sends event to mux without blocking the caller, which may be
+// the event loop handling it.
+func (c *core) sendEventAsync(ev interface{}) {
+	afterFunc(c.clock, 0, func() {
+		c.sendEvent(ev)
+	})
+}
+
 func (c *core) handleMsg(ctx context.Context, msg *Message) error {
 
 	msgHeight, err := msg.Height()
@@ -252,7 +287,10 @@ func (c *core) handleMsg(ctx context.Context, msg *Message) error {
 		return errFutureHeightMessage // No gossip
 	}
 	if msgHeight.Cmp(c.Height()) < 0 {
-		// Old height messages. Do nothing.
+		// Old height messages. Only the precommits of the last height are kept.
+		if msg.Code == msgPrecommit && new(big.Int).Add(msgHeight, common.Big1).Cmp(c.Height()) == 0 {
+			c.handlePastPrecommit(msg)
+		}
 		return errOldHeightMessage // No gossip
 	}
 
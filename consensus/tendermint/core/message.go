@@ -239,6 +239,25 @@ func (m *Message) Height() (*big.Int, error) {
 	return m.decodedMsg.GetHeight(), nil
 }
 
+// Step returns the step of the state machine the message is sent at.
+func (m *Message) Step() (Step, error) {
+	switch m.Code {
+	case msgProposal:
+		return propose, nil
+	case msgPrevote:
+		return prevote, nil
+	case msgPrecommit:
+		return precommit, nil
+	default:
+		return 0, errInvalidMessage
+	}
+}
+
+// Value returns the hash of the block proposed or voted for by the message.
+func (m *Message) Value() (common.Hash, error) {
+	return msgValue(m)
+}
+
 // ==============================================
 //
 // helper functions
@@ -37,14 +37,19 @@ type messageSet struct {
 	messagesMu *sync.RWMutex
 }
 
-func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) {
+// AddVote stores the vote of the sender for blockHash. If a vote for a different
+// value was already received from the same sender, the new vote is discarded and
+// the previously stored one is returned so that the equivocation can be reported.
+func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) *Message {
 	ms.messagesMu.Lock()
 	defer ms.messagesMu.Unlock()
 
 	// Check first if we already received a message from this pal.
-	if _, ok := ms.messages[msg.Address]; ok {
-		// TODO : double signing fault ! Accountability
-		return
+	if previous, ok := ms.messages[msg.Address]; ok {
+		if _, sameValue := ms.votes[blockHash][msg.Address]; sameValue {
+			return nil
+		}
+		return previous
 	}
 
 	var addressesMap map[common.Address]Message
@@ -56,6 +61,7 @@ func (ms *messageSet) AddVote(blockHash common.Hash, msg Message) {
 	addressesMap = ms.votes[blockHash]
 	addressesMap[msg.Address] = msg
 	ms.messages[msg.Address] = &msg
+	return nil
 }
 
 func (ms *messageSet) GetMessages() []*Message {
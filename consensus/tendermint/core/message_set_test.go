@@ -124,3 +124,23 @@ func TestMessageSetValues(t *testing.T) {
 		}
 	})
 }
+
+func TestMessageSetAddConflictingVote(t *testing.T) {
+	blockHash := common.BytesToHash([]byte("123456789"))
+	msg := Message{Address: common.BytesToAddress([]byte("987654321")), power: 1}
+
+	ms := newMessageSet()
+	if previous := ms.AddVote(blockHash, msg); previous != nil {
+		t.Fatalf("Expected no conflicting vote, got %v", previous)
+	}
+	if previous := ms.AddVote(blockHash, msg); previous != nil {
+		t.Fatalf("Expected no conflicting vote for the same value, got %v", previous)
+	}
+	previous := ms.AddVote(common.Hash{}, msg)
+	if previous == nil || previous.Address != msg.Address {
+		t.Fatalf("Expected the previous vote to be returned, got %v", previous)
+	}
+	if got := ms.VotePower(common.Hash{}); got != 0 {
+		t.Fatalf("Expected conflicting vote to be discarded, got %v", got)
+	}
+}
@@ -17,6 +17,9 @@
 package core
 
 import (
+	"fmt"
+
+	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/metrics"
 )
 
@@ -26,4 +29,28 @@ var (
 	tendermintProposeTimer      = metrics.NewRegisteredTimer("tendermint/timer/propose", nil)
 	tendermintPrevoteTimer      = metrics.NewRegisteredTimer("tendermint/timer/prevote", nil)
 	tendermintPrecommitTimer    = metrics.NewRegisteredTimer("tendermint/timer/precommit", nil)
+	tendermintMisbehaviourMeter = metrics.NewRegisteredMeter("tendermint/misbehaviour", nil)
 )
+
+// validatorUptimeGauges returns the gauges of the liveness of a committee member
+// over the uptime window.
+func validatorUptimeGauges(address common.Address) (signed, missed metrics.Gauge, uptime metrics.GaugeFloat64, lastSeen metrics.Gauge) {
+	prefix := validatorUptimePrefix(address)
+	return metrics.GetOrRegisterGauge(prefix+"signed", nil),
+		metrics.GetOrRegisterGauge(prefix+"missed", nil),
+		metrics.GetOrRegisterGaugeFloat64(prefix+"ratio", nil),
+		metrics.GetOrRegisterGauge(prefix+"lastseen", nil)
+}
+
+// unregisterValidatorUptimeGauges removes the gauges of a member which is no
+// longer part of the committees of the uptime window.
+func unregisterValidatorUptimeGauges(address common.Address) {
+	prefix := validatorUptimePrefix(address)
+	for _, name := range []string{"signed", "missed", "ratio", "lastseen"} {
+		metrics.Unregister(prefix + name)
+	}
+}
+
+func validatorUptimePrefix(address common.Address) string {
+	return fmt.Sprintf("tendermint/uptime/%s/", address.Hex())
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+var (
+	// errEvidenceDifferentSender is returned when the two messages of an evidence are not from the same member.
+	errEvidenceDifferentSender = errors.New("evidence messages are from different senders")
+	// errEvidenceDifferentView is returned when the two messages of an evidence are not for the same height, round and step.
+	errEvidenceDifferentView = errors.New("evidence messages are for different height, round or step")
+	// errEvidenceSameValue is returned when the two messages of an evidence are not conflicting.
+	errEvidenceSameValue = errors.New("evidence messages are not conflicting")
+	// errEvidenceHeight is returned when the evidence is verified against a header which is not its parent.
+	errEvidenceHeight = errors.New("evidence height does not match the parent header")
+)
+
+// MisbehaviourEvidence is the proof that a committee member signed two conflicting
+// consensus messages for the same height, round and step. Only the two signed
+// payloads are encoded, every other field is derived from them when decoding so
+// that the evidence can always be verified on its own.
+type MisbehaviourEvidence struct {
+	Offender common.Address
+	Height   *big.Int
+	Round    int64
+	Code     uint64
+	Message1 hexutil.Bytes
+	Message2 hexutil.Bytes
+}
+
+// NewMisbehaviourEvidence builds the evidence out of two signed messages. It returns
+// an error if the messages are not equivocating. The messages are ordered by their
+// hash, so that an equivocation has the same evidence whatever the order it was
+// seen in.
+func NewMisbehaviourEvidence(payload1, payload2 []byte) (*MisbehaviourEvidence, error) {
+	m1, m2 := new(Message), new(Message)
+	if err := m1.FromPayload(payload1); err != nil {
+		return nil, err
+	}
+	if err := m2.FromPayload(payload2); err != nil {
+		return nil, err
+	}
+
+	if m1.Address != m2.Address {
+		return nil, errEvidenceDifferentSender
+	}
+
+	h1, _ := m1.Height()
+	h2, _ := m2.Height()
+	r1, _ := m1.Round()
+	r2, _ := m2.Round()
+	if m1.Code != m2.Code || h1.Cmp(h2) != 0 || r1 != r2 {
+		return nil, errEvidenceDifferentView
+	}
+
+	v1, err := msgValue(m1)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := msgValue(m2)
+	if err != nil {
+		return nil, err
+	}
+	if v1 == v2 {
+		return nil, errEvidenceSameValue
+	}
+	if hash1, hash2 := types.RLPHash(payload1), types.RLPHash(payload2); bytes.Compare(hash1[:], hash2[:]) > 0 {
+		payload1, payload2 = payload2, payload1
+	}
+
+	return &MisbehaviourEvidence{
+		Offender: m1.Address,
+		Height:   h1,
+		Round:    r1,
+		Code:     m1.Code,
+		Message1: payload1,
+		Message2: payload2,
+	}, nil
+}
+
+// Hash returns the identifier of the evidence.
+func (e *MisbehaviourEvidence) Hash() common.Hash {
+	return types.RLPHash(e)
+}
+
+// EncodeRLP serializes e into the Ethereum RLP format.
+func (e *MisbehaviourEvidence) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []interface{}{[]byte(e.Message1), []byte(e.Message2)})
+}
+
+// DecodeRLP implements rlp.Decoder, and rebuilds the evidence out of the two signed messages.
+func (e *MisbehaviourEvidence) DecodeRLP(s *rlp.Stream) error {
+	var evidence struct {
+		Message1 []byte
+		Message2 []byte
+	}
+	if err := s.Decode(&evidence); err != nil {
+		return err
+	}
+	decoded, err := NewMisbehaviourEvidence(evidence.Message1, evidence.Message2)
+	if err != nil {
+		return err
+	}
+	*e = *decoded
+	return nil
+}
+
+// VerifyMisbehaviourEvidence checks that both messages of the evidence are correctly
+// signed by a member of the committee stored in the parent header of the evidence height.
+func VerifyMisbehaviourEvidence(e *MisbehaviourEvidence, parent *types.Header) error {
+	if parent.Number.Uint64()+1 != e.Height.Uint64() {
+		return errEvidenceHeight
+	}
+	// Rebuilding the evidence ensures the messages are conflicting.
+	evidence, err := NewMisbehaviourEvidence(e.Message1, e.Message2)
+	if err != nil {
+		return err
+	}
+	for _, payload := range [][]byte{evidence.Message1, evidence.Message2} {
+		msg := new(Message)
+		if err := msg.FromPayload(payload); err != nil {
+			return err
+		}
+		if _, err := msg.Validate(crypto.CheckValidatorSignature, parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// msgValue returns the value a consensus message is voting or proposing for.
+func msgValue(m *Message) (common.Hash, error) {
+	switch m.Code {
+	case msgProposal:
+		var proposal Proposal
+		if err := m.Decode(&proposal); err != nil {
+			return common.Hash{}, err
+		}
+		return proposal.ProposalBlock.Hash(), nil
+	case msgPrevote, msgPrecommit:
+		var vote Vote
+		if err := m.Decode(&vote); err != nil {
+			return common.Hash{}, err
+		}
+		return vote.ProposedBlockHash, nil
+	default:
+		return common.Hash{}, errInvalidMessage
+	}
+}
+
+// checkProposalEquivocation reports the sender of msg if a different proposal signed
+// by the same member is already stored for the round.
+func (c *core) checkProposalEquivocation(roundMsgs *roundMessages, msg *Message) {
+	roundMsgs.mu.RLock()
+	previous := roundMsgs.proposalMsg
+	roundMsgs.mu.RUnlock()
+
+	if previous == nil || previous.Address != msg.Address || bytes.Equal(previous.Payload(), msg.Payload()) {
+		return
+	}
+	c.reportMisbehaviour(previous, msg)
+}
+
+// reportMisbehaviour packages two conflicting messages as evidence and hands it to the backend.
+func (c *core) reportMisbehaviour(previous, msg *Message) {
+	evidence, err := NewMisbehaviourEvidence(previous.Payload(), msg.Payload())
+	if err != nil {
+		c.logger.Debug("Conflicting messages are not a valid evidence", "err", err)
+		return
+	}
+
+	c.logger.Warn("Detected committee member misbehaviour",
+		"offender", evidence.Offender,
+		"height", evidence.Height,
+		"round", evidence.Round,
+		"code", evidence.Code,
+		"hash", evidence.Hash(),
+	)
+	tendermintMisbehaviourMeter.Mark(1)
+	c.backend.StoreMisbehaviourEvidence(evidence)
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rlp"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMisbehaviourEvidence(t *testing.T) {
+	committeeSet, keys := prepareCommittee(t, 4)
+	members := committeeSet.Committee()
+	offender := members[1].Address
+	height := big.NewInt(10)
+	hash1 := common.HexToHash("0x1")
+	hash2 := common.HexToHash("0x2")
+
+	t.Run("conflicting prevotes are a valid evidence", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrevote, 1, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrevote, 1, height, common.Hash{}, offender, keys[offender])
+
+		evidence, err := NewMisbehaviourEvidence(payload1, payload2)
+		require.NoError(t, err)
+		assert.Equal(t, offender, evidence.Offender)
+		assert.Equal(t, height, evidence.Height)
+		assert.Equal(t, int64(1), evidence.Round)
+		assert.Equal(t, msgPrevote, evidence.Code)
+	})
+
+	t.Run("conflicting proposals are a valid evidence", func(t *testing.T) {
+		_, _, payload1 := prepareProposal(t, 2, height, -1, generateBlock(height), offender, keys[offender])
+		_, _, payload2 := prepareProposal(t, 2, height, -1, generateBlock(big.NewInt(11)), offender, keys[offender])
+
+		evidence, err := NewMisbehaviourEvidence(payload1, payload2)
+		require.NoError(t, err)
+		assert.Equal(t, msgProposal, evidence.Code)
+	})
+
+	t.Run("same value is not an evidence", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrecommit, 1, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrecommit, 1, height, hash1, offender, keys[offender])
+
+		_, err := NewMisbehaviourEvidence(payload1, payload2)
+		assert.Equal(t, errEvidenceSameValue, err)
+	})
+
+	t.Run("different rounds are not an evidence", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrevote, 1, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrevote, 2, height, hash2, offender, keys[offender])
+
+		_, err := NewMisbehaviourEvidence(payload1, payload2)
+		assert.Equal(t, errEvidenceDifferentView, err)
+	})
+
+	t.Run("different steps are not an evidence", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrevote, 1, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrecommit, 1, height, hash2, offender, keys[offender])
+
+		_, err := NewMisbehaviourEvidence(payload1, payload2)
+		assert.Equal(t, errEvidenceDifferentView, err)
+	})
+
+	t.Run("different senders are not an evidence", func(t *testing.T) {
+		other := members[2].Address
+		_, _, payload1 := prepareVote(t, msgPrevote, 1, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrevote, 1, height, hash2, other, keys[other])
+
+		_, err := NewMisbehaviourEvidence(payload1, payload2)
+		assert.Equal(t, errEvidenceDifferentSender, err)
+	})
+
+	t.Run("evidence rlp encoding round trip", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrecommit, 3, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrecommit, 3, height, hash2, offender, keys[offender])
+		evidence, err := NewMisbehaviourEvidence(payload1, payload2)
+		require.NoError(t, err)
+
+		enc, err := rlp.EncodeToBytes(evidence)
+		require.NoError(t, err)
+		decoded := new(MisbehaviourEvidence)
+		require.NoError(t, rlp.DecodeBytes(enc, decoded))
+		assert.Equal(t, evidence, decoded)
+		assert.Equal(t, evidence.Hash(), decoded.Hash())
+	})
+
+	t.Run("evidence doesn't depend on the order of the messages", func(t *testing.T) {
+		_, _, payload1 := prepareVote(t, msgPrecommit, 3, height, hash1, offender, keys[offender])
+		_, _, payload2 := prepareVote(t, msgPrecommit, 3, height, hash2, offender, keys[offender])
+		evidence, err := NewMisbehaviourEvidence(payload1, payload2)
+		require.NoError(t, err)
+		reversed, err := NewMisbehaviourEvidence(payload2, payload1)
+		require.NoError(t, err)
+		assert.Equal(t, evidence, reversed)
+		assert.Equal(t, evidence.Hash(), reversed.Hash())
+	})
+}
+
+func TestVerifyMisbehaviourEvidence(t *testing.T) {
+	committeeSet, keys := prepareCommittee(t, 4)
+	offender := committeeSet.Committee()[0].Address
+	height := big.NewInt(5)
+
+	_, _, payload1 := prepareVote(t, msgPrevote, 0, height, common.HexToHash("0x1"), offender, keys[offender])
+	_, _, payload2 := prepareVote(t, msgPrevote, 0, height, common.HexToHash("0x2"), offender, keys[offender])
+	evidence, err := NewMisbehaviourEvidence(payload1, payload2)
+	require.NoError(t, err)
+
+	t.Run("offender is a member of the parent committee", func(t *testing.T) {
+		parent := &types.Header{Number: big.NewInt(4), Committee: committeeSet.Committee()}
+		assert.NoError(t, VerifyMisbehaviourEvidence(evidence, parent))
+	})
+
+	t.Run("offender is not a member of the parent committee", func(t *testing.T) {
+		other, _ := prepareCommittee(t, 4)
+		parent := &types.Header{Number: big.NewInt(4), Committee: other.Committee()}
+		assert.Error(t, VerifyMisbehaviourEvidence(evidence, parent))
+	})
+
+	t.Run("parent header is not at the evidence height", func(t *testing.T) {
+		parent := &types.Header{Number: big.NewInt(7), Committee: committeeSet.Committee()}
+		assert.Equal(t, errEvidenceHeight, VerifyMisbehaviourEvidence(evidence, parent))
+	})
+}
+
+func TestCore_ReportEquivocation(t *testing.T) {
+	committeeSet, keys := prepareCommittee(t, 4)
+	members := committeeSet.Committee()
+	clientAddr := members[0].Address
+	offender := members[1].Address
+	height := big.NewInt(3)
+	var round int64 = 1
+
+	t.Run("conflicting prevotes are reported", func(t *testing.T) {
+		prevote1, _, _ := prepareVote(t, msgPrevote, round, height, common.HexToHash("0x1"), offender, keys[offender])
+		prevote2, _, _ := prepareVote(t, msgPrevote, round, height, common.Hash{}, offender, keys[offender])
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().Return(clientAddr)
+		backendMock.EXPECT().StoreMisbehaviourEvidence(gomock.Any()).Do(func(evidence *MisbehaviourEvidence) {
+			assert.Equal(t, offender, evidence.Offender)
+			assert.ElementsMatch(t, [][]byte{prevote1.Payload(), prevote2.Payload()}, [][]byte{evidence.Message1, evidence.Message2})
+		})
+
+		c := New(backendMock, config.DefaultConfig())
+		c.setHeight(height)
+		c.setRound(round)
+		c.setCommitteeSet(committeeSet)
+
+		c.acceptVote(c.curRoundMessages, prevote, common.HexToHash("0x1"), *prevote1)
+		c.acceptVote(c.curRoundMessages, prevote, common.Hash{}, *prevote2)
+		assert.Equal(t, uint64(1), c.curRoundMessages.PrevotesTotalPower())
+	})
+
+	t.Run("duplicated precommit is not reported", func(t *testing.T) {
+		precommit1, _, _ := prepareVote(t, msgPrecommit, round, height, common.HexToHash("0x1"), offender, keys[offender])
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().Return(clientAddr)
+
+		c := New(backendMock, config.DefaultConfig())
+		c.setHeight(height)
+		c.setRound(round)
+		c.setCommitteeSet(committeeSet)
+
+		c.acceptVote(c.curRoundMessages, precommit, common.HexToHash("0x1"), *precommit1)
+		c.acceptVote(c.curRoundMessages, precommit, common.HexToHash("0x1"), *precommit1)
+	})
+
+	t.Run("conflicting old round proposal is reported", func(t *testing.T) {
+		proposer := members[0].Address
+		proposalMsg1, _, _ := prepareProposal(t, 0, height, -1, generateBlock(height), proposer, keys[proposer])
+		proposalMsg2, _, _ := prepareProposal(t, 0, height, -1, generateBlock(height), proposer, keys[proposer])
+		require.NoError(t, proposalMsg1.FromPayload(proposalMsg1.Payload()))
+		require.NoError(t, proposalMsg2.FromPayload(proposalMsg2.Payload()))
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().Return(clientAddr)
+		backendMock.EXPECT().StoreMisbehaviourEvidence(gomock.Any())
+
+		c := New(backendMock, config.DefaultConfig())
+		c.setHeight(height)
+		c.setRound(round)
+		c.setCommitteeSet(committeeSet)
+
+		var proposal Proposal
+		require.NoError(t, proposalMsg1.Decode(&proposal))
+		c.messages.getOrCreate(0).SetProposal(&proposal, proposalMsg1, false)
+
+		err := c.handleProposal(context.Background(), proposalMsg2)
+		assert.Equal(t, errOldRoundMessage, err)
+	})
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+const (
+	// DefaultMessageLogFileSize is the size in bytes above which a message log file is rotated.
+	DefaultMessageLogFileSize = 64 * 1024 * 1024
+	// DefaultMessageLogFiles is the number of message log files kept on disk.
+	DefaultMessageLogFiles = 8
+
+	messageLogPattern = "msg-%06d.log"
+	// messageLogQueue is the number of messages waiting to be written, the
+	// messages recorded beyond it are dropped.
+	messageLogQueue = 4096
+	// messageLogFlushInterval is the interval at which the written messages are
+	// flushed, the log being meant to be read while the network is stalled.
+	messageLogFlushInterval = time.Second
+)
+
+// errMessageLogFull is returned when recording a message while the queue of the
+// message log is full.
+var errMessageLogFull = errors.New("message log queue full")
+
+// LoggedMessage is a consensus message received or sent by the node, as recorded
+// in the message log.
+type LoggedMessage struct {
+	Time    uint64         // Arrival or sending time in unix nanoseconds
+	Sent    bool           // Whether the message was broadcast by the node
+	Peer    common.Address // Peer the message was received from, the node itself if sent
+	Payload []byte         // Signed consensus message
+}
+
+// Timestamp returns the time the message was recorded at.
+func (m *LoggedMessage) Timestamp() time.Time {
+	return time.Unix(0, int64(m.Time))
+}
+
+// MessageLog records the consensus messages of the node in rotating files of a
+// directory, for an offline analysis of the consensus rounds. The messages are
+// written in the background so that recording them does not hold the consensus.
+type MessageLog struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+
+	queue     chan []byte // encoded messages
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	// owned by the writing loop once the log is opened
+	file  *os.File
+	buf   *bufio.Writer
+	size  int64
+	index int
+}
+
+// OpenMessageLog opens the message log stored in dir, a new file is started after
+// the existing ones. A file is rotated once it exceeds maxSize bytes and only the
+// maxFiles most recent files are kept.
+func OpenMessageLog(dir string, maxSize int64, maxFiles int) (*MessageLog, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMessageLogFileSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMessageLogFiles
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	indexes, err := messageLogFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &MessageLog{
+		dir:      dir,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		queue:    make(chan []byte, messageLogQueue),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if len(indexes) > 0 {
+		l.index = indexes[len(indexes)-1]
+	}
+	if err := l.rotate(); err != nil {
+		return nil, err
+	}
+	go l.loop()
+	return l, nil
+}
+
+// Record queues a message to be appended to the log. The message is dropped if
+// the queue is full. A nil log records nothing.
+func (l *MessageLog) Record(sent bool, peer common.Address, payload []byte) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case <-l.quit:
+		return os.ErrClosed
+	default:
+	}
+	// The message is encoded right away, the caller may reuse payload.
+	entry, err := rlp.EncodeToBytes(&LoggedMessage{
+		Time:    uint64(time.Now().UnixNano()),
+		Sent:    sent,
+		Peer:    peer,
+		Payload: payload,
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case l.queue <- entry:
+		return nil
+	case <-l.quit:
+		return os.ErrClosed
+	default:
+		return errMessageLogFull
+	}
+}
+
+// Close writes the queued messages and closes the current file of the log.
+func (l *MessageLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.closeOnce.Do(func() { close(l.quit) })
+	<-l.done
+	return l.closeErr
+}
+
+// loop writes the queued messages until the log is closed. The written messages
+// are flushed at every interval rather than one by one.
+func (l *MessageLog) loop() {
+	defer close(l.done)
+	flush := time.NewTicker(messageLogFlushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case entry := <-l.queue:
+			l.report(l.write(entry))
+		case <-flush.C:
+			if l.file != nil {
+				l.report(l.buf.Flush())
+			}
+		case <-l.quit:
+			// the loop being the only receiver, the queued messages are read without blocking.
+			for len(l.queue) > 0 {
+				l.report(l.write(<-l.queue))
+			}
+			l.closeErr = l.closeFile()
+			return
+		}
+	}
+}
+
+// report logs the failure to write the log.
+func (l *MessageLog) report(err error) {
+	if err != nil {
+		log.Warn("Failed to write the consensus message log", "dir", l.dir, "err", err)
+	}
+}
+
+// write appends an encoded message to the current file of the log, rotating it
+// if full.
+func (l *MessageLog) write(entry []byte) error {
+	if l.file == nil {
+		return os.ErrClosed
+	}
+	if l.size+int64(len(entry)) > l.maxSize && l.size > 0 {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.buf.Write(entry)
+	l.size += int64(n)
+	return err
+}
+
+func (l *MessageLog) closeFile() error {
+	if l.file == nil {
+		return nil
+	}
+	err := l.buf.Flush()
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file, l.buf = nil, nil
+	return err
+}
+
+// rotate starts the next file of the log and deletes the files beyond maxFiles.
+func (l *MessageLog) rotate() error {
+	if err := l.closeFile(); err != nil {
+		return err
+	}
+	l.index++
+	file, err := os.OpenFile(filepath.Join(l.dir, fmt.Sprintf(messageLogPattern, l.index)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	l.file, l.buf, l.size = file, bufio.NewWriter(file), 0
+
+	indexes, err := messageLogFiles(l.dir)
+	if err != nil {
+		return err
+	}
+	for len(indexes) > l.maxFiles {
+		if err := os.Remove(filepath.Join(l.dir, fmt.Sprintf(messageLogPattern, indexes[0]))); err != nil {
+			return err
+		}
+		indexes = indexes[1:]
+	}
+	return nil
+}
+
+// messageLogFiles returns the indexes of the message log files of dir in ascending order.
+func messageLogFiles(dir string) ([]int, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []int
+	for _, entry := range entries {
+		var index int
+		if n, err := fmt.Sscanf(entry.Name(), messageLogPattern, &index); err == nil && n == 1 && !entry.IsDir() {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// ReadMessageLog returns the messages recorded in the log stored in dir, in the
+// order they were recorded. An entry truncated by a crash ends its file.
+func ReadMessageLog(dir string) ([]*LoggedMessage, error) {
+	indexes, err := messageLogFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var messages []*LoggedMessage
+	for _, index := range indexes {
+		file, err := os.Open(filepath.Join(dir, fmt.Sprintf(messageLogPattern, index)))
+		if err != nil {
+			return nil, err
+		}
+		stream := rlp.NewStream(bufio.NewReader(file), 0)
+		for {
+			msg := new(LoggedMessage)
+			if err := stream.Decode(msg); err != nil {
+				if err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+					file.Close()
+					return nil, fmt.Errorf("%s: %v", file.Name(), err)
+				}
+				break
+			}
+			messages = append(messages, msg)
+		}
+		file.Close()
+	}
+	return messages, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+)
+
+func TestMessageLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msglog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	peer := common.HexToAddress("0x01")
+	payload := make([]byte, 100)
+
+	t.Run("messages are read back in order", func(t *testing.T) {
+		l, err := OpenMessageLog(dir, 0, 0)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			payload[0] = byte(i)
+			require.NoError(t, l.Record(i == 1, peer, payload))
+		}
+		require.NoError(t, l.Close())
+		assert.Equal(t, os.ErrClosed, l.Record(false, peer, payload))
+
+		messages, err := ReadMessageLog(dir)
+		require.NoError(t, err)
+		require.Len(t, messages, 3)
+		for i, msg := range messages {
+			assert.Equal(t, byte(i), msg.Payload[0])
+			assert.Equal(t, i == 1, msg.Sent)
+			assert.Equal(t, peer, msg.Peer)
+			assert.NotZero(t, msg.Time)
+		}
+	})
+
+	t.Run("messages are flushed while the log is open", func(t *testing.T) {
+		sub := filepath.Join(dir, "open")
+		l, err := OpenMessageLog(sub, 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, l.Record(false, peer, payload))
+		assert.Eventually(t, func() bool {
+			messages, err := ReadMessageLog(sub)
+			return err == nil && len(messages) == 1
+		}, 5*messageLogFlushInterval, messageLogFlushInterval/10)
+		require.NoError(t, l.Close())
+		require.NoError(t, l.Close())
+	})
+
+	t.Run("files are rotated and the oldest ones deleted", func(t *testing.T) {
+		l, err := OpenMessageLog(dir, 300, 3)
+		require.NoError(t, err)
+		for i := 3; i < 13; i++ {
+			payload[0] = byte(i)
+			require.NoError(t, l.Record(false, peer, payload))
+		}
+		require.NoError(t, l.Close())
+
+		indexes, err := messageLogFiles(dir)
+		require.NoError(t, err)
+		// Two messages fit in a file.
+		assert.Equal(t, []int{4, 5, 6}, indexes)
+
+		messages, err := ReadMessageLog(dir)
+		require.NoError(t, err)
+		require.Len(t, messages, 6)
+		for i, msg := range messages {
+			assert.Equal(t, byte(i+7), msg.Payload[0])
+		}
+	})
+
+	t.Run("a truncated entry ends its file", func(t *testing.T) {
+		name := filepath.Join(dir, "msg-000005.log")
+		data, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(name, data[:len(data)-10], 0600))
+
+		messages, err := ReadMessageLog(dir)
+		require.NoError(t, err)
+		require.Len(t, messages, 5)
+		assert.Equal(t, byte(7), messages[0].Payload[0])
+		assert.Equal(t, byte(9), messages[2].Payload[0])
+	})
+}
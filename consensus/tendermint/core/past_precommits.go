@@ -0,0 +1,87 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// pastPrecommits are the precommits of the last committed height, including those
+// received after its block was committed. Their committed seals are carried by the
+// block of the next height as the past committed seals of its parent, which then
+// record every committee member that committed it and not only the first quorum.
+// The zero value holds no precommits.
+type pastPrecommits struct {
+	header   *types.Header // parent of the blocks of the height, nil if the precommits are unknown
+	messages messagesMap
+	mu       sync.RWMutex
+}
+
+// reset keeps the precommits of messages, received for the children of header.
+// messages is left empty. The precommits are dropped if header is nil.
+func (p *pastPrecommits) reset(header *types.Header, messages *messagesMap) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.header = header
+	p.messages = newMessagesMap()
+	if header != nil {
+		messages.moveTo(&p.messages)
+	}
+}
+
+// add stores msg, a precommit received late for vote, once validated against
+// the committee of the parent of the last committed height.
+func (p *pastPrecommits) add(msg *Message, vote *Vote) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.header == nil || p.header.Number.Uint64()+1 != vote.Height.Uint64() {
+		return errOldHeightMessage
+	}
+	if _, err := msg.Validate(crypto.CheckValidatorSignature, p.header); err != nil {
+		return err
+	}
+	if len(msg.CommittedSeal) < types.BFTExtraSeal {
+		return errInvalidSenderOfCommittedSeal
+	}
+	seal := PrepareCommittedSeal(vote.ProposedBlockHash, vote.Round, vote.Height)
+	signer, err := types.GetSignatureAddress(seal, msg.CommittedSeal[:types.BFTExtraSeal])
+	if err != nil || signer != msg.Address {
+		return errInvalidSenderOfCommittedSeal
+	}
+	p.messages.getOrCreate(vote.Round).AddPrecommit(vote.ProposedBlockHash, *msg)
+	return nil
+}
+
+// committedSeals returns the committed seals of the precommits for the block of
+// hash at round, which are validated but for their BLS part.
+func (p *pastPrecommits) committedSeals(hash common.Hash, round int64) [][]byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.header == nil {
+		return nil
+	}
+	var seals [][]byte
+	for _, msg := range p.messages.getOrCreate(round).CommitedSeals(hash) {
+		seals = append(seals, common.CopyBytes(msg.CommittedSeal))
+	}
+	return seals
+}
+
+// PastCommittedSeals implements Tendermint.PastCommittedSeals.
+func (c *core) PastCommittedSeals(hash common.Hash, round int64) [][]byte {
+	return c.pastPrecommits.committedSeals(hash, round)
+}
+
+// handlePastPrecommit stores a precommit of the last committed height, which is
+// never gossiped.
+func (c *core) handlePastPrecommit(msg *Message) {
+	var vote Vote
+	if err := msg.Decode(&vote); err != nil {
+		return
+	}
+	if err := c.pastPrecommits.add(msg, &vote); err != nil {
+		c.logger.Debug("Dropped precommit of the last height", "from", msg.Address, "err", err)
+	}
+}
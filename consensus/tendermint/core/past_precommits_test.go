@@ -0,0 +1,89 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPastPrecommits(t *testing.T) {
+	committeeMembers, keys := generateCommittee(4)
+	parent := &types.Header{Number: big.NewInt(9), Committee: committeeMembers}
+	height := big.NewInt(10)
+	hash := common.HexToHash("0x0b")
+
+	precommit := func(member types.CommitteeMember, round int64, height *big.Int) (*Message, *Vote) {
+		msg, _, _ := prepareVote(t, msgPrecommit, round, height, hash, member.Address, keys[member.Address])
+		var vote Vote
+		require.NoError(t, msg.Decode(&vote))
+		return msg, &vote
+	}
+
+	t.Run("precommits are kept when the height is committed", func(t *testing.T) {
+		messages := newMessagesMap()
+		msg, _ := precommit(committeeMembers[0], 1, height)
+		messages.getOrCreate(1).AddPrecommit(hash, *msg)
+
+		p := new(pastPrecommits)
+		p.reset(parent, &messages)
+
+		assert.Equal(t, [][]byte{msg.CommittedSeal}, p.committedSeals(hash, 1))
+		assert.Empty(t, p.committedSeals(hash, 0))
+		assert.Empty(t, messages.getRounds())
+	})
+
+	t.Run("precommits received late are added", func(t *testing.T) {
+		messages := newMessagesMap()
+		p := new(pastPrecommits)
+		p.reset(parent, &messages)
+
+		for _, member := range committeeMembers {
+			msg, vote := precommit(member, 0, height)
+			require.NoError(t, p.add(msg, vote))
+		}
+		assert.Len(t, p.committedSeals(hash, 0), len(committeeMembers))
+	})
+
+	t.Run("precommits of another height are rejected", func(t *testing.T) {
+		messages := newMessagesMap()
+		p := new(pastPrecommits)
+		p.reset(parent, &messages)
+
+		msg, vote := precommit(committeeMembers[0], 0, big.NewInt(11))
+		assert.Equal(t, errOldHeightMessage, p.add(msg, vote))
+		assert.Empty(t, p.committedSeals(hash, 0))
+	})
+
+	t.Run("precommits are rejected when the parent is unknown", func(t *testing.T) {
+		messages := newMessagesMap()
+		p := new(pastPrecommits)
+		p.reset(nil, &messages)
+
+		msg, vote := precommit(committeeMembers[0], 0, height)
+		assert.Equal(t, errOldHeightMessage, p.add(msg, vote))
+		assert.Nil(t, p.committedSeals(hash, 0))
+	})
+
+	t.Run("committed seal of another member is rejected", func(t *testing.T) {
+		messages := newMessagesMap()
+		p := new(pastPrecommits)
+		p.reset(parent, &messages)
+
+		msg, vote := precommit(committeeMembers[0], 0, height)
+		other, _ := precommit(committeeMembers[1], 0, height)
+		msg.CommittedSeal = other.CommittedSeal
+		msg.Signature, _ = sign(mustPayloadNoSig(t, msg), keys[committeeMembers[0].Address])
+		assert.Equal(t, errInvalidSenderOfCommittedSeal, p.add(msg, vote))
+		assert.Empty(t, p.committedSeals(hash, 0))
+	})
+}
+
+func mustPayloadNoSig(t *testing.T, msg *Message) []byte {
+	payload, err := msg.PayloadNoSig()
+	require.NoError(t, err)
+	return payload
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto/bls"
 )
 
 func (c *core) sendPrecommit(ctx context.Context, isNil bool) {
@@ -64,6 +65,14 @@ func (c *core) sendPrecommit(ctx context.Context, isNil bool) {
 	if err != nil {
 		c.logger.Error("core.sendPrecommit error while signing committed seal", "err", err)
 	}
+	// The BLS committed seal is appended to the secp256k1 one.
+	if sealer, ok := c.backend.(AggregatedSealer); ok && err == nil {
+		blsSeal, err := sealer.SignAggregatedSeal(seal)
+		if err != nil {
+			c.logger.Error("core.sendPrecommit error while signing bls committed seal", "err", err)
+		}
+		msg.CommittedSeal = append(msg.CommittedSeal, blsSeal...)
+	}
 
 	c.sentPrecommit = true
 	c.broadcast(ctx, msg)
@@ -137,6 +146,10 @@ func (c *core) handlePrecommit(ctx context.Context, msg *Message) error {
 func (c *core) verifyCommittedSeal(addressMsg common.Address, committedSealMsg []byte, proposedBlockHash common.Hash, round int64, height *big.Int) error {
 	committedSeal := PrepareCommittedSeal(proposedBlockHash, round, height)
 
+	var blsSeal []byte
+	if len(committedSealMsg) > types.BFTExtraSeal {
+		committedSealMsg, blsSeal = committedSealMsg[:types.BFTExtraSeal], committedSealMsg[types.BFTExtraSeal:]
+	}
 	sealerAddress, err := types.GetSignatureAddress(committedSeal, committedSealMsg)
 	if err != nil {
 		c.logger.Error("Failed to get signer address", "err", err)
@@ -150,6 +163,27 @@ func (c *core) verifyCommittedSeal(addressMsg common.Address, committedSealMsg [
 		return errInvalidSenderOfCommittedSeal
 	}
 
+	if blsSeal != nil {
+		return c.verifyBLSCommittedSeal(addressMsg, committedSeal, blsSeal)
+	}
+	return nil
+}
+
+// verifyBLSCommittedSeal checks the BLS committed seal appended to the secp256k1
+// one against the key registered by the sender for the current committee.
+func (c *core) verifyBLSCommittedSeal(addressMsg common.Address, committedSeal []byte, blsSeal []byte) error {
+	_, member, err := c.committeeSet().GetByAddress(addressMsg)
+	if err != nil {
+		return err
+	}
+	key, err := bls.PublicKeyFromBytes(member.BLSKey)
+	if err != nil {
+		return errInvalidBLSCommittedSeal
+	}
+	sig, err := bls.SignatureFromBytes(blsSeal)
+	if err != nil || !bls.Verify(key, committedSeal, sig) {
+		return errInvalidBLSCommittedSeal
+	}
 	return nil
 }
 
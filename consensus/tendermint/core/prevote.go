@@ -111,15 +111,24 @@ func (c *core) handlePrevote(ctx context.Context, msg *Message) error {
 			}
 			c.logger.Debug("Stopped Scheduled Prevote Timeout")
 
-			if c.step == prevote {
+			lock := c.step == prevote
+			if lock {
 				c.lockedValue = c.curRoundMessages.Proposal().ProposalBlock
 				c.lockedRound = c.Round()
-				c.sendPrecommit(ctx, false)
-				c.setStep(precommit)
 			}
 			c.validValue = c.curRoundMessages.Proposal().ProposalBlock
 			c.validRound = c.Round()
 			c.setValidRoundAndValue = true
+
+			// The lock must be persisted before the precommit is sent
+			if err := c.recordLockState(); err != nil {
+				c.logger.Error("Failed to write lock state to the wal", "err", err)
+				return err
+			}
+			if lock {
+				c.sendPrecommit(ctx, false)
+				c.setStep(precommit)
+			}
 			// Line 44 in Algorithm 1 of The latest gossip on BFT consensus
 		} else if c.step == prevote && c.curRoundMessages.PrevotesPower(common.Hash{}) >= c.committeeSet().Quorum() {
 			if err := c.prevoteTimeout.stopTimer(); err != nil {
@@ -18,7 +18,6 @@ package core
 
 import (
 	"context"
-	"time"
 
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus"
@@ -71,7 +70,8 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 			// if we already have a proposal then it must be different than the current one
 			// it can't happen unless someone's byzantine.
 			if roundMsgs.proposal != nil {
-				return err // do not gossip, TODO: accountability
+				c.checkProposalEquivocation(roundMsgs, msg)
+				return err // do not gossip
 			}
 
 			if !c.isProposerMsg(proposal.Round, msg.Address) {
@@ -99,6 +99,9 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 		return errNotFromProposer
 	}
 
+	// Report the proposer if it already sent a different proposal for this round
+	c.checkProposalEquivocation(c.curRoundMessages, msg)
+
 	// Verify the proposal we received
 	if duration, err := c.backend.VerifyProposal(*proposal.ProposalBlock); err != nil {
 
@@ -109,7 +112,7 @@ func (c *core) handleProposal(ctx context.Context, msg *Message) error {
 		// TODO: implement wiggle time / median time
 		if err == consensus.ErrFutureBlock {
 			c.stopFutureProposalTimer()
-			c.futureProposalTimer = time.AfterFunc(duration, func() {
+			c.futureProposalTimer = afterFunc(c.clock, duration, func() {
 				c.sendEvent(backlogEvent{
 					msg: msg,
 				})
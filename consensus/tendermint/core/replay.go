@@ -0,0 +1,531 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/bft"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	ethcore "github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/event"
+)
+
+// errReplaySign is returned by the replay backend, the messages of the node are
+// taken from the log instead of being signed again.
+var errReplaySign = errors.New("no signing during a replay")
+
+const (
+	// Causes of the round changes of a replay.
+	RoundChangeStart   = "start"      // The replay started at the height of the first message
+	RoundChangeSync    = "sync"       // The node moved to a height it did not commit in the log
+	RoundChangeCommit  = "commit"     // The node committed the previous height
+	RoundChangeTimeout = "timeout"    // The node moved to the round after its precommit timeout
+	RoundChangeSkip    = "round skip" // The node received F+1 voting power of a future round
+)
+
+// maxReplayPasses bounds the handling of the future messages after each message of the log.
+const maxReplayPasses = 100
+
+// ReplayChain gives access to the blocks the logged messages are built upon. If it
+// is an *ethcore.BlockChain the proposers are elected as by the node.
+type ReplayChain interface {
+	GetBlockByNumber(number uint64) *types.Block
+}
+
+// ReplayReport is the analysis of a message log replayed through a headless core.
+type ReplayReport struct {
+	Node         common.Address // Node which recorded the log, zero if it didn't send any message
+	Messages     int            // Distinct consensus messages of the log
+	Invalid      int            // Messages which could not be decoded or verified
+	RoundChanges []*RoundChange
+	Heights      []*HeightReport
+}
+
+// RoundChange is a new round entered by the replayed node.
+type RoundChange struct {
+	Height uint64
+	Round  int64
+	Time   time.Time
+	Cause  string
+}
+
+// HeightReport holds the rounds of a height as seen by the node.
+type HeightReport struct {
+	Height    uint64
+	Committee types.Committee
+	Decision  *Decision // Nil if the height was not committed in the log
+	Rounds    []*RoundReport
+}
+
+// Decision is the block committed by the replayed node.
+type Decision struct {
+	Round int64
+	Hash  common.Hash
+	Time  time.Time
+}
+
+// RoundReport holds the proposals and votes of a round.
+type RoundReport struct {
+	Round      int64
+	Start      time.Time // Time the node entered the round, zero if it never did
+	End        time.Time // Time the node left the round, zero if it never did
+	Proposals  []*ProposalRecord
+	Prevotes   *StepReport
+	Precommits *StepReport
+}
+
+// ProposalRecord is a proposal received or sent by the node.
+type ProposalRecord struct {
+	Proposer   common.Address
+	Hash       common.Hash
+	ValidRound int64
+	Arrival    time.Time
+}
+
+// StepReport tallies the prevotes or precommits of a round.
+type StepReport struct {
+	Tally   []*VoteTally     // Voting power per value, nil votes have an empty hash
+	Quorum  time.Time        // Time a quorum for a single value was reached, zero if never
+	Missing []common.Address // Committee members without a vote in the log
+	Late    []*LateVote      // Votes which arrived after the node left the round
+}
+
+// VoteTally is the voting power received for a value.
+type VoteTally struct {
+	Hash   common.Hash
+	Power  uint64
+	Voters []common.Address
+}
+
+// LateVote is a vote received after the node left the round.
+type LateVote struct {
+	Validator common.Address
+	Delay     time.Duration
+}
+
+// replayMessage is a verified message of the log.
+type replayMessage struct {
+	msg       *Message
+	sent      bool
+	arrival   time.Time
+	height    uint64
+	round     int64
+	committee types.Committee
+}
+
+// Replay replays the messages of a log through a headless core, reproducing the
+// rounds of the node that recorded it. The messages of the node are handled from
+// the log as no key is available: a round change is attributed to a timeout when
+// the node sent a message for a later round before receiving enough messages to
+// skip to it. Proposals are not executed and are accepted as valid.
+func Replay(chain ReplayChain, cfg *config.Config, entries []*LoggedMessage) *ReplayReport {
+	report := new(ReplayReport)
+	messages := verifyLoggedMessages(chain, entries, report)
+	if len(messages) == 0 {
+		return report
+	}
+	for _, m := range messages {
+		if m.sent {
+			report.Node = m.msg.Address
+			break
+		}
+	}
+
+	r := newReplayer(chain, cfg, report)
+	for _, m := range messages {
+		r.replay(m)
+	}
+	r.core.proposeTimeout.reset(propose)
+	r.core.prevoteTimeout.reset(prevote)
+	r.core.precommitTimeout.reset(precommit)
+
+	tallyRounds(report, messages)
+	return report
+}
+
+// verifyLoggedMessages decodes the messages of the log and verifies them against
+// the committee of their height. The duplicates are dropped, keeping the first arrival.
+func verifyLoggedMessages(chain ReplayChain, entries []*LoggedMessage, report *ReplayReport) []*replayMessage {
+	seen := make(map[common.Hash]bool)
+	parents := make(map[uint64]*types.Header)
+	var messages []*replayMessage
+	for _, entry := range entries {
+		hash := types.RLPHash(entry.Payload)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		report.Messages++
+
+		msg := new(Message)
+		if err := msg.FromPayload(entry.Payload); err != nil {
+			report.Invalid++
+			continue
+		}
+		height, err := msg.Height()
+		if err != nil || height.Sign() <= 0 {
+			report.Invalid++
+			continue
+		}
+		round, err := msg.Round()
+		if err != nil {
+			report.Invalid++
+			continue
+		}
+		parent, ok := parents[height.Uint64()-1]
+		if !ok {
+			if block := chain.GetBlockByNumber(height.Uint64() - 1); block != nil {
+				parent = block.Header()
+			}
+			parents[height.Uint64()-1] = parent
+		}
+		if parent == nil {
+			report.Invalid++
+			continue
+		}
+		if _, err := msg.Validate(crypto.CheckValidatorSignature, parent); err != nil {
+			report.Invalid++
+			continue
+		}
+		messages = append(messages, &replayMessage{
+			msg:       msg,
+			sent:      entry.Sent,
+			arrival:   entry.Timestamp(),
+			height:    height.Uint64(),
+			round:     round,
+			committee: parent.Committee,
+		})
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].arrival.Before(messages[j].arrival)
+	})
+	return messages
+}
+
+// replayView is the position of the core in the consensus.
+type replayView struct {
+	height uint64
+	round  int64
+	step   Step
+}
+
+// replayer drives a headless core with the messages of a log.
+type replayer struct {
+	ctx     context.Context
+	chain   ReplayChain
+	backend *replayBackend
+	core    *core
+	report  *ReplayReport
+	heights map[uint64]*HeightReport
+	started bool
+	pending []*Message
+}
+
+func newReplayer(chain ReplayChain, cfg *config.Config, report *ReplayReport) *replayer {
+	backend := &replayBackend{address: report.Node}
+	if bc, ok := chain.(*ethcore.BlockChain); ok {
+		backend.blockchain = bc
+	}
+	c := New(backend, cfg)
+	if backend.blockchain != nil {
+		c.autonityContract = backend.blockchain.GetAutonityContract()
+	}
+	return &replayer{
+		ctx:     context.Background(),
+		chain:   chain,
+		backend: backend,
+		core:    c,
+		report:  report,
+		heights: make(map[uint64]*HeightReport),
+	}
+}
+
+func (r *replayer) view() replayView {
+	return replayView{height: r.core.Height().Uint64(), round: r.core.Round(), step: r.core.step}
+}
+
+// replay handles a message of the log, after moving to the height or round the
+// node was at when it sent it.
+func (r *replayer) replay(m *replayMessage) {
+	switch {
+	case !r.started:
+		if !r.startHeight(m.height, m.arrival, RoundChangeStart) {
+			return
+		}
+	case m.sent && m.height > r.core.Height().Uint64():
+		if !r.startHeight(m.height, m.arrival, RoundChangeSync) {
+			return
+		}
+	}
+	if m.sent && m.height == r.core.Height().Uint64() && m.round > r.core.Round() {
+		r.core.startRound(r.ctx, m.round)
+		r.observe(m.arrival, RoundChangeTimeout)
+	}
+	r.handle(m.msg, m.arrival)
+	r.processPending(m.arrival)
+}
+
+// startHeight moves the core to the given height, built upon the block of the chain.
+func (r *replayer) startHeight(height uint64, at time.Time, cause string) bool {
+	parent := r.chain.GetBlockByNumber(height - 1)
+	if parent == nil {
+		return false
+	}
+	r.backend.last = parent
+	r.startRound0(at, cause)
+	r.started = true
+	return true
+}
+
+// startRound0 starts the height following the last committed block. The block
+// proposed by the node is the one of the chain if known, its proposal being
+// taken from the log anyway.
+func (r *replayer) startRound0(at time.Time, cause string) {
+	height := r.backend.last.NumberU64() + 1
+	block := r.chain.GetBlockByNumber(height)
+	if block == nil {
+		block = types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), ParentHash: r.backend.last.Hash()})
+	}
+	r.core.pendingUnminedBlocksMu.Lock()
+	r.core.pendingUnminedBlocks = map[uint64]*types.Block{height: block}
+	r.core.pendingUnminedBlocksMu.Unlock()
+
+	r.core.startRound(r.ctx, 0)
+	r.observe(at, cause)
+}
+
+// handle delivers a message to the core, a future message is kept until the core
+// reaches its height, round or step.
+func (r *replayer) handle(msg *Message, at time.Time) {
+	switch err := r.core.handleMsg(r.ctx, msg); err {
+	case errFutureHeightMessage, errFutureRoundMessage, errFutureStepMessage:
+		r.pending = append(r.pending, msg)
+	}
+	r.observe(at, RoundChangeSkip)
+	if block := r.backend.committed; block != nil {
+		r.backend.committed = nil
+		r.height(block.NumberU64()).Decision = &Decision{Round: r.backend.committedRound, Hash: block.Hash(), Time: at}
+		if known := r.chain.GetBlockByNumber(block.NumberU64()); known != nil && known.Hash() == block.Hash() {
+			block = known
+		}
+		r.backend.last = block
+		r.startRound0(at, RoundChangeCommit)
+	}
+}
+
+// processPending handles again the future messages until the core stops moving,
+// as done by the backlog of a running core.
+func (r *replayer) processPending(at time.Time) {
+	for pass, changed := 0, true; changed && pass < maxReplayPasses; pass++ {
+		changed = false
+		pending := r.pending
+		r.pending = nil
+		for _, msg := range pending {
+			before := r.view()
+			r.handle(msg, at)
+			if r.view() != before {
+				changed = true
+			}
+		}
+	}
+}
+
+// observe records the round entered by the core since the previous observation.
+func (r *replayer) observe(at time.Time, cause string) {
+	height, round := r.core.Height().Uint64(), r.core.Round()
+	changes := r.report.RoundChanges
+	if n := len(changes); n > 0 && changes[n-1].Height == height && changes[n-1].Round == round {
+		return
+	}
+	if n := len(changes); n > 0 {
+		if previous := r.round(changes[n-1].Height, changes[n-1].Round); previous.End.IsZero() {
+			previous.End = at
+		}
+	}
+	r.report.RoundChanges = append(r.report.RoundChanges, &RoundChange{Height: height, Round: round, Time: at, Cause: cause})
+	r.round(height, round).Start = at
+}
+
+func (r *replayer) height(height uint64) *HeightReport {
+	return reportHeight(r.report, r.heights, height)
+}
+
+func (r *replayer) round(height uint64, round int64) *RoundReport {
+	return reportRound(r.height(height), round)
+}
+
+func reportHeight(report *ReplayReport, heights map[uint64]*HeightReport, height uint64) *HeightReport {
+	h, ok := heights[height]
+	if !ok {
+		h = &HeightReport{Height: height}
+		heights[height] = h
+		report.Heights = append(report.Heights, h)
+		sort.Slice(report.Heights, func(i, j int) bool { return report.Heights[i].Height < report.Heights[j].Height })
+	}
+	return h
+}
+
+func reportRound(h *HeightReport, round int64) *RoundReport {
+	for _, rr := range h.Rounds {
+		if rr.Round == round {
+			return rr
+		}
+	}
+	rr := &RoundReport{Round: round, Prevotes: new(StepReport), Precommits: new(StepReport)}
+	h.Rounds = append(h.Rounds, rr)
+	sort.Slice(h.Rounds, func(i, j int) bool { return h.Rounds[i].Round < h.Rounds[j].Round })
+	return rr
+}
+
+// tallyRounds fills the proposals and votes of the rounds of the report, the first
+// message of a validator for a step is the one counted.
+func tallyRounds(report *ReplayReport, messages []*replayMessage) {
+	heights := make(map[uint64]*HeightReport)
+	for _, h := range report.Heights {
+		heights[h.Height] = h
+	}
+	type stepKey struct {
+		height uint64
+		round  int64
+		code   uint64
+	}
+	voted := make(map[stepKey]map[common.Address]bool)
+	for _, m := range messages {
+		h := reportHeight(report, heights, m.height)
+		if len(h.Committee) == 0 {
+			h.Committee = m.committee
+		}
+		rr := reportRound(h, m.round)
+		if m.msg.Code == msgProposal {
+			var proposal Proposal
+			if err := m.msg.Decode(&proposal); err == nil {
+				rr.Proposals = append(rr.Proposals, &ProposalRecord{
+					Proposer:   m.msg.Address,
+					Hash:       proposal.ProposalBlock.Hash(),
+					ValidRound: proposal.ValidRound,
+					Arrival:    m.arrival,
+				})
+			}
+			continue
+		}
+		var vote Vote
+		if err := m.msg.Decode(&vote); err != nil {
+			continue
+		}
+		key := stepKey{m.height, m.round, m.msg.Code}
+		if voted[key] == nil {
+			voted[key] = make(map[common.Address]bool)
+		}
+		if voted[key][m.msg.Address] {
+			continue
+		}
+		voted[key][m.msg.Address] = true
+
+		step := rr.Prevotes
+		if m.msg.Code == msgPrecommit {
+			step = rr.Precommits
+		}
+		step.add(vote.ProposedBlockHash, m.msg.Address, m.msg.power)
+		if len(h.Committee) > 0 && step.Quorum.IsZero() && step.power(vote.ProposedBlockHash) >= bft.Quorum(h.Committee.TotalVotingPower()) {
+			step.Quorum = m.arrival
+		}
+		if !rr.End.IsZero() && m.arrival.After(rr.End) {
+			step.Late = append(step.Late, &LateVote{Validator: m.msg.Address, Delay: m.arrival.Sub(rr.End)})
+		}
+	}
+
+	for _, h := range report.Heights {
+		for _, rr := range h.Rounds {
+			for code, step := range map[uint64]*StepReport{msgPrevote: rr.Prevotes, msgPrecommit: rr.Precommits} {
+				for _, member := range h.Committee {
+					if !voted[stepKey{h.Height, rr.Round, code}][member.Address] {
+						step.Missing = append(step.Missing, member.Address)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *StepReport) add(hash common.Hash, voter common.Address, power uint64) {
+	for _, t := range s.Tally {
+		if t.Hash == hash {
+			t.Power += power
+			t.Voters = append(t.Voters, voter)
+			return
+		}
+	}
+	s.Tally = append(s.Tally, &VoteTally{Hash: hash, Power: power, Voters: []common.Address{voter}})
+}
+
+func (s *StepReport) power(hash common.Hash) uint64 {
+	for _, t := range s.Tally {
+		if t.Hash == hash {
+			return t.Power
+		}
+	}
+	return 0
+}
+
+// replayBackend is the Backend of a headless core, it records the commits and
+// ignores every other side effect.
+type replayBackend struct {
+	address        common.Address
+	blockchain     *ethcore.BlockChain
+	last           *types.Block
+	committed      *types.Block
+	committedRound int64
+}
+
+func (b *replayBackend) Address() common.Address { return b.address }
+
+func (b *replayBackend) AddSeal(block *types.Block) (*types.Block, error) { return block, nil }
+
+func (b *replayBackend) AskSync(*types.Header) {}
+
+func (b *replayBackend) Broadcast(context.Context, types.Committee, []byte) error { return nil }
+
+func (b *replayBackend) Commit(block *types.Block, round int64, _ [][]byte) error {
+	b.committed, b.committedRound = block, round
+	return nil
+}
+
+func (b *replayBackend) GetContractABI() string { return "" }
+
+func (b *replayBackend) Gossip(context.Context, types.Committee, []byte) {}
+
+func (b *replayBackend) KnownMsgHash() []common.Hash { return nil }
+
+func (b *replayBackend) HandleUnhandledMsgs(context.Context) {}
+
+func (b *replayBackend) LastCommittedProposal() (*types.Block, common.Address) {
+	return b.last, common.Address{}
+}
+
+func (b *replayBackend) Post(interface{}) {}
+
+func (b *replayBackend) SetProposedBlockHash(common.Hash) {}
+
+func (b *replayBackend) Sign([]byte) ([]byte, error) { return nil, errReplaySign }
+
+func (b *replayBackend) StoreMisbehaviourEvidence(*MisbehaviourEvidence) {}
+
+func (b *replayBackend) Subscribe(...interface{}) *event.TypeMuxSubscription { return nil }
+
+func (b *replayBackend) SyncPeer(common.Address) {}
+
+func (b *replayBackend) VerifyProposal(types.Block) (time.Duration, error) { return 0, nil }
+
+func (b *replayBackend) WhiteList() []string { return nil }
+
+func (b *replayBackend) BlockChain() *ethcore.BlockChain { return b.blockchain }
+
+func (b *replayBackend) SetBlockchain(bc *ethcore.BlockChain) { b.blockchain = bc }
+
+func (b *replayBackend) RemoveMessageFromLocalCache([]byte) {}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	tcrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+type testReplayChain map[uint64]*types.Block
+
+func (c testReplayChain) GetBlockByNumber(number uint64) *types.Block {
+	return c[number]
+}
+
+func TestReplay(t *testing.T) {
+	members, keys := generateCommittee(4)
+	genesis := types.NewBlockWithHeader(&types.Header{Number: common.Big0, Committee: members})
+	chain := testReplayChain{0: genesis}
+
+	set, err := newRoundRobinSet(members, common.Address{})
+	require.NoError(t, err)
+	proposer := set.GetProposer(1).Address
+	node := set.GetProposer(2).Address
+	var absent common.Address
+	for _, member := range members {
+		if member.Address != node && member.Address != proposer && member.Address != set.GetProposer(0).Address {
+			absent = member.Address
+		}
+	}
+	var others []common.Address
+	for _, member := range members {
+		if member.Address != node && member.Address != absent {
+			others = append(others, member.Address)
+		}
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: common.Big1, ParentHash: genesis.Hash(), MixDigest: types.BFTDigest, Committee: members})
+	header := block.Header()
+	require.NoError(t, tcrypto.SignHeader(header, keys[proposer]))
+	block = block.WithSeal(header)
+
+	start := time.Unix(1000, 0)
+	var entries []*LoggedMessage
+	record := func(sent bool, payload []byte) {
+		entries = append(entries, &LoggedMessage{
+			Time:    uint64(start.Add(time.Duration(len(entries)) * time.Millisecond).UnixNano()),
+			Sent:    sent,
+			Payload: payload,
+		})
+	}
+	vote := func(code uint64, round int64, hash common.Hash, from common.Address) {
+		_, _, payload := prepareVote(t, code, round, common.Big1, hash, from, keys[from])
+		record(from == node, payload)
+	}
+
+	// Round 0 has no proposal and the absent validator doesn't vote.
+	for _, code := range []uint64{msgPrevote, msgPrecommit} {
+		for _, from := range append(others, node) {
+			vote(code, 0, common.Hash{}, from)
+		}
+	}
+	// The proposal of round 1 arrives before the node times out.
+	_, _, proposal := prepareProposal(t, 1, common.Big1, -1, block, proposer, keys[proposer])
+	record(false, proposal)
+	vote(msgPrevote, 1, block.Hash(), node)
+	for _, from := range append(others, absent) {
+		vote(msgPrevote, 1, block.Hash(), from)
+	}
+	vote(msgPrecommit, 1, block.Hash(), node)
+	for _, from := range others {
+		vote(msgPrecommit, 1, block.Hash(), from)
+	}
+	// Duplicates are counted once and the precommit of the absent validator arrives after the commit.
+	record(false, entries[0].Payload)
+	vote(msgPrecommit, 1, block.Hash(), absent)
+
+	cfg := config.DefaultConfig()
+	cfg.ProposerPolicy = config.RoundRobin
+	report := Replay(chain, cfg, entries)
+
+	assert.Equal(t, node, report.Node)
+	assert.Equal(t, len(entries)-1, report.Messages)
+	assert.Equal(t, 0, report.Invalid)
+
+	var changes []RoundChange
+	for _, change := range report.RoundChanges {
+		changes = append(changes, RoundChange{Height: change.Height, Round: change.Round, Cause: change.Cause})
+	}
+	assert.Equal(t, []RoundChange{
+		{Height: 1, Round: 0, Cause: RoundChangeStart},
+		{Height: 1, Round: 1, Cause: RoundChangeTimeout},
+		{Height: 2, Round: 0, Cause: RoundChangeCommit},
+	}, changes)
+
+	require.Len(t, report.Heights, 2)
+	height := report.Heights[0]
+	require.NotNil(t, height.Decision)
+	assert.Equal(t, int64(1), height.Decision.Round)
+	assert.Equal(t, block.Hash(), height.Decision.Hash)
+	require.Len(t, height.Rounds, 2)
+
+	round0 := height.Rounds[0]
+	assert.Empty(t, round0.Proposals)
+	require.Len(t, round0.Prevotes.Tally, 1)
+	assert.Equal(t, common.Hash{}, round0.Prevotes.Tally[0].Hash)
+	assert.Equal(t, uint64(3), round0.Prevotes.Tally[0].Power)
+	assert.False(t, round0.Prevotes.Quorum.IsZero())
+	assert.Equal(t, []common.Address{absent}, round0.Prevotes.Missing)
+	assert.Equal(t, []common.Address{absent}, round0.Precommits.Missing)
+	assert.False(t, round0.End.IsZero())
+
+	round1 := height.Rounds[1]
+	require.Len(t, round1.Proposals, 1)
+	assert.Equal(t, proposer, round1.Proposals[0].Proposer)
+	assert.Equal(t, block.Hash(), round1.Proposals[0].Hash)
+	assert.Empty(t, round1.Prevotes.Missing)
+	assert.Empty(t, round1.Prevotes.Late)
+	assert.Empty(t, round1.Precommits.Missing)
+	require.Len(t, round1.Precommits.Late, 1)
+	assert.Equal(t, absent, round1.Precommits.Late[0].Validator)
+	assert.True(t, round1.Precommits.Late[0].Delay > 0)
+	assert.Equal(t, uint64(4), round1.Precommits.Tally[0].Power)
+}
+
+func TestReplayUnknownHeight(t *testing.T) {
+	members, keys := generateCommittee(4)
+	_, _, payload := prepareVote(t, msgPrevote, 0, big.NewInt(5), common.Hash{}, members[0].Address, keys[members[0].Address])
+
+	report := Replay(testReplayChain{}, config.DefaultConfig(), []*LoggedMessage{{Payload: payload}, {Payload: []byte{0x01}}})
+	assert.Equal(t, 2, report.Messages)
+	assert.Equal(t, 2, report.Invalid)
+	assert.Empty(t, report.RoundChanges)
+	assert.Empty(t, report.Heights)
+}
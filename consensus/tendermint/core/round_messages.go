@@ -43,6 +43,15 @@ func (s *messagesMap) reset() {
 	s.internal = make(map[int64]*roundMessages)
 }
 
+// moveTo moves the messages of s to dst, s being left empty.
+func (s *messagesMap) moveTo(dst *messagesMap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	dst.internal, s.internal = s.internal, make(map[int64]*roundMessages)
+}
+
 func (s *messagesMap) getOrCreate(round int64) *roundMessages {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -130,12 +139,12 @@ func (s *roundMessages) PrecommitsTotalPower() uint64 {
 	return s.precommits.TotalVotePower()
 }
 
-func (s *roundMessages) AddPrevote(hash common.Hash, msg Message) {
-	s.prevotes.AddVote(hash, msg)
+func (s *roundMessages) AddPrevote(hash common.Hash, msg Message) *Message {
+	return s.prevotes.AddVote(hash, msg)
 }
 
-func (s *roundMessages) AddPrecommit(hash common.Hash, msg Message) {
-	s.precommits.AddVote(hash, msg)
+func (s *roundMessages) AddPrecommit(hash common.Hash, msg Message) *Message {
+	return s.precommits.AddVote(hash, msg)
 }
 
 func (s *roundMessages) CommitedSeals(hash common.Hash) []Message {
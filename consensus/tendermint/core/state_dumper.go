@@ -4,6 +4,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/types"
 	"math/big"
+	"time"
 )
 
 type coreStateRequestEvent struct {
@@ -65,6 +66,9 @@ type TendermintState struct {
 
 	// timer state
 	BlockPeriod           uint64
+	TimeoutPropose        time.Duration
+	TimeoutPrevote        time.Duration
+	TimeoutPrecommit      time.Duration
 	ProposeTimerStarted   bool
 	PrevoteTimerStarted   bool
 	PrecommitTimerStarted bool
@@ -94,6 +98,9 @@ func (c *core) handleStateDump(e coreStateRequestEvent) {
 		Client:            c.address,
 		ProposerPolicy:    uint64(c.proposerPolicy),
 		BlockPeriod:       c.blockPeriod,
+		TimeoutPropose:    c.timeoutPropose(c.Round()),
+		TimeoutPrevote:    c.timeoutPrevote(c.Round()),
+		TimeoutPrecommit:  c.timeoutPrecommit(c.Round()),
 		CurHeightMessages: msgForDump(c.GetCurrentHeightMessages()),
 		BacklogMessages:   getBacklogMsgs(c),
 		UncheckedMsgs:     getBacklogUncheckedMsgs(c),
@@ -108,6 +108,7 @@ func TestGetCoreState(t *testing.T) {
 	assert.Equal(t, sender, state.Client)
 	assert.Equal(t, uint64(c.proposerPolicy), state.ProposerPolicy)
 	assert.Equal(t, c.blockPeriod, state.BlockPeriod)
+	assert.Equal(t, c.timeoutPropose(rounds[1]), state.TimeoutPropose)
 	assert.Len(t, state.CurHeightMessages, 6)
 	assert.Equal(t, height, state.Height)
 	assert.Equal(t, rounds[1], state.Round)
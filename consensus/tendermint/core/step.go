@@ -25,6 +25,13 @@ const (
 	precommitDone
 )
 
+// The steps of the consensus messages, as returned by Message.Step.
+const (
+	ProposeStep   = propose
+	PrevoteStep   = prevote
+	PrecommitStep = precommit
+)
+
 func (s Step) String() string {
 	if s == propose {
 		return "propose"
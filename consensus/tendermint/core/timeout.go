@@ -2,20 +2,41 @@ package core
 
 import (
 	"context"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
 	"github.com/clearmatics/autonity/log"
 	"math/big"
 	"sync"
 	"time"
 )
 
-const (
-	initialProposeTimeout   = 2000 * time.Millisecond
-	proposeTimeoutDelta     = 500 * time.Millisecond
-	initialPrevoteTimeout   = 1000 * time.Millisecond
-	prevoteTimeoutDelta     = 500 * time.Millisecond
-	initialPrecommitTimeout = 1000 * time.Millisecond
-	precommitTimeoutDelta   = 500 * time.Millisecond
-)
+// timeoutConfig holds the base timeout of each step and its increase per round.
+type timeoutConfig struct {
+	initialPropose   time.Duration
+	proposeDelta     time.Duration
+	initialPrevote   time.Duration
+	prevoteDelta     time.Duration
+	initialPrecommit time.Duration
+	precommitDelta   time.Duration
+}
+
+// defaultTimeoutConfig holds the timeouts of the cores not given any.
+var defaultTimeoutConfig = newTimeoutConfig(config.DefaultConfig())
+
+// newTimeoutConfig returns the timeouts of cfg, the unset ones taking their default
+// value. cfg is left unchanged.
+func newTimeoutConfig(cfg *config.Config) *timeoutConfig {
+	filled := *cfg
+	filled.SetDefaults()
+	ms := func(v uint64) time.Duration { return time.Duration(v) * time.Millisecond }
+	return &timeoutConfig{
+		initialPropose:   ms(filled.TimeoutPropose),
+		proposeDelta:     ms(*filled.TimeoutProposeDelta),
+		initialPrevote:   ms(filled.TimeoutPrevote),
+		prevoteDelta:     ms(*filled.TimeoutPrevoteDelta),
+		initialPrecommit: ms(filled.TimeoutPrecommit),
+		precommitDelta:   ms(*filled.TimeoutPrecommitDelta),
+	}
+}
 
 type TimeoutEvent struct {
 	roundWhenCalled  int64
@@ -25,7 +46,8 @@ type TimeoutEvent struct {
 }
 
 type timeout struct {
-	timer   *time.Timer
+	clock   Clock
+	timer   Timer
 	started bool
 	step    Step
 	// start will be refreshed on each new schedule, it is used for metric collection of tendermint timeout.
@@ -49,7 +71,7 @@ func (t *timeout) scheduleTimeout(stepTimeout time.Duration, round int64, height
 	defer t.Unlock()
 	t.started = true
 	t.start = time.Now()
-	t.timer = time.AfterFunc(stepTimeout, func() {
+	t.timer = afterFunc(t.clock, stepTimeout, func() {
 		runAfterTimeout(round, height)
 	})
 }
@@ -184,15 +206,27 @@ func (c *core) handleTimeoutPrecommit(ctx context.Context, msg TimeoutEvent) {
 /////////////// Calculate Timeout Duration Functions ///////////////
 // The timeout may need to be changed depending on the Step
 func (c *core) timeoutPropose(round int64) time.Duration {
-	return initialProposeTimeout + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*proposeTimeoutDelta
+	t := c.timeoutConfig()
+	return t.initialPropose + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*t.proposeDelta
 }
 
 func (c *core) timeoutPrevote(round int64) time.Duration {
-	return initialPrevoteTimeout + time.Duration(round)*prevoteTimeoutDelta
+	t := c.timeoutConfig()
+	return t.initialPrevote + time.Duration(round)*t.prevoteDelta
 }
 
 func (c *core) timeoutPrecommit(round int64) time.Duration {
-	return initialPrecommitTimeout + time.Duration(round)*precommitTimeoutDelta
+	t := c.timeoutConfig()
+	return t.initialPrecommit + time.Duration(round)*t.precommitDelta
+}
+
+// timeoutConfig returns the timeouts of the core, the default ones if it was
+// given none.
+func (c *core) timeoutConfig() *timeoutConfig {
+	if c.timeouts == nil {
+		return defaultTimeoutConfig
+	}
+	return c.timeouts
 }
 
 func (c *core) logTimeoutEvent(message string, msgType string, timeout TimeoutEvent) {
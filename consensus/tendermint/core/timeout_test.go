@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/metrics"
@@ -224,3 +225,40 @@ func TestOnTimeoutPrecommit(t *testing.T) {
 	})
 	engine.onTimeoutPrecommit(2, big.NewInt(4))
 }
+
+func TestTimeoutDuration(t *testing.T) {
+	cfg := &config.Config{
+		BlockPeriod:           2,
+		TimeoutPropose:        3000,
+		TimeoutProposeDelta:   config.TimeoutDelta(100),
+		TimeoutPrevote:        1500,
+		TimeoutPrevoteDelta:   config.TimeoutDelta(200),
+		TimeoutPrecommit:      1200,
+		TimeoutPrecommitDelta: config.TimeoutDelta(300),
+	}
+	engine := core{
+		blockPeriod: cfg.BlockPeriod,
+		timeouts:    newTimeoutConfig(cfg),
+	}
+
+	if got, want := engine.timeoutPropose(3), 5300*time.Millisecond; got != want {
+		t.Fatalf("propose timeout mismatch, got %v, want %v", got, want)
+	}
+	if got, want := engine.timeoutPrevote(3), 2100*time.Millisecond; got != want {
+		t.Fatalf("prevote timeout mismatch, got %v, want %v", got, want)
+	}
+	if got, want := engine.timeoutPrecommit(3), 2100*time.Millisecond; got != want {
+		t.Fatalf("precommit timeout mismatch, got %v, want %v", got, want)
+	}
+
+	// A zero delta keeps the timeout constant and the unset values are defaulted
+	// without changing the configuration.
+	cfg = &config.Config{BlockPeriod: 2, TimeoutPrevoteDelta: config.TimeoutDelta(0)}
+	engine.timeouts = newTimeoutConfig(cfg)
+	if got, want := engine.timeoutPrevote(3), config.DefaultTimeoutPrevote*time.Millisecond; got != want {
+		t.Fatalf("prevote timeout mismatch, got %v, want %v", got, want)
+	}
+	if cfg.TimeoutPrevote != 0 || cfg.TimeoutProposeDelta != nil {
+		t.Fatalf("configuration changed: %+v", cfg)
+	}
+}
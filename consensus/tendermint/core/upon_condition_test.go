@@ -91,6 +91,25 @@ func TestStartRoundVariables(t *testing.T) {
 		err := core.proposeTimeout.stopTimer()
 		assert.NoError(t, err)
 	})
+	t.Run("height is not started when its proposers cannot be elected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// the last block without committee fails the election of the proposers
+		lastBlock := generateBlock(prevHeight)
+		backendMock := NewMockBackend(ctrl)
+		backendMock.EXPECT().Address().Return(clientAddress)
+		backendMock.EXPECT().LastCommittedProposal().Return(lastBlock, clientAddress)
+		backendMock.EXPECT().BlockChain().Return(nil)
+
+		core := New(backendMock, &config.Config{ProposerPolicy: config.WeightedRandomSampling})
+		overrideDefaultCoreValues(core)
+		core.startRound(context.Background(), currentRound)
+
+		// the core is left at the previous height without committee
+		checkConsensusState(t, big.NewInt(-1), int64(-1), precommitDone, &types.Block{}, 0, &types.Block{}, 0, core)
+		assert.Nil(t, core.committeeSet())
+	})
 	t.Run("ensure round x state variables are updated correctly", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// DefaultUptimeWindow is the number of blocks over which the liveness of the
+// committee members is tracked.
+const DefaultUptimeWindow = 1024
+
+// ValidatorUptime is the liveness of a committee member over the blocks of the
+// uptime window in which it was part of the committee.
+type ValidatorUptime struct {
+	Address           common.Address `json:"address"`
+	SignedBlocks      uint64         `json:"signedBlocks"`      // Blocks committed with a seal of the member
+	MissedBlocks      uint64         `json:"missedBlocks"`      // Blocks committed without a seal of the member
+	Uptime            float64        `json:"uptime"`            // Share of the blocks signed by the member
+	LastSeenHeight    uint64         `json:"lastSeenHeight"`    // Last block committed with a seal of the member, out of the window included
+	ProposedBlocks    uint64         `json:"proposedBlocks"`    // Blocks proposed by the member
+	ExpectedProposals float64        `json:"expectedProposals"` // Blocks the member would have proposed given its voting power
+}
+
+// UptimeReport is the liveness of the committee members over a range of blocks.
+type UptimeReport struct {
+	FromHeight uint64            `json:"fromHeight"`
+	ToHeight   uint64            `json:"toHeight"`
+	Validators []ValidatorUptime `json:"validators"`
+}
+
+// blockLiveness records who signed and proposed a block.
+type blockLiveness struct {
+	number   uint64
+	hash     common.Hash
+	proposer common.Address
+	members  []memberLiveness
+}
+
+type memberLiveness struct {
+	address common.Address
+	share   float64 // voting power share
+	signed  bool
+}
+
+// UptimeTracker tracks the liveness of the committee members from the committed
+// seals of the last blocks of the chain. Blocks must be added in order, the
+// tracker is reset on a gap or a reorg.
+type UptimeTracker struct {
+	window   int
+	blocks   []blockLiveness // tracked blocks, ordered by number
+	stats    map[common.Address]*ValidatorUptime
+	lastSeen map[common.Address]uint64
+	mu       sync.RWMutex
+}
+
+// NewUptimeTracker creates a tracker keeping the last window blocks.
+func NewUptimeTracker(window int) *UptimeTracker {
+	t := &UptimeTracker{window: window}
+	t.Reset()
+	return t
+}
+
+// Reset forgets every tracked block.
+func (t *UptimeTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reset()
+}
+
+func (t *UptimeTracker) reset() {
+	for address := range t.stats {
+		unregisterValidatorUptimeGauges(address)
+	}
+	t.blocks = nil
+	t.stats = make(map[common.Address]*ValidatorUptime)
+	t.lastSeen = make(map[common.Address]uint64)
+}
+
+// Window returns the number of blocks the tracker keeps.
+func (t *UptimeTracker) Window() int {
+	return t.window
+}
+
+// Head returns the number and hash of the last tracked block, ok is false if no
+// block is tracked.
+func (t *UptimeTracker) Head() (number uint64, hash common.Hash, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.blocks) == 0 {
+		return 0, common.Hash{}, false
+	}
+	head := t.blocks[len(t.blocks)-1]
+	return head.number, head.hash, true
+}
+
+// Add tracks header, committed by signers among committee, the committee of its
+// parent. The tracker is reset if header is not the child of the last tracked block.
+func (t *UptimeTracker) Add(header *types.Header, committee types.Committee, signers []common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n := len(t.blocks); n > 0 {
+		head := t.blocks[n-1]
+		if head.number+1 != header.Number.Uint64() || head.hash != header.ParentHash {
+			t.reset()
+		}
+	}
+
+	signed := make(map[common.Address]bool, len(signers))
+	for _, signer := range signers {
+		signed[signer] = true
+	}
+	var totalPower uint64
+	for _, member := range committee {
+		totalPower += member.VotingPower.Uint64()
+	}
+	block := blockLiveness{
+		number:   header.Number.Uint64(),
+		hash:     header.Hash(),
+		proposer: header.Coinbase,
+		members:  make([]memberLiveness, len(committee)),
+	}
+	for i, member := range committee {
+		block.members[i] = memberLiveness{address: member.Address, signed: signed[member.Address]}
+		if totalPower > 0 {
+			block.members[i].share = float64(member.VotingPower.Uint64()) / float64(totalPower)
+		}
+		if block.members[i].signed {
+			t.lastSeen[member.Address] = block.number
+		}
+	}
+
+	t.blocks = append(t.blocks, block)
+	t.count(block, 1)
+	if len(t.blocks) > t.window {
+		t.count(t.blocks[0], -1)
+		t.blocks = t.blocks[1:]
+	}
+	for _, member := range block.members {
+		t.updateMetrics(member.address)
+	}
+}
+
+// count adds (delta = 1) or removes (delta = -1) block from the statistics.
+func (t *UptimeTracker) count(block blockLiveness, delta int) {
+	add := func(counter *uint64) {
+		if delta > 0 {
+			*counter++
+		} else {
+			*counter--
+		}
+	}
+	for _, member := range block.members {
+		stats, ok := t.stats[member.address]
+		if !ok {
+			stats = &ValidatorUptime{Address: member.address}
+			t.stats[member.address] = stats
+		}
+		if member.signed {
+			add(&stats.SignedBlocks)
+		} else {
+			add(&stats.MissedBlocks)
+		}
+		if member.address == block.proposer {
+			add(&stats.ProposedBlocks)
+		}
+		stats.ExpectedProposals += float64(delta) * member.share
+		// Members which left the committee for the whole window are forgotten.
+		if stats.SignedBlocks+stats.MissedBlocks == 0 {
+			delete(t.stats, member.address)
+			unregisterValidatorUptimeGauges(member.address)
+		}
+	}
+}
+
+// uptime returns the statistics of a member, with its uptime and last seen height set.
+func (t *UptimeTracker) uptime(address common.Address) ValidatorUptime {
+	uptime := ValidatorUptime{Address: address}
+	if stats, ok := t.stats[address]; ok {
+		uptime = *stats
+	}
+	if blocks := uptime.SignedBlocks + uptime.MissedBlocks; blocks > 0 {
+		uptime.Uptime = float64(uptime.SignedBlocks) / float64(blocks)
+	}
+	uptime.LastSeenHeight = t.lastSeen[address]
+	return uptime
+}
+
+// Uptime returns the liveness of a committee member over the tracked blocks.
+func (t *UptimeTracker) Uptime(address common.Address) ValidatorUptime {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.uptime(address)
+}
+
+// Report returns the liveness of the members of the committees of the tracked
+// blocks, ordered by address.
+func (t *UptimeTracker) Report() *UptimeReport {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	report := &UptimeReport{Validators: make([]ValidatorUptime, 0, len(t.stats))}
+	if len(t.blocks) > 0 {
+		report.FromHeight = t.blocks[0].number
+		report.ToHeight = t.blocks[len(t.blocks)-1].number
+	}
+	for address := range t.stats {
+		report.Validators = append(report.Validators, t.uptime(address))
+	}
+	sort.Slice(report.Validators, func(i, j int) bool {
+		return bytes.Compare(report.Validators[i].Address[:], report.Validators[j].Address[:]) < 0
+	})
+	return report
+}
+
+func (t *UptimeTracker) updateMetrics(address common.Address) {
+	uptime := t.uptime(address)
+	signed, missed, ratio, lastSeen := validatorUptimeGauges(address)
+	signed.Update(int64(uptime.SignedBlocks))
+	missed.Update(int64(uptime.MissedBlocks))
+	ratio.Update(uptime.Uptime)
+	lastSeen.Update(int64(uptime.LastSeenHeight))
+}
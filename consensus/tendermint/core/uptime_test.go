@@ -0,0 +1,86 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/metrics"
+)
+
+func TestUptimeTracker(t *testing.T) {
+	a, b := common.Address{1}, common.Address{2}
+	committee := types.Committee{
+		{Address: a, VotingPower: big.NewInt(3)},
+		{Address: b, VotingPower: big.NewInt(1)},
+	}
+	parent := &types.Header{Number: big.NewInt(0)}
+	next := func(proposer common.Address) *types.Header {
+		header := &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).Add(parent.Number, common.Big1), Coinbase: proposer}
+		parent = header
+		return header
+	}
+
+	tracker := NewUptimeTracker(3)
+	tracker.Add(next(a), committee, []common.Address{a, b})
+	tracker.Add(next(a), committee, []common.Address{a})
+	tracker.Add(next(b), committee, []common.Address{a, b})
+
+	report := tracker.Report()
+	require.Equal(t, uint64(1), report.FromHeight)
+	require.Equal(t, uint64(3), report.ToHeight)
+	require.Len(t, report.Validators, 2)
+	require.Equal(t, ValidatorUptime{
+		Address:           a,
+		SignedBlocks:      3,
+		Uptime:            1,
+		LastSeenHeight:    3,
+		ProposedBlocks:    2,
+		ExpectedProposals: 2.25,
+	}, report.Validators[0])
+	require.Equal(t, ValidatorUptime{
+		Address:           b,
+		SignedBlocks:      2,
+		MissedBlocks:      1,
+		Uptime:            2.0 / 3,
+		LastSeenHeight:    3,
+		ProposedBlocks:    1,
+		ExpectedProposals: 0.75,
+	}, report.Validators[1])
+
+	// The oldest block leaves the window, b keeps its last seen height.
+	tracker.Add(next(a), committee, []common.Address{a})
+	tracker.Add(next(a), types.Committee{committee[0]}, []common.Address{a})
+	uptime := tracker.Uptime(b)
+	require.Equal(t, uint64(1), uptime.SignedBlocks)
+	require.Equal(t, uint64(1), uptime.MissedBlocks)
+	require.Equal(t, uint64(3), uptime.LastSeenHeight)
+	require.Equal(t, uint64(3), tracker.Report().FromHeight)
+	require.NotNil(t, metrics.Get(validatorUptimePrefix(b)+"signed"))
+
+	// The gauges of b are removed once it left the committees of the window.
+	tracker.Add(next(a), types.Committee{committee[0]}, []common.Address{a})
+	tracker.Add(next(a), types.Committee{committee[0]}, []common.Address{a})
+	require.Len(t, tracker.Report().Validators, 1)
+	for _, name := range []string{"signed", "missed", "ratio", "lastseen"} {
+		require.Nil(t, metrics.Get(validatorUptimePrefix(b)+name))
+	}
+	require.NotNil(t, metrics.Get(validatorUptimePrefix(a)+"signed"))
+
+	// A block which doesn't follow the tracked ones resets the tracker.
+	number, hash, ok := tracker.Head()
+	require.True(t, ok)
+	require.Equal(t, uint64(7), number)
+	require.Equal(t, parent.Hash(), hash)
+	parent = &types.Header{Number: big.NewInt(9)}
+	tracker.Add(next(b), committee, []common.Address{b})
+	report = tracker.Report()
+	require.Equal(t, uint64(10), report.FromHeight)
+	require.Equal(t, uint64(10), report.ToHeight)
+	require.Equal(t, uint64(0), report.Validators[0].SignedBlocks)
+	require.Equal(t, uint64(1), report.Validators[0].MissedBlocks)
+	require.Equal(t, uint64(0), report.Validators[0].LastSeenHeight)
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+const (
+	walMessage   uint64 = iota // Signed consensus message sent by the node
+	walLockState               // Locked and valid values and rounds of the node
+)
+
+// errInvalidWALEntry is returned when a WAL entry can't be decoded.
+var errInvalidWALEntry = errors.New("invalid consensus wal entry")
+
+// WAL is the write-ahead log of the Tendermint state machine. The messages signed by
+// the node and its lock changes are recorded before being acted upon, so that after
+// a crash the node resumes the height at the same round and lock, without signing a
+// message conflicting with one sent before the crash.
+type WAL struct {
+	db     ethdb.Database
+	height uint64
+	index  uint64
+}
+
+// NewWAL creates a consensus write-ahead log stored in db.
+func NewWAL(db ethdb.Database) *WAL {
+	return &WAL{db: db}
+}
+
+// walEntry is a single record of the WAL.
+type walEntry struct {
+	Kind uint64
+	Data []byte
+}
+
+// lockState is the payload of a walLockState entry, rounds are offset by one as
+// RLP can't encode the -1 unset round.
+type lockState struct {
+	LockedRound uint64
+	LockedValue *types.Block `rlp:"nil"`
+	ValidRound  uint64
+	ValidValue  *types.Block `rlp:"nil"`
+}
+
+// append durably stores an entry for the given height.
+func (w *WAL) append(height *big.Int, kind uint64, data []byte) error {
+	if w == nil {
+		return nil
+	}
+	entry, err := rlp.EncodeToBytes(&walEntry{Kind: kind, Data: data})
+	if err != nil {
+		return err
+	}
+	if h := height.Uint64(); h != w.height {
+		w.height, w.index = h, uint64(len(rawdb.ReadConsensusWALEntries(w.db, h)))
+	}
+	batch := w.db.NewBatch()
+	rawdb.WriteConsensusWALEntry(batch, w.height, w.index, entry)
+	if err := w.sync(batch); err != nil {
+		return err
+	}
+	w.index++
+	return nil
+}
+
+// sync writes batch and waits for it to reach the disk, the entries being acted
+// upon right after being appended. The databases unable to sync, as the in-memory
+// ones, are only written.
+func (w *WAL) sync(batch ethdb.Batch) error {
+	if b, ok := batch.(ethdb.SyncWriter); ok {
+		return b.WriteSync()
+	}
+	return batch.Write()
+}
+
+// entries returns the entries recorded for the given height in insertion order.
+func (w *WAL) entries(height *big.Int) ([]*walEntry, error) {
+	if w == nil {
+		return nil, nil
+	}
+	var entries []*walEntry
+	for _, data := range rawdb.ReadConsensusWALEntries(w.db, height.Uint64()) {
+		entry := new(walEntry)
+		if err := rlp.DecodeBytes(data, entry); err != nil {
+			return nil, errInvalidWALEntry
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// truncate removes the entries of the heights below the given one.
+func (w *WAL) truncate(height *big.Int) {
+	if w == nil {
+		return
+	}
+	rawdb.DeleteConsensusWAL(w.db, height.Uint64())
+}
+
+// SetWAL sets the write-ahead log used to persist the consensus state, no WAL is
+// written if unset.
+func (c *core) SetWAL(wal *WAL) {
+	c.wal = wal
+}
+
+// recordLockState persists the locked and valid values of the node. It must be called
+// before any message depending on the new lock is sent.
+func (c *core) recordLockState() error {
+	state, err := rlp.EncodeToBytes(&lockState{
+		LockedRound: uint64(c.lockedRound + 1),
+		LockedValue: c.lockedValue,
+		ValidRound:  uint64(c.validRound + 1),
+		ValidValue:  c.validValue,
+	})
+	if err != nil {
+		return err
+	}
+	return c.wal.append(c.Height(), walLockState, state)
+}
+
+// replayWAL restores the state of the current height recorded before a restart: the
+// lock is restored and the node resumes the latest round it sent a message for, the
+// messages it already signed being broadcast again instead of new ones. It returns
+// false if there is nothing to resume.
+func (c *core) replayWAL(ctx context.Context) bool {
+	entries, err := c.wal.entries(c.Height())
+	if err != nil {
+		c.logger.Error("Failed to read consensus wal", "height", c.Height(), "err", err)
+		return false
+	}
+	if len(entries) == 0 {
+		return false
+	}
+
+	var (
+		round    int64 = -1
+		messages []*Message
+		state    *lockState
+	)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case walMessage:
+			msg := new(Message)
+			if err := msg.FromPayload(entry.Data); err != nil {
+				c.logger.Error("Invalid consensus wal message", "err", err)
+				continue
+			}
+			if r, err := msg.Round(); err == nil && r > round {
+				round = r
+			}
+			messages = append(messages, msg)
+		case walLockState:
+			state = new(lockState)
+			if err := rlp.DecodeBytes(entry.Data, state); err != nil {
+				c.logger.Error("Invalid consensus wal lock state", "err", err)
+				state = nil
+			}
+		}
+	}
+	if round < 0 {
+		return false
+	}
+
+	if err := c.setInitialState(round); err != nil {
+		c.logger.Error("Failed to resume the height from the consensus wal", "err", err)
+		return false
+	}
+	if state != nil {
+		c.lockedRound, c.lockedValue = int64(state.LockedRound)-1, state.LockedValue
+		c.validRound, c.validValue = int64(state.ValidRound)-1, state.ValidValue
+	}
+
+	step := propose
+	for _, msg := range messages {
+		if r, _ := msg.Round(); r == round {
+			switch msg.Code {
+			case msgProposal:
+				c.sentProposal = true
+			case msgPrevote:
+				c.sentPrevote = true
+				step = prevote
+			case msgPrecommit:
+				c.sentPrecommit = true
+				step = precommit
+			}
+		}
+	}
+	c.logger.Info("Resuming consensus from the wal", "height", c.Height(), "round", round, "step", step,
+		"lockedRound", c.lockedRound, "validRound", c.validRound)
+	c.setStep(step)
+
+	// Our own messages are handled once broadcast, restoring the round messages.
+	for _, msg := range messages {
+		if err := c.backend.Broadcast(ctx, c.committeeSet().Committee(), msg.Payload()); err != nil {
+			c.logger.Error("Failed to broadcast wal message", "msg", msg, "err", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWAL(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	height := big.NewInt(5)
+
+	wal := NewWAL(db)
+	require.NoError(t, wal.append(height, walMessage, []byte{1}))
+	require.NoError(t, wal.append(height, walMessage, []byte{2}))
+
+	// A restarted node must append after the existing entries.
+	wal = NewWAL(db)
+	require.NoError(t, wal.append(height, walLockState, []byte{3}))
+	require.NoError(t, wal.append(big.NewInt(6), walMessage, []byte{4}))
+
+	entries, err := wal.entries(height)
+	require.NoError(t, err)
+	assert.Equal(t, []*walEntry{{walMessage, []byte{1}}, {walMessage, []byte{2}}, {walLockState, []byte{3}}}, entries)
+
+	wal.truncate(big.NewInt(6))
+	entries, err = wal.entries(height)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	entries, err = wal.entries(big.NewInt(6))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// syncedDB counts the batches of the database written and synced.
+type syncedDB struct {
+	ethdb.Database
+	synced *int
+}
+
+type syncedBatch struct {
+	ethdb.Batch
+	synced *int
+}
+
+func (db syncedDB) NewBatch() ethdb.Batch {
+	return syncedBatch{Batch: db.Database.NewBatch(), synced: db.synced}
+}
+
+func (b syncedBatch) WriteSync() error {
+	*b.synced++
+	return b.Batch.Write()
+}
+
+func TestWALSync(t *testing.T) {
+	synced := 0
+	wal := NewWAL(syncedDB{Database: rawdb.NewMemoryDatabase(), synced: &synced})
+	require.NoError(t, wal.append(big.NewInt(5), walMessage, []byte{1}))
+	require.NoError(t, wal.append(big.NewInt(5), walLockState, []byte{2}))
+	assert.Equal(t, 2, synced, "every entry is synced once appended")
+
+	entries, err := wal.entries(big.NewInt(5))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestCore_BroadcastWritesWAL(t *testing.T) {
+	committeeSet, _ := prepareCommittee(t, 4)
+	clientAddr := committeeSet.Committee()[0].Address
+	height := big.NewInt(3)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backendMock := NewMockBackend(ctrl)
+	backendMock.EXPECT().Address().Return(clientAddr)
+
+	c := New(backendMock, config.DefaultConfig())
+	c.SetWAL(NewWAL(rawdb.NewMemoryDatabase()))
+	c.setHeight(height)
+	c.setCommitteeSet(committeeSet)
+
+	backendMock.EXPECT().Sign(gomock.Any()).Return([]byte{0xca, 0xfe}, nil)
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(_ context.Context, _ interface{}, payload []byte) {
+		entries, err := c.wal.entries(height)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, payload, entries[0].Data)
+	})
+
+	vote, err := Encode(&Vote{Round: 0, Height: height, ProposedBlockHash: common.HexToHash("0x1")})
+	require.NoError(t, err)
+	c.broadcast(context.Background(), &Message{Code: msgPrevote, Msg: vote, Address: clientAddr, CommittedSeal: []byte{}})
+}
+
+func TestCore_ReplayWAL(t *testing.T) {
+	committeeSet, keys := prepareCommittee(t, 4)
+	clientAddr := committeeSet.Committee()[0].Address
+	height := big.NewInt(3)
+	lockedBlock := generateBlock(height)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backendMock := NewMockBackend(ctrl)
+	backendMock.EXPECT().Address().Return(clientAddr)
+
+	c := New(backendMock, config.DefaultConfig())
+	c.SetWAL(NewWAL(rawdb.NewMemoryDatabase()))
+	c.setHeight(height)
+	c.setCommitteeSet(committeeSet)
+
+	// Before the crash the node prevoted nil at round 0, then locked and precommitted at round 1.
+	_, _, prevote0 := prepareVote(t, msgPrevote, 0, height, common.Hash{}, clientAddr, keys[clientAddr])
+	_, _, prevote1 := prepareVote(t, msgPrevote, 1, height, lockedBlock.Hash(), clientAddr, keys[clientAddr])
+	_, _, precommit1 := prepareVote(t, msgPrecommit, 1, height, lockedBlock.Hash(), clientAddr, keys[clientAddr])
+	require.NoError(t, c.wal.append(height, walMessage, prevote0))
+	require.NoError(t, c.wal.append(height, walMessage, prevote1))
+	c.lockedRound, c.lockedValue, c.validRound, c.validValue = 1, lockedBlock, 1, lockedBlock
+	require.NoError(t, c.recordLockState())
+	require.NoError(t, c.wal.append(height, walMessage, precommit1))
+
+	// Restart
+	c.lockedRound, c.lockedValue, c.validRound, c.validValue = -1, nil, -1, nil
+
+	var broadcast [][]byte
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).Do(func(_ context.Context, _ interface{}, payload []byte) {
+		broadcast = append(broadcast, payload)
+	})
+
+	assert.True(t, c.replayWAL(context.Background()))
+	assert.Equal(t, int64(1), c.Round())
+	assert.Equal(t, precommit, c.step)
+	assert.True(t, c.sentPrevote)
+	assert.True(t, c.sentPrecommit)
+	assert.False(t, c.sentProposal)
+	assert.Equal(t, int64(1), c.lockedRound)
+	assert.Equal(t, lockedBlock.Hash(), c.lockedValue.Hash())
+	assert.Equal(t, int64(1), c.validRound)
+	assert.Equal(t, lockedBlock.Hash(), c.validValue.Hash())
+	assert.Equal(t, [][]byte{prevote0, prevote1, precommit1}, broadcast)
+}
+
+func TestCore_ReplayEmptyWAL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backendMock := NewMockBackend(ctrl)
+	backendMock.EXPECT().Address().Return(common.Address{})
+
+	c := New(backendMock, config.DefaultConfig())
+	c.setHeight(big.NewInt(1))
+	assert.False(t, c.replayWAL(context.Background()))
+
+	c.SetWAL(NewWAL(rawdb.NewMemoryDatabase()))
+	assert.False(t, c.replayWAL(context.Background()))
+}
@@ -0,0 +1,100 @@
+package simulation
+
+import (
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tcrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+// Behaviour makes a node deviate from the protocol when it sends its messages.
+type Behaviour interface {
+	// Send returns the messages sent to a peer in place of the payload the core
+	// of the node sent.
+	Send(n *Node, to int, payload []byte) [][]byte
+}
+
+// Silent is a crashed or muted node, it doesn't send any message.
+type Silent struct{}
+
+// Send implements Behaviour.
+func (Silent) Send(*Node, int, []byte) [][]byte {
+	return nil
+}
+
+// Equivocate sends a conflicting message for each of its proposals and votes to
+// the peers with an odd index, the others receiving the messages of its core.
+type Equivocate struct{}
+
+// Send implements Behaviour.
+func (Equivocate) Send(n *Node, to int, payload []byte) [][]byte {
+	if to%2 == 0 {
+		return [][]byte{payload}
+	}
+	conflicting, err := conflictingMessage(n, payload)
+	if err != nil {
+		return [][]byte{payload}
+	}
+	return [][]byte{conflicting}
+}
+
+// conflictingMessage returns a message of the node for the same height, round and
+// step as the one given but with another value. It is derived from the payload so
+// that the runs stay reproducible.
+func conflictingMessage(n *Node, payload []byte) ([]byte, error) {
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(payload); err != nil {
+		return nil, err
+	}
+	step, err := msg.Step()
+	if err != nil {
+		return nil, err
+	}
+
+	var content interface{}
+	var seal []byte
+	switch step {
+	case tendermintCore.ProposeStep:
+		var proposal tendermintCore.Proposal
+		if err := msg.Decode(&proposal); err != nil {
+			return nil, err
+		}
+		header := proposal.ProposalBlock.Header()
+		header.Time++
+		if err := tcrypto.SignHeader(header, n.key); err != nil {
+			return nil, err
+		}
+		block := proposal.ProposalBlock.WithSeal(header)
+		content = tendermintCore.NewProposal(proposal.Round, proposal.Height, proposal.ValidRound, block)
+	case tendermintCore.PrevoteStep, tendermintCore.PrecommitStep:
+		var vote tendermintCore.Vote
+		if err := msg.Decode(&vote); err != nil {
+			return nil, err
+		}
+		vote.ProposedBlockHash = crypto.Keccak256Hash(payload)
+		content = &vote
+		if step == tendermintCore.PrecommitStep {
+			if seal, err = n.Sign(tendermintCore.PrepareCommittedSeal(vote.ProposedBlockHash, vote.Round, vote.Height)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	encoded, err := tendermintCore.Encode(content)
+	if err != nil {
+		return nil, err
+	}
+	conflicting := &tendermintCore.Message{
+		Code:          msg.Code,
+		Msg:           encoded,
+		Address:       n.Address,
+		CommittedSeal: seal,
+	}
+	data, err := conflicting.PayloadNoSig()
+	if err != nil {
+		return nil, err
+	}
+	if conflicting.Signature, err = n.Sign(data); err != nil {
+		return nil, err
+	}
+	return conflicting.Payload(), nil
+}
@@ -0,0 +1,109 @@
+package simulation
+
+import (
+	"container/heap"
+	"time"
+
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+)
+
+// Clock is a virtual clock. The functions it schedules run in the order they are
+// due, then in the order they were scheduled, as the simulation advances the
+// time. It is not safe for concurrent use.
+type Clock struct {
+	now    time.Time
+	seq    uint64
+	timers timerQueue
+}
+
+// NewClock creates a virtual clock set at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// AfterFunc implements core.Clock, f is called once the clock is advanced past
+// the duration.
+func (c *Clock) AfterFunc(d time.Duration, f func()) tendermintCore.Timer {
+	if d < 0 {
+		d = 0
+	}
+	t := &timer{at: c.now.Add(d), seq: c.seq, f: f}
+	c.seq++
+	heap.Push(&c.timers, t)
+	return t
+}
+
+// Step runs the next function due at deadline at the latest and advances the
+// clock to its due time. It returns false if there is none.
+func (c *Clock) Step(deadline time.Time) bool {
+	for len(c.timers) > 0 {
+		t := c.timers[0]
+		if t.at.After(deadline) {
+			return false
+		}
+		heap.Pop(&c.timers)
+		if t.done {
+			continue
+		}
+		t.done = true
+		c.now = t.at
+		t.f()
+		return true
+	}
+	return false
+}
+
+// RunUntil runs the functions due at deadline at the latest and sets the clock to it.
+func (c *Clock) RunUntil(deadline time.Time) {
+	for c.Step(deadline) {
+	}
+	if deadline.After(c.now) {
+		c.now = deadline
+	}
+}
+
+// timer is a function scheduled by the virtual clock.
+type timer struct {
+	at   time.Time
+	seq  uint64
+	f    func()
+	done bool
+}
+
+// Stop implements core.Timer.
+func (t *timer) Stop() bool {
+	if t.done {
+		return false
+	}
+	t.done = true
+	return true
+}
+
+// timerQueue is a min-heap of timers by due time and scheduling order.
+type timerQueue []*timer
+
+func (q timerQueue) Len() int { return len(q) }
+
+func (q timerQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q timerQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *timerQueue) Push(x interface{}) { *q = append(*q, x.(*timer)) }
+
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return t
+}
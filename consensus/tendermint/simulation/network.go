@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Network delivers the messages between the nodes of a simulation. Each message
+// is delayed by the latency plus a random jitter, the messages whose delays
+// overlap being reordered, and may be lost. The nodes can be partitioned and
+// the settings overridden per link.
+type Network struct {
+	Latency  time.Duration // Minimum delay of a message
+	Jitter   time.Duration // Maximum random delay added to the latency
+	DropRate float64       // Probability of a message to be lost
+
+	links  map[route]Link
+	groups map[int]int
+}
+
+// Link overrides the network settings for the messages sent from a node to another.
+type Link struct {
+	Latency  time.Duration
+	Jitter   time.Duration
+	DropRate float64
+}
+
+type route struct {
+	from, to int
+}
+
+// SetLink overrides the settings of the messages sent by a node to another.
+func (n *Network) SetLink(from, to int, link Link) {
+	if n.links == nil {
+		n.links = make(map[route]Link)
+	}
+	n.links[route{from, to}] = link
+}
+
+// ResetLink restores the network settings for the messages sent by a node to another.
+func (n *Network) ResetLink(from, to int) {
+	delete(n.links, route{from, to})
+}
+
+// Partition splits the nodes into groups which can't reach each other, the nodes
+// not listed are isolated. The messages in flight are still delivered.
+func (n *Network) Partition(groups ...[]int) {
+	n.groups = make(map[int]int)
+	for i, group := range groups {
+		for _, node := range group {
+			n.groups[node] = i
+		}
+	}
+}
+
+// Heal removes the partition of the network.
+func (n *Network) Heal() {
+	n.groups = nil
+}
+
+// Connected returns whether a node can send messages to another.
+func (n *Network) Connected(from, to int) bool {
+	if n.groups == nil {
+		return true
+	}
+	g1, ok1 := n.groups[from]
+	g2, ok2 := n.groups[to]
+	return ok1 && ok2 && g1 == g2
+}
+
+// delay returns the delay of a message sent by a node to another, false if the
+// message is lost.
+func (n *Network) delay(rng *rand.Rand, from, to int) (time.Duration, bool) {
+	if !n.Connected(from, to) {
+		return 0, false
+	}
+	latency, jitter, dropRate := n.Latency, n.Jitter, n.DropRate
+	if link, ok := n.links[route{from, to}]; ok {
+		latency, jitter, dropRate = link.Latency, link.Jitter, link.DropRate
+	}
+	if dropRate > 0 && rng.Float64() < dropRate {
+		return 0, false
+	}
+	if jitter > 0 {
+		latency += time.Duration(rng.Int63n(int64(jitter) + 1))
+	}
+	return latency, true
+}
@@ -0,0 +1,254 @@
+package simulation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	tcrypto "github.com/clearmatics/autonity/consensus/tendermint/crypto"
+	"github.com/clearmatics/autonity/consensus/tendermint/events"
+	ethcore "github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/event"
+)
+
+// engine is the part of the Tendermint core driven by the simulation.
+type engine interface {
+	Init(ctx context.Context, unminedBlock *types.Block)
+	HandleEvent(ctx context.Context, ev interface{})
+	SetClock(clock tendermintCore.Clock)
+	Height() *big.Int
+	Round() int64
+	GetCurrentHeightMessages() []*tendermintCore.Message
+}
+
+// Node is a committee member of a simulation.
+type Node struct {
+	Index     int
+	Address   common.Address
+	Behaviour Behaviour // Honest if nil
+
+	key      *ecdsa.PrivateKey
+	sim      *Simulation
+	core     engine
+	chain    []*types.Block
+	evidence []*tendermintCore.MisbehaviourEvidence
+
+	known     map[common.Hash]bool   // messages received by the node
+	peerKnown []map[common.Hash]bool // messages known by each peer, as cached by the backend
+}
+
+// Sign signs data with the key of the node, as the backend does.
+func (n *Node) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), n.key)
+}
+
+// Head returns the last block committed by the node.
+func (n *Node) Head() *types.Block {
+	return n.chain[len(n.chain)-1]
+}
+
+// Evidence returns the misbehaviours detected by the node.
+func (n *Node) Evidence() []*tendermintCore.MisbehaviourEvidence {
+	return n.evidence
+}
+
+// honest returns whether the node follows the protocol.
+func (n *Node) honest() bool {
+	return n.Behaviour == nil
+}
+
+// gossip sends a message to the peers not known to have it.
+func (n *Node) gossip(payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+	for _, peer := range n.sim.nodes {
+		if peer == n || n.peerKnown[peer.Index][hash] {
+			continue
+		}
+		n.peerKnown[peer.Index][hash] = true
+		n.send(peer.Index, payload)
+	}
+}
+
+// send delivers a message of the node to a peer through the network.
+func (n *Node) send(to int, payload []byte) {
+	payloads := [][]byte{payload}
+	if n.Behaviour != nil {
+		payloads = n.Behaviour.Send(n, to, payload)
+	}
+	for _, p := range payloads {
+		delay, ok := n.sim.network.delay(n.sim.rng, n.Index, to)
+		if !ok {
+			continue
+		}
+		peer, from, p := n.sim.nodes[to], n.Index, p
+		n.sim.clock.AfterFunc(delay, func() {
+			peer.receive(from, p)
+		})
+	}
+}
+
+// receive hands a message from a peer to the core unless it was already received.
+func (n *Node) receive(from int, payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+	n.peerKnown[from][hash] = true
+	if n.known[hash] {
+		return
+	}
+	n.known[hash] = true
+	n.handle(events.MessageEvent{Payload: payload})
+}
+
+// post hands an event to the core once the current one is processed.
+func (n *Node) post(ev interface{}) {
+	n.sim.clock.AfterFunc(0, func() {
+		n.handle(ev)
+	})
+}
+
+func (n *Node) handle(ev interface{}) {
+	n.core.HandleEvent(n.sim.ctx, ev)
+}
+
+// nextBlock builds the block the node proposes on top of its chain.
+func (n *Node) nextBlock() *types.Block {
+	parent := n.Head()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Time:       uint64(n.sim.clock.Now().Unix()),
+		Coinbase:   n.Address,
+		MixDigest:  types.BFTDigest,
+		Committee:  parent.Header().Committee,
+	}
+	if err := tcrypto.SignHeader(header, n.key); err != nil {
+		panic(err)
+	}
+	return types.NewBlockWithHeader(header)
+}
+
+// append adds a block to the chain of the node, false if the block doesn't follow
+// the chain.
+func (n *Node) append(block *types.Block, round int64, synced bool) bool {
+	if block.NumberU64() != n.Head().NumberU64()+1 {
+		return false
+	}
+	n.chain = append(n.chain, block)
+	n.sim.commits = append(n.sim.commits, Commit{
+		Node:   n.Index,
+		Height: block.NumberU64(),
+		Round:  round,
+		Hash:   block.Hash(),
+		Time:   n.sim.clock.Now(),
+		Synced: synced,
+	})
+	return true
+}
+
+// newHeight moves the core of the node to the height following its chain.
+func (n *Node) newHeight() {
+	// The next block is ready before the core moves on, as a miner would have it.
+	unmined := n.nextBlock()
+	n.post(events.NewUnminedBlockEvent{NewUnminedBlock: *unmined})
+	n.post(events.CommitEvent{})
+}
+
+// backend implements the core backend of a simulated node.
+type backend struct {
+	*Node
+}
+
+func (b backend) Address() common.Address {
+	return b.Node.Address
+}
+
+func (b backend) AddSeal(block *types.Block) (*types.Block, error) {
+	header := block.Header()
+	if err := tcrypto.SignHeader(header, b.key); err != nil {
+		return nil, err
+	}
+	return block.WithSeal(header), nil
+}
+
+func (b backend) AskSync(*types.Header) {}
+
+func (b backend) Broadcast(_ context.Context, _ types.Committee, payload []byte) error {
+	b.known[crypto.Keccak256Hash(payload)] = true
+	b.gossip(payload)
+	b.post(events.MessageEvent{Payload: payload})
+	return nil
+}
+
+func (b backend) Commit(block *types.Block, round int64, _ [][]byte) error {
+	if b.append(block, round, false) {
+		b.newHeight()
+	}
+	return nil
+}
+
+func (b backend) GetContractABI() string {
+	return ""
+}
+
+func (b backend) Gossip(_ context.Context, _ types.Committee, payload []byte) {
+	b.gossip(payload)
+}
+
+func (b backend) KnownMsgHash() []common.Hash {
+	return nil
+}
+
+func (b backend) HandleUnhandledMsgs(context.Context) {}
+
+func (b backend) LastCommittedProposal() (*types.Block, common.Address) {
+	head := b.Head()
+	if head.NumberU64() == 0 {
+		return head, common.Address{}
+	}
+	proposer, _ := types.Ecrecover(head.Header())
+	return head, proposer
+}
+
+func (b backend) Post(ev interface{}) {
+	b.post(ev)
+}
+
+func (b backend) SetProposedBlockHash(common.Hash) {}
+
+func (b backend) Sign(data []byte) ([]byte, error) {
+	return b.Node.Sign(data)
+}
+
+func (b backend) StoreMisbehaviourEvidence(evidence *tendermintCore.MisbehaviourEvidence) {
+	b.evidence = append(b.evidence, evidence)
+}
+
+func (b backend) Subscribe(...interface{}) *event.TypeMuxSubscription {
+	return nil
+}
+
+func (b backend) SyncPeer(common.Address) {}
+
+func (b backend) VerifyProposal(block types.Block) (time.Duration, error) {
+	head := b.Head()
+	if block.ParentHash() != head.Hash() || block.NumberU64() != head.NumberU64()+1 {
+		return 0, errInvalidProposal
+	}
+	return 0, nil
+}
+
+func (b backend) WhiteList() []string {
+	return nil
+}
+
+func (b backend) BlockChain() *ethcore.BlockChain {
+	return nil
+}
+
+func (b backend) SetBlockchain(*ethcore.BlockChain) {}
+
+func (b backend) RemoveMessageFromLocalCache([]byte) {}
@@ -0,0 +1,272 @@
+// Package simulation runs the Tendermint core of a committee on a virtual clock
+// and a scripted network, without p2p nor blockchain. Each run is reproducible
+// from its seed: the nodes take turns on a single thread, the network delays and
+// losses being drawn from the seeded source. It is meant to test the safety and
+// the liveness of the consensus under adverse conditions, in a fraction of the
+// time a real network would take.
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+)
+
+var (
+	// errInvalidProposal is returned when a proposal doesn't extend the chain of the node.
+	errInvalidProposal = errors.New("proposal doesn't extend the chain")
+	// errNoNodes is returned when a simulation is configured without nodes.
+	errNoNodes = errors.New("no nodes to simulate")
+)
+
+// defaultSyncInterval is the period of the synchronisation of the nodes if unset.
+const defaultSyncInterval = 10 * time.Second
+
+// Config is the configuration of a simulation.
+type Config struct {
+	Nodes int   // Size of the committee
+	Seed  int64 // Seed of the keys, of the network delays and losses
+
+	// Tendermint configuration of the nodes, the proposers are taken in turn.
+	// The default configuration is used if unset.
+	Tendermint *config.Config
+
+	// Period at which the nodes import the blocks of their peers and, if they
+	// stalled, are sent again the messages of their height.
+	SyncInterval time.Duration
+}
+
+// Commit is a block committed by a node.
+type Commit struct {
+	Node   int
+	Height uint64
+	Round  int64 // Round of the decision, zero if synced
+	Hash   common.Hash
+	Time   time.Time
+	Synced bool // Whether the block was imported from a peer
+}
+
+// Simulation is a committee running the Tendermint consensus.
+type Simulation struct {
+	ctx          context.Context
+	rng          *rand.Rand
+	clock        *Clock
+	network      *Network
+	nodes        []*Node
+	syncInterval time.Duration
+	started      bool
+	commits      []Commit
+	views        []view
+}
+
+// view is the height and round of a node.
+type view struct {
+	height uint64
+	round  int64
+}
+
+// New creates a simulation whose nodes are at the genesis block.
+func New(cfg Config) (*Simulation, error) {
+	if cfg.Nodes <= 0 {
+		return nil, errNoNodes
+	}
+	tendermintConfig := config.DefaultConfig()
+	if cfg.Tendermint != nil {
+		c := *cfg.Tendermint
+		tendermintConfig = &c
+	}
+	tendermintConfig.ProposerPolicy = config.RoundRobin
+	syncInterval := cfg.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = defaultSyncInterval
+	}
+
+	sim := &Simulation{
+		ctx:          context.Background(),
+		rng:          rand.New(rand.NewSource(cfg.Seed)),
+		clock:        NewClock(time.Unix(0, 0).UTC()),
+		network:      new(Network),
+		syncInterval: syncInterval,
+		views:        make([]view, cfg.Nodes),
+	}
+
+	committee := make(types.Committee, 0, cfg.Nodes)
+	for i := 0; i < cfg.Nodes; i++ {
+		key := sim.generateKey()
+		node := &Node{
+			Index:     i,
+			Address:   crypto.PubkeyToAddress(key.PublicKey),
+			key:       key,
+			sim:       sim,
+			known:     make(map[common.Hash]bool),
+			peerKnown: make([]map[common.Hash]bool, cfg.Nodes),
+		}
+		for j := range node.peerKnown {
+			node.peerKnown[j] = make(map[common.Hash]bool)
+		}
+		sim.nodes = append(sim.nodes, node)
+		committee = append(committee, types.CommitteeMember{Address: node.Address, VotingPower: common.Big1})
+	}
+	sort.Sort(committee)
+
+	genesis := types.NewBlockWithHeader(&types.Header{
+		Number:    common.Big0,
+		MixDigest: types.BFTDigest,
+		Committee: committee,
+	})
+	for _, node := range sim.nodes {
+		node.chain = []*types.Block{genesis}
+		node.core = tendermintCore.New(backend{node}, tendermintConfig)
+		node.core.SetClock(sim.clock)
+	}
+	return sim, nil
+}
+
+// generateKey derives a key from the seeded source.
+func (s *Simulation) generateKey() *ecdsa.PrivateKey {
+	for {
+		seed := make([]byte, 32)
+		s.rng.Read(seed)
+		if key, err := crypto.ToECDSA(seed); err == nil {
+			return key
+		}
+	}
+}
+
+// Network returns the network of the simulation, its settings can be changed at
+// any time.
+func (s *Simulation) Network() *Network {
+	return s.network
+}
+
+// Node returns the node of the given index.
+func (s *Simulation) Node(i int) *Node {
+	return s.nodes[i]
+}
+
+// Nodes returns the nodes of the simulation.
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Now returns the virtual time elapsed since the start of the simulation.
+func (s *Simulation) Now() time.Duration {
+	return s.clock.Now().Sub(time.Unix(0, 0))
+}
+
+// At calls f once the virtual time reaches t, e.g. to partition the network.
+func (s *Simulation) At(t time.Duration, f func()) {
+	s.clock.AfterFunc(t-s.Now(), f)
+}
+
+// Run advances the simulation by d. The nodes start the consensus on the first run.
+func (s *Simulation) Run(d time.Duration) {
+	deadline := s.clock.Now().Add(d)
+	if !s.started {
+		s.started = true
+		for _, node := range s.nodes {
+			node.core.Init(s.ctx, node.nextBlock())
+		}
+		s.clock.AfterFunc(s.syncInterval, s.sync)
+	}
+	s.clock.RunUntil(deadline)
+}
+
+// Commits returns the blocks committed by the nodes, in order.
+func (s *Simulation) Commits() []Commit {
+	return s.commits
+}
+
+// Height returns the number of the last block committed by a node.
+func (s *Simulation) Height(i int) uint64 {
+	return s.nodes[i].Head().NumberU64()
+}
+
+// CheckSafety returns an error if two honest nodes committed different blocks
+// at the same height.
+func (s *Simulation) CheckSafety() error {
+	decided := make(map[uint64]Commit)
+	for _, commit := range s.commits {
+		if !s.nodes[commit.Node].honest() {
+			continue
+		}
+		if other, ok := decided[commit.Height]; ok && other.Hash != commit.Hash {
+			return fmt.Errorf("conflicting commits at height %d: node %d committed %s, node %d committed %s",
+				commit.Height, other.Node, other.Hash.TerminalString(), commit.Node, commit.Hash.TerminalString())
+		}
+		decided[commit.Height] = commit
+	}
+	return nil
+}
+
+// CheckLiveness returns an error if an honest node didn't reach the height.
+func (s *Simulation) CheckLiveness(height uint64) error {
+	for _, node := range s.nodes {
+		if node.honest() && s.Height(node.Index) < height {
+			return fmt.Errorf("node %d is at height %d, expected %d", node.Index, s.Height(node.Index), height)
+		}
+	}
+	return nil
+}
+
+// sync imports the blocks a node is missing from its peers and resends their
+// messages to the nodes whose view didn't change since the last period, as the
+// sync of the backend does.
+func (s *Simulation) sync() {
+	for _, node := range s.nodes {
+		head := node.Head().NumberU64()
+		for _, peer := range s.nodes {
+			if peer == node || !s.network.Connected(node.Index, peer.Index) || !s.network.Connected(peer.Index, node.Index) {
+				continue
+			}
+			for node.Head().NumberU64() < peer.Head().NumberU64() {
+				node.append(peer.chain[node.Head().NumberU64()+1], 0, true)
+			}
+		}
+		if node.Head().NumberU64() > head {
+			node.newHeight()
+		}
+	}
+
+	for _, node := range s.nodes {
+		current := view{height: node.core.Height().Uint64(), round: node.core.Round()}
+		stalled := current == s.views[node.Index]
+		s.views[node.Index] = current
+		if !stalled {
+			continue
+		}
+		for _, peer := range s.nodes {
+			if peer == node || !s.network.Connected(peer.Index, node.Index) || peer.core.Height().Cmp(node.core.Height()) != 0 {
+				continue
+			}
+			for _, payload := range sortedPayloads(peer.core.GetCurrentHeightMessages()) {
+				peer.send(node.Index, payload)
+			}
+		}
+	}
+	s.clock.AfterFunc(s.syncInterval, s.sync)
+}
+
+// sortedPayloads returns the payloads of the messages by hash, the messages of a
+// height being kept in a map.
+func sortedPayloads(messages []*tendermintCore.Message) [][]byte {
+	payloads := make([][]byte, 0, len(messages))
+	for _, msg := range messages {
+		payloads = append(payloads, msg.Payload())
+	}
+	sort.Slice(payloads, func(i, j int) bool {
+		return bytes.Compare(crypto.Keccak256(payloads[i]), crypto.Keccak256(payloads[j])) < 0
+	})
+	return payloads
+}
@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSimulation(t *testing.T, nodes int, seed int64) *Simulation {
+	sim, err := New(Config{Nodes: nodes, Seed: seed})
+	require.NoError(t, err)
+	sim.Network().Latency = 50 * time.Millisecond
+	sim.Network().Jitter = 100 * time.Millisecond
+	return sim
+}
+
+func TestSimulationDeterminism(t *testing.T) {
+	run := func() []Commit {
+		sim := newTestSimulation(t, 4, 42)
+		sim.Network().DropRate = 0.05
+		sim.Run(30 * time.Second)
+		return sim.Commits()
+	}
+	first := run()
+	require.NotEmpty(t, first)
+	assert.Equal(t, first, run())
+}
+
+func TestSimulationHonest(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		sim := newTestSimulation(t, 4, seed)
+		sim.Network().DropRate = 0.1
+		sim.Run(30 * time.Second)
+		require.NoError(t, sim.CheckSafety(), "seed %d", seed)
+		require.NoError(t, sim.CheckLiveness(10), "seed %d", seed)
+	}
+}
+
+func TestSimulationPartition(t *testing.T) {
+	sim := newTestSimulation(t, 4, 1)
+	sim.At(10*time.Second, func() {
+		sim.Network().Partition([]int{0, 1}, []int{2, 3})
+	})
+	sim.Run(15 * time.Second)
+	height := sim.Height(0)
+	for i := range sim.Nodes() {
+		assert.True(t, sim.Height(i) <= height+1)
+	}
+
+	// No side has a quorum.
+	sim.Run(30 * time.Second)
+	for i := range sim.Nodes() {
+		assert.True(t, sim.Height(i) <= height+1)
+	}
+
+	sim.Network().Heal()
+	sim.Run(30 * time.Second)
+	require.NoError(t, sim.CheckSafety())
+	require.NoError(t, sim.CheckLiveness(height+5))
+}
+
+func TestSimulationEquivocation(t *testing.T) {
+	sim := newTestSimulation(t, 4, 7)
+	sim.Node(0).Behaviour = Equivocate{}
+	sim.Run(30 * time.Second)
+	require.NoError(t, sim.CheckSafety())
+	require.NoError(t, sim.CheckLiveness(10))
+	for _, node := range sim.Nodes()[1:] {
+		require.NotEmpty(t, node.Evidence(), "node %d", node.Index)
+		assert.Equal(t, sim.Node(0).Address, node.Evidence()[0].Offender)
+	}
+}
+
+func TestSimulationSilent(t *testing.T) {
+	t.Run("a faulty node doesn't stall the committee", func(t *testing.T) {
+		sim := newTestSimulation(t, 4, 3)
+		sim.Node(2).Behaviour = Silent{}
+		sim.Run(30 * time.Second)
+		require.NoError(t, sim.CheckSafety())
+		require.NoError(t, sim.CheckLiveness(10))
+	})
+
+	t.Run("no block is committed without a quorum", func(t *testing.T) {
+		sim := newTestSimulation(t, 4, 3)
+		sim.Node(1).Behaviour = Silent{}
+		sim.Node(2).Behaviour = Silent{}
+		sim.Run(30 * time.Second)
+		assert.Empty(t, sim.Commits())
+	})
+}
+
+func TestClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewClock(start)
+	var calls []int
+	clock.AfterFunc(2*time.Second, func() { calls = append(calls, 2) })
+	clock.AfterFunc(time.Second, func() {
+		calls = append(calls, 1)
+		clock.AfterFunc(0, func() { calls = append(calls, 3) })
+	})
+	stopped := clock.AfterFunc(time.Second, func() { calls = append(calls, 4) })
+	clock.AfterFunc(time.Second, func() { calls = append(calls, 5) })
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.RunUntil(start.Add(1500 * time.Millisecond))
+	assert.Equal(t, []int{1, 5, 3}, calls)
+	assert.Equal(t, start.Add(1500*time.Millisecond), clock.Now())
+
+	clock.RunUntil(start.Add(time.Hour))
+	assert.Equal(t, []int{1, 5, 3, 2}, calls)
+}
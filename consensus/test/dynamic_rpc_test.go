@@ -31,6 +31,7 @@ var (
 		"aut_getMinimumGasPrice",
 		"aut_getProposer",
 		"aut_dumpEconomicMetrics",
+		"aut_dumpState",
 	}
 )
 
@@ -79,6 +80,48 @@ func TestDynamicRpcs(t *testing.T) {
 	runTest(t, tc)
 }
 
+// This test checks that the dynamic rpcs and the state dump can be queried at
+// a past block as well as at the latest one.
+func TestHistoricalRpcs(t *testing.T) {
+	tc := &testCase{
+		numValidators: 1,
+		numBlocks:     2,
+		finalAssert: func(t *testing.T, validators map[string]*testNode) {
+			n := validators["VA"]
+			validatorAddress := n.EthAddress().String()
+			ep := n.node.HTTPEndpoint()
+			calls := []rpcCall{
+				{Method: "aut_getMinimumGasPrice", Params: []interface{}{"0x0"}},
+				{Method: "aut_getMinimumGasPrice", Params: []interface{}{"latest"}},
+				{Method: "aut_getUser", Params: []interface{}{validatorAddress, "0x1"}},
+				{Method: "aut_dumpState", Params: []interface{}{"0x0"}},
+				{Method: "aut_dumpState", Params: []interface{}{"latest"}},
+			}
+			for _, body := range calls {
+				body.Jsonrpc = "2.0"
+				body.Id = 1
+				payload, err := json.Marshal(body)
+				require.NoError(t, err)
+				respBytes := callRPC(t, ep, payload)
+				responseMap := make(map[string]interface{})
+				err = json.Unmarshal(respBytes, &responseMap)
+				require.NoError(t, err)
+
+				assert.NotNil(t, responseMap["result"], body.Method)
+				assert.Nil(t, responseMap["error"], body.Method)
+			}
+
+			// A block that doesn't exist yet can't be queried.
+			payload, err := json.Marshal(&rpcCall{Method: "aut_dumpState", Params: []interface{}{"0x1000"}, Jsonrpc: "2.0", Id: 1})
+			require.NoError(t, err)
+			responseMap := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(callRPC(t, ep, payload), &responseMap))
+			assert.NotNil(t, responseMap["error"])
+		},
+	}
+	runTest(t, tc)
+}
+
 type rpcCall struct {
 	Jsonrpc string      `json:"jsonrpc,omitempty"` // nolint
 	Method  string      `json:"method,omitempty"`
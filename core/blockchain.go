@@ -41,6 +41,7 @@ import (
 	"github.com/clearmatics/autonity/core/state"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/core/vm"
+	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/ethdb"
 	"github.com/clearmatics/autonity/event"
 	"github.com/clearmatics/autonity/log"
@@ -306,22 +307,23 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 
 		acConfig := bc.Config().AutonityContractConfig
 
-		var JSONString = acConfig.ABI
-		bytes, err := bc.GetKeyValue([]byte(autonity.ABISPEC))
-		if err == nil || bytes != nil {
-			JSONString = string(bytes)
+		// Nodes upgraded before the contract ABIs were versioned per height only
+		// kept the latest one.
+		if numbers, _ := rawdb.ReadContractABIs(bc.db); len(numbers) == 0 {
+			if legacyABI, err := bc.GetKeyValue([]byte(autonity.ABISPEC)); err == nil {
+				bc.migrateContractABI([]byte(acConfig.ABI), legacyABI)
+			}
 		}
 		contract, err := autonity.NewAutonityContract(
 			bc,
 			acConfig.Operator,
 			acConfig.MinGasPrice,
-			JSONString,
+			acConfig.ABI,
 			&defaultEVMProvider{bc},
 		)
 		if err != nil {
 			return nil, err
 		}
-
 		bc.autonityContract = contract
 		bc.processor.SetAutonityContract(bc.autonityContract)
 	}
@@ -1484,15 +1486,20 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	localTd := bc.GetTd(currentBlock.Hash(), currentBlock.NumberU64())
 	externTd := new(big.Int).Add(block.Difficulty(), ptd)
 
+	// The upgrade of the autonity contract applied by the block is in use from its
+	// state, it is only persisted once the block is canonical.
+	var upgrade *autonity.Upgrade
 	if bc.chainConfig.Tendermint != nil {
+		upgrade = bc.appliedContractUpgrade(block, state)
+
 		// Call network permissioning logic before committing the state
-		err = bc.GetAutonityContract().UpdateEnodesWhitelist(state, block)
+		err = bc.GetAutonityContract().UpdateEnodesWhitelist(state, block, upgrade)
 		if err != nil && err != autonity.ErrAutonityContract {
 			return NonStatTy, err
 		}
 
 		// Measure network economic metrics.
-		err := bc.GetAutonityContract().MeasureMetricsOfNetworkEconomic(block.Header(), state)
+		err := bc.GetAutonityContract().MeasureMetricsOfNetworkEconomic(block.Header(), state, upgrade)
 		if err != nil {
 			panic(err)
 		}
@@ -1601,6 +1608,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.writeHeadBlock(block)
+		if upgrade != nil {
+			bc.autonityContract.CommitUpgrade(block.Header(), state, upgrade)
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 
@@ -2408,7 +2418,22 @@ func (bc *BlockChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (i
 	if i, err := bc.hc.ValidateHeaderChain(chain, checkFreq); err != nil {
 		return i, err
 	}
+	return bc.insertHeaderChain(chain, start)
+}
+
+// InsertTrustedHeaderChain is InsertHeaderChain for headers authenticated by
+// their hash linkage to trusted headers, as the ones between the committee
+// hand-offs verified by the downloader. The engine may skip their seals.
+func (bc *BlockChain) InsertTrustedHeaderChain(chain []*types.Header) (int, error) {
+	start := time.Now()
+	if i, err := bc.hc.ValidateTrustedHeaderChain(chain); err != nil {
+		return i, err
+	}
+	return bc.insertHeaderChain(chain, start)
+}
 
+// insertHeaderChain writes the validated headers of chain.
+func (bc *BlockChain) insertHeaderChain(chain []*types.Header, start time.Time) (int, error) {
 	// Make sure only one thread manipulates the chain at once
 	bc.chainmu.Lock()
 	defer bc.chainmu.Unlock()
@@ -2548,7 +2573,7 @@ func (bc *BlockChain) UpdateEnodeWhitelist(newWhitelist *types.Nodes) {
 	bc.wg.Add(1)
 	go func() {
 		defer bc.wg.Done()
-		bc.autonityFeed.Send(WhitelistEvent{Whitelist: newWhitelist.List})
+		bc.autonityFeed.Send(WhitelistEvent{Whitelist: newWhitelist.List, Roles: newWhitelist.Roles})
 	}()
 }
 
@@ -2564,6 +2589,111 @@ func (bc *BlockChain) GetKeyValue(key []byte) ([]byte, error) {
 	return rawdb.GetKeyValue(bc.db, key)
 }
 
+// appliedContractUpgrade returns the upgrade of the autonity contract applied by
+// block, processed with the given state, nil if there is none.
+func (bc *BlockChain) appliedContractUpgrade(block *types.Block, statedb *state.StateDB) *autonity.Upgrade {
+	if bc.autonityContract == nil {
+		return nil
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil
+	}
+	parentState, err := bc.StateAt(parent.Root)
+	if err != nil {
+		log.Error("Could not read the state of the parent block", "number", parent.Number, "err", err)
+		return nil
+	}
+	upgrade, err := bc.autonityContract.AppliedUpgrade(block.Header(), parentState, statedb)
+	if err != nil {
+		log.Error("Could not read the autonity contract upgrade", "number", block.Number(), "err", err)
+	}
+	return upgrade
+}
+
+// contractUpgradedTopic is the topic of the event logged by the autonity contract
+// when an upgrade is initiated, the upgrade being applied by the finalize of the
+// same block.
+var contractUpgradedTopic = crypto.Keccak256Hash([]byte("ContractUpgraded(string)"))
+
+// migrateContractABI stores the ABIs of the autonity contract per height from the
+// single legacy ABI kept by the nodes predating their versioning, which is the one
+// of the last upgrade. The heights of the upgrades are found from the logs of the
+// canonical chain. The ABIs of the upgrades before the last one are lost, the
+// heights from the first upgrade until the last one are left unresolved.
+func (bc *BlockChain) migrateContractABI(genesisABI, legacyABI []byte) {
+	var upgrades []uint64
+	for number := bc.CurrentBlock().NumberU64(); number > 0; number-- {
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		header := rawdb.ReadHeader(bc.db, hash, number)
+		if header == nil {
+			continue
+		}
+		if !types.BloomLookup(header.Bloom, autonity.ContractAddress) || !types.BloomLookup(header.Bloom, contractUpgradedTopic) {
+			continue
+		}
+		for _, receipt := range rawdb.ReadRawReceipts(bc.db, hash, number) {
+			if hasContractUpgradedLog(receipt.Logs) {
+				upgrades = append(upgrades, number)
+				break
+			}
+		}
+	}
+
+	rawdb.WriteContractABI(bc.db, 0, genesisABI)
+	if len(upgrades) == 0 {
+		// the receipts are missing, the ABI is only known from the head block.
+		head := bc.CurrentBlock().NumberU64()
+		log.Warn("Migrating the upgraded Autonity contract ABI without its height", "unresolved", 1, "from", head)
+		if head > 1 {
+			rawdb.WriteContractABI(bc.db, 1, nil)
+		}
+		rawdb.WriteContractABI(bc.db, head, legacyABI)
+		return
+	}
+	first, last := upgrades[len(upgrades)-1], upgrades[0]
+	if first < last {
+		rawdb.WriteContractABI(bc.db, first, nil)
+	}
+	rawdb.WriteContractABI(bc.db, last, legacyABI)
+	log.Warn("Migrated the upgraded Autonity contract ABI", "unresolved", first, "from", last)
+}
+
+// hasContractUpgradedLog returns whether an upgrade of the autonity contract is
+// initiated in logs.
+func hasContractUpgradedLog(logs []*types.Log) bool {
+	for _, l := range logs {
+		if l.Address == autonity.ContractAddress && len(l.Topics) > 0 && l.Topics[0] == contractUpgradedTopic {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteContractUpgrade stores the record of the autonity contract upgrade
+// applied at the given height.
+func (bc *BlockChain) WriteContractUpgrade(number uint64, upgrade []byte) {
+	rawdb.WriteContractUpgrade(bc.db, number, upgrade)
+}
+
+// WriteContractABI stores the autonity contract ABI in use from the state of the
+// given height onwards.
+func (bc *BlockChain) WriteContractABI(number uint64, abi []byte) {
+	rawdb.WriteContractABI(bc.db, number, abi)
+}
+
+// ReadContractABIs returns the autonity contract ABIs with the heights they are
+// in use from, in ascending height order.
+func (bc *BlockChain) ReadContractABIs() ([]uint64, [][]byte) {
+	return rawdb.ReadContractABIs(bc.db)
+}
+
+// ReadContractUpgrades returns the records of the autonity contract upgrades in
+// ascending height order.
+func (bc *BlockChain) ReadContractUpgrades() [][]byte {
+	return rawdb.ReadContractUpgrades(bc.db)
+}
+
 func (bc *BlockChain) GetMinGasPrice(blockNumber ...uint64) (*big.Int, error) {
 	if bc.autonityContract == nil {
 		return nil, errors.New("the autonity contract is not specified")
@@ -22,10 +22,12 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/clearmatics/autonity/autonity"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/consensus"
 	"github.com/clearmatics/autonity/consensus/ethash"
@@ -3029,3 +3031,46 @@ func TestInitThenFailCreateContract(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the single ABI of the contract upgraded before the ABIs were
+// versioned per height is migrated from the height of the last upgrade, the range
+// from the first upgrade being left unresolved.
+func TestMigrateContractABI(t *testing.T) {
+	genesisABI, legacyABI := []byte("genesis"), []byte("legacy")
+	tests := []struct {
+		upgrades []uint64
+		numbers  []uint64
+		abis     [][]byte
+	}{
+		{upgrades: []uint64{4}, numbers: []uint64{0, 4}, abis: [][]byte{genesisABI, legacyABI}},
+		{upgrades: []uint64{3, 7}, numbers: []uint64{0, 3, 7}, abis: [][]byte{genesisABI, nil, legacyABI}},
+		{upgrades: nil, numbers: []uint64{0, 1, 10}, abis: [][]byte{genesisABI, nil, legacyABI}},
+	}
+	for i, tt := range tests {
+		_, chain, err := newCanonical(ethash.NewFaker(), 0, true)
+		if err != nil {
+			t.Fatalf("test %d: failed to create tester chain: %v", i, err)
+		}
+		parent := chain.Genesis().Header()
+		for number := uint64(1); number <= 10; number++ {
+			receipts := types.Receipts{{Logs: []*types.Log{{Address: autonity.ContractAddress}}}}
+			for _, upgrade := range tt.upgrades {
+				if upgrade == number {
+					receipts[0].Logs[0].Topics = []common.Hash{contractUpgradedTopic}
+				}
+			}
+			header := &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(1), Bloom: types.CreateBloom(receipts)}
+			rawdb.WriteHeader(chain.db, header)
+			rawdb.WriteCanonicalHash(chain.db, header.Hash(), number)
+			rawdb.WriteReceipts(chain.db, header.Hash(), number, receipts)
+			parent = header
+		}
+		chain.currentBlock.Store(types.NewBlockWithHeader(parent))
+
+		chain.migrateContractABI(genesisABI, legacyABI)
+		numbers, abis := rawdb.ReadContractABIs(chain.db)
+		if !reflect.DeepEqual(numbers, tt.numbers) || !reflect.DeepEqual(abis, tt.abis) {
+			t.Errorf("test %d: migrated abis mismatch: have %v %q, want %v %q", i, numbers, abis, tt.numbers, tt.abis)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
 )
 
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
@@ -43,5 +44,9 @@ type ChainSideEvent struct {
 
 type ChainHeadEvent struct{ Block *types.Block }
 
-// WhitelistEvent is posted when the list of authorized enodes is updated.
-type WhitelistEvent struct{ Whitelist []*enode.Node }
+// WhitelistEvent is posted when the list of authorized enodes is updated, along
+// with the user type of each enode in the autonity contract.
+type WhitelistEvent struct {
+	Whitelist []*enode.Node
+	Roles     map[enode.ID]params.UserType
+}
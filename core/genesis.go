@@ -394,13 +394,15 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 
 	if g.Config.AutonityContractConfig != nil {
 		enodes := make([]string, 0, len(g.Config.AutonityContractConfig.Users))
+		roles := make([]params.UserType, 0, len(g.Config.AutonityContractConfig.Users))
 		for _, v := range g.Config.AutonityContractConfig.Users {
 			if v.Enode != "" {
 				enodes = append(enodes, v.Enode)
+				roles = append(roles, v.Type)
 			}
 		}
 
-		rawdb.WriteEnodeWhitelist(db, types.NewNodes(enodes))
+		rawdb.WriteEnodeWhitelist(db, types.NewNodesWithRoles(enodes, roles))
 	}
 	rawdb.WriteChainConfig(db, block.Hash(), g.Config)
 	return block, nil
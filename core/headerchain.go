@@ -232,6 +232,41 @@ func (hc *HeaderChain) WriteHeader(header *types.Header) (status WriteStatus, er
 type WhCallback func(*types.Header) error
 
 func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int) (int, error) {
+	return hc.validateHeaderChain(chain, func() (chan<- struct{}, <-chan error) {
+		// Generate the list of seal verification requests, and start the parallel verifier
+		seals := make([]bool, len(chain))
+		if checkFreq != 0 {
+			// In case of checkFreq == 0 all seals are left false.
+			for i := 0; i < len(seals)/checkFreq; i++ {
+				index := i*checkFreq + hc.rand.Intn(checkFreq)
+				if index >= len(seals) {
+					index = len(seals) - 1
+				}
+				seals[index] = true
+			}
+			// Last should always be verified to avoid junk.
+			seals[len(seals)-1] = true
+		}
+		return hc.engine.VerifyHeaders(hc, chain, seals)
+	})
+}
+
+// ValidateTrustedHeaderChain is ValidateHeaderChain for headers authenticated by
+// their hash linkage to trusted headers, whose seals are not verified if the engine
+// supports it. All the seals are verified otherwise.
+func (hc *HeaderChain) ValidateTrustedHeaderChain(chain []*types.Header) (int, error) {
+	verifier, ok := hc.engine.(consensus.TrustedHeaderVerifier)
+	if !ok {
+		return hc.ValidateHeaderChain(chain, 1)
+	}
+	return hc.validateHeaderChain(chain, func() (chan<- struct{}, <-chan error) {
+		return verifier.VerifyTrustedHeaders(hc, chain)
+	})
+}
+
+// validateHeaderChain checks that chain is linked and verifies its headers with
+// the verifier started by verify.
+func (hc *HeaderChain) validateHeaderChain(chain []*types.Header, verify func() (chan<- struct{}, <-chan error)) (int, error) {
 	// Do a sanity check that the provided chain is actually ordered and linked
 	for i := 1; i < len(chain); i++ {
 		if chain[i].Number.Uint64() != chain[i-1].Number.Uint64()+1 || chain[i].ParentHash != chain[i-1].Hash() {
@@ -244,22 +279,7 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int)
 		}
 	}
 
-	// Generate the list of seal verification requests, and start the parallel verifier
-	seals := make([]bool, len(chain))
-	if checkFreq != 0 {
-		// In case of checkFreq == 0 all seals are left false.
-		for i := 0; i < len(seals)/checkFreq; i++ {
-			index := i*checkFreq + hc.rand.Intn(checkFreq)
-			if index >= len(seals) {
-				index = len(seals) - 1
-			}
-			seals[index] = true
-		}
-		// Last should always be verified to avoid junk.
-		seals[len(seals)-1] = true
-	}
-
-	abort, results := hc.engine.VerifyHeaders(hc, chain, seals)
+	abort, results := verify()
 	defer close(abort)
 
 	// Iterate over the headers and ensure they all check out
@@ -56,9 +56,16 @@ func WriteCanonicalHash(db ethdb.KeyValueWriter, hash common.Hash, number uint64
 	}
 }
 
-// WriteEnodeWhitelist stores the list of permitted enodes
+// storedWhitelist is the RLP encoding of the enode whitelist, the user types
+// being in the order of the enodes. Older databases only have the enodes.
+type storedWhitelist struct {
+	Enodes []string
+	Roles  []params.UserType
+}
+
+// WriteEnodeWhitelist stores the list of permitted enodes and their user types
 func WriteEnodeWhitelist(db ethdb.KeyValueWriter, whitelist *types.Nodes) {
-	bytes, err := rlp.EncodeToBytes(whitelist.StrList)
+	bytes, err := rlp.EncodeToBytes(storedWhitelist{Enodes: whitelist.StrList, Roles: whitelist.RoleList()})
 	if err != nil {
 		log.Crit("Failed to RLP encode enode whitelist", "err", err)
 	}
@@ -69,19 +76,23 @@ func WriteEnodeWhitelist(db ethdb.KeyValueWriter, whitelist *types.Nodes) {
 
 // ReadEnodeWhitelist retrieve the list of permitted enodes
 func ReadEnodeWhitelist(db ethdb.KeyValueReader) *types.Nodes {
-	var strList []string
+	var stored storedWhitelist
 	nodes := &types.Nodes{List: make([]*enode.Node, 0)}
 
 	data, _ := db.Get(enodeWhiteList)
 	if len(data) == 0 {
 		return nodes
 	}
-	if err := rlp.Decode(bytes.NewReader(data), &strList); err != nil {
-		log.Error("Invalid Enode whitelist", "err", err)
-		return nodes
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		var strList []string
+		if err := rlp.Decode(bytes.NewReader(data), &strList); err != nil {
+			log.Error("Invalid Enode whitelist", "err", err)
+			return nodes
+		}
+		return types.NewNodes(strList)
 	}
 
-	nodes = types.NewNodes(strList)
+	nodes = types.NewNodesWithRoles(stored.Enodes, stored.Roles)
 	return nodes
 }
 
@@ -107,6 +118,59 @@ func GetKeyValue(db ethdb.KeyValueReader, key []byte) ([]byte, error) {
 	return bytes, nil
 }
 
+// WriteContractUpgrade stores the RLP encoded record of the autonity contract
+// upgrade applied at the given height.
+func WriteContractUpgrade(db ethdb.KeyValueWriter, number uint64, upgrade []byte) {
+	if err := db.Put(contractUpgradeKey(number), upgrade); err != nil {
+		log.Crit("Failed to store contract upgrade", "err", err)
+	}
+}
+
+// WriteContractABI stores the autonity contract ABI in use from the state of the
+// given height onwards. An empty ABI is unknown.
+func WriteContractABI(db ethdb.KeyValueWriter, number uint64, abi []byte) {
+	if err := db.Put(contractABIKey(number), abi); err != nil {
+		log.Crit("Failed to store contract abi", "err", err)
+	}
+}
+
+// ReadContractABIs retrieves the autonity contract ABIs with the heights they are
+// in use from, in ascending height order.
+func ReadContractABIs(db ethdb.Iteratee) ([]uint64, [][]byte) {
+	var (
+		numbers []uint64
+		abis    [][]byte
+	)
+	it := db.NewIterator(contractABIPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(contractABIPrefix)+8 {
+			continue
+		}
+		numbers = append(numbers, binary.BigEndian.Uint64(it.Key()[len(contractABIPrefix):]))
+		abis = append(abis, common.CopyBytes(it.Value()))
+	}
+	return numbers, abis
+}
+
+// ReadContractUpgrades retrieves the RLP encoded records of the autonity contract
+// upgrades in ascending height order.
+func ReadContractUpgrades(db ethdb.Iteratee) [][]byte {
+	var upgrades [][]byte
+
+	it := db.NewIterator(contractUpgradePrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(contractUpgradePrefix)+8 {
+			continue
+		}
+		upgrades = append(upgrades, common.CopyBytes(it.Value()))
+	}
+	return upgrades
+}
+
 // DeleteCanonicalHash removes the number to hash canonical mapping.
 func DeleteCanonicalHash(db ethdb.KeyValueWriter, number uint64) {
 	if err := db.Delete(headerHashKey(number)); err != nil {
@@ -384,6 +448,10 @@ func HasHeader(db ethdb.Reader, hash common.Hash, number uint64) bool {
 // ReadHeader retrieves the block header corresponding to the hash.
 func ReadHeader(db ethdb.Reader, hash common.Hash, number uint64) *types.Header {
 	if _, header := ReadHeaderRLP(db, hash, number); header != nil {
+		// the headers inside an epoch only carry the hash of the committee
+		if header.CommitteeHash != (common.Hash{}) && len(header.Committee) == 0 {
+			header.Committee = ReadCommittee(db, header.CommitteeHash)
+		}
 		return header
 	}
 	return nil
@@ -408,6 +476,11 @@ func WriteHeader(db ethdb.KeyValueWriter, header *types.Header) {
 	if err := db.Put(key, data); err != nil {
 		log.Crit("Failed to store header", "err", err)
 	}
+	// Store the committee of the headers carrying it, for the following headers
+	// of the epoch to be read back with their committee.
+	if header.CommitteeHash == (common.Hash{}) && len(header.Committee) > 0 {
+		WriteCommittee(db, header.Committee)
+	}
 }
 
 // DeleteHeader removes all block header data associated with a hash.
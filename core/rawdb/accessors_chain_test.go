@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"os"
 	"reflect"
 	"testing"
 
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/p2p/enode"
 	"github.com/clearmatics/autonity/params"
 	"github.com/clearmatics/autonity/rlp"
 	"github.com/davecgh/go-spew/spew"
@@ -498,3 +501,72 @@ func TestCanonicalHashIteration(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the headers inside an epoch are read back with the committee
+// stored by their epoch header.
+func TestEpochHeaderStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	committee := types.Committee{{Address: common.HexToAddress("0x1"), VotingPower: big.NewInt(7)}}
+	epoch := &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(1), MixDigest: types.BFTDigest, Committee: committee}
+	header := &types.Header{Number: big.NewInt(11), Difficulty: big.NewInt(1), MixDigest: types.BFTDigest, ParentHash: epoch.Hash(), CommitteeHash: committee.Hash()}
+
+	if entry := ReadCommittee(db, committee.Hash()); entry != nil {
+		t.Fatalf("Non existent committee returned: %v", entry)
+	}
+	WriteHeader(db, epoch)
+	WriteHeader(db, header)
+	if entry := ReadCommittee(db, committee.Hash()); !reflect.DeepEqual(entry, committee) {
+		t.Fatalf("Committee mismatch: have %v, want %v", entry, committee)
+	}
+	entry := ReadHeader(db, header.Hash(), 11)
+	if entry == nil {
+		t.Fatalf("Stored header not found")
+	}
+	if entry.Hash() != header.Hash() || !reflect.DeepEqual(entry.Committee, committee) {
+		t.Fatalf("Retrieved header mismatch: have %v, want committee %v", entry, committee)
+	}
+}
+
+// Tests that the enode whitelist keeps the user types, and that whitelists
+// stored before them are still read.
+func TestEnodeWhitelistStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+	if nodes := ReadEnodeWhitelist(db); len(nodes.List) != 0 {
+		t.Fatalf("non-empty whitelist returned: %v", nodes.StrList)
+	}
+
+	var enodes []string
+	for i := 0; i < 2; i++ {
+		key, _ := crypto.GenerateKey()
+		enodes = append(enodes, enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), 30303+i, 30303+i).String())
+	}
+	roles := []params.UserType{params.UserValidator, params.UserParticipant}
+	WriteEnodeWhitelist(db, types.NewNodesWithRoles(enodes, roles))
+
+	nodes := ReadEnodeWhitelist(db)
+	if len(nodes.List) != len(enodes) {
+		t.Fatalf("whitelist size mismatch: have %d, want %d", len(nodes.List), len(enodes))
+	}
+	for i, str := range nodes.StrList {
+		want := roles[0]
+		if str == enodes[1] {
+			want = roles[1]
+		}
+		if role := nodes.Role(nodes.List[i].ID()); role != want {
+			t.Fatalf("role mismatch for %s: have %q, want %q", str, role, want)
+		}
+	}
+
+	legacy, _ := rlp.EncodeToBytes(enodes)
+	if err := db.Put(enodeWhiteList, legacy); err != nil {
+		t.Fatal(err)
+	}
+	nodes = ReadEnodeWhitelist(db)
+	if len(nodes.List) != len(enodes) {
+		t.Fatalf("legacy whitelist size mismatch: have %d, want %d", len(nodes.List), len(enodes))
+	}
+	if len(nodes.Roles) != 0 {
+		t.Fatalf("legacy whitelist has roles: %v", nodes.Roles)
+	}
+}
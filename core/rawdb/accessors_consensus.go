@@ -0,0 +1,158 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethdb"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// WriteMisbehaviourEvidence stores the RLP encoded proof of a consensus misbehaviour
+// which happened at the given height.
+func WriteMisbehaviourEvidence(db ethdb.KeyValueWriter, number uint64, hash common.Hash, evidence []byte) {
+	if err := db.Put(misbehaviourEvidenceKey(number, hash), evidence); err != nil {
+		log.Crit("Failed to store misbehaviour evidence", "err", err)
+	}
+}
+
+// HasMisbehaviourEvidence verifies the existence of a misbehaviour evidence.
+func HasMisbehaviourEvidence(db ethdb.KeyValueReader, number uint64, hash common.Hash) bool {
+	has, err := db.Has(misbehaviourEvidenceKey(number, hash))
+	return err == nil && has
+}
+
+// ReadMisbehaviourEvidence retrieves all the RLP encoded evidence stored for the given height.
+func ReadMisbehaviourEvidence(db ethdb.Iteratee, number uint64) [][]byte {
+	return readMisbehaviourEvidence(db, append(misbehaviourEvidencePrefix, encodeBlockNumber(number)...))
+}
+
+// ReadAllMisbehaviourEvidence retrieves all the RLP encoded evidence in ascending height order.
+func ReadAllMisbehaviourEvidence(db ethdb.Iteratee) [][]byte {
+	return readMisbehaviourEvidence(db, misbehaviourEvidencePrefix)
+}
+
+func readMisbehaviourEvidence(db ethdb.Iteratee, prefix []byte) [][]byte {
+	var evidence [][]byte
+
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(misbehaviourEvidencePrefix)+8+common.HashLength {
+			continue
+		}
+		evidence = append(evidence, common.CopyBytes(it.Value()))
+	}
+	return evidence
+}
+
+// WriteCommittee stores a committee by its hash, which the headers inside an
+// epoch carry in its place.
+func WriteCommittee(db ethdb.KeyValueWriter, committee types.Committee) {
+	data, err := rlp.EncodeToBytes(committee)
+	if err != nil {
+		log.Crit("Failed to RLP encode committee", "err", err)
+	}
+	if err := db.Put(committeeKey(committee.Hash()), data); err != nil {
+		log.Crit("Failed to store committee", "err", err)
+	}
+}
+
+// ReadCommittee retrieves the committee having the given hash.
+func ReadCommittee(db ethdb.KeyValueReader, hash common.Hash) types.Committee {
+	data, _ := db.Get(committeeKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var committee types.Committee
+	if err := rlp.DecodeBytes(data, &committee); err != nil {
+		log.Error("Invalid committee RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return committee
+}
+
+// WriteConsensusWALEntry appends an entry to the consensus write-ahead log of the given height.
+func WriteConsensusWALEntry(db ethdb.KeyValueWriter, number uint64, index uint64, entry []byte) {
+	if err := db.Put(consensusWALKey(number, index), entry); err != nil {
+		log.Crit("Failed to store consensus wal entry", "err", err)
+	}
+}
+
+// ReadConsensusWALEntries retrieves the consensus write-ahead log entries of the given
+// height in insertion order.
+func ReadConsensusWALEntries(db ethdb.Iteratee, number uint64) [][]byte {
+	var entries [][]byte
+
+	it := db.NewIterator(append(consensusWALPrefix, encodeBlockNumber(number)...), nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(consensusWALPrefix)+8+8 {
+			continue
+		}
+		entries = append(entries, common.CopyBytes(it.Value()))
+	}
+	return entries
+}
+
+// DeleteConsensusWAL removes the consensus write-ahead log entries of all the heights
+// below the given one.
+func DeleteConsensusWAL(db ethdb.KeyValueStore, number uint64) {
+	it := db.NewIterator(consensusWALPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(consensusWALPrefix)+8+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(consensusWALPrefix):]) >= number {
+			break
+		}
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete consensus wal entry", "err", err)
+		}
+	}
+}
+
+// ReadSigningWatermark retrieves the RLP encoded watermark of the latest consensus
+// message signed by the validator.
+func ReadSigningWatermark(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(signingWatermarkKey)
+	return data
+}
+
+// WriteSigningWatermark stores the RLP encoded watermark of the latest consensus
+// message signed by the validator. It has to reach the disk before the signature
+// is handed out, db being a batch written with WriteSync.
+func WriteSigningWatermark(db ethdb.KeyValueWriter, watermark []byte) {
+	if err := db.Put(signingWatermarkKey, watermark); err != nil {
+		log.Crit("Failed to store signing watermark", "err", err)
+	}
+}
+
+// DeleteSigningWatermark removes the signing watermark of the validator.
+func DeleteSigningWatermark(db ethdb.KeyValueWriter) {
+	if err := db.Delete(signingWatermarkKey); err != nil {
+		log.Crit("Failed to delete signing watermark", "err", err)
+	}
+}
+
+// ReadGossipBitmaps retrieves the RLP encoded bitmaps of the consensus messages the
+// peers have at the height being decided.
+func ReadGossipBitmaps(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(gossipBitmapsKey)
+	return data
+}
+
+// WriteGossipBitmaps stores the RLP encoded bitmaps of the consensus messages the
+// peers have at the height being decided.
+func WriteGossipBitmaps(db ethdb.KeyValueWriter, bitmaps []byte) {
+	if err := db.Put(gossipBitmapsKey, bitmaps); err != nil {
+		log.Crit("Failed to store gossip bitmaps", "err", err)
+	}
+}
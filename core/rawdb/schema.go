@@ -60,6 +60,12 @@ var (
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
+	// signingWatermarkKey tracks the latest consensus message signed by the validator.
+	signingWatermarkKey = []byte("TendermintSigningWatermark")
+
+	// gossipBitmapsKey tracks the consensus messages the peers have at the height being decided.
+	gossipBitmapsKey = []byte("TendermintGossipBitmaps")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -75,6 +81,12 @@ var (
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	codePrefix            = []byte("c") // codePrefix + code hash -> account code
 
+	misbehaviourEvidencePrefix = []byte("tm-evidence-")  // misbehaviourEvidencePrefix + num (uint64 big endian) + hash -> evidence
+	consensusWALPrefix         = []byte("tm-wal-")       // consensusWALPrefix + num (uint64 big endian) + index (uint64 big endian) -> wal entry
+	contractUpgradePrefix      = []byte("ac-upgrade-")   // contractUpgradePrefix + num (uint64 big endian) -> autonity contract upgrade
+	contractABIPrefix          = []byte("ac-abi-")       // contractABIPrefix + num (uint64 big endian) -> autonity contract abi
+	committeePrefix            = []byte("tm-committee-") // committeePrefix + committee hash -> committee
+
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
@@ -192,6 +204,31 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// misbehaviourEvidenceKey = misbehaviourEvidencePrefix + num (uint64 big endian) + hash
+func misbehaviourEvidenceKey(number uint64, hash common.Hash) []byte {
+	return append(append(misbehaviourEvidencePrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// consensusWALKey = consensusWALPrefix + num (uint64 big endian) + index (uint64 big endian)
+func consensusWALKey(number uint64, index uint64) []byte {
+	return append(append(consensusWALPrefix, encodeBlockNumber(number)...), encodeBlockNumber(index)...)
+}
+
+// contractUpgradeKey = contractUpgradePrefix + num (uint64 big endian)
+func contractUpgradeKey(number uint64) []byte {
+	return append(contractUpgradePrefix, encodeBlockNumber(number)...)
+}
+
+// contractABIKey = contractABIPrefix + num (uint64 big endian)
+func contractABIKey(number uint64) []byte {
+	return append(contractABIPrefix, encodeBlockNumber(number)...)
+}
+
+// committeeKey = committeePrefix + hash
+func committeeKey(hash common.Hash) []byte {
+	return append(committeePrefix, hash.Bytes()...)
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(preimagePrefix, hash.Bytes()...)
@@ -50,6 +50,9 @@ var (
 	ErrEmptyCommittedSeals = errors.New("zero committed seals")
 	// ErrNegativeRound is returned if the round field is negative
 	ErrNegativeRound = errors.New("negative round")
+
+	errInvalidCommitteeMember = errors.New("invalid committee member encoding")
+	errInvalidHeaderExtra     = errors.New("invalid optional header fields encoding")
 )
 
 // BFTFilteredHeader returns a filtered header which some information (like seal, committed seals)
@@ -60,6 +63,8 @@ func BFTFilteredHeader(h *Header, keepSeal bool) *Header {
 		newHeader.ProposerSeal = []byte{}
 	}
 	newHeader.CommittedSeals = [][]byte{}
+	newHeader.AggregatedSeal = nil
+	newHeader.SealBitmap = nil
 	newHeader.Round = 0
 	newHeader.Extra = []byte{}
 	return newHeader
@@ -141,6 +146,17 @@ func WriteCommittedSeals(h *Header, committedSeals [][]byte) error {
 	return nil
 }
 
+// WriteAggregatedSeal writes the aggregated committed seals of a block header, the
+// bitmap flags the signers among the parent header committee.
+func WriteAggregatedSeal(h *Header, seal []byte, bitmap []byte) error {
+	if len(seal) == 0 || len(bitmap) == 0 {
+		return ErrInvalidCommittedSeals
+	}
+	h.AggregatedSeal = common.CopyBytes(seal)
+	h.SealBitmap = common.CopyBytes(bitmap)
+	return nil
+}
+
 func RLPHash(v interface{}) (h common.Hash) {
 	hw := sha3.NewLegacyKeccak256()
 	rlp.Encode(hw, v)
@@ -17,11 +17,13 @@
 package types
 
 import (
+	"bytes"
 	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/rlp"
 )
 
 func TestHeaderHash(t *testing.T) {
@@ -122,9 +124,9 @@ func TestHeaderHash(t *testing.T) {
 		},
 		{
 			setExtra(PosHeader, headerExtra{
-				PastCommittedSeals: [][]byte{common.Hex2Bytes("0xfacebooc"), common.Hex2Bytes("0xbabababa")},
+				PastCommittedSeals: PastSeals{Kind: ECDSASeals, Seals: [][]byte{common.Hex2Bytes("0xfacebooc"), common.Hex2Bytes("0xbabababa")}},
 			}),
-			common.HexToHash("0x0e006ab23161c9d88dab8cf00b6de143f1230b8f8a9d6b91ed10bb788edb9e4f"),
+			common.HexToHash("0xc914dac57ed4c31b141b17a223cc21ac0f64d5c0eabd691a76d605005fd2a78f"),
 		},
 	}
 	for i := range testCases {
@@ -143,3 +145,200 @@ func setExtra(h Header, hExtra headerExtra) Header {
 
 	return h
 }
+
+func TestAggregatedSealEncoding(t *testing.T) {
+	header := &Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		MixDigest:  BFTDigest,
+		Committee: Committee{
+			{Address: common.HexToAddress("0x1"), VotingPower: big.NewInt(1)},
+			{Address: common.HexToAddress("0x2"), VotingPower: big.NewInt(2), BLSKey: []byte{0xb1, 0x5}},
+		},
+		Round: 2,
+	}
+	hash := header.Hash()
+	if err := WriteAggregatedSeal(header, []byte{0xaa, 0xbb}, []byte{0x3}); err != nil {
+		t.Fatal(err)
+	}
+	if header.Hash() != hash {
+		t.Errorf("aggregated seal changed the header hash")
+	}
+
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Header)
+	if err := rlp.DecodeBytes(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.AggregatedSeal, header.AggregatedSeal) || !reflect.DeepEqual(decoded.SealBitmap, header.SealBitmap) {
+		t.Errorf("aggregated seal mismatch: have %x %x, want %x %x", decoded.AggregatedSeal, decoded.SealBitmap, header.AggregatedSeal, header.SealBitmap)
+	}
+	if !reflect.DeepEqual(decoded.Committee, header.Committee) {
+		t.Errorf("committee mismatch: have %v, want %v", decoded.Committee, header.Committee)
+	}
+
+	// The members without BLS key keep their original encoding.
+	member, err := rlp.EncodeToBytes(&header.Committee[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _ := rlp.EncodeToBytes([]interface{}{header.Committee[0].Address, header.Committee[0].VotingPower})
+	if !bytes.Equal(member, legacy) {
+		t.Errorf("committee member encoding mismatch: have %x, want %x", member, legacy)
+	}
+}
+
+func TestMisbehaviourEncoding(t *testing.T) {
+	header := &Header{
+		Number:       big.NewInt(1),
+		Difficulty:   big.NewInt(1),
+		MixDigest:    BFTDigest,
+		Round:        1,
+		Misbehaviour: [][]byte{{0x1, 0x2}, {0x3}},
+	}
+	hash := header.Hash()
+
+	for _, aggregated := range []bool{false, true} {
+		if aggregated {
+			if err := WriteAggregatedSeal(header, []byte{0xaa, 0xbb}, []byte{0x3}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		enc, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Header)
+		if err := rlp.DecodeBytes(enc, decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded.Misbehaviour, header.Misbehaviour) {
+			t.Errorf("misbehaviour mismatch: have %x, want %x", decoded.Misbehaviour, header.Misbehaviour)
+		}
+		if !bytes.Equal(decoded.AggregatedSeal, header.AggregatedSeal) || !bytes.Equal(decoded.SealBitmap, header.SealBitmap) {
+			t.Errorf("aggregated seal mismatch: have %x %x, want %x %x", decoded.AggregatedSeal, decoded.SealBitmap, header.AggregatedSeal, header.SealBitmap)
+		}
+		if decoded.Hash() != hash {
+			t.Errorf("hash mismatch: have %x, want %x", decoded.Hash(), hash)
+		}
+	}
+
+	// The evidence is a field of its own, following the aggregated seal.
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var original originalHeader
+	if err := rlp.DecodeBytes(enc, &original); err != nil {
+		t.Fatal(err)
+	}
+	var extra headerExtra
+	if err := rlp.DecodeBytes(original.Extra, &extra); err != nil {
+		t.Fatal(err)
+	}
+	var misbehaviour [][]byte
+	if len(extra.Optional) != 3 || rlp.DecodeBytes(extra.Optional[2], &misbehaviour) != nil || !reflect.DeepEqual(misbehaviour, header.Misbehaviour) {
+		t.Errorf("misbehaviour field mismatch: have %x, want %x", extra.Optional, header.Misbehaviour)
+	}
+	extra.Optional[2], _ = rlp.EncodeToBytes([][]byte{})
+	if err := new(Header).decodeOptionalExtra(extra.Optional); err != errInvalidHeaderExtra {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidHeaderExtra)
+	}
+
+	// The evidence is signed by the proposer.
+	withoutEvidence := CopyHeader(header)
+	withoutEvidence.Misbehaviour = nil
+	if withoutEvidence.Hash() == hash {
+		t.Errorf("misbehaviour is not part of the header hash")
+	}
+}
+
+func TestPastSealsEncoding(t *testing.T) {
+	// Empty past seals keep the encoding of an empty list of seals.
+	empty, err := rlp.EncodeToBytes(PastSeals{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _ := rlp.EncodeToBytes([][]byte{})
+	if !bytes.Equal(empty, legacy) {
+		t.Errorf("empty past seals encoding mismatch: have %x, want %x", empty, legacy)
+	}
+
+	header := &Header{
+		Number:             big.NewInt(2),
+		Difficulty:         big.NewInt(1),
+		MixDigest:          BFTDigest,
+		PastCommittedSeals: PastSeals{Kind: AggregatedSeals, Seals: [][]byte{{0x3}, {0xaa, 0xbb}}},
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Header)
+	if err := rlp.DecodeBytes(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.PastCommittedSeals, header.PastCommittedSeals) {
+		t.Errorf("past seals mismatch: have %v, want %v", decoded.PastCommittedSeals, header.PastCommittedSeals)
+	}
+
+	json, err := header.PastCommittedSeals.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshalled PastSeals
+	if err := unmarshalled.UnmarshalJSON(json); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshalled, header.PastCommittedSeals) {
+		t.Errorf("past seals JSON mismatch: have %v, want %v", unmarshalled, header.PastCommittedSeals)
+	}
+
+	// Past seals without seals are only encoded empty.
+	invalid, _ := rlp.EncodeToBytes([]interface{}{ECDSASeals, [][]byte{}})
+	if err := rlp.DecodeBytes(invalid, new(PastSeals)); err != errInvalidPastSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidPastSeals)
+	}
+}
+
+func TestEpochHeaderEncoding(t *testing.T) {
+	committee := Committee{
+		{Address: common.HexToAddress("0x3"), VotingPower: big.NewInt(3)},
+		{Address: common.HexToAddress("0x4"), VotingPower: big.NewInt(4), BLSKey: []byte{0xb1, 0x5}},
+	}
+	header := &Header{
+		Number:        big.NewInt(5),
+		Difficulty:    big.NewInt(1),
+		MixDigest:     BFTDigest,
+		Committee:     committee,
+		CommitteeHash: committee.Hash(),
+		Round:         1,
+		Misbehaviour:  [][]byte{{0x1}},
+	}
+	hash := header.Hash()
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The committee is left to be resolved from the epoch header.
+	decoded := new(Header)
+	if err := rlp.DecodeBytes(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.CommitteeHash != header.CommitteeHash || decoded.Committee != nil {
+		t.Errorf("committee mismatch: have %x %v, want %x without committee", decoded.CommitteeHash, decoded.Committee, header.CommitteeHash)
+	}
+	if decoded.Hash() != hash || !reflect.DeepEqual(decoded.Misbehaviour, header.Misbehaviour) {
+		t.Errorf("header mismatch: have %x, want %x", decoded.Hash(), hash)
+	}
+
+	withoutHash := CopyHeader(header)
+	withoutHash.CommitteeHash = common.Hash{}
+	if withoutHash.Hash() == hash {
+		t.Errorf("committee hash is not part of the header hash")
+	}
+}
@@ -91,24 +91,78 @@ type Header struct {
 		for computing the sigHash.
 	*/
 	Committee Committee `json:"committee"           gencodec:"required"`
+	// CommitteeHash is set on the headers inside an epoch, which carry the hash of
+	// the epoch committee instead of the committee itself. Committee is only restored
+	// when such a header is read from the database, it is otherwise resolved from
+	// the epoch header.
+	CommitteeHash common.Hash `json:"committeeHash,omitempty"`
 	// used for committee member lookup, lazily initialised.
 	committeeMap map[common.Address]*CommitteeMember
 	// Used to ensure the committeeMap is created only once.
 	once sync.Once
 
-	ProposerSeal       []byte   `json:"proposerSeal"        gencodec:"required"`
-	Round              uint64   `json:"round"               gencodec:"required"`
-	CommittedSeals     [][]byte `json:"committedSeals"      gencodec:"required"`
-	PastCommittedSeals [][]byte `json:"pastCommittedSeals"  gencodec:"required"`
+	ProposerSeal       []byte    `json:"proposerSeal"        gencodec:"required"`
+	Round              uint64    `json:"round"               gencodec:"required"`
+	CommittedSeals     [][]byte  `json:"committedSeals"      gencodec:"required"`
+	PastCommittedSeals PastSeals `json:"pastCommittedSeals"  gencodec:"required"`
+
+	/*
+		Aggregated seals mode, the BLS committed seals are aggregated into a single
+		signature, SealBitmap flagging the signers among the parent header committee.
+	*/
+	AggregatedSeal []byte `json:"aggregatedSeal,omitempty"`
+	SealBitmap     []byte `json:"sealBitmap,omitempty"`
+
+	/*
+		RLP encoded evidence of committee members double signing, included by the
+		proposer for the offenders to be punished by the finalize of the block.
+	*/
+	Misbehaviour [][]byte `json:"misbehaviour,omitempty"`
 }
 
 type CommitteeMember struct {
 	Address     common.Address `json:"address"            gencodec:"required"       abi:"addr"`
 	VotingPower *big.Int       `json:"votingPower"        gencodec:"required"`
+	BLSKey      hexutil.Bytes  `json:"blsKey,omitempty"   abi:"blsKey"`
+}
+
+// EncodeRLP serializes m, the BLS key is omitted if unset to keep the encoding of
+// the committees without BLS keys unchanged.
+func (m *CommitteeMember) EncodeRLP(w io.Writer) error {
+	if len(m.BLSKey) == 0 {
+		return rlp.Encode(w, []interface{}{m.Address, m.VotingPower})
+	}
+	return rlp.Encode(w, []interface{}{m.Address, m.VotingPower, m.BLSKey})
+}
+
+// DecodeRLP implements rlp.Decoder, the BLS key is optional.
+func (m *CommitteeMember) DecodeRLP(s *rlp.Stream) error {
+	var member struct {
+		Address     common.Address
+		VotingPower *big.Int
+		BLSKey      [][]byte `rlp:"tail"`
+	}
+	if err := s.Decode(&member); err != nil {
+		return err
+	}
+	if len(member.BLSKey) > 1 {
+		return errInvalidCommitteeMember
+	}
+	m.Address, m.VotingPower, m.BLSKey = member.Address, member.VotingPower, nil
+	if len(member.BLSKey) == 1 {
+		m.BLSKey = member.BLSKey[0]
+	}
+	return nil
 }
 
 type Committee []CommitteeMember
 
+// Hash returns the hash of the committee, carried in its place by the headers
+// inside an epoch.
+func (c Committee) Hash() common.Hash {
+	return rlpHash(c)
+}
+
 // originalHeader represents the ethereum blockchain header.
 type originalHeader struct {
 	ParentHash  common.Hash    `json:"parentHash"       gencodec:"required"`
@@ -133,7 +187,21 @@ type headerExtra struct {
 	ProposerSeal       []byte    `json:"proposerSeal"        gencodec:"required"`
 	Round              uint64    `json:"round"               gencodec:"required"`
 	CommittedSeals     [][]byte  `json:"committedSeals"      gencodec:"required"`
-	PastCommittedSeals [][]byte  `json:"pastCommittedSeals"  gencodec:"required"`
+	PastCommittedSeals PastSeals `json:"pastCommittedSeals"  gencodec:"required"`
+	// Optional fields, only encoded if set so that the encoding of the other
+	// headers is unchanged: see encodeOptionalExtra.
+	Optional []rlp.RawValue `rlp:"tail"`
+}
+
+// epochHeaderExtra is the encoding of headerExtra for the headers inside an
+// epoch, the committee being replaced by its hash.
+type epochHeaderExtra struct {
+	CommitteeHash      common.Hash
+	ProposerSeal       []byte
+	Round              uint64
+	CommittedSeals     [][]byte
+	PastCommittedSeals PastSeals
+	Optional           []rlp.RawValue `rlp:"tail"`
 }
 
 // field type overrides for gencodec
@@ -148,9 +216,11 @@ type headerMarshaling struct {
 	/*
 		PoS header fields type overriedes
 	*/
-	ProposerSeal       hexutil.Bytes
-	CommittedSeals     []hexutil.Bytes
-	PastCommittedSeals []hexutil.Bytes
+	ProposerSeal   hexutil.Bytes
+	CommittedSeals []hexutil.Bytes
+	AggregatedSeal hexutil.Bytes
+	SealBitmap     hexutil.Bytes
+	Misbehaviour   []hexutil.Bytes
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
@@ -204,14 +274,17 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 		return err
 	}
 
-	hExtra := &headerExtra{}
 	if origin.MixDigest == BFTDigest {
-		if err := rlp.DecodeBytes(origin.Extra, hExtra); err == nil {
+		if hExtra, committeeHash, err := decodeHeaderExtra(origin.Extra); err == nil {
 			h.CommittedSeals = hExtra.CommittedSeals
 			h.Committee = hExtra.Committee
+			h.CommitteeHash = committeeHash
 			h.PastCommittedSeals = hExtra.PastCommittedSeals
 			h.ProposerSeal = hExtra.ProposerSeal
 			h.Round = hExtra.Round
+			if err := h.decodeOptionalExtra(hExtra.Optional); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -234,18 +307,114 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
-// EncodeRLP serializes b into the Ethereum RLP block format.
-func (h *Header) EncodeRLP(w io.Writer) error {
-	hExtra := headerExtra{
-		Committee:          h.Committee,
-		ProposerSeal:       h.ProposerSeal,
-		Round:              h.Round,
-		CommittedSeals:     h.CommittedSeals,
-		PastCommittedSeals: h.PastCommittedSeals,
+// decodeHeaderExtra decodes the BFT fields of a header, the first one being
+// either the committee or the hash of the epoch committee.
+func decodeHeaderExtra(extra []byte) (*headerExtra, common.Hash, error) {
+	content, _, err := rlp.SplitList(extra)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	kind, _, _, err := rlp.Split(content)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	if kind == rlp.List {
+		hExtra := new(headerExtra)
+		return hExtra, common.Hash{}, rlp.DecodeBytes(extra, hExtra)
+	}
+	epochExtra := new(epochHeaderExtra)
+	if err := rlp.DecodeBytes(extra, epochExtra); err != nil {
+		return nil, common.Hash{}, err
+	}
+	return &headerExtra{
+		ProposerSeal:       epochExtra.ProposerSeal,
+		Round:              epochExtra.Round,
+		CommittedSeals:     epochExtra.CommittedSeals,
+		PastCommittedSeals: epochExtra.PastCommittedSeals,
+		Optional:           epochExtra.Optional,
+	}, epochExtra.CommitteeHash, nil
+}
+
+// encodeOptionalExtra encodes the optional BFT fields of h: the bitmap and the
+// signature of the aggregated seals, both empty if the misbehaviour evidence is
+// carried without aggregated seals, followed by the list of the misbehaviour
+// evidence. Nothing is encoded if neither is set.
+func (h *Header) encodeOptionalExtra() ([]rlp.RawValue, error) {
+	if len(h.AggregatedSeal) == 0 && len(h.Misbehaviour) == 0 {
+		return nil, nil
 	}
+	fields := []interface{}{h.SealBitmap, h.AggregatedSeal}
+	if len(h.Misbehaviour) > 0 {
+		fields = append(fields, h.Misbehaviour)
+	}
+	optional := make([]rlp.RawValue, len(fields))
+	for i, field := range fields {
+		enc, err := rlp.EncodeToBytes(field)
+		if err != nil {
+			return nil, err
+		}
+		optional[i] = enc
+	}
+	return optional, nil
+}
 
+// decodeOptionalExtra decodes the optional BFT fields encoded by encodeOptionalExtra.
+func (h *Header) decodeOptionalExtra(optional []rlp.RawValue) error {
+	if len(optional) == 0 {
+		return nil
+	}
+	if len(optional) < 2 || len(optional) > 3 {
+		return errInvalidHeaderExtra
+	}
+	var bitmap, seal []byte
+	if err := rlp.DecodeBytes(optional[0], &bitmap); err != nil {
+		return err
+	}
+	if err := rlp.DecodeBytes(optional[1], &seal); err != nil {
+		return err
+	}
+	if len(seal) > 0 {
+		h.SealBitmap, h.AggregatedSeal = bitmap, seal
+	}
+	if len(optional) == 3 {
+		var misbehaviour [][]byte
+		if err := rlp.DecodeBytes(optional[2], &misbehaviour); err != nil {
+			return err
+		}
+		if len(misbehaviour) == 0 {
+			return errInvalidHeaderExtra
+		}
+		h.Misbehaviour = misbehaviour
+	}
+	return nil
+}
+
+// EncodeRLP serializes b into the Ethereum RLP block format.
+func (h *Header) EncodeRLP(w io.Writer) error {
 	original := h.original()
 	if h.MixDigest == BFTDigest {
+		optional, err := h.encodeOptionalExtra()
+		if err != nil {
+			return err
+		}
+		var hExtra interface{} = headerExtra{
+			Committee:          h.Committee,
+			ProposerSeal:       h.ProposerSeal,
+			Round:              h.Round,
+			CommittedSeals:     h.CommittedSeals,
+			PastCommittedSeals: h.PastCommittedSeals,
+			Optional:           optional,
+		}
+		if h.CommitteeHash != (common.Hash{}) {
+			hExtra = epochHeaderExtra{
+				CommitteeHash:      h.CommitteeHash,
+				ProposerSeal:       h.ProposerSeal,
+				Round:              h.Round,
+				CommittedSeals:     h.CommittedSeals,
+				PastCommittedSeals: h.PastCommittedSeals,
+				Optional:           optional,
+			}
+		}
 		extra, err := rlp.EncodeToBytes(hExtra)
 		if err != nil {
 			return err
@@ -423,6 +592,7 @@ func CopyHeader(h *Header) *Header {
 	}
 
 	/* PoS fields deep copy section*/
+	cpy.committeeMap, cpy.once = nil, sync.Once{}
 	if len(h.Committee) > 0 {
 		cpy.Committee = make([]CommitteeMember, len(h.Committee))
 		for i, val := range h.Committee {
@@ -430,6 +600,9 @@ func CopyHeader(h *Header) *Header {
 				Address:     val.Address,
 				VotingPower: new(big.Int).Set(val.VotingPower),
 			}
+			if len(val.BLSKey) > 0 {
+				cpy.Committee[i].BLSKey = common.CopyBytes(val.BLSKey)
+			}
 		}
 	}
 
@@ -446,11 +619,17 @@ func CopyHeader(h *Header) *Header {
 		}
 	}
 
-	if len(h.PastCommittedSeals) > 0 {
-		cpy.PastCommittedSeals = make([][]byte, len(h.PastCommittedSeals))
-		for i, val := range h.PastCommittedSeals {
-			cpy.PastCommittedSeals[i] = make([]byte, len(val))
-			copy(cpy.PastCommittedSeals[i], val)
+	cpy.PastCommittedSeals = h.PastCommittedSeals.Copy()
+
+	if len(h.AggregatedSeal) > 0 {
+		cpy.AggregatedSeal = common.CopyBytes(h.AggregatedSeal)
+		cpy.SealBitmap = common.CopyBytes(h.SealBitmap)
+	}
+
+	if len(h.Misbehaviour) > 0 {
+		cpy.Misbehaviour = make([][]byte, len(h.Misbehaviour))
+		for i, evidence := range h.Misbehaviour {
+			cpy.Misbehaviour[i] = common.CopyBytes(evidence)
 		}
 	}
 
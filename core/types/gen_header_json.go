@@ -36,10 +36,14 @@ func (h Header) MarshalJSON() ([]byte, error) {
 	}
 	type ExtraHeader struct {
 		Committee          Committee       `json:"committee"           gencodec:"required"`
+		CommitteeHash      *common.Hash    `json:"committeeHash,omitempty"`
 		ProposerSeal       hexutil.Bytes   `json:"proposerSeal"        gencodec:"required"`
 		Round              hexutil.Uint64  `json:"round"               gencodec:"required"`
 		CommittedSeals     []hexutil.Bytes `json:"committedSeals"      gencodec:"required"`
-		PastCommittedSeals []hexutil.Bytes `json:"pastCommittedSeals"  gencodec:"required"`
+		PastCommittedSeals PastSeals       `json:"pastCommittedSeals"  gencodec:"required"`
+		AggregatedSeal     hexutil.Bytes   `json:"aggregatedSeal,omitempty"`
+		SealBitmap         hexutil.Bytes   `json:"sealBitmap,omitempty"`
+		Misbehaviour       []hexutil.Bytes `json:"misbehaviour,omitempty"`
 	}
 
 	var enc Header
@@ -64,6 +68,9 @@ func (h Header) MarshalJSON() ([]byte, error) {
 	if h.Committee != nil {
 		encExtra.Committee = h.Committee
 	}
+	if h.CommitteeHash != (common.Hash{}) {
+		encExtra.CommitteeHash = &h.CommitteeHash
+	}
 	if h.ProposerSeal != nil {
 		encExtra.ProposerSeal = h.ProposerSeal
 	}
@@ -74,10 +81,13 @@ func (h Header) MarshalJSON() ([]byte, error) {
 			encExtra.CommittedSeals[k] = v
 		}
 	}
-	if h.PastCommittedSeals != nil {
-		encExtra.PastCommittedSeals = make([]hexutil.Bytes, len(h.PastCommittedSeals))
-		for k, v := range h.PastCommittedSeals {
-			encExtra.PastCommittedSeals[k] = v
+	encExtra.PastCommittedSeals = h.PastCommittedSeals
+	encExtra.AggregatedSeal = h.AggregatedSeal
+	encExtra.SealBitmap = h.SealBitmap
+	if h.Misbehaviour != nil {
+		encExtra.Misbehaviour = make([]hexutil.Bytes, len(h.Misbehaviour))
+		for k, v := range h.Misbehaviour {
+			encExtra.Misbehaviour[k] = v
 		}
 	}
 
@@ -112,10 +122,14 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 	}
 	type ExtraHeader struct {
 		Committee          *Committee       `json:"committee"           gencodec:"required"`
+		CommitteeHash      *common.Hash     `json:"committeeHash,omitempty"`
 		ProposerSeal       *hexutil.Bytes   `json:"proposerSeal"        gencodec:"required"`
 		Round              *hexutil.Uint64  `json:"round"               gencodec:"required"`
 		CommittedSeals     *[]hexutil.Bytes `json:"committedSeals"      gencodec:"required"`
-		PastCommittedSeals *[]hexutil.Bytes `json:"pastCommittedSeals"  gencodec:"required"`
+		PastCommittedSeals *PastSeals       `json:"pastCommittedSeals"  gencodec:"required"`
+		AggregatedSeal     *hexutil.Bytes   `json:"aggregatedSeal,omitempty"`
+		SealBitmap         *hexutil.Bytes   `json:"sealBitmap,omitempty"`
+		Misbehaviour       []hexutil.Bytes  `json:"misbehaviour,omitempty"`
 	}
 	var dec Header
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -191,6 +205,9 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 	if decExtra.Committee != nil {
 		h.Committee = *decExtra.Committee
 	}
+	if decExtra.CommitteeHash != nil {
+		h.CommitteeHash = *decExtra.CommitteeHash
+	}
 
 	if decExtra.ProposerSeal != nil {
 		h.ProposerSeal = *decExtra.ProposerSeal
@@ -210,9 +227,18 @@ func (h *Header) UnmarshalJSON(input []byte) error {
 	}
 
 	if decExtra.PastCommittedSeals != nil {
-		h.PastCommittedSeals = make([][]byte, len(*decExtra.PastCommittedSeals))
-		for k, v := range *decExtra.PastCommittedSeals {
-			h.PastCommittedSeals[k] = v
+		h.PastCommittedSeals = *decExtra.PastCommittedSeals
+	}
+	if decExtra.AggregatedSeal != nil {
+		h.AggregatedSeal = *decExtra.AggregatedSeal
+	}
+	if decExtra.SealBitmap != nil {
+		h.SealBitmap = *decExtra.SealBitmap
+	}
+	if decExtra.Misbehaviour != nil {
+		h.Misbehaviour = make([][]byte, len(decExtra.Misbehaviour))
+		for k, v := range decExtra.Misbehaviour {
+			h.Misbehaviour[k] = v
 		}
 	}
 	return nil
@@ -7,11 +7,16 @@ import (
 
 	"github.com/clearmatics/autonity/log"
 	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
 )
 
 type Nodes struct {
 	List    []*enode.Node
 	StrList []string
+
+	// Roles is the user type of the enodes in the autonity contract, an enode
+	// missing from it has no known role.
+	Roles map[enode.ID]params.UserType
 }
 
 func NewNodes(strList []string) *Nodes {
@@ -20,8 +25,8 @@ func NewNodes(strList []string) *Nodes {
 	errCh := make(chan error, len(strList))
 
 	n := &Nodes{
-		make([]*enode.Node, len(strList)),
-		make([]string, len(strList)),
+		List:    make([]*enode.Node, len(strList)),
+		StrList: make([]string, len(strList)),
 	}
 
 	for _, enodeStr := range strList {
@@ -65,8 +70,8 @@ func NewNodes(strList []string) *Nodes {
 
 func filterNodes(n *Nodes) *Nodes {
 	filtered := &Nodes{
-		make([]*enode.Node, 0, len(n.List)),
-		make([]string, 0, len(n.StrList)),
+		List:    make([]*enode.Node, 0, len(n.List)),
+		StrList: make([]string, 0, len(n.StrList)),
 	}
 
 	for i, node := range n.List {
@@ -78,3 +83,36 @@ func filterNodes(n *Nodes) *Nodes {
 
 	return filtered
 }
+
+// NewNodesWithRoles parses the enodes as NewNodes and records the user type of
+// each of them, roles being in the order of strList.
+func NewNodesWithRoles(strList []string, roles []params.UserType) *Nodes {
+	n := NewNodes(strList)
+	byEnode := make(map[string]params.UserType, len(strList))
+	for i, enodeStr := range strList {
+		if i < len(roles) {
+			byEnode[enodeStr] = roles[i]
+		}
+	}
+	n.Roles = make(map[enode.ID]params.UserType, len(n.List))
+	for i, node := range n.List {
+		if role, ok := byEnode[n.StrList[i]]; ok && role != "" {
+			n.Roles[node.ID()] = role
+		}
+	}
+	return n
+}
+
+// Role returns the user type of the enode, empty if unknown.
+func (n *Nodes) Role(id enode.ID) params.UserType {
+	return n.Roles[id]
+}
+
+// RoleList returns the user types of the enodes in the order of StrList.
+func (n *Nodes) RoleList() []params.UserType {
+	roles := make([]params.UserType, len(n.List))
+	for i, node := range n.List {
+		roles[i] = n.Roles[node.ID()]
+	}
+	return roles
+}
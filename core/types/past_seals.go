@@ -0,0 +1,117 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	"github.com/clearmatics/autonity/rlp"
+)
+
+// Kinds of the committed seals carried by the past committed seals of a header.
+const (
+	// ECDSASeals are the committed seals of the committee members.
+	ECDSASeals uint8 = iota
+	// AggregatedSeals are the bitmap of the signers followed by their aggregated
+	// BLS seal.
+	AggregatedSeals
+)
+
+// errInvalidPastSeals is returned when decoding past committed seals without seals.
+var errInvalidPastSeals = errors.New("invalid past committed seals encoding")
+
+// PastSeals are the committed seals of the parent of a header, for the round the
+// parent was committed at. They carry the kind of the seals gathered by the proposer
+// of the header, which can differ from the ones the parent header was committed
+// with locally.
+type PastSeals struct {
+	Kind  uint8
+	Seals [][]byte
+}
+
+// pastSealsJSON is the JSON encoding of PastSeals.
+type pastSealsJSON struct {
+	Kind  hexutil.Uint64  `json:"kind"`
+	Seals []hexutil.Bytes `json:"seals"`
+}
+
+// Empty returns whether no seals are carried, which is the case of the children of
+// the genesis block.
+func (p PastSeals) Empty() bool {
+	return len(p.Seals) == 0
+}
+
+// Copy returns a deep copy of p.
+func (p PastSeals) Copy() PastSeals {
+	cpy := PastSeals{Kind: p.Kind}
+	if len(p.Seals) > 0 {
+		cpy.Seals = make([][]byte, len(p.Seals))
+		for i, seal := range p.Seals {
+			cpy.Seals[i] = common.CopyBytes(seal)
+		}
+	}
+	return cpy
+}
+
+// EncodeRLP implements rlp.Encoder. Empty past seals are encoded as an empty list.
+func (p PastSeals) EncodeRLP(w io.Writer) error {
+	if p.Empty() {
+		return rlp.Encode(w, []interface{}{})
+	}
+	return rlp.Encode(w, []interface{}{p.Kind, p.Seals})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (p *PastSeals) DecodeRLP(s *rlp.Stream) error {
+	raw, err := s.Raw()
+	if err != nil {
+		return err
+	}
+	content, _, err := rlp.SplitList(raw)
+	if err != nil {
+		return err
+	}
+	if len(content) == 0 {
+		*p = PastSeals{}
+		return nil
+	}
+	var dec struct {
+		Kind  uint8
+		Seals [][]byte
+	}
+	if err := rlp.DecodeBytes(raw, &dec); err != nil {
+		return err
+	}
+	if len(dec.Seals) == 0 {
+		return errInvalidPastSeals
+	}
+	*p = PastSeals{Kind: dec.Kind, Seals: dec.Seals}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p PastSeals) MarshalJSON() ([]byte, error) {
+	enc := pastSealsJSON{Kind: hexutil.Uint64(p.Kind), Seals: make([]hexutil.Bytes, len(p.Seals))}
+	for i, seal := range p.Seals {
+		enc.Seals[i] = seal
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PastSeals) UnmarshalJSON(input []byte) error {
+	var dec pastSealsJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*p = PastSeals{Kind: uint8(dec.Kind)}
+	if len(dec.Seals) > 0 {
+		p.Seals = make([][]byte, len(dec.Seals))
+		for i, seal := range dec.Seals {
+			p.Seals[i] = seal
+		}
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/clearmatics/autonity/common/math"
 	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/crypto/blake2b"
+	"github.com/clearmatics/autonity/crypto/bls"
 	"github.com/clearmatics/autonity/crypto/bls12381"
 	"github.com/clearmatics/autonity/crypto/bn256"
 	"github.com/clearmatics/autonity/p2p/enode"
@@ -112,6 +113,12 @@ var PrecompiledContractsYoloV1 = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{255}): &checkEnode{},
 }
 
+// PrecompiledContractsBLS contains the pre-compiled contracts enabled by the BLS
+// fork on top of those of the Ethereum release.
+var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{254}): &checkBLSPossession{},
+}
+
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 // It returns
 // - the returned bytes,
@@ -992,3 +999,26 @@ func (c checkEnode) Run(input []byte) ([]byte, error) {
 	}
 	return true32Byte, nil
 }
+
+// checkBLSPossession implemented as a native contract, it verifies the proof of
+// possession of a BLS key, the input being the key followed by the proof.
+type checkBLSPossession struct{}
+
+func (c checkBLSPossession) RequiredGas(_ []byte) uint64 {
+	return params.BLSPossessionCheckGas
+}
+
+func (c checkBLSPossession) Run(input []byte) ([]byte, error) {
+	if len(input) != bls.PublicKeyLength+bls.SignatureLength {
+		return false32Byte, nil
+	}
+	key, err := bls.PublicKeyFromBytes(input[:bls.PublicKeyLength])
+	if err != nil {
+		return false32Byte, nil
+	}
+	proof, err := bls.SignatureFromBytes(input[bls.PublicKeyLength:])
+	if err != nil || !bls.VerifyPossession(key, proof) {
+		return false32Byte, nil
+	}
+	return true32Byte, nil
+}
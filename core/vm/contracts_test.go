@@ -21,10 +21,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/crypto/bls"
+	"github.com/clearmatics/autonity/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -247,6 +250,43 @@ func TestPrecompileBlake2FMalformedInput(t *testing.T) {
 
 func TestPrecompiledEcrecover(t *testing.T) { testJson("ecRecover", "01", t) }
 
+func TestPrecompiledBLSFork(t *testing.T) {
+	config := &params.ChainConfig{ChainID: big.NewInt(1), BLSBlock: big.NewInt(5)}
+	addr := common.HexToAddress("fe")
+	if _, ok := NewEVM(Context{BlockNumber: big.NewInt(4)}, nil, config, Config{}).precompile(addr); ok {
+		t.Errorf("BLS possession check enabled before the fork")
+	}
+	if _, ok := NewEVM(Context{BlockNumber: big.NewInt(5)}, nil, config, Config{}).precompile(addr); !ok {
+		t.Errorf("BLS possession check disabled after the fork")
+	}
+}
+
+func TestPrecompiledBLSPossessionCheck(t *testing.T) {
+	p := PrecompiledContractsBLS[common.HexToAddress("fe")]
+	sk, _ := bls.GenerateKey()
+	other, _ := bls.GenerateKey()
+	key := sk.PublicKey().Marshal()
+	for _, test := range []struct {
+		name  string
+		input []byte
+		want  []byte
+	}{
+		{"valid", append(append([]byte{}, key...), sk.ProvePossession().Marshal()...), true32Byte},
+		{"other key proof", append(append([]byte{}, key...), other.ProvePossession().Marshal()...), false32Byte},
+		{"signature as proof", append(append([]byte{}, key...), sk.Sign(key).Marshal()...), false32Byte},
+		{"short input", key, false32Byte},
+		{"invalid key", make([]byte, bls.PublicKeyLength+bls.SignatureLength), false32Byte},
+	} {
+		res, _, err := RunPrecompiledContract(p, test.input, params.BLSPossessionCheckGas)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !bytes.Equal(res, test.want) {
+			t.Errorf("%s: result mismatch: have %x, want %x", test.name, res, test.want)
+		}
+	}
+}
+
 func testJson(name, addr string, t *testing.T) {
 	tests, err := loadJson(name)
 	if err != nil {
@@ -55,6 +55,9 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsBLS {
+		p, ok = PrecompiledContractsBLS[addr]
+	}
 	return p, ok
 }
 
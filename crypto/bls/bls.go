@@ -0,0 +1,268 @@
+// Package bls implements BLS signatures over the BLS12-381 curve, with public keys
+// in G1 and signatures in G2. Signatures of a same message can be aggregated and
+// verified with a single pairing check. Public keys must come with a proof of
+// possession to prevent rogue key attacks on aggregated signatures.
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/clearmatics/autonity/crypto/bls12381"
+)
+
+const (
+	SecretKeyLength = 32  // Length of a serialized secret key
+	PublicKeyLength = 96  // Length of a serialized uncompressed G1 public key
+	SignatureLength = 192 // Length of a serialized uncompressed G2 signature
+)
+
+var (
+	errInvalidSecretKey = errors.New("invalid bls secret key")
+	errInvalidPublicKey = errors.New("invalid bls public key")
+	errInvalidSignature = errors.New("invalid bls signature")
+	errNoPublicKeys     = errors.New("no bls public keys")
+	errNoSignatures     = errors.New("no bls signatures")
+)
+
+var (
+	// Domain separation tags of the signatures and of the proofs of possession.
+	dstSignature  = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	dstPossession = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+	// fieldModulus is the order of the base field of BLS12-381.
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+)
+
+// SecretKey is a BLS secret key.
+type SecretKey struct {
+	k *big.Int
+}
+
+// PublicKey is a BLS public key, a point of G1.
+type PublicKey struct {
+	p *bls12381.PointG1
+}
+
+// Signature is a BLS signature, a point of G2.
+type Signature struct {
+	p *bls12381.PointG2
+}
+
+// GenerateKey creates a new random secret key.
+func GenerateKey() (*SecretKey, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(bls12381.NewG1().Q(), big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	return &SecretKey{k: k.Add(k, big.NewInt(1))}, nil
+}
+
+// SecretKeyFromBytes parses a secret key serialized by SecretKey.Bytes.
+func SecretKeyFromBytes(b []byte) (*SecretKey, error) {
+	k := new(big.Int).SetBytes(b)
+	if len(b) != SecretKeyLength || k.Sign() == 0 || k.Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, errInvalidSecretKey
+	}
+	return &SecretKey{k: k}, nil
+}
+
+// Bytes serializes the secret key.
+func (sk *SecretKey) Bytes() []byte {
+	b := make([]byte, SecretKeyLength)
+	return sk.k.FillBytes(b)
+}
+
+// LoadKey loads a secret key from the given file, hex encoded.
+func LoadKey(file string) (*SecretKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, err
+	}
+	return SecretKeyFromBytes(b)
+}
+
+// SaveKey saves a secret key to the given file with restrictive permissions,
+// hex encoded.
+func SaveKey(file string, sk *SecretKey) error {
+	return ioutil.WriteFile(file, []byte(hex.EncodeToString(sk.Bytes())), 0600)
+}
+
+// PublicKey returns the public key of sk.
+func (sk *SecretKey) PublicKey() *PublicKey {
+	g1 := bls12381.NewG1()
+	return &PublicKey{p: g1.MulScalar(g1.New(), g1.One(), sk.k)}
+}
+
+// Sign signs msg.
+func (sk *SecretKey) Sign(msg []byte) *Signature {
+	return sk.sign(msg, dstSignature)
+}
+
+// ProvePossession returns the proof of possession of sk, the signature of its
+// public key under a dedicated domain.
+func (sk *SecretKey) ProvePossession() *Signature {
+	return sk.sign(sk.PublicKey().Marshal(), dstPossession)
+}
+
+func (sk *SecretKey) sign(msg, dst []byte) *Signature {
+	g2 := bls12381.NewG2()
+	h := hashToG2(msg, dst)
+	return &Signature{p: g2.MulScalar(h, h, sk.k)}
+}
+
+// PublicKeyFromBytes parses a public key serialized by PublicKey.Marshal. The point
+// at infinity and points out of the prime order subgroup are rejected.
+func PublicKeyFromBytes(b []byte) (*PublicKey, error) {
+	g1 := bls12381.NewG1()
+	p, err := g1.FromBytes(b)
+	if err != nil || g1.IsZero(p) || !g1.InCorrectSubgroup(p) {
+		return nil, errInvalidPublicKey
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// Marshal serializes the public key.
+func (pk *PublicKey) Marshal() []byte {
+	return bls12381.NewG1().ToBytes(new(bls12381.PointG1).Set(pk.p))
+}
+
+// SignatureFromBytes parses a signature serialized by Signature.Marshal. The point
+// at infinity and points out of the prime order subgroup are rejected.
+func SignatureFromBytes(b []byte) (*Signature, error) {
+	g2 := bls12381.NewG2()
+	p, err := g2.FromBytes(b)
+	if err != nil || g2.IsZero(p) || !g2.InCorrectSubgroup(p) {
+		return nil, errInvalidSignature
+	}
+	return &Signature{p: p}, nil
+}
+
+// Marshal serializes the signature.
+func (s *Signature) Marshal() []byte {
+	return bls12381.NewG2().ToBytes(new(bls12381.PointG2).Set(s.p))
+}
+
+// Verify checks that sig is the signature of msg by pk.
+func Verify(pk *PublicKey, msg []byte, sig *Signature) bool {
+	return verify(pk, hashToG2(msg, dstSignature), sig)
+}
+
+// VerifyPossession checks the proof of possession of the secret key of pk.
+func VerifyPossession(pk *PublicKey, proof *Signature) bool {
+	return verify(pk, hashToG2(pk.Marshal(), dstPossession), proof)
+}
+
+// FastAggregateVerify checks that sig is the aggregated signature of msg by all
+// the given public keys, which must have been proven to be possessed.
+func FastAggregateVerify(pks []*PublicKey, msg []byte, sig *Signature) bool {
+	pk, err := AggregatePublicKeys(pks)
+	if err != nil {
+		return false
+	}
+	return Verify(pk, msg, sig)
+}
+
+// verify checks e(pk, h) == e(g1, sig).
+func verify(pk *PublicKey, h *bls12381.PointG2, sig *Signature) bool {
+	engine := bls12381.NewPairingEngine()
+	if engine.G1.IsZero(pk.p) || engine.G2.IsZero(sig.p) {
+		return false
+	}
+	engine.AddPair(new(bls12381.PointG1).Set(pk.p), h)
+	engine.AddPairInv(engine.G1.One(), new(bls12381.PointG2).Set(sig.p))
+	return engine.Check()
+}
+
+// AggregatePublicKeys sums public keys.
+func AggregatePublicKeys(pks []*PublicKey) (*PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, errNoPublicKeys
+	}
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, pk := range pks {
+		g1.Add(agg, agg, pk.p)
+	}
+	return &PublicKey{p: agg}, nil
+}
+
+// AggregateSignatures sums signatures.
+func AggregateSignatures(sigs []*Signature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, errNoSignatures
+	}
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, sig := range sigs {
+		g2.Add(agg, agg, sig.p)
+	}
+	return &Signature{p: agg}, nil
+}
+
+// hashToG2 maps msg to a point of G2 as specified by the hash_to_curve suite
+// BLS12381G2_XMD:SHA-256_SSWU_RO_.
+func hashToG2(msg, dst []byte) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	uniform := expandMessageXMD(msg, dst, 2*2*64)
+	q := g2.Zero()
+	for i := 0; i < 2; i++ {
+		// An element of Fp2 is encoded as c1 || c0.
+		var in [96]byte
+		c0 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128:i*128+64]), fieldModulus)
+		c1 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128+64:i*128+128]), fieldModulus)
+		c1.FillBytes(in[:48])
+		c0.FillBytes(in[48:])
+		// The cofactor is cleared by the mapping, which is linear, so the sum of the
+		// mapped points is the point of the suite.
+		p, err := g2.MapToCurve(in[:])
+		if err != nil {
+			panic(err) // unreachable, the field elements are reduced
+		}
+		g2.Add(q, q, p)
+	}
+	return g2.Affine(q)
+}
+
+// expandMessageXMD implements expand_message_xmd with SHA-256.
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	ell := (length + sha256.Size - 1) / sha256.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	out := append(make([]byte, 0, ell*sha256.Size), bi...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, sha256.Size)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(xored)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length]
+}
@@ -0,0 +1,134 @@
+package bls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandMessageXMD(t *testing.T) {
+	// Test vectors of RFC 9380, appendix K.1.
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	for _, tt := range []struct {
+		msg  string
+		want string
+	}{
+		{"", "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	} {
+		if have := hex.EncodeToString(expandMessageXMD([]byte(tt.msg), dst, 32)); have != tt.want {
+			t.Errorf("expand %q mismatch: have %s, want %s", tt.msg, have, tt.want)
+		}
+	}
+}
+
+func TestHashToG2(t *testing.T) {
+	// Test vector of RFC 9380, appendix J.10.1.
+	dst := []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_")
+	x1 := "05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d"
+	x0 := "0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a"
+
+	p := (&Signature{p: hashToG2([]byte{}, dst)}).Marshal()
+	if have := hex.EncodeToString(p[:96]); have != x1+x0 {
+		t.Errorf("hash to curve mismatch: have %s, want %s", have, x1+x0)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	sk, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("committed seal")
+	sig := sk.Sign(msg)
+
+	if !Verify(sk.PublicKey(), msg, sig) {
+		t.Fatalf("valid signature rejected")
+	}
+	if Verify(sk.PublicKey(), []byte("other message"), sig) {
+		t.Fatalf("signature of another message accepted")
+	}
+	other, _ := GenerateKey()
+	if Verify(other.PublicKey(), msg, sig) {
+		t.Fatalf("signature of another key accepted")
+	}
+	// A proof of possession is not a signature of the public key.
+	if Verify(sk.PublicKey(), sk.PublicKey().Marshal(), sk.ProvePossession()) {
+		t.Fatalf("proof of possession accepted as a signature")
+	}
+	if !VerifyPossession(sk.PublicKey(), sk.ProvePossession()) {
+		t.Fatalf("valid proof of possession rejected")
+	}
+	if VerifyPossession(other.PublicKey(), sk.ProvePossession()) {
+		t.Fatalf("proof of possession of another key accepted")
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	sk, _ := GenerateKey()
+	sk2, err := SecretKeyFromBytes(sk.Bytes())
+	if err != nil || sk2.k.Cmp(sk.k) != 0 {
+		t.Fatalf("secret key round trip failed: %v", err)
+	}
+	pk, err := PublicKeyFromBytes(sk.PublicKey().Marshal())
+	if err != nil || !bytes.Equal(pk.Marshal(), sk.PublicKey().Marshal()) {
+		t.Fatalf("public key round trip failed: %v", err)
+	}
+	sig, err := SignatureFromBytes(sk.Sign([]byte("msg")).Marshal())
+	if err != nil || !Verify(pk, []byte("msg"), sig) {
+		t.Fatalf("signature round trip failed: %v", err)
+	}
+
+	if _, err := PublicKeyFromBytes(make([]byte, PublicKeyLength)); err != errInvalidPublicKey {
+		t.Errorf("infinity public key accepted")
+	}
+	if _, err := SignatureFromBytes(make([]byte, SignatureLength)); err != errInvalidSignature {
+		t.Errorf("infinity signature accepted")
+	}
+	if _, err := SecretKeyFromBytes(make([]byte, SecretKeyLength)); err != errInvalidSecretKey {
+		t.Errorf("zero secret key accepted")
+	}
+}
+
+func TestSaveLoadKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "blskey")
+	sk, _ := GenerateKey()
+	if err := SaveKey(file, sk); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadKey(file)
+	if err != nil || loaded.k.Cmp(sk.k) != 0 {
+		t.Fatalf("loaded key mismatch: %v", err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	msg := []byte("committed seal")
+	var (
+		pks  []*PublicKey
+		sigs []*Signature
+	)
+	for i := 0; i < 4; i++ {
+		sk, _ := GenerateKey()
+		pks = append(pks, sk.PublicKey())
+		sigs = append(sigs, sk.Sign(msg))
+	}
+	sig, err := AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !FastAggregateVerify(pks, msg, sig) {
+		t.Fatalf("valid aggregated signature rejected")
+	}
+	if FastAggregateVerify(pks[:3], msg, sig) {
+		t.Fatalf("aggregated signature accepted with a missing signer")
+	}
+	partial, _ := AggregateSignatures(sigs[:3])
+	if FastAggregateVerify(pks, msg, partial) {
+		t.Fatalf("aggregated signature accepted with an extra signer")
+	}
+	if FastAggregateVerify(nil, msg, sig) {
+		t.Fatalf("aggregated signature accepted without signers")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"os"
 	"reflect"
@@ -315,6 +316,34 @@ func NewPrivateDebugAPI(eth *Ethereum) *PrivateDebugAPI {
 	return &PrivateDebugAPI{eth: eth}
 }
 
+// contractUpgradeSimulationTimeout is the time after which a simulation of an
+// upgrade of the autonity contract is aborted.
+const contractUpgradeSimulationTimeout = 10 * time.Second
+
+// SimulateContractUpgrade dry-runs the upgrade of the autonity contract to the
+// given hex encoded bytecode and ABI on the state of the given block, or of the
+// latest block if omitted, and reports the differences it makes. The calls to the
+// contract are capped to the RPC gas cap and the simulation is aborted after
+// contractUpgradeSimulationTimeout.
+func (api *PrivateDebugAPI) SimulateContractUpgrade(ctx context.Context, bytecode string, abi string, blockNrOrHash *rpc.BlockNumberOrHash) (*autonity.UpgradeSimulation, error) {
+	bc := api.eth.BlockChain()
+	ac := bc.GetAutonityContract()
+	if ac == nil {
+		return nil, errors.New("the autonity contract is not specified")
+	}
+	stateDB, header, err := autonityStateAndHeader(bc, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	gas := uint64(math.MaxUint64 / 2)
+	if gasCap := api.eth.config.RPCGasCap; gasCap != 0 && gasCap < gas {
+		gas = gasCap
+	}
+	ctx, cancel := context.WithTimeout(ctx, contractUpgradeSimulationTimeout)
+	defer cancel()
+	return ac.SimulateUpgrade(ctx, stateDB, header, bytecode, abi, gas)
+}
+
 // Preimage is a debug API function that returns the preimage for a sha3 hash, if known.
 func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
 	if preimage := rawdb.ReadPreimage(api.eth.ChainDb(), hash); preimage != nil {
@@ -541,9 +570,18 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 // struct would be better defined in the rpc package or in the autonity
 // package, circular dependencies make it infeasible.
 type AutonityContractAPI struct {
+	bc    *core.BlockChain
+	ac    *autonity.Contract
 	calls map[string]reflect.Value
 }
 
+// AutonityStateDump is the decoded state of the autonity contract at a block.
+type AutonityStateDump struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	*autonity.StateDump
+}
+
 // NewAutonityContractAPI builds a map of function name to method representing
 // the view functions of the autonity contract, the map is then used as the
 // return value for AllMethods. The methods are dynamically generated from the
@@ -552,18 +590,22 @@ type AutonityContractAPI struct {
 // themselves make no use of the method receiver. This design is required to be
 // able to fit into the current approach taken for registering rpc services.
 // See rpc.Server.RegisterName().
+//
+// Every method takes, after the contract arguments, an optional block number
+// or hash selecting the state the call is run against, as eth_call does. The
+// latest block is used when it is omitted.
 func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.Contract) *AutonityContractAPI {
 	var viewMethodStr = "view"
-	var contractABI = ac.ABI()
 	var contractViewMethods = make(map[string]reflect.Value)
 
-	for n, m := range contractABI.Methods {
+	for n, m := range ac.ABI().Methods {
 		functionName := n
 		// Only expose read-only functions.
 		if m.StateMutability == viewMethodStr {
 			// The RPC service expect the first argument of an API method to be the receiver object.
 			inArgs := []reflect.Type{reflect.TypeOf(&AutonityContractAPI{})}
 			inArgs = append(inArgs, m.Inputs.Types()...)
+			inArgs = append(inArgs, reflect.TypeOf(&rpc.BlockNumberOrHash{}))
 			sig := reflect.FuncOf(inArgs, []reflect.Type{
 				reflect.TypeOf((*interface{})(nil)).Elem(),
 				reflect.TypeOf((*error)(nil)).Elem(),
@@ -575,13 +617,15 @@ func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.Contract) *Autonit
 					makereturn := func(res interface{}, err error) []reflect.Value {
 						return []reflect.Value{reflect.ValueOf(&res).Elem(), reflect.ValueOf(&err).Elem()}
 					}
-					stateDB, err := bc.State()
+					// The last argument is the optional block.
+					blockNrOrHash := args[len(args)-1].Interface().(*rpc.BlockNumberOrHash)
+					stateDB, header, err := autonityStateAndHeader(bc, blockNrOrHash)
 					if err != nil {
 						return makereturn(nil, err)
 					}
 					var iargs []interface{}
 					// args[0] is the reflect.Value of *AutonityContractAPI.
-					for i, arg := range args[1:] {
+					for i, arg := range args[1 : len(args)-1] {
 						// If the argument is a pointer it is then an optional parameter for the RPC handler. The
 						// json unmarshalling function set it to nil if the argument isn't set in the RPC call.
 						// There are no optional parameters for the Autonity contract methods. Solidity doesn't
@@ -592,12 +636,22 @@ func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.Contract) *Autonit
 						iargs = append(iargs, arg.Interface())
 					}
 
+					// The contract is called with the ABI it had at the block, which
+					// differs from the current one if it has been upgraded since.
+					contractABI := ac.ABIAt(header.Number.Uint64())
+					if contractABI == nil {
+						return makereturn(nil, fmt.Errorf("contract abi unknown at block %d", header.Number.Uint64()))
+					}
+					if _, ok := contractABI.Methods[functionName]; !ok {
+						return makereturn(nil, fmt.Errorf("method %s is not available at block %d", functionName, header.Number.Uint64()))
+					}
+
 					// Pack the arguments call the function and then unpack the result and return it.
 					packedArgs, err := contractABI.Pack(functionName, iargs...)
 					if err != nil {
 						return makereturn(nil, err)
 					}
-					packedResult, err := ac.CallContractFunc(stateDB, bc.CurrentHeader(), functionName, packedArgs)
+					packedResult, err := ac.CallContractFunc(stateDB, header, functionName, packedArgs)
 					if err != nil {
 						return makereturn(nil, err)
 					}
@@ -611,9 +665,69 @@ func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.Contract) *Autonit
 				})
 		}
 	}
-	return &AutonityContractAPI{calls: contractViewMethods}
+	// The decoded state dump and the upgrade history are served next to the
+	// contract methods, the contract has no method of these names.
+	contractViewMethods["dumpState"] = reflect.ValueOf((*AutonityContractAPI).DumpState)
+	contractViewMethods["upgradeHistory"] = reflect.ValueOf((*AutonityContractAPI).UpgradeHistory)
+	return &AutonityContractAPI{bc: bc, ac: ac, calls: contractViewMethods}
 }
 
 func (a *AutonityContractAPI) AllMethods() map[string]reflect.Value {
 	return a.calls
 }
+
+// DumpState returns the whole state of the autonity contract, as returned by
+// getState, decoded at the given block or at the latest block if omitted.
+func (a *AutonityContractAPI) DumpState(blockNrOrHash *rpc.BlockNumberOrHash) (*AutonityStateDump, error) {
+	stateDB, header, err := autonityStateAndHeader(a.bc, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := a.ac.DumpState(stateDB, header)
+	if err != nil {
+		return nil, err
+	}
+	return &AutonityStateDump{
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		StateDump:   dump,
+	}, nil
+}
+
+// UpgradeHistory returns the upgrades of the autonity contract applied so far.
+func (a *AutonityContractAPI) UpgradeHistory() ([]autonity.ContractUpgrade, error) {
+	return a.ac.UpgradeHistory()
+}
+
+// autonityStateAndHeader returns the state and the header of the block the
+// autonity contract is queried at, the latest block if blockNrOrHash is nil.
+func autonityStateAndHeader(bc *core.BlockChain, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	var header *types.Header
+	if blockNrOrHash == nil {
+		header = bc.CurrentHeader()
+	} else if blockNr, ok := blockNrOrHash.Number(); ok {
+		switch blockNr {
+		case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+			header = bc.CurrentHeader()
+		case rpc.EarliestBlockNumber:
+			header = bc.GetHeaderByNumber(0)
+		default:
+			header = bc.GetHeaderByNumber(uint64(blockNr))
+		}
+	} else if hash, ok := blockNrOrHash.Hash(); ok {
+		header = bc.GetHeaderByHash(hash)
+		if header != nil && blockNrOrHash.RequireCanonical && bc.GetCanonicalHash(header.Number.Uint64()) != hash {
+			return nil, nil, errors.New("hash is not currently canonical")
+		}
+	} else {
+		return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	stateDB, err := bc.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateDB, header, nil
+}
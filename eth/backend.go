@@ -21,15 +21,19 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
 
 	tendermintBackend "github.com/clearmatics/autonity/consensus/tendermint/backend"
 	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/crypto/bls"
 	"github.com/clearmatics/autonity/p2p/enode"
 
 	"github.com/clearmatics/autonity/accounts"
+	"github.com/clearmatics/autonity/accounts/external"
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/consensus"
@@ -56,6 +60,9 @@ import (
 	"github.com/clearmatics/autonity/rpc"
 )
 
+// datadirBLSKey is the path within the datadir to the BLS key of the node.
+const datadirBLSKey = "blskey"
+
 // Ethereum implements the Ethereum full node service.
 type Ethereum struct {
 	config *Config
@@ -91,6 +98,11 @@ type Ethereum struct {
 
 	glienickeCh  chan core.WhitelistEvent
 	glienickeSub event.Subscription
+
+	messageLog *tendermintcore.MessageLog // consensus message log, outlives the engine restarts
+
+	proxies     []*enode.Node // sentries of the validator or validators of the sentry, always whitelisted
+	proxiesOnly bool          // whether the proxies are the only peers, the node being a validator with sentries
 }
 
 // New creates a new Ethereum object (including the
@@ -146,10 +158,29 @@ func New(stack *node.Node, config *Config, cons func(basic consensus.Engine) con
 	)
 	log.Info("Initialised chain configuration", "config", chainConfig)
 
-	consEngine := CreateConsensusEngine(stack, chainConfig, config, config.Miner.Notify, config.Miner.Noverify, chainDb, &vmConfig)
+	consEngine, err := CreateConsensusEngine(stack, chainConfig, config, config.Miner.Notify, config.Miner.Noverify, chainDb, &vmConfig)
+	if err != nil {
+		return nil, err
+	}
 	if cons != nil {
 		consEngine = cons(consEngine)
 	}
+	if tendermint, ok := consEngine.(*tendermintBackend.Backend); ok && chainConfig.Tendermint.AggregatedSeals {
+		key, err := blsKey(stack.ResolvePath(datadirBLSKey))
+		if err != nil {
+			return nil, err
+		}
+		tendermint.SetBLSKey(key)
+	}
+	var messageLog *tendermintcore.MessageLog
+	if tendermint, ok := consEngine.(*tendermintBackend.Backend); ok && config.Tendermint.MessageLog != "" {
+		dir := stack.ResolvePath(config.Tendermint.MessageLog)
+		if messageLog, err = tendermintcore.OpenMessageLog(dir, tendermintcore.DefaultMessageLogFileSize, tendermintcore.DefaultMessageLogFiles); err != nil {
+			return nil, fmt.Errorf("consensus message log: %v", err)
+		}
+		log.Info("Recording consensus messages", "dir", dir)
+		tendermint.SetMessageLog(messageLog)
+	}
 
 	eth := &Ethereum{
 		config:            config,
@@ -165,6 +196,7 @@ func New(stack *node.Node, config *Config, cons func(basic consensus.Engine) con
 		bloomIndexer:      NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		glienickeCh:       make(chan core.WhitelistEvent),
 		p2pServer:         stack.Server(),
+		messageLog:        messageLog,
 	}
 
 	// force to set the istanbul etherbase to node key address
@@ -218,6 +250,13 @@ func New(stack *node.Node, config *Config, cons func(basic consensus.Engine) con
 	if eth.protocolManager, err = NewProtocolManager(chainConfig, checkpoint, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb, cacheLimit, config.Whitelist, &stack.Config().NodeKey().PublicKey); err != nil {
 		return nil, err
 	}
+	eth.protocolManager.SetRolePolicies(config.PeerRoles)
+	if err := eth.setupSentries(&config.Tendermint); err != nil {
+		return nil, err
+	}
+	if config.CommitteeCheckpoint != nil && chainConfig.Tendermint != nil {
+		eth.protocolManager.downloader.SetCommitteeCheckpoint(config.CommitteeCheckpoint, tendermintBackend.VerifyCommitteeHandoff)
+	}
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
@@ -254,11 +293,72 @@ func makeExtraData(extra []byte) []byte {
 	return extra
 }
 
+// CreateLightConsensusEngine creates the consensus engine used by a light client
+// to verify the headers it follows.
+func CreateLightConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, config *Config, db ethdb.Database) (consensus.Engine, error) {
+	if chainConfig.Tendermint != nil {
+		return tendermintBackend.NewLight(&config.Tendermint, chainConfig), nil
+	}
+	return CreateConsensusEngine(ctx, chainConfig, config, nil, false, db, nil)
+}
+
+// consensusSigner returns the signer of the tendermint consensus data: the external
+// signer at endpoint, which keeps the validator key out of the node, or the node
+// key if endpoint is empty. As the committee members are reached by their p2p
+// identity, the external signer must hold the account of the node key. The
+// external signer keeps its own signing watermark, refusing any consensus data
+// at or below the latest it signed for the account.
+func consensusSigner(ctx *node.Node, endpoint string) (tendermintBackend.Signer, error) {
+	if endpoint == "" {
+		return tendermintBackend.NewKeySigner(ctx.Config().NodeKey()), nil
+	}
+	wallet, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("consensus signer: %v", err)
+	}
+	signer, err := tendermintBackend.NewClefSigner(wallet, crypto.PubkeyToAddress(ctx.Config().NodeKey().PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Using external consensus signer", "url", endpoint, "address", signer.Address())
+	return signer, nil
+}
+
+// blsKey loads the BLS key signing the aggregated committed seals from file, a new
+// key is generated and stored if the file doesn't exist. An ephemeral key is used if
+// the node has no data directory.
+func blsKey(file string) (*bls.SecretKey, error) {
+	if file == "" {
+		return bls.GenerateKey()
+	}
+	if key, err := bls.LoadKey(file); err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("bls key: %v", err)
+	}
+	key, err := bls.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	if err := bls.SaveKey(file, key); err != nil {
+		return nil, err
+	}
+	log.Info("Generated BLS key, register it in the Autonity contract to sign aggregated committed seals", "file", file)
+	return key, nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
-func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool, db ethdb.Database, vmConfig *vm.Config) consensus.Engine {
+func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool, db ethdb.Database, vmConfig *vm.Config) (consensus.Engine, error) {
 
 	if chainConfig.Tendermint != nil {
-		return tendermintBackend.New(&config.Tendermint, ctx.Config().NodeKey(), db, chainConfig, vmConfig)
+		signer, err := consensusSigner(ctx, config.Tendermint.Signer)
+		if err != nil {
+			return nil, err
+		}
+		return tendermintBackend.New(&config.Tendermint, signer, db, chainConfig, vmConfig), nil
 	}
 
 	// Otherwise assume proof-of-work
@@ -267,10 +367,10 @@ func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, conf
 	switch ethConfig.PowMode {
 	case ethash.ModeFake:
 		log.Warn("Ethash used in fake mode")
-		return ethash.NewFaker()
+		return ethash.NewFaker(), nil
 	case ethash.ModeTest:
 		log.Warn("Ethash used in test mode")
-		return ethash.NewTester(nil, noverify)
+		return ethash.NewTester(nil, noverify), nil
 	default:
 		engine := ethash.New(ethash.Config{
 			CacheDir:         ctx.ResolvePath(ethConfig.CacheDir),
@@ -283,7 +383,7 @@ func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, conf
 			DatasetsLockMmap: ethConfig.DatasetsLockMmap,
 		}, notify, noverify)
 		engine.SetThreads(-1) // Disable CPU mining
-		return engine
+		return engine, nil
 	}
 }
 
@@ -301,6 +401,11 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   params.Version,
 			Service:   NewAutonityContractAPI(s.BlockChain(), s.BlockChain().GetAutonityContract()),
 			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   filters.NewAutonityEventAPI(s.APIBackend, false),
+			Public:    true,
 		})
 	}
 
@@ -517,8 +622,12 @@ func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
-	protos := make([]p2p.Protocol, len(ProtocolVersions))
-	for i, vsn := range ProtocolVersions {
+	versions := ProtocolVersions
+	if _, ok := s.engine.(consensus.Handler); ok {
+		versions = consensusProtocolVersions
+	}
+	protos := make([]p2p.Protocol, len(versions))
+	for i, vsn := range versions {
 		protos[i] = s.protocolManager.makeProtocol(vsn)
 		protos[i].Attributes = []enr.Entry{s.currentEthEntry()}
 		protos[i].DialCandidates = s.dialCandidates
@@ -556,12 +665,16 @@ func (s *Ethereum) glienickeEventLoop(server *p2p.Server) {
 
 	savedList := rawdb.ReadEnodeWhitelist(s.chainDb)
 	log.Info("Reading Whitelist", "list", savedList.StrList)
-	server.UpdateWhitelist(savedList.List)
+	server.UpdateWhitelist(s.withProxies(savedList.List))
 
 	for {
 		select {
 		case event := <-s.glienickeCh:
-			whitelist := append([]*enode.Node{}, event.Whitelist...)
+			if s.proxiesOnly {
+				// The sentries are the only peers whatever the whitelist.
+				continue
+			}
+			whitelist := s.withProxies(event.Whitelist)
 			// Filter the list of need to be dropped peers depending on TD.
 			for _, connectedPeer := range s.protocolManager.peers.Peers() {
 				found := false
@@ -591,6 +704,16 @@ func (s *Ethereum) glienickeEventLoop(server *p2p.Server) {
 	}
 }
 
+// withProxies returns the enodes to connect to given the whitelist: the sentries
+// for a validator with sentries, the whitelist and the protected validators for
+// a sentry.
+func (s *Ethereum) withProxies(whitelist []*enode.Node) []*enode.Node {
+	if s.proxiesOnly {
+		return s.proxies
+	}
+	return append(append([]*enode.Node{}, whitelist...), s.proxies...)
+}
+
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
@@ -604,6 +727,7 @@ func (s *Ethereum) Stop() error {
 	s.miner.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
+	s.messageLog.Close()
 	s.chainDb.Close()
 	s.eventMux.Stop()
 	return nil
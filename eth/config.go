@@ -160,6 +160,10 @@ type Config struct {
 	// Tendermint options
 	Tendermint config.Config
 
+	// Network privileges of the peers by user type of the autonity contract,
+	// overriding DefaultRolePolicies
+	PeerRoles RolePolicies `toml:",omitempty"`
+
 	// Transaction pool options
 	TxPool core.TxPoolConfig
 
@@ -190,4 +194,8 @@ type Config struct {
 
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
+
+	// CommitteeCheckpoint is a trusted header enabling the committee skip sync
+	// of Tendermint chains, it can be nil.
+	CommitteeCheckpoint *downloader.CommitteeCheckpoint `toml:",omitempty"`
 }
@@ -0,0 +1,194 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+)
+
+// errCheckpointMismatch is returned when a peer serves a header at the committee
+// checkpoint height which is not the trusted one.
+var errCheckpointMismatch = errors.New("committee checkpoint hash mismatch")
+
+// CommitteeCheckpoint is a header height and hash trusted by the node operator,
+// it anchors the committee skip sync.
+type CommitteeCheckpoint struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// HandoffVerifier checks that header was committed by a quorum of the committee
+// stored in trusted, trusted being any ancestor of header.
+type HandoffVerifier func(header, trusted *types.Header) error
+
+// SetCommitteeCheckpoint enables the committee skip sync: before downloading the
+// chain, the headers where the committee changed between the checkpoint and the
+// remote head are located and verified, giving a trusted head right away. The
+// headers in between are then back-filled by the regular sync, which must match
+// the verified hand-off points. Being authenticated by those, the back-filled
+// headers are imported without verifying their seals during fast sync.
+func (d *Downloader) SetCommitteeCheckpoint(checkpoint *CommitteeCheckpoint, verify HandoffVerifier) {
+	d.handoffLock.Lock()
+	defer d.handoffLock.Unlock()
+
+	d.committeeCheckpoint = checkpoint
+	d.verifyHandoff = verify
+}
+
+// TrustedHead returns the latest header verified by the committee skip sync, it
+// may not be imported in the local chain yet.
+func (d *Downloader) TrustedHead() *types.Header {
+	d.handoffLock.RLock()
+	defer d.handoffLock.RUnlock()
+
+	return d.trustedHead
+}
+
+// trustedHeaderInserter is a chain importing the headers authenticated by the
+// committee hand-offs without verifying their seals.
+type trustedHeaderInserter interface {
+	InsertTrustedHeaderChain([]*types.Header) (int, error)
+}
+
+// handoffsTrusted returns whether headers are at or below the trusted head. Such
+// headers are authenticated by their hash linkage to the verified hand-off points,
+// enforced by checkHandoffs, so that their seals need no verification.
+func (d *Downloader) handoffsTrusted(headers []*types.Header) bool {
+	head := d.TrustedHead()
+	return head != nil && headers[len(headers)-1].Number.Uint64() <= head.Number.Uint64()
+}
+
+// lastHandoff returns the height of the latest verified hand-off point at or
+// below number, zero if there is none.
+func (d *Downloader) lastHandoff(number uint64) uint64 {
+	d.handoffLock.RLock()
+	defer d.handoffLock.RUnlock()
+
+	var last uint64
+	for height := range d.handoffs {
+		if height <= number && height > last {
+			last = height
+		}
+	}
+	return last
+}
+
+// hopCommittees verifies the remote head by following the committee hand-offs
+// from the committee checkpoint. The header at the remote head height is verified
+// against the latest trusted committee, if it does not carry a quorum of seals from
+// it, the committee changed in between and the search is bisected until the
+// hand-off header is found, which then becomes trusted.
+func (d *Downloader) hopCommittees(p *peerConnection, head *types.Header) error {
+	d.handoffLock.RLock()
+	checkpoint, verify := d.committeeCheckpoint, d.verifyHandoff
+	d.handoffLock.RUnlock()
+
+	if checkpoint == nil || verify == nil || head.Number.Uint64() <= checkpoint.Number {
+		return nil
+	}
+	trusted := d.lightchain.CurrentHeader()
+	if trusted.Number.Uint64() < checkpoint.Number {
+		cp, err := d.fetchHeaderByNumber(p, checkpoint.Number)
+		if err != nil {
+			return err
+		}
+		if cp.Hash() != checkpoint.Hash {
+			return fmt.Errorf("%w: %v", errBadPeer, errCheckpointMismatch)
+		}
+		trusted = cp
+	}
+	handoffs := map[uint64]common.Hash{checkpoint.Number: checkpoint.Hash}
+	start := time.Now()
+
+	target := head.Number.Uint64()
+	for trusted.Number.Uint64() < target {
+		candidate := target
+		for {
+			header := head
+			if candidate != target {
+				var err error
+				if header, err = d.fetchHeaderByNumber(p, candidate); err != nil {
+					return err
+				}
+			}
+			err := verify(header, trusted)
+			if err == nil && candidate == trusted.Number.Uint64()+1 && header.ParentHash != trusted.Hash() {
+				err = errInvalidAncestor
+			}
+			if err == nil {
+				p.log.Trace("Verified committee hand-off", "number", header.Number, "hash", header.Hash())
+				handoffs[candidate] = header.Hash()
+				trusted = header
+				committeeHopMeter.Mark(1)
+				break
+			}
+			// The next header after a trusted one has to be committed by its committee.
+			if candidate == trusted.Number.Uint64()+1 {
+				return fmt.Errorf("%w: committee hand-off at %d: %v", errInvalidChain, candidate, err)
+			}
+			candidate = trusted.Number.Uint64() + (candidate-trusted.Number.Uint64())/2
+		}
+	}
+	log.Info("Verified remote head through committee hand-offs", "number", head.Number, "hash", head.Hash(),
+		"handoffs", len(handoffs)-1, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	d.handoffLock.Lock()
+	d.trustedHead = head
+	d.handoffs = handoffs
+	d.handoffLock.Unlock()
+	return nil
+}
+
+// checkHandoffs ensures the back-filled headers go through the verified hand-off points.
+func (d *Downloader) checkHandoffs(headers []*types.Header) error {
+	d.handoffLock.RLock()
+	defer d.handoffLock.RUnlock()
+
+	if len(d.handoffs) == 0 {
+		return nil
+	}
+	for _, header := range headers {
+		if hash, ok := d.handoffs[header.Number.Uint64()]; ok && header.Hash() != hash {
+			return fmt.Errorf("%w: header %d does not match the committee hand-off %x", errInvalidChain, header.Number, hash)
+		}
+	}
+	return nil
+}
+
+// fetchHeaderByNumber retrieves a single header from a remote peer.
+func (d *Downloader) fetchHeaderByNumber(p *peerConnection, number uint64) (*types.Header, error) {
+	go p.peer.RequestHeadersByNumber(number, 1, 0, false)
+
+	ttl := d.requestTTL()
+	timeout := time.After(ttl)
+	for {
+		select {
+		case <-d.cancelCh:
+			return nil, errCanceled
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			headers := packet.(*headerPack).headers
+			if len(headers) != 1 || headers[0].Number.Uint64() != number {
+				return nil, fmt.Errorf("%w: header %d not delivered", errBadPeer, number)
+			}
+			return headers[0], nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for header timed out", "number", number, "elapsed", ttl)
+			return nil, errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+)
+
+// committeeEra simulates a committee change every 100 blocks, the header at a
+// multiple of 100 storing the new committee.
+func committeeEra(number uint64) uint64 {
+	return number / 100
+}
+
+// testHandoffVerifier accepts header if it is committed by the committee of trusted.
+func testHandoffVerifier(header, trusted *types.Header) error {
+	if committeeEra(header.Number.Uint64()-1) != committeeEra(trusted.Number.Uint64()) {
+		return errors.New("not committed by the trusted committee")
+	}
+	return nil
+}
+
+func TestCommitteeSkipSync(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 65, chain)
+
+	checkpoint := chain.headerm[chain.chain[10]]
+	tester.downloader.SetCommitteeCheckpoint(&CommitteeCheckpoint{Number: 10, Hash: checkpoint.Hash()}, testHandoffVerifier)
+
+	if err := tester.sync("peer", nil, FullSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, chain.len())
+
+	head := tester.downloader.TrustedHead()
+	if head == nil || head.Hash() != chain.headBlock().Hash() {
+		t.Fatalf("trusted head mismatch: have %v, want %v", head, chain.headBlock().Hash())
+	}
+	// Every committee hand-off must have been verified.
+	for number := uint64(100); number < uint64(chain.len()); number += 100 {
+		if hash, ok := tester.downloader.handoffs[number]; !ok || hash != chain.chain[number] {
+			t.Errorf("committee hand-off %d not verified", number)
+		}
+	}
+}
+
+func TestCommitteeSkipSyncCheckpointMismatch(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 65, chain)
+
+	tester.downloader.SetCommitteeCheckpoint(&CommitteeCheckpoint{Number: 10, Hash: chain.chain[11]}, testHandoffVerifier)
+
+	if err := tester.sync("peer", nil, FullSync); !errors.Is(err, errBadPeer) {
+		t.Fatalf("sync error mismatch: have %v, want %v", err, errBadPeer)
+	}
+	if tester.downloader.TrustedHead() != nil {
+		t.Fatalf("trusted head set after a failed sync")
+	}
+}
+
+func TestCommitteeSkipSyncInvalidHandoff(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 65, chain)
+
+	// The header storing the third committee is not committed by the second one.
+	verify := func(header, trusted *types.Header) error {
+		if header.Number.Uint64() >= 300 && committeeEra(trusted.Number.Uint64()) < 3 {
+			return errors.New("invalid seals")
+		}
+		return testHandoffVerifier(header, trusted)
+	}
+	tester.downloader.SetCommitteeCheckpoint(&CommitteeCheckpoint{Number: 10, Hash: chain.chain[10]}, verify)
+
+	if err := tester.sync("peer", nil, FullSync); !errors.Is(err, errInvalidChain) {
+		t.Fatalf("sync error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+}
+
+func TestCommitteeSkipSyncSeals(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 65, chain)
+
+	tester.downloader.SetCommitteeCheckpoint(&CommitteeCheckpoint{Number: 10, Hash: chain.chain[10]}, testHandoffVerifier)
+	if err := tester.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	// The headers are imported without their seals but those before the pivot.
+	if !tester.trusted[1] {
+		t.Errorf("header imported with its seals verified")
+	}
+	if tester.trusted[uint64(chain.len()-1)] {
+		t.Errorf("pivot header imported without its seals verified")
+	}
+}
+
+func TestCommitteeSkipSyncRollback(t *testing.T) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", 65, chain)
+
+	// The hand-off at 500 trusted by a previous sync is not the one served by the peer.
+	tester.downloader.handoffs = map[uint64]common.Hash{10: chain.chain[10], 500: chain.chain[501]}
+	tester.downloader.trustedHead = chain.headerm[chain.chain[chain.len()-1]]
+	if last := tester.downloader.lastHandoff(499); last != 10 {
+		t.Fatalf("last hand-off mismatch: have %d, want 10", last)
+	}
+
+	if err := tester.sync("peer", nil, FastSync); !errors.Is(err, errInvalidChain) {
+		t.Fatalf("sync error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	if head := tester.CurrentHeader().Number.Uint64(); head > 10 {
+		t.Fatalf("headers after the last hand-off not rolled back: head %d", head)
+	}
+}
@@ -138,6 +138,13 @@ type Downloader struct {
 	receiptWakeCh chan bool            // [eth/63] Channel to signal the receipt fetcher of new tasks
 	headerProcCh  chan []*types.Header // [eth/62] Channel to feed the header processor new tasks
 
+	// Committee skip sync
+	committeeCheckpoint *CommitteeCheckpoint   // Trusted header anchoring the committee skip sync, nil if disabled
+	verifyHandoff       HandoffVerifier        // Verifies a header against the committee of a trusted ancestor
+	trustedHead         *types.Header          // Latest header verified through committee hand-offs
+	handoffs            map[uint64]common.Hash // Verified headers the back-filled chain must go through
+	handoffLock         sync.RWMutex           // Lock protecting the committee skip sync fields
+
 	// State sync
 	pivotHeader *types.Header // Pivot block header to dynamically push the syncing state root
 	pivotLock   sync.RWMutex  // Lock protecting pivot header reads from updates
@@ -467,6 +474,11 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	}
 	height := latest.Number.Uint64()
 
+	if mode == FullSync || mode == FastSync {
+		if err := d.hopCommittees(p, latest); err != nil {
+			return err
+		}
+	}
 	origin, err := d.findAncestor(p, latest)
 	if err != nil {
 		return err
@@ -1484,6 +1496,7 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 	var (
 		rollback    uint64 // Zero means no rollback (fine as you can't unroll the genesis)
 		rollbackErr error
+		unchecked   uint64 // Highest header imported without verifying its seals
 		mode        = d.getMode()
 	)
 	defer func() {
@@ -1579,6 +1592,11 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 				}
 				chunk := headers[:limit]
 
+				if err := d.checkHandoffs(chunk); err != nil {
+					rollbackErr = err
+					return err
+				}
+
 				// In case of header only syncing, validate the chunk immediately
 				if mode == FastSync || mode == LightSync {
 					// If we're importing pure headers, verify based on their recentness
@@ -1594,25 +1612,41 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 					if chunk[len(chunk)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > pivot {
 						frequency = 1
 					}
-					if n, err := d.lightchain.InsertHeaderChain(chunk, frequency); err != nil {
+					// The seals of the headers authenticated by the committee hand-offs aren't verified
+					inserter, trusted := d.lightchain.(trustedHeaderInserter)
+					trusted = trusted && frequency != 1 && d.handoffsTrusted(chunk)
+					insert := func() (int, error) {
+						if trusted {
+							return inserter.InsertTrustedHeaderChain(chunk)
+						}
+						return d.lightchain.InsertHeaderChain(chunk, frequency)
+					}
+					if n, err := insert(); err != nil {
 						rollbackErr = err
 
 						// If some headers were inserted, track them as uncertain
-						if (mode == FastSync || frequency > 1) && n > 0 && rollback == 0 {
+						if (mode == FastSync || frequency > 1 || trusted) && n > 0 && rollback == 0 {
 							rollback = chunk[0].Number.Uint64()
 						}
 						log.Warn("Invalid header encountered", "number", chunk[n].Number, "hash", chunk[n].Hash(), "parent", chunk[n].ParentHash, "err", err)
 						return fmt.Errorf("%w: %v", errInvalidChain, err)
 					}
 					// All verifications passed, track all headers within the alloted limits
+					head := chunk[len(chunk)-1].Number.Uint64()
 					if mode == FastSync {
-						head := chunk[len(chunk)-1].Number.Uint64()
 						if head-rollback > uint64(fsHeaderSafetyNet) {
 							rollback = head - uint64(fsHeaderSafetyNet)
 						} else {
 							rollback = 1
 						}
 					}
+					// The headers imported without their seals are uncertain until the next hand-off
+					if trusted {
+						unchecked = head
+					}
+					if last := d.lastHandoff(head); unchecked > last && (rollback == 0 || last+1 < rollback) {
+						rollback = last + 1
+					}
 				}
 				// Unless we're doing light chains, schedule the headers for associated content retrieval
 				if mode == FullSync || mode == FastSync {
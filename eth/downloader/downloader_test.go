@@ -63,6 +63,8 @@ type downloadTester struct {
 	ancientReceipts map[common.Hash]types.Receipts // Ancient receipts belonging to the tester
 	ancientChainTd  map[common.Hash]*big.Int       // Ancient total difficulties of the blocks in the local chain
 
+	trusted map[uint64]bool // Headers imported without verifying their seals by number
+
 	lock sync.RWMutex
 }
 
@@ -253,6 +255,16 @@ func (dl *downloadTester) getTd(hash common.Hash) *big.Int {
 
 // InsertHeaderChain injects a new batch of headers into the simulated chain.
 func (dl *downloadTester) InsertHeaderChain(headers []*types.Header, checkFreq int) (i int, err error) {
+	return dl.insertHeaderChain(headers, false)
+}
+
+// InsertTrustedHeaderChain injects a new batch of headers authenticated by the
+// committee hand-offs into the simulated chain.
+func (dl *downloadTester) InsertTrustedHeaderChain(headers []*types.Header) (i int, err error) {
+	return dl.insertHeaderChain(headers, true)
+}
+
+func (dl *downloadTester) insertHeaderChain(headers []*types.Header, trusted bool) (i int, err error) {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 	// Do a quick check, as the blockchain.InsertHeaderChain doesn't insert anything in case of errors
@@ -280,6 +292,10 @@ func (dl *downloadTester) InsertHeaderChain(headers []*types.Header, checkFreq i
 		}
 		dl.ownHashes = append(dl.ownHashes, hash)
 		dl.ownHeaders[hash] = header
+		if dl.trusted == nil {
+			dl.trusted = make(map[uint64]bool)
+		}
+		dl.trusted[header.Number.Uint64()] = trusted
 
 		td := dl.getTd(header.ParentHash)
 		dl.ownChainTd[hash] = new(big.Int).Add(td, header.Difficulty)
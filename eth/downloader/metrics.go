@@ -41,5 +41,7 @@ var (
 	stateInMeter   = metrics.NewRegisteredMeter("eth/downloader/states/in", nil)
 	stateDropMeter = metrics.NewRegisteredMeter("eth/downloader/states/drop", nil)
 
+	committeeHopMeter = metrics.NewRegisteredMeter("eth/downloader/committee/hops", nil)
+
 	throttleCounter = metrics.NewRegisteredCounter("eth/downloader/throttle", nil)
 )
@@ -0,0 +1,138 @@
+package filters
+
+import (
+	"context"
+
+	ethereum "github.com/clearmatics/autonity"
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rpc"
+)
+
+// AutonityEventAPI offers subscriptions to the decoded events of the Autonity
+// contract. It is registered under the aut namespace, a client subscribes
+// with aut_subscribe and the name of the event, e.g. "userAdded".
+type AutonityEventAPI struct {
+	backend Backend
+	events  *EventSystem
+}
+
+// NewAutonityEventAPI returns a new AutonityEventAPI instance.
+func NewAutonityEventAPI(backend Backend, lightMode bool) *AutonityEventAPI {
+	return &AutonityEventAPI{
+		backend: backend,
+		events:  NewEventSystem(backend, lightMode),
+	}
+}
+
+// CommitteeChanged sends the committee of each new head whose committee
+// differs from the one of its parent.
+func (api *AutonityEventAPI) CommitteeChanged(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeNewHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				parent, err := api.backend.HeaderByHash(context.Background(), h.ParentHash)
+				if err != nil {
+					log.Debug("Could not retrieve parent header", "number", h.Number, "err", err)
+					continue
+				}
+				if ev := autonity.NewCommitteeChangedEvent(parent, h); ev != nil {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// UserAdded sends the users added to the Autonity contract.
+func (api *AutonityEventAPI) UserAdded(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeContractEvents(ctx, autonity.EventTopics[autonity.EventUserAdded])
+}
+
+// StakeChanged sends the stake minted and burned by the Autonity contract.
+func (api *AutonityEventAPI) StakeChanged(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeContractEvents(ctx, autonity.EventTopics[autonity.EventStakeChanged])
+}
+
+// ContractUpgraded sends the upgrades requested to the Autonity contract.
+func (api *AutonityEventAPI) ContractUpgraded(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeContractEvents(ctx, autonity.EventTopics[autonity.EventContractUpgraded])
+}
+
+// MinGasPriceChanged sends the updates of the minimum gas price.
+func (api *AutonityEventAPI) MinGasPriceChanged(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeContractEvents(ctx, autonity.EventTopics[autonity.EventMinGasPriceChanged])
+}
+
+// ValidatorSlashed sends the validators punished for a misbehaviour.
+func (api *AutonityEventAPI) ValidatorSlashed(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribeContractEvents(ctx, autonity.EventTopics[autonity.EventValidatorSlashed])
+}
+
+// subscribeContractEvents decodes and sends the logs of the Autonity contract
+// matching one of the topics, including the logs of the finalize receipt.
+func (api *AutonityEventAPI) subscribeContractEvents(ctx context.Context, topics []common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub      = notifier.CreateSubscription()
+		matchedLogs = make(chan []*types.Log)
+		crit        = ethereum.FilterQuery{
+			Addresses: []common.Address{autonity.ContractAddress},
+			Topics:    [][]common.Hash{topics},
+		}
+	)
+
+	logsSub, err := api.events.SubscribeLogs(crit, matchedLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case logs := <-matchedLogs:
+				for _, l := range logs {
+					ev, err := autonity.DecodeEvent(l)
+					if err != nil {
+						log.Debug("Could not decode autonity contract log", "tx", l.TxHash, "err", err)
+						continue
+					}
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				logsSub.Unsubscribe()
+				return
+			case <-notifier.Closed(): // connection dropped
+				logsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,71 @@
+package filters
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/rawdb"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/rpc"
+)
+
+func TestAutonityEventSubscription(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		server  = rpc.NewServer()
+	)
+	require.NoError(t, server.RegisterName("aut", NewAutonityEventAPI(backend, false)))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	events := make(chan *autonity.StakeChangedEvent)
+	sub, err := client.Subscribe(context.Background(), "aut", events, autonity.EventStakeChanged)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	contractABI, err := abi.JSON(strings.NewReader(autonity.AutonityABI))
+	require.NoError(t, err)
+	newLog := func(event string, args ...interface{}) *types.Log {
+		data, err := contractABI.Events[event].Inputs.NonIndexed().Pack(args...)
+		require.NoError(t, err)
+		return &types.Log{
+			Address:     autonity.ContractAddress,
+			Topics:      []common.Hash{contractABI.Events[event].ID},
+			Data:        data,
+			BlockNumber: 3,
+			TxHash:      common.ACHash(big.NewInt(3)),
+		}
+	}
+	user := common.HexToAddress("0x1234")
+
+	// Give the event system time to install the subscription.
+	time.Sleep(100 * time.Millisecond)
+	backend.logsFeed.Send([]*types.Log{
+		newLog("MinimumGasPriceUpdated", big.NewInt(1)),
+		newLog("MintedStake", user, big.NewInt(5)),
+	})
+
+	select {
+	case ev := <-events:
+		require.Equal(t, user, ev.Address)
+		require.Equal(t, autonity.StakeMinted, ev.Direction)
+		require.Equal(t, big.NewInt(5), ev.Amount)
+		require.True(t, ev.Finalize)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	_, err = client.Subscribe(context.Background(), "aut", events, "unknown")
+	require.Error(t, err)
+}
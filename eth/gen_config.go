@@ -47,16 +47,18 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Miner                   miner.Config
 		Ethash                  ethash.Config
 		Tendermint              config.Config
+		PeerRoles               RolePolicies `toml:",omitempty"`
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
 		EWASMInterpreter        string
 		EVMInterpreter          string
-		RPCGasCap               uint64                         `toml:",omitempty"`
-		RPCTxFeeCap             float64                        `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		RPCGasCap               uint64                          `toml:",omitempty"`
+		RPCTxFeeCap             float64                         `toml:",omitempty"`
+		Checkpoint              *params.TrustedCheckpoint       `toml:",omitempty"`
+		CheckpointOracle        *params.CheckpointOracleConfig  `toml:",omitempty"`
+		CommitteeCheckpoint     *downloader.CommitteeCheckpoint `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -88,6 +90,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
 	enc.Tendermint = c.Tendermint
+	enc.PeerRoles = c.PeerRoles
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
@@ -98,6 +101,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.CommitteeCheckpoint = c.CommitteeCheckpoint
 	return &enc, nil
 }
 
@@ -133,16 +137,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Miner                   *miner.Config
 		Ethash                  *ethash.Config
 		Tendermint              *config.Config
+		PeerRoles               RolePolicies `toml:",omitempty"`
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
 		EWASMInterpreter        *string
 		EVMInterpreter          *string
-		RPCGasCap               *uint64                        `toml:",omitempty"`
-		RPCTxFeeCap             *float64                       `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		RPCGasCap               *uint64                         `toml:",omitempty"`
+		RPCTxFeeCap             *float64                        `toml:",omitempty"`
+		Checkpoint              *params.TrustedCheckpoint       `toml:",omitempty"`
+		CheckpointOracle        *params.CheckpointOracleConfig  `toml:",omitempty"`
+		CommitteeCheckpoint     *downloader.CommitteeCheckpoint `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -235,6 +241,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Tendermint != nil {
 		c.Tendermint = *dec.Tendermint
 	}
+	if dec.PeerRoles != nil {
+		c.PeerRoles = dec.PeerRoles
+	}
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
@@ -265,5 +274,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.CommitteeCheckpoint != nil {
+		c.CommitteeCheckpoint = dec.CommitteeCheckpoint
+	}
 	return nil
 }
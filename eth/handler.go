@@ -105,7 +105,11 @@ type ProtocolManager struct {
 	whitelistCh         chan core.WhitelistEvent
 	whitelistSub        event.Subscription
 	enodesWhitelist     []*enode.Node
+	enodesRoles         map[enode.ID]params.UserType
+	addressRoles        map[common.Address]params.UserType
 	enodesWhitelistLock sync.RWMutex
+	rolePolicies        RolePolicies
+	proxies             proxies
 
 	engine consensus.Engine
 	pub    *ecdsa.PublicKey
@@ -130,6 +134,10 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 		quitSync:    make(chan struct{}),
 		pub:         pub,
 	}
+	if config.AutonityContractConfig != nil {
+		manager.rolePolicies = make(RolePolicies, len(DefaultRolePolicies))
+		manager.SetRolePolicies(DefaultRolePolicies)
+	}
 	if handler, ok := manager.engine.(consensus.Handler); ok {
 		handler.SetBroadcaster(manager)
 	}
@@ -217,10 +225,64 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 	manager.txFetcher = fetcher.NewTxFetcher(txpool.Has, txpool.AddRemotes, fetchTx)
 
 	manager.chainSync = newChainSyncer(manager)
-	manager.enodesWhitelist = rawdb.ReadEnodeWhitelist(chaindb).List
+	enodes := rawdb.ReadEnodeWhitelist(chaindb)
+	manager.setWhitelist(enodes.List, enodes.Roles)
 	return manager, nil
 }
 
+// SetRolePolicies overrides the network privileges of the peers of the given
+// user types, they are only enforced on chains with an autonity contract.
+func (pm *ProtocolManager) SetRolePolicies(policies RolePolicies) {
+	if pm.rolePolicies == nil {
+		return
+	}
+	for role, policy := range policies {
+		pm.rolePolicies[role] = policy
+	}
+}
+
+// setWhitelist updates the authorized enodes and their user types.
+func (pm *ProtocolManager) setWhitelist(whitelist []*enode.Node, roles map[enode.ID]params.UserType) {
+	addressRoles := make(map[common.Address]params.UserType, len(whitelist))
+	for _, node := range whitelist {
+		if pubKey := node.Pubkey(); pubKey != nil {
+			addressRoles[crypto.PubkeyToAddress(*pubKey)] = roles[node.ID()]
+		}
+	}
+
+	pm.enodesWhitelistLock.Lock()
+	defer pm.enodesWhitelistLock.Unlock()
+	pm.enodesWhitelist = whitelist
+	pm.enodesRoles = roles
+	pm.addressRoles = addressRoles
+}
+
+// peerRole returns the user type of an enode, the peers outside of the whitelist
+// being participants.
+func (pm *ProtocolManager) peerRole(id enode.ID) params.UserType {
+	pm.enodesWhitelistLock.RLock()
+	defer pm.enodesWhitelistLock.RUnlock()
+	for _, node := range pm.enodesWhitelist {
+		if node.ID() == id {
+			return pm.enodesRoles[id]
+		}
+	}
+	return params.UserParticipant
+}
+
+// ConsensusPeer implements consensus.Broadcaster.ConsensusPeer, the role of the
+// address in the whitelist must be allowed to send consensus messages unless the
+// peer is a proxy.
+func (pm *ProtocolManager) ConsensusPeer(addr common.Address) bool {
+	if _, ok := pm.proxies.addresses[addr]; ok {
+		return true
+	}
+	pm.enodesWhitelistLock.RLock()
+	role, ok := pm.addressRoles[addr]
+	pm.enodesWhitelistLock.RUnlock()
+	return ok && pm.rolePolicies.policy(role).Consensus
+}
+
 func (pm *ProtocolManager) makeProtocol(version uint) p2p.Protocol {
 	length, ok := protocolLengths[version]
 	if !ok {
@@ -239,7 +301,9 @@ func (pm *ProtocolManager) makeProtocol(version uint) p2p.Protocol {
 		Version: version,
 		Length:  length,
 		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
-			return pm.runPeer(pm.newPeer(int(version), p, rw, pm.txpool.Get))
+			peer := pm.newPeer(ethVersion(version), p, rw, pm.txpool.Get)
+			peer.consensusVersion = version
+			return pm.runPeer(peer)
 		},
 		NodeInfo: func() interface{} {
 			return pm.NodeInfo()
@@ -341,9 +405,7 @@ func (pm *ProtocolManager) glienickeEventLoop() {
 	for {
 		select {
 		case event := <-pm.whitelistCh:
-			pm.enodesWhitelistLock.Lock()
-			pm.enodesWhitelist = event.Whitelist
-			pm.enodesWhitelistLock.Unlock()
+			pm.setWhitelist(event.Whitelist, event.Roles)
 		// Err() channel will be closed when unsubscribing.
 		case <-pm.whitelistSub.Err():
 			return
@@ -374,14 +436,28 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		return err
 	}
 
+	if pm.proxies.exclusive && !pm.isProxy(p.Node().ID()) {
+		p.Log().Debug("Dropping peer which isn't a sentry")
+		return errUnauthaurizedPeer
+	}
 	err := pm.IsInWhitelist(p.Node().ID(), p.td.Uint64(), p.Log())
 	if err != nil {
 		return err
 	}
+	p.proxy = pm.isProxy(p.Node().ID())
+	p.role = pm.peerRole(p.Node().ID())
 	// Todo : pause relaying if not whitelisted until full sync
 
-	// Register the peer locally
-	if err := pm.peers.Register(p, pm.removePeer); err != nil {
+	// Register the peer locally, the proxies being exempt from the role limits
+	var roleLimit int
+	if !p.proxy {
+		roleLimit = pm.rolePolicies.policy(p.role).MaxPeers
+	}
+	if err := pm.peers.Register(p, roleLimit, pm.removePeer); err != nil {
+		if err == errTooManyRolePeers {
+			p.Log().Debug("Too many peers of the same role", "role", p.role, "limit", roleLimit)
+			return p2p.DiscTooManyPeers
+		}
 		p.Log().Error("Ethereum peer registration failed", "err", err)
 		return err
 	}
@@ -438,6 +514,9 @@ func (pm *ProtocolManager) handle(p *peer) error {
 }
 
 func (pm *ProtocolManager) IsInWhitelist(id enode.ID, td uint64, logger log.Logger) error {
+	if pm.isProxy(id) {
+		return nil
+	}
 	head := pm.blockchain.CurrentHeader()
 
 	whitelisted := false
@@ -495,6 +574,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			return err
 		}
 	}
+	// The blocks propagated by a role which may not propagate them are ignored,
+	// the peer is still served.
+	if (msg.Code == NewBlockHashesMsg || msg.Code == NewBlockMsg) && !p.proxy && !pm.rolePolicies.policy(p.role).Propagate {
+		p.Log().Trace("Ignoring block propagation", "role", p.role, "code", msg.Code)
+		return nil
+	}
 
 	// Handle the message depending on its contents
 	switch {
@@ -1035,6 +1120,8 @@ func (pm *ProtocolManager) txBroadcastLoop() {
 	}
 }
 
+// FindPeers implements consensus.Broadcaster.FindPeers, all the peers are
+// returned if targets is nil.
 func (pm *ProtocolManager) FindPeers(targets map[common.Address]struct{}) map[common.Address]consensus.Peer {
 	m := make(map[common.Address]consensus.Peer)
 
@@ -1044,7 +1131,7 @@ func (pm *ProtocolManager) FindPeers(targets map[common.Address]struct{}) map[co
 			continue
 		}
 		addr := crypto.PubkeyToAddress(*pubKey)
-		if _, ok := targets[addr]; ok {
+		if _, ok := targets[addr]; ok || targets == nil {
 			m[addr] = p
 		}
 	}
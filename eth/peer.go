@@ -27,6 +27,7 @@ import (
 	"github.com/clearmatics/autonity/core/forkid"
 	"github.com/clearmatics/autonity/core/types"
 	"github.com/clearmatics/autonity/p2p"
+	"github.com/clearmatics/autonity/params"
 	"github.com/clearmatics/autonity/rlp"
 	mapset "github.com/deckarep/golang-set"
 )
@@ -35,6 +36,7 @@ var (
 	errClosed            = errors.New("peer set is closed")
 	errAlreadyRegistered = errors.New("peer is already registered")
 	errNotRegistered     = errors.New("peer is not registered")
+	errTooManyRolePeers  = errors.New("too many peers of the same role")
 )
 
 const (
@@ -76,6 +78,9 @@ type PeerInfo struct {
 	Version    int      `json:"version"`    // Ethereum protocol version negotiated
 	Difficulty *big.Int `json:"difficulty"` // Total difficulty of the peer's blockchain
 	Head       string   `json:"head"`       // SHA3 hash of the peer's best owned block
+	Role       string   `json:"role"`       // User type of the peer in the autonity contract
+	Proxy      bool     `json:"proxy"`      // Whether the peer is a sentry of this validator or a validator of this sentry
+	Consensus  uint     `json:"consensus"`  // Consensus protocol version negotiated
 }
 
 // propEvent is a block propagation, waiting for its turn in the broadcast queue.
@@ -90,8 +95,11 @@ type peer struct {
 	*p2p.Peer
 	rw p2p.MsgReadWriter
 
-	version  int         // Protocol version negotiated
-	syncDrop *time.Timer // Timed connection dropper if sync progress isn't validated in time
+	version          int             // Protocol version negotiated
+	consensusVersion uint            // Version of the consensus protocol negotiated, the eth one without consensus handler
+	role             params.UserType // User type of the peer in the whitelist, empty if not whitelisted
+	proxy            bool            // Whether the peer relays the consensus messages of a validator for this node
+	syncDrop         *time.Timer     // Timed connection dropper if sync progress isn't validated in time
 
 	head common.Hash
 	td   *big.Int
@@ -291,9 +299,17 @@ func (p *peer) Info() *PeerInfo {
 		Version:    p.version,
 		Difficulty: td,
 		Head:       hash.Hex(),
+		Role:       string(p.role),
+		Proxy:      p.proxy,
+		Consensus:  p.consensusVersion,
 	}
 }
 
+// ConsensusVersion implements consensus.VersionedPeer.ConsensusVersion
+func (p *peer) ConsensusVersion() uint {
+	return p.consensusVersion
+}
+
 // Head retrieves a copy of the current head hash and total difficulty of the
 // peer.
 func (p *peer) Head() (hash common.Hash, td *big.Int) {
@@ -714,9 +730,10 @@ func newPeerSet() *peerSet {
 }
 
 // Register injects a new peer into the working set, or returns an error if the
-// peer is already known. If a new peer it registered, its broadcast loop is also
-// started.
-func (ps *peerSet) Register(p *peer, removePeer func(string)) error {
+// peer is already known or if roleLimit peers of its role are registered, a zero
+// limit admitting any number of them. If a new peer it registered, its broadcast
+// loop is also started.
+func (ps *peerSet) Register(p *peer, roleLimit int, removePeer func(string)) error {
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
 
@@ -726,6 +743,9 @@ func (ps *peerSet) Register(p *peer, removePeer func(string)) error {
 	if _, ok := ps.peers[p.id]; ok {
 		return errAlreadyRegistered
 	}
+	if roleLimit > 0 && ps.roleLen(p.role) >= roleLimit {
+		return errTooManyRolePeers
+	}
 	ps.peers[p.id] = p
 
 	go p.broadcastBlocks(removePeer)
@@ -780,6 +800,17 @@ func (ps *peerSet) Len() int {
 	return len(ps.peers)
 }
 
+// roleLen returns the number of peers of a user type, the lock must be held.
+func (ps *peerSet) roleLen(role params.UserType) int {
+	count := 0
+	for _, p := range ps.peers {
+		if p.role == role {
+			count++
+		}
+	}
+	return count
+}
+
 // PeersWithoutBlock retrieves a list of peers that do not have a given block in
 // their set of known hashes.
 func (ps *peerSet) PeersWithoutBlock(hash common.Hash) []*peer {
@@ -34,6 +34,13 @@ const (
 	eth63 = 63
 	eth64 = 64
 	eth65 = 65
+
+	// tendermint66 runs the eth65 messages and gossips the consensus messages
+	// through have bitmaps, it is only offered with a consensus handler.
+	tendermint66 = 66
+	// tendermint67 adds to tendermint66 the catch-up requests of the messages
+	// a lagging node misses.
+	tendermint67 = 67
 )
 
 // protocolName is the official short name of the protocol used during capability negotiation.
@@ -43,7 +50,20 @@ const protocolName = "eth"
 var ProtocolVersions = []uint{eth65, eth64, eth63}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var protocolLengths = map[uint]uint64{eth65: 19, eth64: 19, eth63: 19}
+var protocolLengths = map[uint]uint64{tendermint67: 23, tendermint66: 21, eth65: 19, eth64: 19, eth63: 19}
+
+// consensusProtocolVersions are the supported versions of the protocol run with
+// a consensus handler, the older nodes negotiating one of the eth versions.
+var consensusProtocolVersions = append([]uint{tendermint67, tendermint66}, ProtocolVersions...)
+
+// ethVersion returns the version of the eth messages run by a protocol version,
+// the tendermint versions extending the latest eth one.
+func ethVersion(version uint) int {
+	if version > eth65 {
+		return eth65
+	}
+	return int(version)
+}
 
 const protocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -122,6 +142,7 @@ var errorToString = map[int]string{
 	ErrForkIDRejected:          "Fork ID rejected",
 	ErrNoStatusMsg:             "No status message",
 	ErrExtraStatusMsg:          "Extra status message",
+	ErrNoPubKeyFound:           "No public key found",
 }
 
 type txPool interface {
@@ -480,3 +480,22 @@ func TestGetBlockHeadersDataEncodeDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestConsensusProtocolVersions(t *testing.T) {
+	if consensusProtocolVersions[0] != tendermint67 {
+		t.Fatalf("primary consensus protocol version mismatch: have %d, want %d", consensusProtocolVersions[0], tendermint67)
+	}
+	for _, version := range consensusProtocolVersions {
+		if _, ok := protocolLengths[version]; !ok {
+			t.Errorf("no protocol length for version %d", version)
+		}
+	}
+	for _, version := range []uint{tendermint66, tendermint67} {
+		if v := ethVersion(version); v != eth65 {
+			t.Errorf("tendermint%d eth messages mismatch: have %d, want %d", version, v, eth65)
+		}
+	}
+	if v := ethVersion(eth64); v != eth64 {
+		t.Errorf("eth64 version mismatch: have %d, want %d", v, eth64)
+	}
+}
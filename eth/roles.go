@@ -0,0 +1,39 @@
+package eth
+
+import (
+	"github.com/clearmatics/autonity/params"
+)
+
+// RolePolicy is the network privileges granted to the peers of a user type of
+// the autonity contract.
+type RolePolicy struct {
+	MaxPeers  int  `toml:",omitempty"` // Maximum number of connected peers of the role, unlimited if zero
+	Consensus bool `toml:",omitempty"` // Whether the peers may send consensus messages
+	Propagate bool `toml:",omitempty"` // Whether the peers may propagate blocks, otherwise they only sync and submit transactions
+}
+
+// RolePolicies are the policies of the user types. The peers which aren't in the
+// whitelist are participants, and unknown user types get the participant policy.
+// Nil policies don't restrict the peers.
+type RolePolicies map[params.UserType]RolePolicy
+
+// DefaultRolePolicies only lets validators take part in the consensus and
+// participants only sync and submit transactions.
+var DefaultRolePolicies = RolePolicies{
+	params.UserValidator:   {Consensus: true, Propagate: true},
+	params.UserStakeHolder: {Propagate: true},
+	params.UserParticipant: {},
+}
+
+// policy returns the policy of the role. Whitelists stored before the user types
+// were recorded have no roles, their peers aren't restricted until the whitelist
+// is updated by the next block.
+func (r RolePolicies) policy(role params.UserType) RolePolicy {
+	if r == nil || role == "" {
+		return RolePolicy{Consensus: true, Propagate: true}
+	}
+	if p, ok := r[role]; ok {
+		return p
+	}
+	return r[params.UserParticipant]
+}
@@ -0,0 +1,108 @@
+package eth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/eth/downloader"
+	"github.com/clearmatics/autonity/p2p"
+	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolePolicies(t *testing.T) {
+	t.Run("unknown user types get the participant policy", func(t *testing.T) {
+		policies := RolePolicies{
+			params.UserValidator:   {Consensus: true, Propagate: true},
+			params.UserParticipant: {MaxPeers: 3},
+		}
+		assert.Equal(t, RolePolicy{MaxPeers: 3}, policies.policy(params.UserStakeHolder))
+		assert.Equal(t, RolePolicy{Consensus: true, Propagate: true}, policies.policy(params.UserValidator))
+	})
+
+	t.Run("whitelists without user types aren't restricted", func(t *testing.T) {
+		assert.Equal(t, RolePolicy{Consensus: true, Propagate: true}, DefaultRolePolicies.policy(""))
+	})
+
+	t.Run("nil policies don't restrict the peers", func(t *testing.T) {
+		var policies RolePolicies
+		assert.Equal(t, RolePolicy{Consensus: true, Propagate: true}, policies.policy(params.UserParticipant))
+	})
+}
+
+func TestProtocolManagerRoles(t *testing.T) {
+	var (
+		nodes = make([]*enode.Node, 3)
+		addrs = make([]common.Address, 3)
+	)
+	for i := range nodes {
+		key, _ := crypto.GenerateKey()
+		nodes[i] = enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), 30303+i, 30303+i)
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	roles := map[enode.ID]params.UserType{
+		nodes[0].ID(): params.UserValidator,
+		nodes[1].ID(): params.UserParticipant,
+	}
+
+	pm := &ProtocolManager{rolePolicies: make(RolePolicies)}
+	pm.SetRolePolicies(DefaultRolePolicies)
+	pm.setWhitelist(nodes[:2], roles)
+
+	assert.Equal(t, params.UserType(params.UserValidator), pm.peerRole(nodes[0].ID()))
+	assert.Equal(t, params.UserType(params.UserParticipant), pm.peerRole(nodes[1].ID()))
+	assert.Equal(t, params.UserType(params.UserParticipant), pm.peerRole(nodes[2].ID()))
+
+	assert.True(t, pm.ConsensusPeer(addrs[0]))
+	assert.False(t, pm.ConsensusPeer(addrs[1]))
+	assert.False(t, pm.ConsensusPeer(addrs[2]))
+
+	pm.SetRolePolicies(RolePolicies{params.UserParticipant: {Consensus: true}})
+	assert.True(t, pm.ConsensusPeer(addrs[1]))
+	assert.True(t, pm.ConsensusPeer(addrs[0]), "the other policies are kept")
+}
+
+func TestPeerSetRoleLimit(t *testing.T) {
+	ps := newPeerSet()
+	defer ps.Close()
+	newRolePeer := func(role params.UserType) *peer {
+		_, rw := p2p.MsgPipe()
+		p := newPeer(eth65, newTestP2PPeer("peer"), rw, nil)
+		p.role = role
+		return p
+	}
+
+	require.NoError(t, ps.Register(newRolePeer(params.UserParticipant), 2, func(string) {}))
+	require.NoError(t, ps.Register(newRolePeer(params.UserParticipant), 2, func(string) {}))
+	assert.Equal(t, errTooManyRolePeers, ps.Register(newRolePeer(params.UserParticipant), 2, func(string) {}))
+
+	// The limit only counts the peers of the same role, a zero limit admits any number of them.
+	assert.NoError(t, ps.Register(newRolePeer(params.UserValidator), 1, func(string) {}))
+	assert.NoError(t, ps.Register(newRolePeer(params.UserParticipant), 0, func(string) {}))
+	assert.Equal(t, 4, ps.Len())
+}
+
+func TestIgnoredBlockPropagation(t *testing.T) {
+	p2pPeer := newTestP2PPeer("peer")
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 4, nil, nil, []string{p2pPeer.Info().Enode})
+	defer pm.Stop()
+	pm.setWhitelist([]*enode.Node{p2pPeer.Node()}, map[enode.ID]params.UserType{p2pPeer.ID(): params.UserParticipant})
+	peer, errc := newTestPeer(p2pPeer, eth65, pm, true)
+	defer peer.close()
+
+	// The announcement of a participant is dropped, the peer is still served.
+	head := pm.blockchain.CurrentBlock()
+	require.NoError(t, p2p.Send(peer.app, NewBlockHashesMsg, newBlockHashesData{{Hash: head.Hash(), Number: head.NumberU64()}}))
+	require.NoError(t, p2p.Send(peer.app, GetBlockHeadersMsg, &getBlockHeadersData{Origin: hashOrNumber{Hash: head.Hash()}, Amount: 1}))
+	assert.NoError(t, p2p.ExpectMsg(peer.app, BlockHeadersMsg, []*types.Header{head.Header()}))
+	select {
+	case err := <-errc:
+		t.Fatalf("peer dropped: %v", err)
+	default:
+	}
+}
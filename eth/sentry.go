@@ -0,0 +1,115 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintBackend "github.com/clearmatics/autonity/consensus/tendermint/backend"
+	"github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/p2p/enode"
+)
+
+// proxies are the peers relaying the consensus messages of a validator: the
+// sentries of a validator, or the validators of a sentry. They are authorized
+// whatever the whitelist and their role.
+type proxies struct {
+	ids       map[enode.ID]struct{}
+	addresses map[common.Address]struct{}
+	exclusive bool // Whether the proxies are the only authorized peers
+}
+
+// SetSentries restricts the peers of a validator to its sentries, it must be
+// called before the protocol manager starts.
+func (pm *ProtocolManager) SetSentries(sentries []*enode.Node) {
+	pm.setProxies(sentries, true)
+}
+
+// SetProtectedValidators authorizes the validators a sentry relays the consensus
+// messages of, it must be called before the protocol manager starts.
+func (pm *ProtocolManager) SetProtectedValidators(validators []*enode.Node) {
+	pm.setProxies(validators, false)
+}
+
+func (pm *ProtocolManager) setProxies(nodes []*enode.Node, exclusive bool) {
+	pm.proxies = proxies{
+		ids:       make(map[enode.ID]struct{}, len(nodes)),
+		addresses: make(map[common.Address]struct{}, len(nodes)),
+		exclusive: exclusive,
+	}
+	for _, node := range nodes {
+		pm.proxies.ids[node.ID()] = struct{}{}
+		if pubKey := node.Pubkey(); pubKey != nil {
+			pm.proxies.addresses[crypto.PubkeyToAddress(*pubKey)] = struct{}{}
+		}
+	}
+}
+
+// isProxy returns whether the peer relays the consensus messages of a validator
+// for this node.
+func (pm *ProtocolManager) isProxy(id enode.ID) bool {
+	_, ok := pm.proxies.ids[id]
+	return ok
+}
+
+// setupSentries applies the sentry configuration of the node. A validator with
+// sentries only connects to them and leaves the discovery so that its enode isn't
+// advertised, a sentry always accepts the validators it protects.
+func (s *Ethereum) setupSentries(cfg *config.Config) error {
+	if len(cfg.Sentries) > 0 && len(cfg.ProtectedValidators) > 0 {
+		return errors.New("a node can't both have sentries and be a sentry")
+	}
+	sentries, err := parseEnodes(cfg.Sentries)
+	if err != nil {
+		return fmt.Errorf("invalid sentry: %v", err)
+	}
+	validators, err := parseEnodes(cfg.ProtectedValidators)
+	if err != nil {
+		return fmt.Errorf("invalid protected validator: %v", err)
+	}
+	tendermint, _ := s.engine.(*tendermintBackend.Backend)
+
+	switch {
+	case len(sentries) > 0:
+		s.proxies, s.proxiesOnly = sentries, true
+		s.protocolManager.SetSentries(sentries)
+		if tendermint != nil {
+			tendermint.SetSentries(enodeAddresses(sentries))
+		}
+		s.p2pServer.NoDiscovery = true
+		s.p2pServer.DiscoveryV5 = false
+		log.Info("Connecting through sentries only", "sentries", cfg.Sentries)
+	case len(validators) > 0:
+		s.proxies = validators
+		s.protocolManager.SetProtectedValidators(validators)
+		if tendermint != nil {
+			tendermint.SetProtectedValidators(enodeAddresses(validators))
+		}
+		log.Info("Relaying consensus messages as a sentry", "validators", cfg.ProtectedValidators)
+	}
+	return nil
+}
+
+func parseEnodes(urls []string) ([]*enode.Node, error) {
+	nodes := make([]*enode.Node, 0, len(urls))
+	for _, url := range urls {
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func enodeAddresses(nodes []*enode.Node) []common.Address {
+	addresses := make([]common.Address, 0, len(nodes))
+	for _, node := range nodes {
+		if pubKey := node.Pubkey(); pubKey != nil {
+			addresses = append(addresses, crypto.PubkeyToAddress(*pubKey))
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,48 @@
+package eth
+
+import (
+	"net"
+	"testing"
+
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxies(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sentry := enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), 30303, 30303)
+	whitelisted := enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 2), 30303, 30303)
+	other, _ := crypto.GenerateKey()
+
+	pm := &ProtocolManager{rolePolicies: make(RolePolicies)}
+	pm.SetRolePolicies(DefaultRolePolicies)
+	pm.SetSentries([]*enode.Node{sentry})
+	assert.True(t, pm.proxies.exclusive)
+	assert.True(t, pm.isProxy(whitelisted.ID()), "the proxies are identified by their key")
+	assert.True(t, pm.ConsensusPeer(crypto.PubkeyToAddress(key.PublicKey)), "a sentry may relay consensus messages")
+	assert.False(t, pm.ConsensusPeer(crypto.PubkeyToAddress(other.PublicKey)))
+
+	pm.SetProtectedValidators([]*enode.Node{sentry})
+	assert.False(t, pm.proxies.exclusive)
+
+	s := &Ethereum{proxies: []*enode.Node{sentry}}
+	whitelist := []*enode.Node{enode.NewV4(&other.PublicKey, net.IPv4(127, 0, 0, 1), 30304, 30304)}
+	assert.Equal(t, append(whitelist, sentry), s.withProxies(whitelist))
+	s.proxiesOnly = true
+	assert.Equal(t, []*enode.Node{sentry}, s.withProxies(whitelist))
+}
+
+func TestParseEnodes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	node := enode.NewV4(&key.PublicKey, net.IPv4(127, 0, 0, 1), 30303, 30303)
+
+	nodes, err := parseEnodes([]string{node.String()})
+	require.NoError(t, err)
+	assert.Equal(t, node.ID(), nodes[0].ID())
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), enodeAddresses(nodes)[0])
+
+	_, err = parseEnodes([]string{"enode://invalid"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,347 @@
+// Package autonityclient provides a client for the Autonity specific RPC APIs,
+// the consensus engine state in the tendermint namespace and the Autonity
+// contract in the aut namespace, on top of the Ethereum RPC API.
+package autonityclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/clearmatics/autonity"
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/hexutil"
+	"github.com/clearmatics/autonity/consensus/tendermint/backend"
+	"github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/ethclient"
+	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
+	"github.com/clearmatics/autonity/rpc"
+)
+
+// Client defines typed wrappers for the Autonity RPC API, the Ethereum RPC API
+// being available through the embedded ethclient.Client.
+type Client struct {
+	*ethclient.Client
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given URL with context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{Client: ethclient.NewClient(c), c: c}
+}
+
+// User is a user of the Autonity contract.
+type User struct {
+	Address common.Address
+	Type    params.UserType
+	Stake   *big.Int
+	Enode   *enode.Node // nil if the user has no node
+}
+
+// Consensus Engine
+
+// CommitteeAt returns the committee of the given block, which validates the next
+// block. The committee of the latest block is returned if blockNumber is nil.
+func (ac *Client) CommitteeAt(ctx context.Context, blockNumber *big.Int) (types.Committee, error) {
+	var committee types.Committee
+	err := ac.c.CallContext(ctx, &committee, "tendermint_getCommittee", toBlockNumArg(blockNumber))
+	return committee, err
+}
+
+// CommitteeAtHash returns the committee of the block with the given hash.
+func (ac *Client) CommitteeAtHash(ctx context.Context, hash common.Hash) (types.Committee, error) {
+	var committee types.Committee
+	err := ac.c.CallContext(ctx, &committee, "tendermint_getCommitteeAtHash", hash)
+	return committee, err
+}
+
+// Whitelist returns the nodes allowed to connect to the node, the enodes which
+// can't be parsed are left out as the node does.
+func (ac *Client) Whitelist(ctx context.Context) ([]*enode.Node, error) {
+	var whitelist []string
+	if err := ac.c.CallContext(ctx, &whitelist, "tendermint_getWhitelist"); err != nil {
+		return nil, err
+	}
+	return types.NewNodes(whitelist).List, nil
+}
+
+// ContractAddress returns the address of the Autonity contract.
+func (ac *Client) ContractAddress(ctx context.Context) (common.Address, error) {
+	var address common.Address
+	err := ac.c.CallContext(ctx, &address, "tendermint_getContractAddress")
+	return address, err
+}
+
+// ContractABIAt returns the JSON ABI the Autonity contract had at the given
+// block, the latest one if blockNumber is nil.
+func (ac *Client) ContractABIAt(ctx context.Context, blockNumber *big.Int) (string, error) {
+	var abi string
+	err := ac.c.CallContext(ctx, &abi, "tendermint_getContractABI", toBlockNumArg(blockNumber))
+	return abi, err
+}
+
+// CoreState returns the state of the consensus engine of the node.
+func (ac *Client) CoreState(ctx context.Context) (*core.TendermintState, error) {
+	var state core.TendermintState
+	if err := ac.c.CallContext(ctx, &state, "tendermint_getCoreState"); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// MisbehaviourEvidence returns every evidence of misbehaviour recorded by the node.
+func (ac *Client) MisbehaviourEvidence(ctx context.Context) ([]*core.MisbehaviourEvidence, error) {
+	var evidence []*core.MisbehaviourEvidence
+	err := ac.c.CallContext(ctx, &evidence, "tendermint_getMisbehaviourEvidence")
+	return evidence, err
+}
+
+// MisbehaviourEvidenceAt returns the evidence of misbehaviour detected at the
+// given height, the one consensus is running for if blockNumber is -1.
+func (ac *Client) MisbehaviourEvidenceAt(ctx context.Context, blockNumber *big.Int) ([]*core.MisbehaviourEvidence, error) {
+	var evidence []*core.MisbehaviourEvidence
+	err := ac.c.CallContext(ctx, &evidence, "tendermint_getMisbehaviourEvidence", toBlockNumArg(blockNumber))
+	return evidence, err
+}
+
+// SigningWatermark returns the latest consensus message signed by the node, nil
+// if it never signed any.
+func (ac *Client) SigningWatermark(ctx context.Context) (*backend.SigningWatermark, error) {
+	var watermark *backend.SigningWatermark
+	err := ac.c.CallContext(ctx, &watermark, "tendermint_getSigningWatermark")
+	return watermark, err
+}
+
+// ValidatorUptime returns the signed and missed blocks and the proposals of the
+// committee members over the last blocks.
+func (ac *Client) ValidatorUptime(ctx context.Context) (*core.UptimeReport, error) {
+	var report *core.UptimeReport
+	err := ac.c.CallContext(ctx, &report, "tendermint_getValidatorUptime")
+	return report, err
+}
+
+// Autonity Contract
+//
+// The contract is read at the given block, at the latest block if blockNumber is nil.
+
+// rpcCommitteeMember is a committee member as returned by the contract.
+type rpcCommitteeMember struct {
+	Addr        common.Address `json:"addr"`
+	VotingPower *big.Int       `json:"votingPower"`
+	BlsKey      []byte         `json:"blsKey"`
+}
+
+// ContractCommitteeAt returns the committee computed by the Autonity contract.
+func (ac *Client) ContractCommitteeAt(ctx context.Context, blockNumber *big.Int) (types.Committee, error) {
+	var members []rpcCommitteeMember
+	if err := ac.c.CallContext(ctx, &members, "aut_getCommittee", toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	committee := make(types.Committee, 0, len(members))
+	for _, m := range members {
+		committee = append(committee, types.CommitteeMember{
+			Address:     m.Addr,
+			VotingPower: m.VotingPower,
+			BLSKey:      m.BlsKey,
+		})
+	}
+	return committee, nil
+}
+
+// ContractWhitelistAt returns the enodes of the users of the Autonity contract,
+// the enodes which can't be parsed are left out as the node does.
+func (ac *Client) ContractWhitelistAt(ctx context.Context, blockNumber *big.Int) ([]*enode.Node, error) {
+	var whitelist []string
+	if err := ac.c.CallContext(ctx, &whitelist, "aut_getWhitelist", toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return types.NewNodes(whitelist).List, nil
+}
+
+// rpcUser is a user as returned by the contract.
+type rpcUser struct {
+	Addr     common.Address `json:"addr"`
+	UserType uint8          `json:"userType"`
+	Stake    *big.Int       `json:"stake"`
+	Enode    string         `json:"enode"`
+}
+
+// UserAt returns a user of the Autonity contract, ethereum.NotFound if the
+// address isn't one.
+func (ac *Client) UserAt(ctx context.Context, address common.Address, blockNumber *big.Int) (*User, error) {
+	var u rpcUser
+	if err := ac.c.CallContext(ctx, &u, "aut_getUser", address, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if u.Addr == (common.Address{}) {
+		return nil, ethereum.NotFound
+	}
+	userType, ok := params.UserTypeFromID(int(u.UserType))
+	if !ok {
+		return nil, fmt.Errorf("unknown user type %d of %s", u.UserType, u.Addr.Hex())
+	}
+	return newUser(u.Addr, userType, u.Stake, u.Enode)
+}
+
+// UsersAt returns the users of the Autonity contract.
+func (ac *Client) UsersAt(ctx context.Context, blockNumber *big.Int) ([]*User, error) {
+	state, err := ac.StateAt(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*User, 0, len(state.Users))
+	for _, u := range state.Users {
+		user, err := newUser(u.Address, u.Type, u.Stake, u.Enode)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// StateAt returns the state of the Autonity contract, as returned by getState.
+func (ac *Client) StateAt(ctx context.Context, blockNumber *big.Int) (*autonity.StateDump, error) {
+	var state autonity.StateDump
+	if err := ac.c.CallContext(ctx, &state, "aut_dumpState", toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// StakeAt returns the stake of an account.
+func (ac *Client) StakeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var stake *big.Int
+	err := ac.c.CallContext(ctx, &stake, "aut_balanceOf", account, toBlockNumArg(blockNumber))
+	return stake, err
+}
+
+// TotalStakeAt returns the stake held by all the users.
+func (ac *Client) TotalStakeAt(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	var total *big.Int
+	err := ac.c.CallContext(ctx, &total, "aut_totalSupply", toBlockNumArg(blockNumber))
+	return total, err
+}
+
+// ValidatorsAt returns the addresses of the validators.
+func (ac *Client) ValidatorsAt(ctx context.Context, blockNumber *big.Int) ([]common.Address, error) {
+	var validators []common.Address
+	err := ac.c.CallContext(ctx, &validators, "aut_getValidators", toBlockNumArg(blockNumber))
+	return validators, err
+}
+
+// StakeholdersAt returns the addresses of the users allowed to hold stake.
+func (ac *Client) StakeholdersAt(ctx context.Context, blockNumber *big.Int) ([]common.Address, error) {
+	var stakeholders []common.Address
+	err := ac.c.CallContext(ctx, &stakeholders, "aut_getStakeholders", toBlockNumArg(blockNumber))
+	return stakeholders, err
+}
+
+// MinimumGasPriceAt returns the minimum gas price of the transactions.
+func (ac *Client) MinimumGasPriceAt(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
+	var price *big.Int
+	err := ac.c.CallContext(ctx, &price, "aut_getMinimumGasPrice", toBlockNumArg(blockNumber))
+	return price, err
+}
+
+// ContractVersionAt returns the version of the Autonity contract.
+func (ac *Client) ContractVersionAt(ctx context.Context, blockNumber *big.Int) (string, error) {
+	var version string
+	err := ac.c.CallContext(ctx, &version, "aut_getVersion", toBlockNumArg(blockNumber))
+	return version, err
+}
+
+// ProposerAt returns the proposer elected by the Autonity contract for a round.
+func (ac *Client) ProposerAt(ctx context.Context, height *big.Int, round uint64, blockNumber *big.Int) (common.Address, error) {
+	var proposer common.Address
+	err := ac.c.CallContext(ctx, &proposer, "aut_getProposer", height, new(big.Int).SetUint64(round), toBlockNumArg(blockNumber))
+	return proposer, err
+}
+
+// JailedUntilAt returns the block until which a validator is jailed, zero if it
+// isn't jailed.
+func (ac *Client) JailedUntilAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var until *big.Int
+	err := ac.c.CallContext(ctx, &until, "aut_getJailedUntil", account, toBlockNumArg(blockNumber))
+	return until, err
+}
+
+// UpgradeHistory returns the upgrades of the Autonity contract applied so far.
+func (ac *Client) UpgradeHistory(ctx context.Context) ([]autonity.ContractUpgrade, error) {
+	var history []autonity.ContractUpgrade
+	err := ac.c.CallContext(ctx, &history, "aut_upgradeHistory")
+	return history, err
+}
+
+// Subscriptions
+
+// SubscribeCommitteeChanged subscribes to the committee changes of the new heads.
+func (ac *Client) SubscribeCommitteeChanged(ctx context.Context, ch chan<- *autonity.CommitteeChangedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventCommitteeChanged)
+}
+
+// SubscribeUserAdded subscribes to the users added to the Autonity contract.
+func (ac *Client) SubscribeUserAdded(ctx context.Context, ch chan<- *autonity.UserAddedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventUserAdded)
+}
+
+// SubscribeStakeChanged subscribes to the stake minted and burned.
+func (ac *Client) SubscribeStakeChanged(ctx context.Context, ch chan<- *autonity.StakeChangedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventStakeChanged)
+}
+
+// SubscribeContractUpgraded subscribes to the upgrades requested to the Autonity contract.
+func (ac *Client) SubscribeContractUpgraded(ctx context.Context, ch chan<- *autonity.ContractUpgradedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventContractUpgraded)
+}
+
+// SubscribeMinGasPriceChanged subscribes to the updates of the minimum gas price.
+func (ac *Client) SubscribeMinGasPriceChanged(ctx context.Context, ch chan<- *autonity.MinGasPriceChangedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventMinGasPriceChanged)
+}
+
+// SubscribeValidatorSlashed subscribes to the validators punished for a misbehaviour.
+func (ac *Client) SubscribeValidatorSlashed(ctx context.Context, ch chan<- *autonity.ValidatorSlashedEvent) (ethereum.Subscription, error) {
+	return ac.c.Subscribe(ctx, "aut", ch, autonity.EventValidatorSlashed)
+}
+
+// newUser builds a user out of the values stored by the contract.
+func newUser(address common.Address, userType params.UserType, stake *big.Int, enodeURL string) (*User, error) {
+	user := &User{Address: address, Type: userType, Stake: stake}
+	if enodeURL != "" {
+		node, err := enode.ParseV4(enodeURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enode of %s: %v", address.Hex(), err)
+		}
+		user.Enode = node
+	}
+	return user, nil
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,252 @@
+package autonityclient
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ethereum "github.com/clearmatics/autonity"
+	"github.com/clearmatics/autonity/accounts/abi"
+	"github.com/clearmatics/autonity/accounts/abi/bind"
+	"github.com/clearmatics/autonity/autonity"
+	"github.com/clearmatics/autonity/common"
+	"github.com/clearmatics/autonity/common/acdefault"
+	tendermintConfig "github.com/clearmatics/autonity/consensus/tendermint/config"
+	"github.com/clearmatics/autonity/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/eth"
+	"github.com/clearmatics/autonity/eth/downloader"
+	"github.com/clearmatics/autonity/ethclient"
+	"github.com/clearmatics/autonity/node"
+	"github.com/clearmatics/autonity/p2p"
+	"github.com/clearmatics/autonity/p2p/enode"
+	"github.com/clearmatics/autonity/params"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+	testEnode  = enode.NewV4(&testKey.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303)
+	testStake  = uint64(100)
+)
+
+// newTestBackend starts an in-process node sealing blocks on its own, testAddr
+// being both the only validator and the operator of the Autonity contract. It
+// returns once a few blocks were committed.
+func newTestBackend(t *testing.T) (*node.Node, *core.Genesis) {
+	if acdefault.Bytecode() == "" {
+		t.Skip("Autonity contract bytecode not generated")
+	}
+	chainConfig := *params.TestChainConfig
+	chainConfig.Ethash = nil
+	chainConfig.Tendermint = tendermintConfig.DefaultConfig()
+	chainConfig.AutonityContractConfig = &params.AutonityContractGenesis{
+		Operator: testAddr,
+		Users:    []params.User{{Address: &testAddr, Enode: testEnode.URLv4(), Type: params.UserValidator, Stake: testStake}},
+	}
+	require.NoError(t, chainConfig.AutonityContractConfig.Prepare())
+	genesis := &core.Genesis{
+		Config:     &chainConfig,
+		GasLimit:   math.MaxUint64 - 1,
+		Difficulty: big.NewInt(1),
+		Mixhash:    types.BFTDigest,
+		Alloc:      core.GenesisAlloc{testAddr: {Balance: new(big.Int).Exp(big.NewInt(2), big.NewInt(128), nil)}},
+	}
+
+	n, err := node.New(&node.Config{
+		P2P:   p2p.Config{PrivateKey: testKey, NoDiscovery: true, MaxPeers: 1},
+		NoUSB: true,
+	})
+	require.NoError(t, err)
+	config := eth.DefaultConfig
+	config.Genesis = genesis
+	config.NetworkId = chainConfig.ChainID.Uint64()
+	config.SyncMode = downloader.FullSync
+	config.Tendermint = *chainConfig.Tendermint
+	service, err := eth.New(n, &config, nil)
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	require.NoError(t, service.StartMining(1))
+
+	deadline := time.After(30 * time.Second)
+	for service.BlockChain().CurrentBlock().NumberU64() < 3 {
+		select {
+		case <-deadline:
+			n.Close()
+			t.Fatal("no block committed")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return n, genesis
+}
+
+func TestConsensusEngine(t *testing.T) {
+	backend, genesis := newTestBackend(t)
+	c, _ := backend.Attach()
+	defer backend.Close()
+	defer c.Close()
+	client := NewClient(c)
+	ctx := context.Background()
+
+	t.Run("committee at a block", func(t *testing.T) {
+		committee, err := client.CommitteeAt(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, committee, 1)
+		assert.Equal(t, testAddr, committee[0].Address)
+		assert.Equal(t, new(big.Int).SetUint64(testStake), committee[0].VotingPower)
+
+		first, err := client.CommitteeAt(ctx, big.NewInt(1))
+		require.NoError(t, err)
+		assert.Equal(t, committee, first)
+
+		_, err = client.CommitteeAt(ctx, big.NewInt(1000000))
+		assert.Error(t, err)
+
+		header, err := ethclient.NewClient(c).HeaderByNumber(ctx, big.NewInt(2))
+		require.NoError(t, err)
+		atHash, err := client.CommitteeAtHash(ctx, header.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, committee, atHash)
+	})
+
+	t.Run("whitelist is parsed", func(t *testing.T) {
+		whitelist, err := client.Whitelist(ctx)
+		require.NoError(t, err)
+		require.Len(t, whitelist, 1)
+		assert.Equal(t, testEnode.ID(), whitelist[0].ID())
+	})
+
+	t.Run("contract address and abi", func(t *testing.T) {
+		address, err := client.ContractAddress(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, autonity.ContractAddress, address)
+
+		contractABI, err := client.ContractABIAt(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, genesis.Config.AutonityContractConfig.ABI, contractABI)
+		contractABI, err = client.ContractABIAt(ctx, big.NewInt(1))
+		require.NoError(t, err)
+		assert.Equal(t, genesis.Config.AutonityContractConfig.ABI, contractABI)
+	})
+
+	t.Run("core state", func(t *testing.T) {
+		state, err := client.CoreState(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, testAddr, state.Client)
+		assert.True(t, state.Height.Uint64() > 1)
+		require.Len(t, state.Committee, 1)
+		assert.Equal(t, testAddr, state.Committee[0].Address)
+	})
+
+	t.Run("misbehaviour evidence", func(t *testing.T) {
+		evidence, err := client.MisbehaviourEvidence(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, evidence)
+
+		evidence, err = client.MisbehaviourEvidenceAt(ctx, big.NewInt(1))
+		require.NoError(t, err)
+		assert.Empty(t, evidence)
+	})
+}
+
+func TestAutonityContract(t *testing.T) {
+	backend, genesis := newTestBackend(t)
+	c, _ := backend.Attach()
+	defer backend.Close()
+	defer c.Close()
+	client := NewClient(c)
+	ctx := context.Background()
+
+	t.Run("committee", func(t *testing.T) {
+		committee, err := client.ContractCommitteeAt(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, committee, 1)
+		assert.Equal(t, testAddr, committee[0].Address)
+		assert.Equal(t, new(big.Int).SetUint64(testStake), committee[0].VotingPower)
+	})
+
+	t.Run("whitelist", func(t *testing.T) {
+		whitelist, err := client.ContractWhitelistAt(ctx, big.NewInt(1))
+		require.NoError(t, err)
+		require.Len(t, whitelist, 1)
+		assert.Equal(t, testEnode.ID(), whitelist[0].ID())
+	})
+
+	t.Run("users", func(t *testing.T) {
+		user, err := client.UserAt(ctx, testAddr, big.NewInt(1))
+		require.NoError(t, err)
+		assert.Equal(t, testAddr, user.Address)
+		assert.Equal(t, params.UserType(params.UserValidator), user.Type)
+		assert.Equal(t, new(big.Int).SetUint64(testStake), user.Stake)
+		require.NotNil(t, user.Enode)
+		assert.Equal(t, testEnode.ID(), user.Enode.ID())
+
+		_, err = client.UserAt(ctx, common.Address{2}, nil)
+		assert.Equal(t, ethereum.NotFound, err)
+
+		users, err := client.UsersAt(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, testAddr, users[0].Address)
+
+		state, err := client.StateAt(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, state.Users, 1)
+		assert.Equal(t, testAddr, state.Users[0].Address)
+		assert.NotEmpty(t, state.ContractVersion)
+	})
+
+	t.Run("stake", func(t *testing.T) {
+		stake, err := client.StakeAt(ctx, testAddr, nil)
+		require.NoError(t, err)
+		assert.Equal(t, new(big.Int).SetUint64(testStake), stake)
+
+		stake, err = client.StakeAt(ctx, common.Address{2}, big.NewInt(1))
+		require.NoError(t, err)
+		assert.Zero(t, stake.Sign())
+	})
+
+	t.Run("proposer", func(t *testing.T) {
+		proposer, err := client.ProposerAt(ctx, big.NewInt(2), 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, testAddr, proposer)
+	})
+
+	t.Run("subscription", func(t *testing.T) {
+		ch := make(chan *autonity.UserAddedEvent)
+		sub, err := client.SubscribeUserAdded(ctx, ch)
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+
+		// The operator adds a stakeholder.
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		added := crypto.PubkeyToAddress(key.PublicKey)
+		parsed, err := abi.JSON(strings.NewReader(genesis.Config.AutonityContractConfig.ABI))
+		require.NoError(t, err)
+		conn := ethclient.NewClient(c)
+		contract := bind.NewBoundContract(autonity.ContractAddress, parsed, conn, conn, conn)
+		url := enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303).URLv4()
+		_, err = contract.Transact(bind.NewKeyedTransactor(testKey), "addUser", added, big.NewInt(10), url, uint8(params.UserType(params.UserStakeHolder).GetID()))
+		require.NoError(t, err)
+
+		select {
+		case ev := <-ch:
+			assert.Equal(t, added, ev.Address)
+			assert.Equal(t, params.UserType(params.UserStakeHolder), ev.Type)
+			assert.Equal(t, big.NewInt(10), ev.Stake)
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(30 * time.Second):
+			t.Fatal("no event received")
+		}
+	})
+}
@@ -38,6 +38,13 @@ type Batch interface {
 	Replay(w KeyValueWriter) error
 }
 
+// SyncWriter wraps the WriteSync method of the batches able to wait for their
+// changes to reach the disk.
+type SyncWriter interface {
+	// WriteSync flushes any accumulated data to disk and syncs it.
+	WriteSync() error
+}
+
 // Batcher wraps the NewBatch method of a backing data store.
 type Batcher interface {
 	// NewBatch creates a write-only database that buffers changes to its host db
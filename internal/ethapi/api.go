@@ -1129,7 +1129,7 @@ func FormatLogs(logs []vm.StructLog) []StructLogRes {
 
 // RPCMarshalHeader converts the given header to the RPC output .
 func RPCMarshalHeader(head *types.Header) map[string]interface{} {
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"number":             (*hexutil.Big)(head.Number),
 		"hash":               head.Hash(),
 		"parentHash":         head.ParentHash,
@@ -1153,6 +1153,11 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 		"round":              head.Round,
 		"proposerSeal":       head.ProposerSeal,
 	}
+	if len(head.AggregatedSeal) > 0 {
+		result["aggregatedSeal"] = hexutil.Bytes(head.AggregatedSeal)
+		result["sealBitmap"] = hexutil.Bytes(head.SealBitmap)
+	}
+	return result
 }
 
 // RPCMarshalBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
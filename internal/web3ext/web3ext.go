@@ -109,6 +109,11 @@ web3._extend({
 			call: 'admin_removePeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'resetSigningWatermark',
+			call: 'admin_resetSigningWatermark',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'addTrustedPeer',
 			call: 'admin_addTrustedPeer',
@@ -408,6 +413,12 @@ web3._extend({
 			params: 2,
 			inputFormatter:[null, null],
 		}),
+		new web3._extend.Method({
+			name: 'simulateContractUpgrade',
+			call: 'debug_simulateContractUpgrade',
+			params: 3,
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'freezeClient',
 			call: 'debug_freezeClient',
@@ -843,7 +854,8 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'getContractABI',
 			call: 'tendermint_getContractABI',
-			params: 0
+			params: 1,
+			inputFormatter: [null]
 		}),
 		new web3._extend.Method({
 			name: 'getWhitelist',
@@ -854,6 +866,27 @@ web3._extend({
 			name: 'getCoreState',
 			call: 'tendermint_getCoreState',
 			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getMisbehaviourEvidence',
+			call: 'tendermint_getMisbehaviourEvidence',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getSigningWatermark',
+			call: 'tendermint_getSigningWatermark',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getBLSKey',
+			call: 'tendermint_getBLSKey',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorUptime',
+			call: 'tendermint_getValidatorUptime',
+			params: 0
 		})
 	]
 });
@@ -169,6 +169,9 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 		//
 		// For the clique consensus engine, the start header is the block header
 		// of the latest epoch covered by checkpoint.
+		//
+		// For the tendermint consensus engine, the start header is the block header
+		// of the checkpoint, its committee is the one verifying the next headers.
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 		defer cancel()
 		if !checkpoint.Empty() && !h.backend.blockchain.SyncCheckpoint(ctx, checkpoint) {
@@ -41,6 +41,16 @@ func (ut UserType) GetID() int {
 	return userTypeID[ut]
 }
 
+// UserTypeFromID returns the user type stored in the contract under id.
+func UserTypeFromID(id int) (UserType, bool) {
+	for ut, utID := range userTypeID {
+		if utID == id {
+			return ut, true
+		}
+	}
+	return "", false
+}
+
 // Autonity contract config. It'is used for deployment.
 type AutonityContractGenesis struct {
 	// Bytecode of validators contract
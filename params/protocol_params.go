@@ -128,6 +128,7 @@ const (
 	Bn256PairingPerPointGasByzantium uint64 = 80000  // Byzantium per-point price for an elliptic curve pairing check
 	Bn256PairingPerPointGasIstanbul  uint64 = 34000  // Per-point price for an elliptic curve pairing check
 	EnodeCheckGas                    uint64 = 1
+	BLSPossessionCheckGas            uint64 = 381000 // Price of a BLS key proof of possession check, a hash to G2 and a two pairs pairing check
 
 	Bls12381G1AddGas          uint64 = 600    // Price for BLS12-381 elliptic curve G1 point addition
 	Bls12381G1MulGas          uint64 = 12000  // Price for BLS12-381 elliptic curve G1 point scalar multiplication
@@ -116,6 +116,7 @@ type SignerAPI struct {
 	validator   Validator
 	rejectMode  bool
 	credentials storage.Storage
+	tendermint  *tendermintWatermarks
 }
 
 // Metadata about a request
@@ -283,12 +284,20 @@ func NewSignerAPI(am *accounts.Manager, chainID int64, noUSB bool, ui UIClientAP
 	if advancedMode {
 		log.Info("Clef is in advanced mode: will warn instead of reject")
 	}
-	signer := &SignerAPI{big.NewInt(chainID), am, ui, validator, !advancedMode, credentials}
+	watermarks := &tendermintWatermarks{storage: storage.NewEphemeralStorage()}
+	signer := &SignerAPI{big.NewInt(chainID), am, ui, validator, !advancedMode, credentials, watermarks}
 	if !noUSB {
 		signer.startUSBListener()
 	}
 	return signer
 }
+
+// SetTendermintWatermarks sets the storage of the tendermint signing watermarks,
+// which are only kept in memory otherwise. It must be called before serving.
+func (api *SignerAPI) SetTendermintWatermarks(watermarks storage.Storage) {
+	api.tendermint = &tendermintWatermarks{storage: watermarks}
+}
+
 func (api *SignerAPI) openTrezor(url accounts.URL) {
 	resp, err := api.UI.OnInputRequired(UserInputRequest{
 		Prompt: "Pin required to open Trezor wallet\n" +
@@ -55,6 +55,10 @@ var (
 		accounts.MimetypeTextPlain,
 		0x45,
 	}
+	ApplicationTendermint = SigFormat{
+		accounts.MimetypeTendermint,
+		0x02,
+	}
 )
 
 type ValidatorData struct {
@@ -215,6 +219,28 @@ func (api *SignerAPI) determineSignatureFormat(ctx context.Context, contentType
 			},
 		}
 		req = &SignDataRequest{ContentType: mediaType, Rawdata: []byte(msg), Messages: messages, Hash: sighash}
+	case ApplicationTendermint.Mime:
+		// Tendermint consensus messages, committed seals and proposer seals are decoded
+		// and checked, then signed without any prefix. The signing watermark is raised
+		// before the request is approved, a denied request is never signed afterwards.
+		stringData, ok := data.(string)
+		if !ok {
+			return nil, useEthereumV, fmt.Errorf("input for %v must be an hex-encoded string", ApplicationTendermint.Mime)
+		}
+		consensusData, err := hexutil.Decode(stringData)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		messages, sighash, watermark, err := tendermintData(addr.Address(), consensusData)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		if err := api.tendermint.raise(addr.Address(), watermark); err != nil {
+			return nil, useEthereumV, err
+		}
+		req = &SignDataRequest{ContentType: mediaType, Rawdata: consensusData, Messages: messages, Hash: sighash}
+		// Tendermint uses V on the form 0 or 1
+		useEthereumV = false
 	default: // also case TextPlain.Mime:
 		// Calculates an Ethereum ECDSA signature for:
 		// hash = keccak256("\x19${byteVersion}Ethereum Signed Message:\n${message length}${message}")
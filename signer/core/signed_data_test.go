@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"path"
 	"strings"
 	"testing"
@@ -30,6 +31,7 @@ import (
 	"github.com/clearmatics/autonity/common"
 	"github.com/clearmatics/autonity/common/hexutil"
 	"github.com/clearmatics/autonity/common/math"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
 	"github.com/clearmatics/autonity/crypto"
 	"github.com/clearmatics/autonity/signer/core"
 )
@@ -229,6 +231,21 @@ func TestSignData(t *testing.T) {
 	if signature == nil || len(signature) != 65 {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(signature))
 	}
+	// application/x-tendermint
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	consensusData := tendermintCore.PrepareCommittedSeal(common.HexToHash("0x01"), 0, big.NewInt(1))
+	signature, err = api.SignData(context.Background(), core.ApplicationTendermint.Mime, a, hexutil.Encode(consensusData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(consensusData), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != a.Address() {
+		t.Errorf("Expected signer %v, got %v", a.Address(), signer)
+	}
 }
 
 func TestDomainChainId(t *testing.T) {
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/log"
+	"github.com/clearmatics/autonity/rlp"
+	"github.com/clearmatics/autonity/signer/storage"
+)
+
+var (
+	// errTendermintData is returned when the data to sign is neither a consensus
+	// message, a committed seal nor a block header.
+	errTendermintData = errors.New("not a tendermint consensus message, committed seal or header")
+	// errTendermintSigner is returned when the data to sign claims another signer
+	// than the account signing it.
+	errTendermintSigner = errors.New("tendermint consensus data of another signer")
+	// errTendermintWatermark is returned when the data to sign is not above the
+	// latest tendermint consensus data signed by the account.
+	errTendermintWatermark = errors.New("refusing to sign tendermint consensus data at or below the signing watermark")
+)
+
+// tendermintWatermark is the position in the consensus of tendermint data signed
+// by an account. At a given height and round, the committed seal of a precommit is
+// signed right before the precommit message.
+type tendermintWatermark struct {
+	Height *big.Int            `json:"height"`
+	Round  int64               `json:"round"`
+	Step   tendermintCore.Step `json:"step"`
+	Seal   bool                `json:"seal"`  // committed seal
+	Value  common.Hash         `json:"value"` // block hash proposed, voted for or sealed
+	Hash   common.Hash         `json:"hash"`  // hash of the data signed
+
+	header bool // proposer seal of a block header, only its height is known
+}
+
+// cmp compares the position of w in the consensus with the one of o.
+func (w *tendermintWatermark) cmp(o *tendermintWatermark) int {
+	if c := w.Height.Cmp(o.Height); c != 0 {
+		return c
+	}
+	if w.Round != o.Round {
+		if w.Round < o.Round {
+			return -1
+		}
+		return 1
+	}
+	if c := w.Step.Cmp(o.Step); c != 0 {
+		return c
+	}
+	if w.Seal != o.Seal {
+		if w.Seal {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// tendermintWatermarks keeps for each account the latest tendermint consensus data
+// it signed, so that clef refuses to sign any data at or below it whatever the node
+// asks for, even if the node lost its own watermark. The block headers can be
+// sealed at any time of their height, they are only refused below the height of
+// the watermark. Signing the same data again is allowed, so that a restarted node
+// can resume its round. The watermarks are reset by deleting their storage.
+type tendermintWatermarks struct {
+	storage storage.Storage
+	mu      sync.Mutex
+}
+
+// read loads the watermark of account, nil if the account never signed.
+func (w *tendermintWatermarks) read(account common.Address) (*tendermintWatermark, error) {
+	data, err := w.storage.Get(account.Hex())
+	if err == storage.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	watermark := new(tendermintWatermark)
+	if err := json.Unmarshal([]byte(data), watermark); err != nil || watermark.Height == nil {
+		// Signing must not silently restart from scratch.
+		return nil, fmt.Errorf("invalid tendermint signing watermark of %v", account)
+	}
+	return watermark, nil
+}
+
+// raise checks that next is above the watermark of account and records it as the
+// new watermark before it is signed.
+func (w *tendermintWatermarks) raise(account common.Address, next *tendermintWatermark) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current, err := w.read(account)
+	if err != nil {
+		return err
+	}
+	if next.header {
+		if current != nil && next.Height.Cmp(current.Height) < 0 {
+			log.Warn("Refusing to seal a header below the signing watermark", "account", account, "watermark", current.Height, "height", next.Height)
+			return errTendermintWatermark
+		}
+		return nil
+	}
+	if current != nil {
+		switch c := next.cmp(current); {
+		case c == 0 && next.Hash == current.Hash:
+			return nil
+		case c <= 0:
+			log.Warn("Refusing to sign tendermint data at or below the signing watermark", "account", account,
+				"watermark", fmt.Sprintf("%v/%v/%v", current.Height, current.Round, current.Step),
+				"data", fmt.Sprintf("%v/%v/%v", next.Height, next.Round, next.Step))
+			return errTendermintWatermark
+		case current.Seal && next.Height.Cmp(current.Height) == 0 && next.Round == current.Round && next.Value != current.Value:
+			// The precommit must be for the block of its committed seal.
+			log.Warn("Refusing to sign a precommit conflicting with its committed seal", "account", account, "seal", current.Value, "value", next.Value)
+			return errTendermintWatermark
+		}
+	}
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	w.storage.Put(account.Hex(), string(data))
+	return nil
+}
+
+// tendermintData decodes the tendermint consensus data to sign with account: a
+// consensus message without its signature, a committed seal or a block header
+// to seal. It returns the messages describing the data to the user, the hash to
+// sign, the data not being signed blindly, and the position of the data in the
+// consensus.
+func tendermintData(account common.Address, data []byte) ([]*NameValueType, []byte, *tendermintWatermark, error) {
+	msg := new(tendermintCore.Message)
+	if err := msg.FromPayload(data); err == nil {
+		return tendermintMessage(account, msg, data)
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(data, header); err == nil {
+		if header.Coinbase != account {
+			return nil, nil, nil, errTendermintSigner
+		}
+		if header.Number == nil || header.Number.Sign() <= 0 {
+			return nil, nil, nil, errTendermintData
+		}
+		messages := []*NameValueType{
+			{Name: "Tendermint block proposal", Typ: "description", Value: ""},
+			{Name: "Height", Typ: "uint64", Value: header.Number.String()},
+			{Name: "Round", Typ: "uint64", Value: fmt.Sprint(header.Round)},
+			{Name: "Seal hash", Typ: "hash", Value: types.SigHash(header).Hex()},
+		}
+		sighash := crypto.Keccak256(types.SigHash(header).Bytes())
+		return messages, sighash, &tendermintWatermark{Height: header.Number, header: true}, nil
+	}
+	hash, round, height, err := tendermintCore.ParseCommittedSeal(data)
+	if err != nil {
+		return nil, nil, nil, errTendermintData
+	}
+	messages := []*NameValueType{
+		{Name: "Tendermint committed seal", Typ: "description", Value: ""},
+		{Name: "Height", Typ: "uint64", Value: height.String()},
+		{Name: "Round", Typ: "uint64", Value: fmt.Sprint(round)},
+		{Name: "Block hash", Typ: "hash", Value: hash.Hex()},
+	}
+	sighash := crypto.Keccak256(data)
+	watermark := &tendermintWatermark{
+		Height: height,
+		Round:  round,
+		Step:   tendermintCore.PrecommitStep,
+		Seal:   true,
+		Value:  hash,
+		Hash:   common.BytesToHash(sighash),
+	}
+	return messages, sighash, watermark, nil
+}
+
+// tendermintMessage checks a consensus message to sign with account and returns
+// the messages describing it, the hash to sign and its position in the consensus.
+func tendermintMessage(account common.Address, msg *tendermintCore.Message, data []byte) ([]*NameValueType, []byte, *tendermintWatermark, error) {
+	if msg.Address != account {
+		return nil, nil, nil, errTendermintSigner
+	}
+	if len(msg.Signature) > 0 {
+		return nil, nil, nil, errTendermintData
+	}
+	height, err := msg.Height()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	round, err := msg.Round()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if height == nil || height.Sign() <= 0 || round < 0 || round > tendermintCore.MaxRound {
+		return nil, nil, nil, errTendermintData
+	}
+	step, err := msg.Step()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	value, err := msg.Value()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	messages := []*NameValueType{
+		{Name: "Tendermint consensus message", Typ: "description", Value: ""},
+		{Name: "Step", Typ: "string", Value: step.String()},
+		{Name: "Height", Typ: "uint64", Value: height.String()},
+		{Name: "Round", Typ: "uint64", Value: fmt.Sprint(round)},
+		{Name: "Value", Typ: "hash", Value: value.Hex()},
+	}
+	sighash := crypto.Keccak256(data)
+	watermark := &tendermintWatermark{
+		Height: height,
+		Round:  round,
+		Step:   step,
+		Value:  value,
+		Hash:   common.BytesToHash(sighash),
+	}
+	return messages, sighash, watermark, nil
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/clearmatics/autonity/common"
+	tendermintCore "github.com/clearmatics/autonity/consensus/tendermint/core"
+	"github.com/clearmatics/autonity/core/types"
+	"github.com/clearmatics/autonity/crypto"
+	"github.com/clearmatics/autonity/rlp"
+	"github.com/clearmatics/autonity/signer/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTendermintData(t *testing.T) {
+	account, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	vote, err := rlp.EncodeToBytes(&tendermintCore.Vote{Round: 2, Height: big.NewInt(5), ProposedBlockHash: common.HexToHash("0xaa")})
+	require.NoError(t, err)
+	prevote := func(signer common.Address) []byte {
+		payload, err := rlp.EncodeToBytes(&tendermintCore.Message{Code: 1, Msg: vote, Address: signer})
+		require.NoError(t, err)
+		return payload
+	}
+
+	t.Run("consensus message", func(t *testing.T) {
+		messages, hash, _, err := tendermintData(account, prevote(account))
+		require.NoError(t, err)
+		assert.Equal(t, crypto.Keccak256(prevote(account)), hash)
+		assert.Equal(t, "prevote", messages[1].Value)
+		assert.Equal(t, "5", messages[2].Value)
+
+		_, _, _, err = tendermintData(account, prevote(other))
+		assert.Equal(t, errTendermintSigner, err)
+	})
+
+	t.Run("committed seal", func(t *testing.T) {
+		seal := tendermintCore.PrepareCommittedSeal(common.HexToHash("0xaa"), 2, big.NewInt(5))
+		messages, hash, _, err := tendermintData(account, seal)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.Keccak256(seal), hash)
+		assert.Equal(t, "5", messages[1].Value)
+
+		seal = tendermintCore.PrepareCommittedSeal(common.HexToHash("0xaa"), tendermintCore.MaxRound+1, big.NewInt(5))
+		_, _, _, err = tendermintData(account, seal)
+		assert.Equal(t, errTendermintData, err)
+	})
+
+	t.Run("block header", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(5), Coinbase: account, Difficulty: big.NewInt(1)}
+		data, err := rlp.EncodeToBytes(header)
+		require.NoError(t, err)
+		_, hash, _, err := tendermintData(account, data)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.Keccak256(types.SigHash(header).Bytes()), hash)
+
+		_, _, _, err = tendermintData(other, data)
+		assert.Equal(t, errTendermintSigner, err)
+	})
+
+	t.Run("blind data", func(t *testing.T) {
+		_, _, _, err := tendermintData(account, crypto.Keccak256([]byte("data")))
+		assert.Equal(t, errTendermintData, err)
+
+		// The height of a committed seal has no leading zeros and fits in 32 bytes.
+		hash := crypto.Keccak256([]byte("data"))
+		_, _, _, err = tendermintData(account, append(make([]byte, 9), hash...))
+		assert.Equal(t, errTendermintData, err)
+		_, _, _, err = tendermintData(account, append(append(make([]byte, 8), bytes.Repeat([]byte{0x01}, 33)...), hash...))
+		assert.Equal(t, errTendermintData, err)
+	})
+}
+
+func TestTendermintWatermarks(t *testing.T) {
+	account, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	hash := common.HexToHash("0xaa")
+
+	message := func(signer common.Address, code uint64, round int64, height int64, value common.Hash) []byte {
+		vote, err := rlp.EncodeToBytes(&tendermintCore.Vote{Round: round, Height: big.NewInt(height), ProposedBlockHash: value})
+		require.NoError(t, err)
+		payload, err := rlp.EncodeToBytes(&tendermintCore.Message{Code: code, Msg: vote, Address: signer})
+		require.NoError(t, err)
+		return payload
+	}
+	header := func(height int64) []byte {
+		data, err := rlp.EncodeToBytes(&types.Header{Number: big.NewInt(height), Coinbase: account, Difficulty: big.NewInt(1)})
+		require.NoError(t, err)
+		return data
+	}
+	watermarks := &tendermintWatermarks{storage: storage.NewEphemeralStorage()}
+	raise := func(signer common.Address, data []byte) error {
+		_, _, watermark, err := tendermintData(signer, data)
+		require.NoError(t, err)
+		return watermarks.raise(signer, watermark)
+	}
+
+	require.NoError(t, raise(account, header(5)))
+	require.NoError(t, raise(account, message(account, 1, 0, 5, hash)))
+	require.NoError(t, raise(account, tendermintCore.PrepareCommittedSeal(hash, 0, big.NewInt(5))))
+	// The precommit must be for the block of its committed seal.
+	assert.Equal(t, errTendermintWatermark, raise(account, message(account, 2, 0, 5, common.HexToHash("0xbb"))))
+	require.NoError(t, raise(account, message(account, 2, 0, 5, hash)))
+	// The same data can be signed again.
+	require.NoError(t, raise(account, message(account, 2, 0, 5, hash)))
+
+	// Anything at or below the watermark is refused.
+	assert.Equal(t, errTendermintWatermark, raise(account, message(account, 2, 0, 5, common.Hash{})))
+	assert.Equal(t, errTendermintWatermark, raise(account, message(account, 1, 0, 5, common.Hash{})))
+	assert.Equal(t, errTendermintWatermark, raise(account, tendermintCore.PrepareCommittedSeal(common.Hash{}, 0, big.NewInt(5))))
+	assert.Equal(t, errTendermintWatermark, raise(account, message(account, 1, 3, 4, hash)))
+	assert.Equal(t, errTendermintWatermark, raise(account, header(4)))
+
+	// Headers are sealed at any time of the height of the watermark.
+	require.NoError(t, raise(account, header(5)))
+	require.NoError(t, raise(account, message(account, 1, 1, 5, common.Hash{})))
+	require.NoError(t, raise(account, message(account, 1, 0, 6, hash)))
+
+	// The watermarks are kept per account.
+	require.NoError(t, raise(other, message(other, 1, 0, 5, hash)))
+
+	// A watermark which can't be read refuses everything.
+	watermarks.storage.Put(account.Hex(), "invalid")
+	assert.Error(t, raise(account, message(account, 1, 0, 7, hash)))
+}